@@ -10,6 +10,7 @@ import (
 	"github.com/werf/werf/cmd/werf/common"
 	"github.com/werf/werf/pkg/build"
 	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/docker_registry"
 	"github.com/werf/werf/pkg/git_repo"
 	"github.com/werf/werf/pkg/git_repo/gitdata"
 	"github.com/werf/werf/pkg/giterminism_manager"
@@ -86,6 +87,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupIntrospectAfterError(&commonCmdData, cmd)
 	common.SetupIntrospectBeforeError(&commonCmdData, cmd)
@@ -101,6 +103,10 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	common.SetupSaveBuildReport(&commonCmdData, cmd)
 	common.SetupBuildReportPath(&commonCmdData, cmd)
+	common.SetupSignKey(&commonCmdData, cmd)
+	common.SetupProvenance(&commonCmdData, cmd)
+	common.SetupSBOM(&commonCmdData, cmd)
+	common.SetupSyncCacheMounts(&commonCmdData, cmd)
 	common.SetupDeprecatedReportPath(&commonCmdData, cmd)
 	common.SetupDeprecatedReportFormat(&commonCmdData, cmd)
 
@@ -159,6 +165,7 @@ func runMain(ctx context.Context, imagesToProcess build.ImagesToProcess) error {
 	if err := common.DockerRegistryInit(ctx, &commonCmdData); err != nil {
 		return err
 	}
+	defer docker_registry.LogRateLimitStats(ctx)
 
 	defer func() {
 		if err := common.RunAutoHostCleanup(ctx, &commonCmdData, containerBackend); err != nil {
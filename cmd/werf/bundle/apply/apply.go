@@ -72,6 +72,8 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
+	common.SetupVerifyKey(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -181,6 +183,14 @@ func runApply(ctx context.Context) error {
 		return fmt.Errorf("unable to pull bundle: %w", err)
 	}
 
+	if bundleImages, err := bundles.GetBundleImages(bundleTmpDir); err != nil {
+		return err
+	} else if pinnedReferences, err := common.VerifyImageSignatures(ctx, &commonCmdData, bundleImages); err != nil {
+		return err
+	} else if err := bundles.PinBundleImages(bundleTmpDir, pinnedReferences); err != nil {
+		return err
+	}
+
 	var lockManager *lock_manager.LockManager
 	if m, err := lock_manager.NewLockManager(namespace); err != nil {
 		return fmt.Errorf("unable to create lock manager: %w", err)
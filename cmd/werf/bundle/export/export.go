@@ -109,6 +109,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -369,7 +370,10 @@ func runExport(ctx context.Context, imagesToProcess build.ImagesToProcess) error
 
 	helm_v3.Settings.Debug = *commonCmdData.LogDebug
 
-	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{DisableSecretsDecryption: *commonCmdData.IgnoreSecretKey})
+	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{
+		DisableSecretsDecryption: *commonCmdData.IgnoreSecretKey,
+		BackendConfig:            werfConfig.Meta.Secrets,
+	})
 
 	loader.GlobalLoadOptions = &loader.LoadOptions{
 		ChartExtender: wc,
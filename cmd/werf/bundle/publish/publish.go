@@ -94,6 +94,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -120,6 +121,10 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	common.SetupSaveBuildReport(&commonCmdData, cmd)
 	common.SetupBuildReportPath(&commonCmdData, cmd)
+	common.SetupSignKey(&commonCmdData, cmd)
+	common.SetupProvenance(&commonCmdData, cmd)
+	common.SetupSBOM(&commonCmdData, cmd)
+	common.SetupSyncCacheMounts(&commonCmdData, cmd)
 	common.SetupDeprecatedReportPath(&commonCmdData, cmd)
 	common.SetupDeprecatedReportFormat(&commonCmdData, cmd)
 
@@ -344,6 +349,7 @@ func runPublish(ctx context.Context, imagesToProcess build.ImagesToProcess) erro
 
 	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{
 		DisableSecretsDecryption: *commonCmdData.IgnoreSecretKey,
+		BackendConfig:            werfConfig.Meta.Secrets,
 	})
 
 	// FIXME(1.3): compatibility mode with older 1.2 versions, which do not require WERF_SECRET_KEY in the 'werf bundle publish' command
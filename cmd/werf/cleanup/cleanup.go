@@ -11,6 +11,7 @@ import (
 	"github.com/werf/logboek"
 	"github.com/werf/werf/cmd/werf/common"
 	"github.com/werf/werf/pkg/cleaning"
+	"github.com/werf/werf/pkg/docker_registry"
 	"github.com/werf/werf/pkg/git_repo"
 	"github.com/werf/werf/pkg/git_repo/gitdata"
 	"github.com/werf/werf/pkg/image"
@@ -26,6 +27,8 @@ var commonCmdData common.CmdData
 
 var cmdData struct {
 	ScanContextOnly string
+	SavePlanFile    string
+	PlanFile        string
 }
 
 func NewCmd(ctx context.Context) *cobra.Command {
@@ -35,7 +38,11 @@ func NewCmd(ctx context.Context) *cobra.Command {
 		DisableFlagsInUseLine: true,
 		Short:                 "Cleanup project images in the container registry",
 		Long:                  common.GetLongCommandDescription(GetCleanupDocs().Long),
-		Example:               `  $ werf cleanup --repo registry.mydomain.com/myproject/werf`,
+		Example: `  $ werf cleanup --repo registry.mydomain.com/myproject/werf
+
+  # Prepare the cleanup plan, review it and apply it later
+  $ werf cleanup --repo registry.mydomain.com/myproject/werf --dry-run --save-plan-file cleanup-plan.json
+  $ werf cleanup --repo registry.mydomain.com/myproject/werf --plan-file cleanup-plan.json`,
 		Annotations: map[string]string{
 			common.DocsLongMD: GetCleanupDocs().LongMD,
 		},
@@ -77,6 +84,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupScanContextNamespaceOnly(&commonCmdData, cmd)
 	common.SetupDryRun(&commonCmdData, cmd)
@@ -102,10 +110,17 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.PersistentFlags().StringVarP(&cmdData.ScanContextOnly, "scan-context-only", "", os.Getenv("WERF_SCAN_CONTEXT_ONLY"), "Scan for used images only in the specified kube context, scan all contexts from kube config otherwise (default false or $WERF_SCAN_CONTEXT_ONLY)")
 	cmd.PersistentFlags().StringVarP(&cmdData.ScanContextOnly, "kube-context", "", os.Getenv("WERF_SCAN_CONTEXT_ONLY"), "Scan for used images only in the specified kube context, scan all contexts from kube config otherwise (default false or $WERF_SCAN_CONTEXT_ONLY)")
 
+	cmd.Flags().StringVarP(&cmdData.SavePlanFile, "save-plan-file", "", os.Getenv("WERF_SAVE_PLAN_FILE"), "Save the JSON plan of deleted (or to be deleted in the dry run mode) stages, custom tags and metadata with the reasons to the specified file (default $WERF_SAVE_PLAN_FILE)")
+	cmd.Flags().StringVarP(&cmdData.PlanFile, "plan-file", "", os.Getenv("WERF_PLAN_FILE"), "Apply the plan previously saved with --save-plan-file: delete only the objects from the plan, fail if any of them is not planned for deletion anymore (default $WERF_PLAN_FILE)")
+
 	return cmd
 }
 
 func runCleanup(ctx context.Context) error {
+	if cmdData.PlanFile != "" && cmdData.SavePlanFile != "" {
+		return fmt.Errorf("--plan-file and --save-plan-file options cannot be used together")
+	}
+
 	if err := werf.Init(*commonCmdData.TmpDir, *commonCmdData.HomeDir); err != nil {
 		return fmt.Errorf("initialization error: %w", err)
 	}
@@ -140,6 +155,7 @@ func runCleanup(ctx context.Context) error {
 	if err := common.DockerRegistryInit(ctx, &commonCmdData); err != nil {
 		return err
 	}
+	defer docker_registry.LogRateLimitStats(ctx)
 
 	defer func() {
 		if err := common.RunAutoHostCleanup(ctx, &commonCmdData, containerBackend); err != nil {
@@ -258,6 +274,8 @@ It is worth noting that auto-cleaning is enabled by default, and manual use is u
 		ConfigMetaCleanup:                       werfConfig.Meta.Cleanup,
 		KeepStagesBuiltWithinLastNHours:         *commonCmdData.KeepStagesBuiltWithinLastNHours,
 		DryRun:                                  *commonCmdData.DryRun,
+		SavePlanFile:                            cmdData.SavePlanFile,
+		PlanFile:                                cmdData.PlanFile,
 	}
 
 	logboek.LogOptionalLn()
@@ -65,9 +65,15 @@ type CmdData struct {
 	Parallel           *bool
 	ParallelTasksLimit *int64
 
+	SynchronizationToken         *string
+	SynchronizationTLSClientCert *string
+	SynchronizationTLSClientKey  *string
+	SynchronizationCACert        *string
+
 	DockerConfig                    *string
 	InsecureRegistry                *bool
 	SkipTlsVerifyRegistry           *bool
+	RegistryMaxConcurrentRequests   *int64
 	InsecureHelmDependencies        *bool
 	DryRun                          *bool
 	KeepStagesBuiltWithinLastNHours *uint64
@@ -98,6 +104,14 @@ type CmdData struct {
 	SaveBuildReport *bool
 	BuildReportPath *string
 
+	SignKey    *string
+	VerifyKeys *[]string
+	Provenance *bool
+	SBOM       *string
+
+	SyncCacheMounts        *bool
+	SyncCacheMountsMaxSize *string
+
 	SaveDeployReport *bool
 	UseDeployReport  *bool
 	DeployReportPath *string
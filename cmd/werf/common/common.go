@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 
@@ -23,6 +24,8 @@ import (
 	"github.com/werf/werf/pkg/git_repo"
 	"github.com/werf/werf/pkg/giterminism_manager"
 	"github.com/werf/werf/pkg/logging"
+	"github.com/werf/werf/pkg/sbom"
+	"github.com/werf/werf/pkg/signing"
 	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/true_git"
 	"github.com/werf/werf/pkg/util"
@@ -214,7 +217,7 @@ func SetupSaveBuildReport(cmdData *CmdData, cmd *cobra.Command) {
 
 func SetupBuildReportPath(cmdData *CmdData, cmd *cobra.Command) {
 	cmdData.BuildReportPath = new(string)
-	cmd.Flags().StringVarP(cmdData.BuildReportPath, "build-report-path", "", os.Getenv("WERF_BUILD_REPORT_PATH"), fmt.Sprintf("Change build report path and format (by default $WERF_BUILD_REPORT_PATH or %q if not set). Extension must be either .json for JSON format or .env for env-file format. If extension not specified, then .json is used", DefaultBuildReportPathJSON))
+	cmd.Flags().StringVarP(cmdData.BuildReportPath, "build-report-path", "", os.Getenv("WERF_BUILD_REPORT_PATH"), fmt.Sprintf("Change build report path and format (by default $WERF_BUILD_REPORT_PATH or %q if not set). Extension must be either .json for JSON format, .env for env-file format, .xml for JUnit XML format or .md for Markdown summary. If extension not specified, then .json is used", DefaultBuildReportPathJSON))
 }
 
 func GetSaveBuildReport(cmdData *CmdData) bool {
@@ -236,13 +239,141 @@ func GetBuildReportPathAndFormat(cmdData *CmdData) (string, build.ReportFormat,
 		return *cmdData.BuildReportPath, build.ReportJSON, nil
 	case ".env":
 		return *cmdData.BuildReportPath, build.ReportEnvFile, nil
+	case ".xml":
+		return *cmdData.BuildReportPath, build.ReportJUnit, nil
+	case ".md":
+		return *cmdData.BuildReportPath, build.ReportMarkdown, nil
 	case "":
 		return *cmdData.BuildReportPath + ".json", build.ReportJSON, nil
 	default:
-		return "", "", fmt.Errorf("invalid --build-report-path %q: extension must be either .json or .env or unspecified", *cmdData.BuildReportPath)
+		return "", "", fmt.Errorf("invalid --build-report-path %q: extension must be either .json, .env, .xml, .md or unspecified", *cmdData.BuildReportPath)
 	}
 }
 
+func SetupSignKey(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.SignKey = new(string)
+	cmd.Flags().StringVarP(cmdData.SignKey, "sign-key", "", os.Getenv("WERF_SIGN_KEY"), `Sign the final images in the repo with the private key (cosign-compatible signatures). Password for the encrypted key can be set with $WERF_SIGN_KEY_PASSWORD or $COSIGN_PASSWORD.
+Default $WERF_SIGN_KEY`)
+}
+
+func SetupVerifyKey(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.VerifyKeys = new([]string)
+	cmd.Flags().StringArrayVarP(cmdData.VerifyKeys, "verify-key", "", []string{}, `Refuse to deploy images without a valid signature made with one of the public keys (can specify multiple).
+Also, can be specified with $WERF_VERIFY_KEY_* (e.g. $WERF_VERIFY_KEY_1=cosign.pub, $WERF_VERIFY_KEY_2=...)`)
+}
+
+func SetupProvenance(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.Provenance = new(bool)
+	cmd.Flags().BoolVarP(cmdData.Provenance, "provenance", "", util.GetBoolEnvironmentDefaultFalse("WERF_PROVENANCE"), `Attach SLSA provenance (in-toto statement) to the final images in the repo and include it into the build report.
+Default $WERF_PROVENANCE or false`)
+}
+
+func GetProvenance(cmdData *CmdData) bool {
+	return cmdData.Provenance != nil && *cmdData.Provenance
+}
+
+func SetupSBOM(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.SBOM = new(string)
+	cmd.Flags().StringVarP(cmdData.SBOM, "sbom", "", os.Getenv("WERF_SBOM"), `Generate SBOM listing OS packages and language dependencies of the final images, attach it to the images in the repo and include it into the build report: spdx or cyclonedx.
+Default $WERF_SBOM or SBOM is not generated`)
+}
+
+func GetSBOMFormat(cmdData *CmdData) (sbom.Format, error) {
+	if cmdData.SBOM == nil || *cmdData.SBOM == "" {
+		return "", nil
+	}
+
+	format, err := sbom.ParseFormat(*cmdData.SBOM)
+	if err != nil {
+		return "", fmt.Errorf("bad --sbom value: %w", err)
+	}
+	return format, nil
+}
+
+func SetupSyncCacheMounts(cmdData *CmdData, cmd *cobra.Command) {
+	cmdData.SyncCacheMounts = new(bool)
+	cmd.Flags().BoolVarP(cmdData.SyncCacheMounts, "sync-cache-mounts", "", util.GetBoolEnvironmentDefaultFalse("WERF_SYNC_CACHE_MOUNTS"), `Import the RUN --mount=type=cache volumes of the staged Dockerfile images from the repo before the build and export them to the repo after the build to reuse the cache across runners.
+Default $WERF_SYNC_CACHE_MOUNTS or false`)
+
+	defaultMaxSize := "1GiB"
+	if envValue := os.Getenv("WERF_SYNC_CACHE_MOUNTS_MAX_SIZE"); envValue != "" {
+		defaultMaxSize = envValue
+	}
+
+	cmdData.SyncCacheMountsMaxSize = new(string)
+	cmd.Flags().StringVarP(cmdData.SyncCacheMountsMaxSize, "sync-cache-mounts-max-size", "", defaultMaxSize, `Do not export the cache mounts whose archive is larger than the size (e.g. 500MiB, 2GB), 0 disables the limit.
+Default $WERF_SYNC_CACHE_MOUNTS_MAX_SIZE or 1GiB`)
+}
+
+func GetSyncCacheMounts(cmdData *CmdData) bool {
+	return cmdData.SyncCacheMounts != nil && *cmdData.SyncCacheMounts
+}
+
+func GetSyncCacheMountsMaxSize(cmdData *CmdData) (uint64, error) {
+	if cmdData.SyncCacheMountsMaxSize == nil || *cmdData.SyncCacheMountsMaxSize == "" {
+		return 0, nil
+	}
+
+	size, err := humanize.ParseBytes(*cmdData.SyncCacheMountsMaxSize)
+	if err != nil {
+		return 0, fmt.Errorf("bad --sync-cache-mounts-max-size value %q: %w", *cmdData.SyncCacheMountsMaxSize, err)
+	}
+	return size, nil
+}
+
+func GetSigner(cmdData *CmdData) (*signing.Signer, error) {
+	if cmdData.SignKey == nil || *cmdData.SignKey == "" {
+		return nil, nil
+	}
+
+	if cmdData.Repo == nil || cmdData.Repo.Address == nil || *cmdData.Repo.Address == "" || *cmdData.Repo.Address == storage.LocalStorageAddress {
+		return nil, fmt.Errorf("--sign-key requires --repo: the images are signed in the repo")
+	}
+
+	return signing.LoadSigner(*cmdData.SignKey)
+}
+
+func GetVerifiers(cmdData *CmdData) ([]*signing.Verifier, error) {
+	var keyPaths []string
+	if cmdData.VerifyKeys != nil {
+		keyPaths = append(util.PredefinedValuesByEnvNamePrefix("WERF_VERIFY_KEY_"), *cmdData.VerifyKeys...)
+	}
+
+	return signing.LoadVerifiers(keyPaths)
+}
+
+// VerifyImageSignatures refuses images without a valid signature made with one of the keys specified by --verify-key.
+// It returns the references pinned to the verified digests by the original references,
+// or nil if the verification is not enabled.
+func VerifyImageSignatures(ctx context.Context, cmdData *CmdData, references []string) (map[string]string, error) {
+	verifiers, err := GetVerifiers(cmdData)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(verifiers) == 0 || len(references) == 0 {
+		return nil, nil
+	}
+
+	pinnedReferences := make(map[string]string, len(references))
+	if err := logboek.Context(ctx).Default().LogProcess("Verifying image signatures").DoError(func() error {
+		for _, reference := range references {
+			pinnedReference, err := signing.VerifyImage(ctx, docker_registry.API(), verifiers, reference)
+			if err != nil {
+				return fmt.Errorf("image %s verification failed: %w", reference, err)
+			}
+			pinnedReferences[reference] = pinnedReference
+			logboek.Context(ctx).Default().LogF("Image %s: signature verified\n", pinnedReference)
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return pinnedReferences, nil
+}
+
 func SetupSaveDeployReport(cmdData *CmdData, cmd *cobra.Command) {
 	cmdData.SaveDeployReport = new(bool)
 	cmd.Flags().BoolVarP(cmdData.SaveDeployReport, "save-deploy-report", "", util.GetBoolEnvironmentDefaultFalse("WERF_SAVE_DEPLOY_REPORT"), fmt.Sprintf("Save deploy report (by default $WERF_SAVE_DEPLOY_REPORT or %t). Its path and format configured with --deploy-report-path", DefaultSaveDeployReport))
@@ -406,6 +537,7 @@ Also, can be specified with $WERF_CACHE_REPO_* (e.g. $WERF_CACHE_REPO_1=..., $WE
 func SetupRepoOptions(cmdData *CmdData, cmd *cobra.Command, opts RepoDataOptions) {
 	SetupInsecureRegistry(cmdData, cmd)
 	SetupSkipTlsVerifyRegistry(cmdData, cmd)
+	SetupRegistryMaxConcurrentRequests(cmdData, cmd)
 	SetupRepo(cmdData, cmd, opts)
 }
 
@@ -544,6 +676,21 @@ func SetupSkipTlsVerifyRegistry(cmdData *CmdData, cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(cmdData.SkipTlsVerifyRegistry, "skip-tls-verify-registry", "", util.GetBoolEnvironmentDefaultFalse("WERF_SKIP_TLS_VERIFY_REGISTRY"), "Skip TLS certificate validation when accessing a registry (default $WERF_SKIP_TLS_VERIFY_REGISTRY)")
 }
 
+func SetupRegistryMaxConcurrentRequests(cmdData *CmdData, cmd *cobra.Command) {
+	if cmdData.RegistryMaxConcurrentRequests != nil {
+		return
+	}
+
+	var defaultValue int64
+	if v := GetIntEnvVarStrict("WERF_REGISTRY_MAX_CONCURRENT_REQUESTS"); v != nil {
+		defaultValue = *v
+	}
+
+	cmdData.RegistryMaxConcurrentRequests = new(int64)
+	cmd.Flags().Int64VarP(cmdData.RegistryMaxConcurrentRequests, "registry-max-concurrent-requests", "", defaultValue, `Limit the number of concurrent requests to each container registry (default $WERF_REGISTRY_MAX_CONCURRENT_REQUESTS or no limit).
+werf lowers the limit automatically when the registry throttles requests`)
+}
+
 func SetupDryRun(cmdData *CmdData, cmd *cobra.Command) {
 	cmdData.DryRun = new(bool)
 	cmd.Flags().BoolVarP(cmdData.DryRun, "dry-run", "", util.GetBoolEnvironmentDefaultFalse("WERF_DRY_RUN"), "Indicate what the command would do without actually doing that (default $WERF_DRY_RUN)")
@@ -1318,7 +1465,15 @@ func ProcessLogTerminalWidth(cmdData *CmdData) error {
 }
 
 func DockerRegistryInit(ctx context.Context, cmdData *CmdData) error {
-	return docker_registry.Init(ctx, *cmdData.InsecureRegistry, *cmdData.SkipTlsVerifyRegistry)
+	var maxConcurrentRequests int
+	if cmdData.RegistryMaxConcurrentRequests != nil {
+		if *cmdData.RegistryMaxConcurrentRequests < 0 {
+			return fmt.Errorf("--registry-max-concurrent-requests value (%d) can not be negative", *cmdData.RegistryMaxConcurrentRequests)
+		}
+		maxConcurrentRequests = int(*cmdData.RegistryMaxConcurrentRequests)
+	}
+
+	return docker_registry.Init(ctx, *cmdData.InsecureRegistry, *cmdData.SkipTlsVerifyRegistry, maxConcurrentRequests)
 }
 
 func ValidateMinimumNArgs(minArgs int, args []string, cmd *cobra.Command) error {
@@ -37,6 +37,13 @@ func GetConveyorOptions(ctx context.Context, commonCmdData *CmdData, imagesToPro
 	}
 
 	conveyorOptions.DeferBuildLog = GetDeferredBuildLog(ctx, commonCmdData)
+	conveyorOptions.SyncCacheMounts = GetSyncCacheMounts(commonCmdData)
+
+	syncCacheMountsMaxSize, err := GetSyncCacheMountsMaxSize(commonCmdData)
+	if err != nil {
+		return build.ConveyorOptions{}, err
+	}
+	conveyorOptions.SyncCacheMountsMaxSize = syncCacheMountsMaxSize
 
 	return conveyorOptions, nil
 }
@@ -98,6 +105,17 @@ func GetBuildOptions(ctx context.Context, commonCmdData *CmdData, werfConfig *co
 			IntrospectBeforeError: *commonCmdData.IntrospectBeforeError,
 		},
 		IntrospectOptions: introspectOptions,
+		Provenance:        GetProvenance(commonCmdData),
+	}
+
+	buildOptions.Signer, err = GetSigner(commonCmdData)
+	if err != nil {
+		return buildOptions, err
+	}
+
+	buildOptions.SBOMFormat, err = GetSBOMFormat(commonCmdData)
+	if err != nil {
+		return buildOptions, err
 	}
 
 	usedNewBuildReportOption := (commonCmdData.SaveBuildReport != nil && *commonCmdData.SaveBuildReport == true) || (commonCmdData.BuildReportPath != nil && *commonCmdData.BuildReportPath != "")
@@ -15,10 +15,13 @@ const (
 	DisableOptionsInUseLineAnno string = "disableOptionsInUseLine"
 
 	DocsLongMD string = "docsLongMD"
+	// DocsRunnableCommandGroupAnno marks the command having subcommands, which has its own page in the docs
+	DocsRunnableCommandGroupAnno string = "docsRunnableCommandGroup"
 
-	WerfDebugAnsibleArgs Env = "WERF_DEBUG_ANSIBLE_ARGS"
-	WerfSecretKey        Env = "WERF_SECRET_KEY"
-	WerfOldSecretKey     Env = "WERF_OLD_SECRET_KEY"
+	WerfDebugAnsibleArgs   Env = "WERF_DEBUG_ANSIBLE_ARGS"
+	WerfSecretKey          Env = "WERF_SECRET_KEY"
+	WerfOldSecretKey       Env = "WERF_OLD_SECRET_KEY"
+	WerfRecipientSecretKey Env = "WERF_RECIPIENT_SECRET_KEY"
 )
 
 var envDescription = map[Env]string{
@@ -28,7 +31,8 @@ var envDescription = map[Env]string{
 Secret key also can be defined in files:
 * ~/.werf/global_secret_key (globally),
 * .werf_secret_key (per project)`,
-	WerfOldSecretKey: "Use specified old secret key to rotate secrets",
+	WerfOldSecretKey:       "Use specified old secret key to rotate secrets",
+	WerfRecipientSecretKey: "Use specified secret key as a new recipient of the secret values file",
 }
 
 func EnvsDescription(envs ...Env) string {
@@ -12,6 +12,7 @@ import (
 	"github.com/werf/werf/pkg/container_backend"
 	"github.com/werf/werf/pkg/docker_registry"
 	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/util"
 )
 
 func CreateDockerRegistry(addr string, insecureRegistry, skipTlsVerifyRegistry bool) (docker_registry.Interface, error) {
@@ -56,6 +57,16 @@ func (repoData *RepoData) CreateStagesStorage(ctx context.Context, containerBack
 
 	if addr == storage.LocalStorageAddress {
 		return storage.NewLocalStagesStorage(containerBackend), nil
+	} else if storage.IsOCILayoutStorageAddress(addr) {
+		// The registry is only used to export stages from the layout and to copy stages between the layout and a repo
+		regOpts := repoData.GetDockerRegistryOptions(insecureRegistry, skipTlsVerifyRegistry)
+		dockerRegistry, err := docker_registry.NewDockerRegistry(storage.OCILayoutArchive_ImageRepo, docker_registry.DefaultImplementationName, regOpts)
+		if err != nil {
+			return nil, fmt.Errorf("error creating container registry accessor for %q: %w", addr, err)
+		}
+
+		layoutDir := util.GetAbsoluteFilepath(strings.TrimPrefix(addr, storage.OCILayoutStorageAddressPrefix))
+		return storage.NewOCILayoutStagesStorage(storage.OCILayoutStorageAddressPrefix+layoutDir, containerBackend, dockerRegistry), nil
 	} else {
 		dockerRegistry, err := repoData.CreateDockerRegistry(ctx, insecureRegistry, skipTlsVerifyRegistry)
 		if err != nil {
@@ -68,21 +79,28 @@ func (repoData *RepoData) CreateStagesStorage(ctx context.Context, containerBack
 type RepoData struct {
 	Name string
 
-	Address           *string
-	Implementation    *string // legacy
-	ContainerRegistry *string
-	DockerHubUsername *string
-	DockerHubPassword *string
-	DockerHubToken    *string
-	GitHubToken       *string
-	HarborUsername    *string
-	HarborPassword    *string
-	QuayToken         *string
-	SelectelAccount   *string
-	SelectelVPC       *string
-	SelectelVPCID     *string
-	SelectelUsername  *string
-	SelectelPassword  *string
+	Address             *string
+	Implementation      *string // legacy
+	ContainerRegistry   *string
+	ArtifactoryUsername *string
+	ArtifactoryPassword *string
+	DockerHubUsername   *string
+	DockerHubPassword   *string
+	DockerHubToken      *string
+	GitHubToken         *string
+	GiteaToken          *string
+	HarborUsername      *string
+	HarborPassword      *string
+	NexusUsername       *string
+	NexusPassword       *string
+	NexusURL            *string
+	NexusRepository     *string
+	QuayToken           *string
+	SelectelAccount     *string
+	SelectelVPC         *string
+	SelectelVPCID       *string
+	SelectelUsername    *string
+	SelectelPassword    *string
 
 	RepoDataOptions
 }
@@ -131,12 +149,19 @@ func (d *RepoData) GetDockerRegistryOptions(insecureRegistry, skipTlsVerifyRegis
 	}
 
 	if !d.OnlyAddress {
+		opts.ArtifactoryUsername = *d.ArtifactoryUsername
+		opts.ArtifactoryPassword = *d.ArtifactoryPassword
 		opts.DockerHubUsername = *d.DockerHubUsername
 		opts.DockerHubPassword = *d.DockerHubPassword
 		opts.DockerHubToken = *d.DockerHubToken
 		opts.GitHubToken = *d.GitHubToken
+		opts.GiteaToken = *d.GiteaToken
 		opts.HarborUsername = *d.HarborUsername
 		opts.HarborPassword = *d.HarborPassword
+		opts.NexusUsername = *d.NexusUsername
+		opts.NexusPassword = *d.NexusPassword
+		opts.NexusURL = *d.NexusURL
+		opts.NexusRepository = *d.NexusRepository
 		opts.QuayToken = *d.QuayToken
 		opts.SelectelUsername = *d.SelectelUsername
 		opts.SelectelPassword = *d.SelectelPassword
@@ -164,12 +189,19 @@ func (repoData *RepoData) SetupCmd(cmd *cobra.Command) {
 
 	repoData.SetupImplementationForRepoData(cmd, makeOpt("implementation"), []string{makeEnvVar("IMPLEMENTATION")}) // legacy
 	repoData.SetupContainerRegistryForRepoData(cmd, makeOpt("container-registry"), []string{makeEnvVar("CONTAINER_REGISTRY")})
+	repoData.SetupArtifactoryUsernameForRepoData(cmd, makeOpt("artifactory-username"), []string{makeEnvVar("ARTIFACTORY_USERNAME")})
+	repoData.SetupArtifactoryPasswordForRepoData(cmd, makeOpt("artifactory-password"), []string{makeEnvVar("ARTIFACTORY_PASSWORD")})
 	repoData.SetupDockerHubUsernameForRepoData(cmd, makeOpt("docker-hub-username"), []string{makeEnvVar("DOCKER_HUB_USERNAME")})
 	repoData.SetupDockerHubPasswordForRepoData(cmd, makeOpt("docker-hub-password"), []string{makeEnvVar("DOCKER_HUB_PASSWORD")})
 	repoData.SetupDockerHubTokenForRepoData(cmd, makeOpt("docker-hub-token"), []string{makeEnvVar("DOCKER_HUB_TOKEN")})
 	repoData.SetupGithubTokenForRepoData(cmd, makeOpt("github-token"), []string{makeEnvVar("GITHUB_TOKEN")})
+	repoData.SetupGiteaTokenForRepoData(cmd, makeOpt("gitea-token"), []string{makeEnvVar("GITEA_TOKEN")})
 	repoData.SetupHarborUsernameForRepoData(cmd, makeOpt("harbor-username"), []string{makeEnvVar("HARBOR_USERNAME")})
 	repoData.SetupHarborPasswordForRepoData(cmd, makeOpt("harbor-password"), []string{makeEnvVar("HARBOR_PASSWORD")})
+	repoData.SetupNexusUsernameForRepoData(cmd, makeOpt("nexus-username"), []string{makeEnvVar("NEXUS_USERNAME")})
+	repoData.SetupNexusPasswordForRepoData(cmd, makeOpt("nexus-password"), []string{makeEnvVar("NEXUS_PASSWORD")})
+	repoData.SetupNexusURLForRepoData(cmd, makeOpt("nexus-url"), []string{makeEnvVar("NEXUS_URL")})
+	repoData.SetupNexusRepositoryForRepoData(cmd, makeOpt("nexus-repository"), []string{makeEnvVar("NEXUS_REPOSITORY")})
 	repoData.SetupQuayTokenForRepoData(cmd, makeOpt("quay-token"), []string{makeEnvVar("QUAY_TOKEN")})
 	repoData.SetupSelectelUsernameForRepoData(cmd, makeOpt("selectel-username"), []string{makeEnvVar("SELECTEL_USERNAME")})
 	repoData.SetupSelectelPasswordForRepoData(cmd, makeOpt("selectel-password"), []string{makeEnvVar("SELECTEL_PASSWORD")})
@@ -186,6 +218,12 @@ func MergeRepoData(ctx context.Context, repoDataArr ...*RepoData) *RepoData {
 			value := repoData.GetContainerRegistry(ctx)
 			res.ContainerRegistry = &value
 		}
+		if res.ArtifactoryUsername == nil || *res.ArtifactoryUsername == "" {
+			res.ArtifactoryUsername = repoData.ArtifactoryUsername
+		}
+		if res.ArtifactoryPassword == nil || *res.ArtifactoryPassword == "" {
+			res.ArtifactoryPassword = repoData.ArtifactoryPassword
+		}
 		if res.DockerHubUsername == nil || *res.DockerHubUsername == "" {
 			res.DockerHubUsername = repoData.DockerHubUsername
 		}
@@ -198,12 +236,27 @@ func MergeRepoData(ctx context.Context, repoDataArr ...*RepoData) *RepoData {
 		if res.GitHubToken == nil || *res.GitHubToken == "" {
 			res.GitHubToken = repoData.GitHubToken
 		}
+		if res.GiteaToken == nil || *res.GiteaToken == "" {
+			res.GiteaToken = repoData.GiteaToken
+		}
 		if res.HarborUsername == nil || *res.HarborUsername == "" {
 			res.HarborUsername = repoData.HarborUsername
 		}
 		if res.HarborPassword == nil || *res.HarborPassword == "" {
 			res.HarborPassword = repoData.HarborPassword
 		}
+		if res.NexusUsername == nil || *res.NexusUsername == "" {
+			res.NexusUsername = repoData.NexusUsername
+		}
+		if res.NexusPassword == nil || *res.NexusPassword == "" {
+			res.NexusPassword = repoData.NexusPassword
+		}
+		if res.NexusURL == nil || *res.NexusURL == "" {
+			res.NexusURL = repoData.NexusURL
+		}
+		if res.NexusRepository == nil || *res.NexusRepository == "" {
+			res.NexusRepository = repoData.NexusRepository
+		}
 		if res.QuayToken == nil || *res.QuayToken == "" {
 			res.QuayToken = repoData.QuayToken
 		}
@@ -272,6 +325,32 @@ Default %s or auto mode (detect container registry by repo address).`,
 	)
 }
 
+func (repoData *RepoData) SetupArtifactoryUsernameForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Artifactory username (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.ArtifactoryUsername = new(string)
+	cmd.Flags().StringVarP(
+		repoData.ArtifactoryUsername,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
+func (repoData *RepoData) SetupArtifactoryPasswordForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Artifactory password, API key or identity token (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.ArtifactoryPassword = new(string)
+	cmd.Flags().StringVarP(
+		repoData.ArtifactoryPassword,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
 func (repoData *RepoData) SetupDockerHubUsernameForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
 	usage := fmt.Sprintf("%s Docker Hub username (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
 
@@ -324,6 +403,19 @@ func (repoData *RepoData) SetupGithubTokenForRepoData(cmd *cobra.Command, paramN
 	)
 }
 
+func (repoData *RepoData) SetupGiteaTokenForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Gitea (Forgejo) token (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.GiteaToken = new(string)
+	cmd.Flags().StringVarP(
+		repoData.GiteaToken,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
 func (repoData *RepoData) SetupHarborUsernameForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
 	usage := fmt.Sprintf("%s Harbor username (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
 
@@ -350,6 +442,58 @@ func (repoData *RepoData) SetupHarborPasswordForRepoData(cmd *cobra.Command, par
 	)
 }
 
+func (repoData *RepoData) SetupNexusUsernameForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Nexus username (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.NexusUsername = new(string)
+	cmd.Flags().StringVarP(
+		repoData.NexusUsername,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
+func (repoData *RepoData) SetupNexusPasswordForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Nexus password (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.NexusPassword = new(string)
+	cmd.Flags().StringVarP(
+		repoData.NexusPassword,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
+func (repoData *RepoData) SetupNexusURLForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Nexus Repository Manager URL if it differs from the registry address (e.g. https://nexus.company.com) (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.NexusURL = new(string)
+	cmd.Flags().StringVarP(
+		repoData.NexusURL,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
+func (repoData *RepoData) SetupNexusRepositoryForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
+	usage := fmt.Sprintf("%s Nexus repository name to look for image tags in (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
+
+	repoData.NexusRepository = new(string)
+	cmd.Flags().StringVarP(
+		repoData.NexusRepository,
+		paramName,
+		"",
+		getDefaultValueByParamEnvNames(paramEnvNames),
+		usage,
+	)
+}
+
 func (repoData *RepoData) SetupSelectelUsernameForRepoData(cmd *cobra.Command, paramName string, paramEnvNames []string) {
 	usage := fmt.Sprintf("%s Selectel username (default %s)", repoData.Name, strings.Join(getParamEnvNamesForUsageDescription(paramEnvNames), ", "))
 
@@ -3,7 +3,9 @@ package common
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -15,6 +17,7 @@ import (
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/storage/synchronization_server"
+	"github.com/werf/werf/pkg/util"
 	"github.com/werf/werf/pkg/werf"
 	"github.com/werf/werf/pkg/werf/global_warnings"
 	"github.com/werf/werf/pkg/werf/locker_with_retry"
@@ -32,7 +35,18 @@ Default:
  - :local if --repo is not specified, or
  - %s if --repo has been specified.
 
-The same address should be specified for all werf processes that work with a single repo. :local address allows execution of werf processes from a single host only`, storage.DefaultHttpSynchronizationServer))
+The same address should be specified for all werf processes that work with a single repo. :local address allows execution of werf processes from a single host only.
+file+db://DIR address keeps synchronization data in the embedded database in the specified directory, which can be shared by werf processes of several CI runners on a single host`, storage.DefaultHttpSynchronizationServer))
+
+	cmdData.SynchronizationToken = new(string)
+	cmdData.SynchronizationTLSClientCert = new(string)
+	cmdData.SynchronizationTLSClientKey = new(string)
+	cmdData.SynchronizationCACert = new(string)
+
+	cmd.Flags().StringVarP(cmdData.SynchronizationToken, "synchronization-token", "", os.Getenv("WERF_SYNCHRONIZATION_TOKEN"), "Bearer token to authenticate on the http synchronization server (default $WERF_SYNCHRONIZATION_TOKEN)")
+	cmd.Flags().StringVarP(cmdData.SynchronizationTLSClientCert, "synchronization-tls-client-cert", "", os.Getenv("WERF_SYNCHRONIZATION_TLS_CLIENT_CERT"), "Client certificate file to authenticate on the https synchronization server (default $WERF_SYNCHRONIZATION_TLS_CLIENT_CERT)")
+	cmd.Flags().StringVarP(cmdData.SynchronizationTLSClientKey, "synchronization-tls-client-key", "", os.Getenv("WERF_SYNCHRONIZATION_TLS_CLIENT_KEY"), "Private key file of the --synchronization-tls-client-cert certificate (default $WERF_SYNCHRONIZATION_TLS_CLIENT_KEY)")
+	cmd.Flags().StringVarP(cmdData.SynchronizationCACert, "synchronization-ca-cert", "", os.Getenv("WERF_SYNCHRONIZATION_CA_CERT"), "CA certificate file to verify the https synchronization server certificate instead of the system CA bundle (default $WERF_SYNCHRONIZATION_CA_CERT)")
 }
 
 type SynchronizationType string
@@ -41,12 +55,15 @@ const (
 	LocalSynchronization      SynchronizationType = "LocalSynchronization"
 	KubernetesSynchronization SynchronizationType = "KubernetesSynchronization"
 	HttpSynchronization       SynchronizationType = "HttpSynchronization"
+	FileDbSynchronization     SynchronizationType = "FileDbSynchronization"
 )
 
 type SynchronizationParams struct {
 	Address             string
 	SynchronizationType SynchronizationType
 	KubeParams          *storage.KubernetesSynchronizationParams
+	FileDbDir           string
+	HttpClient          *http.Client
 }
 
 func checkSynchronizationKubernetesParamsForWarnings(cmdData *CmdData) {
@@ -131,10 +148,25 @@ func GetSynchronization(ctx context.Context, cmdData *CmdData, projectName strin
 	}
 
 	getHttpParamsFunc := func(synchronization string, stagesStorage storage.StagesStorage) (*SynchronizationParams, error) {
+		if (*cmdData.SynchronizationTLSClientCert == "") != (*cmdData.SynchronizationTLSClientKey == "") {
+			return nil, fmt.Errorf("both --synchronization-tls-client-cert and --synchronization-tls-client-key should be specified")
+		}
+
+		httpClient, err := synchronization_server.NewHttpClient(synchronization_server.HttpClientOptions{
+			Token:             *cmdData.SynchronizationToken,
+			LockOwner:         storage.GetLockOwnerClientID(),
+			TLSClientCertFile: *cmdData.SynchronizationTLSClientCert,
+			TLSClientKeyFile:  *cmdData.SynchronizationTLSClientKey,
+			CACertFile:        *cmdData.SynchronizationCACert,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("unable to create synchronization http client: %w", err)
+		}
+
 		var address string
 		if err := logboek.Info().LogProcess(fmt.Sprintf("Getting client id for the http synchronization server")).
 			DoError(func() error {
-				if clientID, err := synchronization_server.GetOrCreateClientID(ctx, projectName, synchronization_server.NewSynchronizationClient(synchronization), stagesStorage); err != nil {
+				if clientID, err := synchronization_server.GetOrCreateClientID(ctx, projectName, synchronization_server.NewSynchronizationClient(synchronization, httpClient), stagesStorage); err != nil {
 					return fmt.Errorf("unable to get synchronization client id: %w", err)
 				} else {
 					address = fmt.Sprintf("%s/%s", synchronization, clientID)
@@ -145,12 +177,12 @@ func GetSynchronization(ctx context.Context, cmdData *CmdData, projectName strin
 			return nil, err
 		}
 
-		return &SynchronizationParams{Address: address, SynchronizationType: HttpSynchronization}, nil
+		return &SynchronizationParams{Address: address, SynchronizationType: HttpSynchronization, HttpClient: httpClient}, nil
 	}
 
 	switch {
 	case *cmdData.Synchronization == "":
-		if stagesStorage.Address() == storage.LocalStorageAddress {
+		if stagesStorage.Address() == storage.LocalStorageAddress || storage.IsOCILayoutStorageAddress(stagesStorage.Address()) {
 			return &SynchronizationParams{SynchronizationType: LocalSynchronization, Address: storage.LocalStorageAddress}, nil
 		}
 
@@ -162,9 +194,24 @@ func GetSynchronization(ctx context.Context, cmdData *CmdData, projectName strin
 		return getKubeParamsFunc(*cmdData.Synchronization, GetOndemandKubeInitializer())
 	case strings.HasPrefix(*cmdData.Synchronization, "http://") || strings.HasPrefix(*cmdData.Synchronization, "https://"):
 		return getHttpParamsFunc(*cmdData.Synchronization, stagesStorage)
+	case strings.HasPrefix(*cmdData.Synchronization, "file+db://"):
+		dir, err := storage.ParseFileDbSynchronization(*cmdData.Synchronization)
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse synchronization address %s: %w", *cmdData.Synchronization, err)
+		}
+
+		return &SynchronizationParams{Address: *cmdData.Synchronization, SynchronizationType: FileDbSynchronization, FileDbDir: util.GetAbsoluteFilepath(dir)}, nil
 	default:
-		return nil, fmt.Errorf("only --synchronization=%s or --synchronization=kubernetes://NAMESPACE or --synchronization=http[s]://HOST:PORT/CLIENT_ID is supported, got %q", storage.LocalStorageAddress, *cmdData.Synchronization)
+		return nil, fmt.Errorf("only --synchronization=%s or --synchronization=kubernetes://NAMESPACE or --synchronization=http[s]://HOST:PORT/CLIENT_ID or --synchronization=file+db://DIR is supported, got %q", storage.LocalStorageAddress, *cmdData.Synchronization)
+	}
+}
+
+// CheckLocksInspectionSupported returns an error for the synchronization, which locks cannot be listed and released.
+func CheckLocksInspectionSupported(synchronization *SynchronizationParams) error {
+	if synchronization.SynchronizationType == LocalSynchronization {
+		return fmt.Errorf("%w: %s synchronization uses the file locks of the werf processes running on this host, specify --synchronization=kubernetes://NAMESPACE, --synchronization=http[s]://HOST:PORT or --synchronization=file+db://DIR", storage.ErrLocksInspectionNotSupported, storage.LocalStorageAddress)
 	}
+	return nil
 }
 
 func GetStorageLockManager(ctx context.Context, synchronization *SynchronizationParams) (storage.LockManager, error) {
@@ -189,9 +236,22 @@ func GetStorageLockManager(ctx context.Context, synchronization *Synchronization
 			}), nil
 		}
 	case HttpSynchronization:
-		locker := distributed_locker.NewHttpLocker(fmt.Sprintf("%s/locker", synchronization.Address))
+		locker := distributed_locker.NewDistributedLocker(&distributed_locker.HttpBackend{
+			URLEndpoint: fmt.Sprintf("%s/locker", synchronization.Address),
+			HttpClient:  synchronization.HttpClient,
+		})
 		lockerWithRetry := locker_with_retry.NewLockerWithRetry(ctx, locker, locker_with_retry.LockerWithRetryOptions{MaxAcquireAttempts: 10, MaxReleaseAttempts: 10})
-		return storage.NewGenericLockManager(lockerWithRetry), nil
+
+		lockManager := storage.NewGenericLockManager(lockerWithRetry)
+		lockManager.Inspector = synchronization_server.NewLocksHttpClient(fmt.Sprintf("%s/locks", synchronization.Address), synchronization.HttpClient)
+		return lockManager, nil
+	case FileDbSynchronization:
+		backend := storage.NewBoltLockerBackend(filepath.Join(synchronization.FileDbDir, storage.BoltSynchronizationDatabaseFileName), "")
+		backend.Owner = storage.GetLockOwnerClientID()
+
+		lockManager := storage.NewGenericLockManager(distributed_locker.NewDistributedLocker(backend))
+		lockManager.Inspector = backend
+		return lockManager, nil
 	default:
 		panic(fmt.Sprintf("unsupported synchronization address %q", synchronization.Address))
 	}
@@ -0,0 +1,46 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/pkg/tracing"
+)
+
+// RunWithTracing runs the command inside the root span when the OTLP endpoint is configured.
+func RunWithTracing(cmd *cobra.Command, run func() error) error {
+	if !tracing.IsEnabled() {
+		return run()
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if err := tracing.Init(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "WARNING: tracing is disabled: %s\n", err)
+		return run()
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := tracing.Shutdown(shutdownCtx); err != nil {
+			fmt.Fprintf(os.Stderr, "WARNING: unable to send traces: %s\n", err)
+		}
+	}()
+
+	command := getTelemetryCommand(cmd)
+	ctx, span := tracing.Start(tracing.ContextWithParentFromEnv(ctx), command, tracing.CommandKey.String(command))
+	cmd.SetContext(ctx)
+
+	err := run()
+	tracing.End(span, err)
+
+	return err
+}
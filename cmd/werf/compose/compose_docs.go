@@ -33,6 +33,7 @@ services:
     image: $WERF_FRONTEND_DOCKER_IMAGE_NAME
   backend:
     image: $WERF_GEODATA_BACKEND_DOCKER_IMAGE_NAME
+With the Buildah backend ($WERF_BUILDAH_MODE) podman-compose is used by default, the images are available to podman from the same local storage.
 `
 
 	docs.LongMD = short + "\n\n" +
@@ -63,7 +64,8 @@ services:
 		"    image: $WERF_FRONTEND_DOCKER_IMAGE_NAME\n" +
 		"  backend:\n" +
 		"    image: $WERF_GEODATA_BACKEND_DOCKER_IMAGE_NAME\n" +
-		"```\n"
+		"```\n\n" +
+		"With the Buildah backend (`$WERF_BUILDAH_MODE`) `podman-compose` is used by default, the images are available to podman from the same local storage.\n"
 
 	return docs
 }
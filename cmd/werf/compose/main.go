@@ -13,6 +13,7 @@ import (
 	"github.com/werf/logboek"
 	"github.com/werf/werf/cmd/werf/common"
 	"github.com/werf/werf/pkg/build"
+	"github.com/werf/werf/pkg/buildah"
 	"github.com/werf/werf/pkg/container_backend"
 	"github.com/werf/werf/pkg/git_repo"
 	"github.com/werf/werf/pkg/git_repo/gitdata"
@@ -203,6 +204,7 @@ func newCmd(ctx context.Context, composeCmdName string, options *newCmdOptions)
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -227,15 +229,32 @@ func newCmd(ctx context.Context, composeCmdName string, options *newCmdOptions)
 
 	cmd.Flags().StringVarP(&cmdData.RawComposeOptions, "docker-compose-options", "", os.Getenv("WERF_DOCKER_COMPOSE_OPTIONS"), "Define docker-compose options (default $WERF_DOCKER_COMPOSE_OPTIONS)")
 	cmd.Flags().StringVarP(&cmdData.RawComposeCommandOptions, "docker-compose-command-options", "", os.Getenv("WERF_DOCKER_COMPOSE_COMMAND_OPTIONS"), "Define docker-compose command options (default $WERF_DOCKER_COMPOSE_COMMAND_OPTIONS)")
-	cmd.Flags().StringVarP(&cmdData.ComposeBinPath, "docker-compose-bin-path", "", os.Getenv("WERF_DOCKER_COMPOSE_BIN_PATH"), "Define docker-compose bin path (default $WERF_DOCKER_COMPOSE_BIN_PATH)")
+	cmd.Flags().StringVarP(&cmdData.ComposeBinPath, "docker-compose-bin-path", "", os.Getenv("WERF_DOCKER_COMPOSE_BIN_PATH"), "Define docker-compose bin path (default $WERF_DOCKER_COMPOSE_BIN_PATH, podman-compose is used for the Buildah backend if not specified)")
 
 	return cmd
 }
 
-func checkComposeBin(cmdData composeCmdData) error {
-	dockerComposeBinPath := "docker-compose"
+// getComposeBinPath returns podman-compose for the buildah backend, because podman uses the same local storage as buildah.
+func getComposeBinPath(cmdData composeCmdData) (string, error) {
 	if cmdData.ComposeBinPath != "" {
-		dockerComposeBinPath = cmdData.ComposeBinPath
+		return cmdData.ComposeBinPath, nil
+	}
+
+	buildahMode, _, err := common.GetBuildahMode()
+	if err != nil {
+		return "", fmt.Errorf("unable to determine buildah mode: %w", err)
+	}
+	if *buildahMode != buildah.ModeDisabled {
+		return "podman-compose", nil
+	}
+
+	return "docker-compose", nil
+}
+
+func checkComposeBin(cmdData composeCmdData) error {
+	dockerComposeBinPath, err := getComposeBinPath(cmdData)
+	if err != nil {
+		return err
 	}
 
 	if _, err := exec.LookPath(dockerComposeBinPath); err != nil {
@@ -444,15 +463,20 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		dockerComposeArgs = append(dockerComposeArgs, cmdData.ComposeCommandArgs...)
 	}
 
+	dockerComposeBinPath, err := getComposeBinPath(cmdData)
+	if err != nil {
+		return err
+	}
+
 	// TODO: use docker CLI compose command instead of host docker-compose binary
 	if *commonCmdData.DryRun {
 		for _, env := range envArray {
 			fmt.Println("export", env)
 		}
-		fmt.Printf("docker-compose %s\n", strings.Join(dockerComposeArgs, " "))
+		fmt.Printf("%s %s\n", dockerComposeBinPath, strings.Join(dockerComposeArgs, " "))
 		return nil
 	} else {
-		cmd := exec.Command("docker-compose", dockerComposeArgs...)
+		cmd := exec.Command(dockerComposeBinPath, dockerComposeArgs...)
 		cmd.Stdin = os.Stdin
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
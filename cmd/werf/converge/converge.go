@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel/attribute"
 	helm_v3 "helm.sh/helm/v3/cmd/helm"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
@@ -39,6 +40,7 @@ import (
 	"github.com/werf/werf/pkg/storage/lrumeta"
 	"github.com/werf/werf/pkg/storage/manager"
 	"github.com/werf/werf/pkg/tmp_manager"
+	"github.com/werf/werf/pkg/tracing"
 	"github.com/werf/werf/pkg/true_git"
 	"github.com/werf/werf/pkg/util"
 	"github.com/werf/werf/pkg/werf"
@@ -129,6 +131,7 @@ werf converge --repo registry.mydomain.com/web --env production`,
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -162,6 +165,11 @@ werf converge --repo registry.mydomain.com/web --env production`,
 
 	common.SetupSaveBuildReport(&commonCmdData, cmd)
 	common.SetupBuildReportPath(&commonCmdData, cmd)
+	common.SetupSignKey(&commonCmdData, cmd)
+	common.SetupProvenance(&commonCmdData, cmd)
+	common.SetupSBOM(&commonCmdData, cmd)
+	common.SetupSyncCacheMounts(&commonCmdData, cmd)
+	common.SetupVerifyKey(&commonCmdData, cmd)
 	common.SetupDeprecatedReportPath(&commonCmdData, cmd)
 	common.SetupDeprecatedReportFormat(&commonCmdData, cmd)
 
@@ -375,7 +383,22 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		logboek.LogOptionalLn()
 	}
 
-	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{DisableSecretsDecryption: *commonCmdData.IgnoreSecretKey})
+	if pinnedReferences, err := common.VerifyImageSignatures(ctx, &commonCmdData, util.MapFuncToSlice(imagesInfoGetters, func(getter *image.InfoGetter) string {
+		return getter.GetName()
+	})); err != nil {
+		return err
+	} else {
+		for _, getter := range imagesInfoGetters {
+			if pinnedReference, ok := pinnedReferences[getter.GetName()]; ok {
+				getter.PinnedName = pinnedReference
+			}
+		}
+	}
+
+	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{
+		DisableSecretsDecryption: *commonCmdData.IgnoreSecretKey,
+		BackendConfig:            werfConfig.Meta.Secrets,
+	})
 
 	namespace, err := deploy_params.GetKubernetesNamespace(*commonCmdData.Namespace, *commonCmdData.Environment, werfConfig)
 	if err != nil {
@@ -522,11 +545,15 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		})
 
 		return command_helpers.LockReleaseWrapper(ctx, releaseName, lockManager, func() error {
-			if err := helmDeployCmd.Run(ctx); err != nil {
-				return fmt.Errorf("helm deploy failed: %w", err)
-			}
+			return tracing.Do(ctx, "helm.deploy", deployTracingAttributes(releaseName, namespace), func(ctx context.Context) error {
+				helm.SetResourcesWaiterTraceContext(ctx, actionConfig)
 
-			return nil
+				if err := helmDeployCmd.Run(ctx); err != nil {
+					return fmt.Errorf("helm deploy failed: %w", err)
+				}
+
+				return nil
+			})
 		})
 	} else {
 		var deployReportPath *string
@@ -554,14 +581,25 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		})
 
 		return command_helpers.LockReleaseWrapper(ctx, releaseName, lockManager, func() error {
-			if err := helmUpgradeCmd.RunE(helmUpgradeCmd, []string{releaseName, filepath.Join(giterminismManager.ProjectDir(), chartDir)}); err != nil {
-				return fmt.Errorf("helm upgrade have failed: %w", err)
-			}
-			return nil
+			return tracing.Do(ctx, "helm.upgrade", deployTracingAttributes(releaseName, namespace), func(ctx context.Context) error {
+				helm.SetResourcesWaiterTraceContext(ctx, actionConfig)
+
+				if err := helmUpgradeCmd.RunE(helmUpgradeCmd, []string{releaseName, filepath.Join(giterminismManager.ProjectDir(), chartDir)}); err != nil {
+					return fmt.Errorf("helm upgrade have failed: %w", err)
+				}
+				return nil
+			})
 		})
 	}
 }
 
+func deployTracingAttributes(releaseName, namespace string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		tracing.ReleaseKey.String(releaseName),
+		tracing.NamespaceKey.String(namespace),
+	}
+}
+
 func createMaintenanceHelper(ctx context.Context, actionConfig *action.Configuration, kubeConfigOptions kube.KubeConfigOptions) *maintenance_helper.MaintenanceHelper {
 	maintenanceOpts := maintenance_helper.MaintenanceHelperOptions{
 		KubeConfigOptions: kubeConfigOptions,
@@ -0,0 +1,84 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/giterminism_manager"
+)
+
+func NewDownCmd(ctx context.Context) *cobra.Command {
+	var cmdData devCmdData
+
+	return newCmd(ctx, &newCmdOptions{
+		Use:   "down [options] [SERVICE...]",
+		Short: "Stop and remove the containers of the services started by werf dev up.",
+		Long: `Stop and remove the containers of the services started by werf dev up.
+
+The network of the services is removed if all services are stopped. Images are not removed.`,
+		Example: `  # Stop and remove all services
+  $ werf dev down
+
+  # Stop and remove the backend service only
+  $ werf dev down backend`,
+	}, &cmdData, runDown)
+}
+
+func runDown(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData, cmdData *devCmdData) error {
+	projectName, err := getProjectName(ctx, giterminismManager, commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	project, err := loadComposeProjectWithoutImages(giterminismManager, cmdData)
+	if err != nil {
+		return err
+	}
+
+	services, err := project.StartOrder(nil)
+	if err != nil {
+		return err
+	}
+
+	toStop := map[string]bool{}
+	for _, name := range cmdData.Services {
+		if _, ok := project.Services[name]; !ok {
+			return fmt.Errorf("service %q is not defined", name)
+		}
+		toStop[name] = true
+	}
+
+	// Dependent services are stopped first.
+	for i := len(services) - 1; i >= 0; i-- {
+		service := services[i]
+		if len(toStop) != 0 && !toStop[service.Name] {
+			continue
+		}
+
+		name := containerName(projectName, service.Name)
+		container, err := findContainer(ctx, containerBackend, name)
+		if err != nil {
+			return err
+		}
+		if container == nil {
+			continue
+		}
+
+		if err := logboek.Context(ctx).Default().LogProcess("Removing service %q", service.Name).DoError(func() error {
+			return containerBackend.Rm(ctx, name, container_backend.RmOpts{Force: true})
+		}); err != nil {
+			return fmt.Errorf("unable to remove container %q: %w", name, err)
+		}
+	}
+
+	if len(toStop) != 0 {
+		return nil
+	}
+
+	return containerBackend.RemoveNetwork(ctx, networkName(projectName))
+}
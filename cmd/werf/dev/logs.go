@@ -0,0 +1,174 @@
+package dev
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/giterminism_manager"
+)
+
+func NewLogsCmd(ctx context.Context) *cobra.Command {
+	var cmdData devCmdData
+
+	cmd := newCmd(ctx, &newCmdOptions{
+		Use:   "logs [options] [SERVICE...]",
+		Short: "Show the logs of the services started by werf dev up.",
+		Long: `Show the logs of the services started by werf dev up.
+
+The lines are prefixed with the service name when the logs of several services are shown.`,
+		Example: `  # Follow the logs of all services
+  $ werf dev logs --follow
+
+  # Show the last 100 lines of the backend logs
+  $ werf dev logs --tail 100 backend`,
+	}, &cmdData, runLogs)
+
+	cmd.Flags().BoolVarP(&cmdData.LogsFollow, "follow", "f", false, "Follow the logs output")
+	cmd.Flags().StringVarP(&cmdData.LogsTail, "tail", "", "", "Number of lines to show from the end of the logs for each service (default all)")
+
+	return cmd
+}
+
+func runLogs(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData, cmdData *devCmdData) error {
+	projectName, err := getProjectName(ctx, giterminismManager, commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	project, err := loadComposeProjectWithoutImages(giterminismManager, cmdData)
+	if err != nil {
+		return err
+	}
+
+	serviceNames := cmdData.Services
+	if len(serviceNames) == 0 {
+		for name := range project.Services {
+			serviceNames = append(serviceNames, name)
+		}
+		sort.Strings(serviceNames)
+	}
+
+	var containers []string
+	var prefixes []string
+	for _, serviceName := range serviceNames {
+		if _, ok := project.Services[serviceName]; !ok {
+			return fmt.Errorf("service %q is not defined", serviceName)
+		}
+
+		name := containerName(projectName, serviceName)
+		container, err := findContainer(ctx, containerBackend, name)
+		if err != nil {
+			return err
+		}
+		if container == nil {
+			continue
+		}
+
+		containers = append(containers, name)
+		prefixes = append(prefixes, serviceName)
+	}
+
+	if len(containers) == 0 {
+		return fmt.Errorf("no running services found: run werf dev up first")
+	}
+
+	if len(containers) == 1 {
+		return containerBackend.ContainerLogs(ctx, containers[0], container_backend.ContainerLogsOpts{
+			Follow: cmdData.LogsFollow,
+			Tail:   cmdData.LogsTail,
+			Stdout: os.Stdout,
+			Stderr: os.Stderr,
+		})
+	}
+
+	prefixWidth := 0
+	for _, prefix := range prefixes {
+		if len(prefix) > prefixWidth {
+			prefixWidth = len(prefix)
+		}
+	}
+
+	var mux sync.Mutex
+	var wg sync.WaitGroup
+	errs := make([]error, len(containers))
+
+	for i := range containers {
+		i := i
+		prefix := fmt.Sprintf("%-*s | ", prefixWidth, prefixes[i])
+		stdout := &prefixWriter{mux: &mux, out: os.Stdout, prefix: prefix}
+		stderr := &prefixWriter{mux: &mux, out: os.Stderr, prefix: prefix}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			errs[i] = containerBackend.ContainerLogs(ctx, containers[i], container_backend.ContainerLogsOpts{
+				Follow: cmdData.LogsFollow,
+				Tail:   cmdData.LogsTail,
+				Stdout: stdout,
+				Stderr: stderr,
+			})
+			stdout.Flush()
+			stderr.Flush()
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// prefixWriter writes the complete lines with the prefix, the lines of the concurrent writers are not mixed.
+type prefixWriter struct {
+	mux    *sync.Mutex
+	out    io.Writer
+	prefix string
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		ind := bytes.IndexByte(w.buf, '\n')
+		if ind == -1 {
+			break
+		}
+
+		if err := w.writeLine(w.buf[:ind+1]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[ind+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *prefixWriter) Flush() {
+	if len(w.buf) != 0 {
+		_ = w.writeLine(append(w.buf, '\n'))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	_, err := fmt.Fprintf(w.out, "%s%s", w.prefix, line)
+	return err
+}
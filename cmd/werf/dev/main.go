@@ -0,0 +1,283 @@
+package dev
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/compose"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/git_repo"
+	"github.com/werf/werf/pkg/git_repo/gitdata"
+	"github.com/werf/werf/pkg/giterminism_manager"
+	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/ssh_agent"
+	"github.com/werf/werf/pkg/storage/lrumeta"
+	"github.com/werf/werf/pkg/true_git"
+	"github.com/werf/werf/pkg/werf"
+	"github.com/werf/werf/pkg/werf/global_warnings"
+)
+
+const (
+	// configHashLabel marks the container with the hash of the image and the service configuration,
+	// werf dev up recreates the container only when the hash changes.
+	configHashLabel = "werf.io/dev-config-hash"
+)
+
+var imageEnvNameRegexp = regexp.MustCompile(`^WERF_(.+_)?DOCKER_IMAGE_NAME$`)
+
+type newCmdOptions struct {
+	Use   string
+	Short string
+	Long  string
+
+	Example string
+
+	// BuildSupport enables the building of images and the follow mode.
+	BuildSupport bool
+}
+
+type devCmdData struct {
+	ComposeFile string
+	Services    []string
+
+	LogsFollow bool
+	LogsTail   string
+}
+
+type runFunc func(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData, cmdData *devCmdData) error
+
+func newCmd(ctx context.Context, options *newCmdOptions, cmdData *devCmdData, run runFunc) *cobra.Command {
+	var commonCmdData common.CmdData
+
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   options.Use,
+		Short:                 options.Short,
+		Long:                  common.GetLongCommandDescription(options.Long),
+		DisableFlagsInUseLine: true,
+		Annotations: map[string]string{
+			common.DisableOptionsInUseLineAnno: "1",
+		},
+		Example: options.Example,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			defer global_warnings.PrintGlobalWarnings(ctx)
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			cmdData.Services = args
+
+			return runMain(ctx, &commonCmdData, cmdData, options.BuildSupport, run)
+		},
+	})
+
+	common.SetupDir(&commonCmdData, cmd)
+	common.SetupGitWorkTree(&commonCmdData, cmd)
+	common.SetupConfigTemplatesDir(&commonCmdData, cmd)
+	common.SetupConfigPath(&commonCmdData, cmd)
+	common.SetupEnvironment(&commonCmdData, cmd)
+
+	common.SetupGiterminismOptions(&commonCmdData, cmd)
+
+	common.SetupTmpDir(&commonCmdData, cmd, common.SetupTmpDirOptions{})
+	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
+	common.SetupSSHKey(&commonCmdData, cmd)
+
+	if options.BuildSupport {
+		common.SetupSecondaryStagesStorageOptions(&commonCmdData, cmd)
+		common.SetupCacheStagesStorageOptions(&commonCmdData, cmd)
+		common.SetupRepoOptions(&commonCmdData, cmd, common.RepoDataOptions{OptionalRepo: true})
+		common.SetupFinalRepo(&commonCmdData, cmd)
+
+		common.SetupRequireBuiltImages(&commonCmdData, cmd)
+		common.SetupFollow(&commonCmdData, cmd)
+
+		common.SetupSynchronization(&commonCmdData, cmd)
+		common.SetupKubeConfig(&commonCmdData, cmd)
+		common.SetupKubeConfigBase64(&commonCmdData, cmd)
+		common.SetupKubeContext(&commonCmdData, cmd)
+
+		common.SetupVirtualMerge(&commonCmdData, cmd)
+	}
+
+	common.SetupDockerConfig(&commonCmdData, cmd, "Command needs granted permissions to read and pull images from the specified repo")
+	common.SetupInsecureRegistry(&commonCmdData, cmd)
+	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
+
+	commonCmdData.SetupPlatform(cmd)
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+	common.SetupLogProjectDir(&commonCmdData, cmd)
+
+	cmd.Flags().StringVarP(&cmdData.ComposeFile, "compose-file", "", os.Getenv("WERF_COMPOSE_FILE"), fmt.Sprintf("Use custom compose file (default $WERF_COMPOSE_FILE or one of %s in the project directory)", strings.Join(compose.DefaultFileNames, ", ")))
+
+	return cmd
+}
+
+func runMain(ctx context.Context, commonCmdData *common.CmdData, cmdData *devCmdData, buildSupport bool, run runFunc) error {
+	if err := werf.Init(*commonCmdData.TmpDir, *commonCmdData.HomeDir); err != nil {
+		return fmt.Errorf("initialization error: %w", err)
+	}
+
+	containerBackend, processCtx, err := common.InitProcessContainerBackend(ctx, commonCmdData)
+	if err != nil {
+		return err
+	}
+	ctx = processCtx
+
+	if _, isDockerServer := containerBackend.(*container_backend.DockerServerBackend); !isDockerServer {
+		return fmt.Errorf("werf dev commands are supported only by the docker server backend: the buildah backend cannot run detached containers, create networks and show container logs")
+	}
+
+	gitDataManager, err := gitdata.GetHostGitDataManager(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting host git data manager: %w", err)
+	}
+
+	if err := git_repo.Init(gitDataManager); err != nil {
+		return err
+	}
+
+	if err := image.Init(); err != nil {
+		return err
+	}
+
+	if err := lrumeta.Init(); err != nil {
+		return err
+	}
+
+	if err := true_git.Init(ctx, true_git.Options{LiveGitOutput: *commonCmdData.LogDebug}); err != nil {
+		return err
+	}
+
+	if buildSupport {
+		if err := common.DockerRegistryInit(ctx, commonCmdData); err != nil {
+			return err
+		}
+	}
+
+	giterminismManager, err := common.GetGiterminismManager(ctx, commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	common.ProcessLogProjectDir(commonCmdData, giterminismManager.ProjectDir())
+
+	if err := ssh_agent.Init(ctx, common.GetSSHKey(commonCmdData)); err != nil {
+		return fmt.Errorf("cannot initialize ssh agent: %w", err)
+	}
+	defer func() {
+		err := ssh_agent.Terminate()
+		if err != nil {
+			logboek.Warn().LogF("WARNING: ssh agent termination failed: %s\n", err)
+		}
+	}()
+
+	if buildSupport && *commonCmdData.Follow {
+		return common.FollowGitHead(ctx, commonCmdData, func(ctx context.Context, headCommitGiterminismManager giterminism_manager.Interface) error {
+			return run(ctx, containerBackend, headCommitGiterminismManager, commonCmdData, cmdData)
+		})
+	}
+
+	return run(ctx, containerBackend, giterminismManager, commonCmdData, cmdData)
+}
+
+// loadComposeProject loads the compose file, the variables are substituted from the werf images environment and
+// the process environment.
+func loadComposeProject(giterminismManager giterminism_manager.Interface, cmdData *devCmdData, imagesEnvArray []string) (*compose.Project, error) {
+	imagesEnv := map[string]string{}
+	for _, env := range imagesEnvArray {
+		key, value, _ := strings.Cut(env, "=")
+		imagesEnv[key] = value
+	}
+
+	lookupEnv := func(name string) (string, bool) {
+		if value, ok := imagesEnv[name]; ok {
+			return value, true
+		}
+		return os.LookupEnv(name)
+	}
+
+	return loadComposeProjectWithLookup(giterminismManager, cmdData, lookupEnv)
+}
+
+// loadComposeProjectWithoutImages loads the compose file to manage the existing containers, the images are not built
+// and the werf image variables are substituted with placeholders.
+func loadComposeProjectWithoutImages(giterminismManager giterminism_manager.Interface, cmdData *devCmdData) (*compose.Project, error) {
+	lookupEnv := func(name string) (string, bool) {
+		if value, ok := os.LookupEnv(name); ok {
+			return value, true
+		}
+		if imageEnvNameRegexp.MatchString(name) {
+			return strings.ToLower(name), true
+		}
+		return "", false
+	}
+
+	return loadComposeProjectWithLookup(giterminismManager, cmdData, lookupEnv)
+}
+
+func loadComposeProjectWithLookup(giterminismManager giterminism_manager.Interface, cmdData *devCmdData, lookupEnv func(name string) (string, bool)) (*compose.Project, error) {
+	path := cmdData.ComposeFile
+	if path == "" {
+		var err error
+		path, err = compose.FindFile(giterminismManager.ProjectDir())
+		if err != nil {
+			return nil, err
+		}
+	} else if !filepath.IsAbs(path) {
+		path = filepath.Join(giterminismManager.ProjectDir(), path)
+	}
+
+	return compose.Load(path, lookupEnv)
+}
+
+func getProjectName(ctx context.Context, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData) (string, error) {
+	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, commonCmdData, giterminismManager, common.GetWerfConfigOptions(commonCmdData, false))
+	if err != nil {
+		return "", fmt.Errorf("unable to load werf config: %w", err)
+	}
+
+	return werfConfig.Meta.Project, nil
+}
+
+func containerName(projectName, serviceName string) string {
+	return fmt.Sprintf("%s-%s", projectName, serviceName)
+}
+
+func networkName(projectName string) string {
+	return fmt.Sprintf("%s-dev", projectName)
+}
+
+// findContainer returns the container with the exact name, the backend name filter matches the substring of the name.
+func findContainer(ctx context.Context, containerBackend container_backend.ContainerBackend, name string) (*image.Container, error) {
+	containers, err := containerBackend.Containers(ctx, container_backend.ContainersOptions{
+		Filters: []image.ContainerFilter{{Name: name}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get containers: %w", err)
+	}
+
+	for _, container := range containers {
+		for _, containerName := range container.Names {
+			if strings.TrimPrefix(containerName, "/") == name {
+				return &container, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,274 @@
+package dev
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/build"
+	"github.com/werf/werf/pkg/compose"
+	"github.com/werf/werf/pkg/config"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/giterminism_manager"
+	"github.com/werf/werf/pkg/ssh_agent"
+	"github.com/werf/werf/pkg/storage/manager"
+	"github.com/werf/werf/pkg/tmp_manager"
+)
+
+func NewUpCmd(ctx context.Context) *cobra.Command {
+	var cmdData devCmdData
+
+	return newCmd(ctx, &newCmdOptions{
+		Use:   "up [options] [SERVICE...]",
+		Short: "Build images and start the services of the compose file.",
+		Long: `Build images and start the services of the compose file.
+
+The compose file is read by werf itself, docker-compose binary is not needed. Image environment variables $WERF_<FORMATTED_WERF_IMAGE_NAME>_DOCKER_IMAGE_NAME (the same as for werf compose) are available in the compose file.
+The services are started in the background in the order of dependencies, the containers are connected to the <PROJECT>-dev network and available to each other by the service names.
+The container of the service is recreated only if the image or the service configuration has changed, so only the services whose images have new stage digests are restarted.
+Use --follow with --dev to rebuild and restart the changed services on each change of the project files.
+
+Supported service keys: image, command, entrypoint, environment, ports, volumes, working_dir, user, depends_on and network_mode, other keys are ignored.
+
+The werf dev commands require the Docker server backend, the Buildah backend ($WERF_BUILDAH_MODE) cannot run detached containers, create networks and show container logs.`,
+		Example: `  # Build images and start all services
+  $ werf dev up
+
+  # Rebuild images and restart changed services on each change of the project files
+  $ werf dev up --follow --dev
+
+  # Start the backend service and its dependencies
+  $ werf dev up backend`,
+		BuildSupport: true,
+	}, &cmdData, runUp)
+}
+
+func runUp(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData, cmdData *devCmdData) error {
+	_, werfConfig, err := common.GetRequiredWerfConfig(ctx, commonCmdData, giterminismManager, common.GetWerfConfigOptions(commonCmdData, true))
+	if err != nil {
+		return fmt.Errorf("unable to load werf config: %w", err)
+	}
+	projectName := werfConfig.Meta.Project
+
+	imagesEnvArray, err := buildImages(ctx, containerBackend, giterminismManager, commonCmdData, werfConfig)
+	if err != nil {
+		return err
+	}
+
+	project, err := loadComposeProject(giterminismManager, cmdData, imagesEnvArray)
+	if err != nil {
+		return err
+	}
+
+	services, err := project.StartOrder(cmdData.Services)
+	if err != nil {
+		return err
+	}
+
+	network := networkName(projectName)
+	if err := containerBackend.CreateNetwork(ctx, network); err != nil {
+		return err
+	}
+
+	for _, service := range services {
+		if err := upService(ctx, containerBackend, project, service, projectName, network, imagesEnvArray); err != nil {
+			return fmt.Errorf("unable to start service %q: %w", service.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func buildImages(ctx context.Context, containerBackend container_backend.ContainerBackend, giterminismManager giterminism_manager.Interface, commonCmdData *common.CmdData, werfConfig *config.WerfConfig) ([]string, error) {
+	if len(werfConfig.StapelImages)+len(werfConfig.ImagesFromDockerfile) == 0 {
+		return nil, nil
+	}
+
+	projectName := werfConfig.Meta.Project
+	imagesToProcess := common.GetImagesToProcess(nil, false)
+
+	projectTmpDir, err := tmp_manager.CreateProjectDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting project tmp dir failed: %w", err)
+	}
+	defer tmp_manager.ReleaseProjectDir(projectTmpDir)
+
+	stagesStorage, err := common.GetStagesStorage(ctx, containerBackend, commonCmdData)
+	if err != nil {
+		return nil, err
+	}
+	finalStagesStorage, err := common.GetOptionalFinalStagesStorage(ctx, containerBackend, commonCmdData)
+	if err != nil {
+		return nil, err
+	}
+	synchronization, err := common.GetSynchronization(ctx, commonCmdData, projectName, stagesStorage)
+	if err != nil {
+		return nil, err
+	}
+	storageLockManager, err := common.GetStorageLockManager(ctx, synchronization)
+	if err != nil {
+		return nil, err
+	}
+	secondaryStagesStorageList, err := common.GetSecondaryStagesStorageList(ctx, stagesStorage, containerBackend, commonCmdData)
+	if err != nil {
+		return nil, err
+	}
+	cacheStagesStorageList, err := common.GetCacheStagesStorageList(ctx, containerBackend, commonCmdData)
+	if err != nil {
+		return nil, err
+	}
+
+	storageManager := manager.NewStorageManager(projectName, stagesStorage, finalStagesStorage, secondaryStagesStorageList, cacheStagesStorageList, storageLockManager)
+
+	logboek.Context(ctx).Info().LogOptionalLn()
+
+	conveyorOptions, err := common.GetConveyorOptions(ctx, commonCmdData, imagesToProcess)
+	if err != nil {
+		return nil, err
+	}
+
+	conveyorWithRetry := build.NewConveyorWithRetryWrapper(werfConfig, giterminismManager, giterminismManager.ProjectDir(), projectTmpDir, ssh_agent.SSHAuthSock, containerBackend, storageManager, storageLockManager, conveyorOptions)
+	defer conveyorWithRetry.Terminate()
+
+	var imagesEnvArray []string
+	if err := conveyorWithRetry.WithRetryBlock(ctx, func(c *build.Conveyor) error {
+		if common.GetRequireBuiltImages(ctx, commonCmdData) {
+			if err := c.ShouldBeBuilt(ctx, build.ShouldBeBuiltOptions{}); err != nil {
+				return err
+			}
+		} else {
+			if err := c.Build(ctx, build.BuildOptions{SkipImageMetadataPublication: *commonCmdData.Dev}); err != nil {
+				return err
+			}
+		}
+
+		for _, img := range c.GetExportedImages() {
+			if err := c.FetchLastImageStage(ctx, img.TargetPlatform, img.Name); err != nil {
+				return err
+			}
+		}
+
+		imagesEnvArray = c.GetImagesEnvArray()
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return imagesEnvArray, nil
+}
+
+func upService(ctx context.Context, containerBackend container_backend.ContainerBackend, project *compose.Project, service *compose.Service, projectName, network string, imagesEnvArray []string) error {
+	imageInfo, err := containerBackend.GetImageInfo(ctx, service.Image, container_backend.GetImageInfoOpts{})
+	if err != nil {
+		return fmt.Errorf("unable to get image %q info: %w", service.Image, err)
+	}
+	if imageInfo == nil {
+		if err := containerBackend.Pull(ctx, service.Image, container_backend.PullOpts{}); err != nil {
+			return fmt.Errorf("unable to pull image %q: %w", service.Image, err)
+		}
+
+		imageInfo, err = containerBackend.GetImageInfo(ctx, service.Image, container_backend.GetImageInfoOpts{})
+		if err != nil {
+			return fmt.Errorf("unable to get image %q info: %w", service.Image, err)
+		}
+		if imageInfo == nil {
+			return fmt.Errorf("image %q not found after pull", service.Image)
+		}
+	}
+
+	name := containerName(projectName, service.Name)
+	opts := serviceRunContainerOpts(project, service, name, network, imagesEnvArray)
+
+	configHash, err := serviceConfigHash(imageInfo.ID, opts)
+	if err != nil {
+		return err
+	}
+	opts.Labels = append(opts.Labels, fmt.Sprintf("%s=%s", configHashLabel, configHash))
+
+	container, err := findContainer(ctx, containerBackend, name)
+	if err != nil {
+		return err
+	}
+
+	if container != nil {
+		if container.State == "running" && container.Labels[configHashLabel] == configHash {
+			logboek.Context(ctx).Default().LogF("Service %q is up to date\n", service.Name)
+			return nil
+		}
+
+		if err := containerBackend.Rm(ctx, name, container_backend.RmOpts{Force: true}); err != nil {
+			return fmt.Errorf("unable to remove container %q: %w", name, err)
+		}
+	}
+
+	return logboek.Context(ctx).Default().LogProcess("Starting service %q", service.Name).DoError(func() error {
+		return containerBackend.RunContainer(ctx, service.Image, opts)
+	})
+}
+
+func serviceRunContainerOpts(project *compose.Project, service *compose.Service, name, network string, imagesEnvArray []string) container_backend.RunContainerOpts {
+	opts := container_backend.RunContainerOpts{
+		Name:       name,
+		Command:    service.Command,
+		Ports:      service.Ports,
+		WorkingDir: service.WorkingDir,
+		User:       service.User,
+		Detach:     true,
+	}
+
+	if service.Entrypoint != nil {
+		opts.Entrypoint = append([]string{}, *service.Entrypoint...)
+	}
+
+	if service.NetworkMode != "" {
+		opts.Network = service.NetworkMode
+	} else {
+		opts.Network = network
+		opts.NetworkAliases = []string{service.Name}
+	}
+
+	for _, env := range service.Environment {
+		if strings.Contains(env, "=") {
+			opts.Envs = append(opts.Envs, env)
+			continue
+		}
+
+		// The variable without value is taken from the werf images environment or the process environment.
+		if value, ok := lookupImagesEnv(imagesEnvArray, env); ok {
+			opts.Envs = append(opts.Envs, fmt.Sprintf("%s=%s", env, value))
+		} else {
+			opts.Envs = append(opts.Envs, env)
+		}
+	}
+
+	for _, volume := range service.Volumes {
+		opts.Volumes = append(opts.Volumes, project.VolumeSpec(volume))
+	}
+
+	return opts
+}
+
+func lookupImagesEnv(imagesEnvArray []string, name string) (string, bool) {
+	for _, env := range imagesEnvArray {
+		if key, value, _ := strings.Cut(env, "="); key == name {
+			return value, true
+		}
+	}
+	return "", false
+}
+
+func serviceConfigHash(imageID string, opts container_backend.RunContainerOpts) (string, error) {
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal container options: %w", err)
+	}
+
+	return fmt.Sprintf("%x", sha256.Sum256(append([]byte(imageID), data...))), nil
+}
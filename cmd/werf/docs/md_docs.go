@@ -230,6 +230,18 @@ func genCliSidebar(cmd *cobra.Command, indent int, buf *bytes.Buffer) error {
 		}
 
 		indent += 2
+
+		if _, ok := cmd.Annotations[common.DocsRunnableCommandGroupAnno]; ok {
+			commandRecord := fmt.Sprintf(`
+%[1]s- title: %[2]s
+%[1]s  url: /reference/cli/%[3]s.html
+`, strings.Repeat("  ", indent), cmd.CommandPath(), fullCommandFilesystemPath(cmd.CommandPath()))
+
+			if _, err := buf.WriteString(commandRecord); err != nil {
+				return err
+			}
+		}
+
 		for _, command := range cmd.Commands() {
 			if cmd.Hidden {
 				continue
@@ -267,7 +279,7 @@ toc: false
 			}
 
 			var fullCommandName string
-			if len(cmd.Commands()) == 0 {
+			if _, ok := cmd.Annotations[common.DocsRunnableCommandGroupAnno]; len(cmd.Commands()) == 0 || ok {
 				fullCommandName = fullCommandFilesystemPath(cmd.CommandPath())
 			} else {
 				fullCommandName = fullCommandFilesystemPath(cmd.Commands()[0].CommandPath())
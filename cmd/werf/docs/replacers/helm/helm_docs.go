@@ -499,6 +499,25 @@ Encryption key should be in $WERF_SECRET_KEY or .werf_secret_key file.`
 	return docs
 }
 
+func GetHelmSecretDiffDocs() structs.DocsStruct {
+	var docs structs.DocsStruct
+
+	docs.Long = `Decrypt two versions of secret file or secret values file and show the changes.
+By default the file at HEAD is compared with the working tree version, other git revisions can be specified with the --from and --to options.
+Secret values files are compared value by value, values can be hidden with the --mask-values option.
+
+With the --textconv option the command prints decrypted content of FILE_PATH and can be used as git textconv program to show decrypted changes with git diff.
+Encryption key should be in $WERF_SECRET_KEY or .werf_secret_key file.`
+
+	docs.LongMD = "Decrypt two versions of secret file or secret values file and show the changes.\n\n" +
+		"By default the file at `HEAD` is compared with the working tree version, other git revisions can be specified with the `--from` and `--to` options. " +
+		"Secret values files are compared value by value, values can be hidden with the `--mask-values` option.\n\n" +
+		"With the `--textconv` option the command prints decrypted content of `FILE_PATH` and can be used as git textconv program to show decrypted changes with `git diff`.\n\n" +
+		"Encryption key should be in `$WERF_SECRET_KEY` or `.werf_secret_key file`."
+
+	return docs
+}
+
 func GetHelmSecretEncryptDocs() structs.DocsStruct {
 	var docs structs.DocsStruct
 
@@ -534,7 +553,10 @@ New key should reside either in the $WERF_SECRET_KEY or .werf_secret_key file.
 Command will extract data with the old key, generate new secret data and rewrite files:
 * standard raw Secret files in the .helm/secret folder;
 * standard secret Values YAML file .helm/secret-values.yaml;
-* additional secret Values YAML files specified with EXTRA_SECRET_VALUES_FILE_PATH params`
+* additional secret Values YAML files specified with EXTRA_SECRET_VALUES_FILE_PATH params.
+
+With the --migrate-format option the files are regenerated with the current key only:
+data encrypted in the legacy format is rewritten in the latest authenticated format and $WERF_OLD_SECRET_KEY is not required.`
 
 	docs.LongMD = "Regenerate Secret files with new Secret key.\n\n" +
 		"Old key should be specified in the `$WERF_OLD_SECRET_KEY`.\n\n" +
@@ -542,7 +564,9 @@ Command will extract data with the old key, generate new secret data and rewrite
 		"Command will extract data with the old key, generate new Secret data and rewrite files:\n" +
 		"* standard raw Secret files in the `.helm/secret folder`;\n" +
 		"* standard Secret Values YAML file `.helm/secret-values.yaml`;\n" +
-		"* additional Secret Values YAML files specified with `EXTRA_SECRET_VALUES_FILE_PATH` params."
+		"* additional Secret Values YAML files specified with `EXTRA_SECRET_VALUES_FILE_PATH` params.\n\n" +
+		"With the `--migrate-format` option the files are regenerated with the current key only: " +
+		"data encrypted in the legacy format is rewritten in the latest authenticated format and `$WERF_OLD_SECRET_KEY` is not required."
 
 	return docs
 }
@@ -607,6 +631,45 @@ Encryption key should be in $WERF_SECRET_KEY or .werf_secret_key file.`
 	return docs
 }
 
+func GetHelmSecretValuesAddRecipientDocs() structs.DocsStruct {
+	var docs structs.DocsStruct
+
+	docs.Long = `Allow one more secret key to decrypt secret values file.
+Current encryption key should be in $WERF_SECRET_KEY or .werf_secret_key file, new recipient key should be in $WERF_RECIPIENT_SECRET_KEY.
+
+Values of the file are encrypted with a random data key, which is encrypted separately for each recipient key.
+The file is converted to this format on the first call, afterwards recipients are added without re-encryption of the values.`
+
+	docs.LongMD = "Allow one more secret key to decrypt secret values file.\n\n" +
+		"Current encryption key should be in `$WERF_SECRET_KEY` or `.werf_secret_key` file, new recipient key should be in `$WERF_RECIPIENT_SECRET_KEY`.\n\n" +
+		"Values of the file are encrypted with a random data key, which is encrypted separately for each recipient key. " +
+		"The file is converted to this format on the first call, afterwards recipients are added without re-encryption of the values."
+
+	return docs
+}
+
+func GetHelmSecretValuesRemoveRecipientDocs() structs.DocsStruct {
+	var docs structs.DocsStruct
+
+	docs.Long = `Disallow secret key with RECIPIENT_ID to decrypt secret values file.
+The values are not re-encrypted, so change them if the removed key is compromised.`
+
+	docs.LongMD = "Disallow secret key with `RECIPIENT_ID` to decrypt secret values file.\n\n" +
+		"The values are not re-encrypted, so change them if the removed key is compromised."
+
+	return docs
+}
+
+func GetHelmSecretValuesListRecipientsDocs() structs.DocsStruct {
+	var docs structs.DocsStruct
+
+	docs.Long = `List IDs and names of secret keys allowed to decrypt secret values file.`
+
+	docs.LongMD = "List IDs and names of secret keys allowed to decrypt secret values file."
+
+	return docs
+}
+
 func GetHelmSecretValuesEncryptDocs() structs.DocsStruct {
 	var docs structs.DocsStruct
 
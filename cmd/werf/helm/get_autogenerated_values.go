@@ -81,6 +81,7 @@ func NewGetAutogeneratedValuesCmd(ctx context.Context) *cobra.Command {
 	common.SetupDockerConfig(&getAutogeneratedValuedCmdData, cmd, "Command needs granted permissions to read and pull images from the specified repo")
 	common.SetupInsecureRegistry(&getAutogeneratedValuedCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&getAutogeneratedValuedCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&getAutogeneratedValuedCmdData, cmd)
 
 	common.SetupStubTags(&getAutogeneratedValuedCmdData, cmd)
 
@@ -17,15 +17,19 @@ import (
 	"github.com/werf/werf/cmd/werf/common"
 	helm2 "github.com/werf/werf/cmd/werf/docs/replacers/helm"
 	helm_secret_decrypt "github.com/werf/werf/cmd/werf/helm/secret/decrypt"
+	helm_secret_diff "github.com/werf/werf/cmd/werf/helm/secret/diff"
 	helm_secret_encrypt "github.com/werf/werf/cmd/werf/helm/secret/encrypt"
 	helm_secret_file_decrypt "github.com/werf/werf/cmd/werf/helm/secret/file/decrypt"
 	helm_secret_file_edit "github.com/werf/werf/cmd/werf/helm/secret/file/edit"
 	helm_secret_file_encrypt "github.com/werf/werf/cmd/werf/helm/secret/file/encrypt"
 	helm_secret_generate_secret_key "github.com/werf/werf/cmd/werf/helm/secret/generate_secret_key"
 	helm_secret_rotate_secret_key "github.com/werf/werf/cmd/werf/helm/secret/rotate_secret_key"
+	helm_secret_values_add_recipient "github.com/werf/werf/cmd/werf/helm/secret/values/add_recipient"
 	helm_secret_values_decrypt "github.com/werf/werf/cmd/werf/helm/secret/values/decrypt"
 	helm_secret_values_edit "github.com/werf/werf/cmd/werf/helm/secret/values/edit"
 	helm_secret_values_encrypt "github.com/werf/werf/cmd/werf/helm/secret/values/encrypt"
+	helm_secret_values_list_recipients "github.com/werf/werf/cmd/werf/helm/secret/values/list_recipients"
+	helm_secret_values_remove_recipient "github.com/werf/werf/cmd/werf/helm/secret/values/remove_recipient"
 	"github.com/werf/werf/pkg/deploy/helm"
 	"github.com/werf/werf/pkg/deploy/helm/chart_extender"
 	"github.com/werf/werf/pkg/deploy/helm/chart_extender/helpers"
@@ -258,6 +262,9 @@ func secretCmd(ctx context.Context) *cobra.Command {
 		helm_secret_values_encrypt.NewCmd(ctx),
 		helm_secret_values_decrypt.NewCmd(ctx),
 		helm_secret_values_edit.NewCmd(ctx),
+		helm_secret_values_add_recipient.NewCmd(ctx),
+		helm_secret_values_remove_recipient.NewCmd(ctx),
+		helm_secret_values_list_recipients.NewCmd(ctx),
 	)
 
 	cmd.AddCommand(
@@ -266,6 +273,7 @@ func secretCmd(ctx context.Context) *cobra.Command {
 		helm_secret_generate_secret_key.NewCmd(ctx),
 		helm_secret_encrypt.NewCmd(ctx),
 		helm_secret_decrypt.NewCmd(ctx),
+		helm_secret_diff.NewCmd(ctx),
 		helm_secret_rotate_secret_key.NewCmd(ctx),
 	)
 
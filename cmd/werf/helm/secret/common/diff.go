@@ -0,0 +1,231 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	yaml_v3 "gopkg.in/yaml.v3"
+
+	"github.com/werf/werf/pkg/deploy/secrets_manager"
+	"github.com/werf/werf/pkg/giterminism_manager"
+	"github.com/werf/werf/pkg/secret"
+	"github.com/werf/werf/pkg/true_git"
+	"github.com/werf/werf/pkg/util"
+)
+
+const secretDiffMask = "***"
+
+type SecretDiffOptions struct {
+	// FromRevision is a git revision of the old version of the file.
+	FromRevision string
+	// ToRevision is a git revision of the new version of the file, the working tree version is used if empty.
+	ToRevision string
+	// MaskValues hides decrypted values and shows only changed paths.
+	MaskValues bool
+}
+
+// SecretDiff decrypts two versions of the secret file or the secret values file and prints the changes.
+// Secret values files are compared value by value, other secret files are compared line by line.
+func SecretDiff(ctx context.Context, m *secrets_manager.SecretsManager, workingDir string, giterminismManager giterminism_manager.Interface, filePath string, opts SecretDiffOptions) error {
+	encoder, err := m.GetYamlEncoder(ctx, workingDir)
+	if err != nil {
+		return err
+	}
+
+	gitFilePath, err := getSecretDiffGitFilePath(giterminismManager, filePath)
+	if err != nil {
+		return err
+	}
+
+	oldEncodedData, oldDesc, err := readSecretDiffFile(ctx, giterminismManager, filePath, gitFilePath, opts.FromRevision)
+	if err != nil {
+		return err
+	}
+
+	newEncodedData, newDesc, err := readSecretDiffFile(ctx, giterminismManager, filePath, gitFilePath, opts.ToRevision)
+	if err != nil {
+		return err
+	}
+
+	values := isSecretValuesData(oldEncodedData) && isSecretValuesData(newEncodedData)
+
+	oldData, err := decryptSecretDiffData(encoder, oldEncodedData, values)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt %s: %w", oldDesc, err)
+	}
+
+	newData, err := decryptSecretDiffData(encoder, newEncodedData, values)
+	if err != nil {
+		return fmt.Errorf("unable to decrypt %s: %w", newDesc, err)
+	}
+
+	if !values {
+		return printSecretFileDiff(oldData, newData, oldDesc, newDesc, opts.MaskValues)
+	}
+
+	entries, err := secret.DiffYamlData(oldData, newData)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		return nil
+	}
+
+	fmt.Printf("--- %s\n+++ %s\n", oldDesc, newDesc)
+
+	var mask string
+	if opts.MaskValues {
+		mask = secretDiffMask
+	}
+
+	for _, entry := range entries {
+		fmt.Println(entry.String(mask))
+	}
+
+	return nil
+}
+
+// SecretTextconv prints decrypted content of the secret file or the secret values file.
+// Git calls textconv program with the path to a temporary copy of the file, which can be outside of the project.
+func SecretTextconv(ctx context.Context, m *secrets_manager.SecretsManager, workingDir, filePath string) error {
+	encoder, err := m.GetYamlEncoder(ctx, workingDir)
+	if err != nil {
+		return err
+	}
+
+	encodedData, err := ReadFileData(filePath)
+	if err != nil {
+		return err
+	}
+
+	data, err := decryptSecretDiffData(encoder, encodedData, isSecretValuesData(encodedData))
+	if err != nil {
+		return err
+	}
+
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+
+	fmt.Printf("%s", data)
+
+	return nil
+}
+
+func getSecretDiffGitFilePath(giterminismManager giterminism_manager.Interface, filePath string) (string, error) {
+	absFilePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	relFilePath, err := filepath.Rel(giterminismManager.LocalGitRepo().GetWorkTreeDir(), absFilePath)
+	if err != nil {
+		return "", err
+	}
+
+	if relFilePath == ".." || strings.HasPrefix(relFilePath, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("secret file %q is outside of the git work tree %q", absFilePath, giterminismManager.LocalGitRepo().GetWorkTreeDir())
+	}
+
+	return filepath.ToSlash(relFilePath), nil
+}
+
+// readSecretDiffFile reads the file at the specified revision or from the working tree if the revision is empty.
+// Not existing file is considered empty to show the creation or the deletion of the file as a change.
+func readSecretDiffFile(ctx context.Context, giterminismManager giterminism_manager.Interface, filePath, gitFilePath, revision string) ([]byte, string, error) {
+	if revision == "" {
+		desc := fmt.Sprintf("%s (working tree)", gitFilePath)
+
+		if exist, err := util.FileExists(filePath); err != nil {
+			return nil, "", err
+		} else if !exist {
+			return nil, desc, nil
+		}
+
+		data, err := ReadFileData(filePath)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return bytes.TrimSpace(data), desc, nil
+	}
+
+	desc := fmt.Sprintf("%s (%s)", gitFilePath, revision)
+
+	commit, err := true_git.ResolveCommit(ctx, giterminismManager.LocalGitRepo().GetWorkTreeDir(), revision)
+	if err != nil {
+		return nil, "", err
+	}
+
+	gitRepo := giterminismManager.LocalGitRepo()
+	if exist, err := gitRepo.IsCommitFileExist(ctx, commit, gitFilePath); err != nil {
+		return nil, "", err
+	} else if !exist {
+		return nil, desc, nil
+	}
+
+	data, err := gitRepo.ReadCommitFile(ctx, commit, gitFilePath)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return bytes.TrimSpace(data), desc, nil
+}
+
+// isSecretValuesData distinguishes secret values file (yaml map with encrypted values) from secret file (single encrypted string).
+func isSecretValuesData(encodedData []byte) bool {
+	if len(encodedData) == 0 {
+		return true
+	}
+
+	var config yaml_v3.Node
+	if err := yaml_v3.Unmarshal(encodedData, &config); err != nil {
+		return false
+	}
+
+	return len(config.Content) > 0 && config.Content[0].Kind == yaml_v3.MappingNode
+}
+
+func decryptSecretDiffData(encoder *secret.YamlEncoder, encodedData []byte, values bool) ([]byte, error) {
+	encodedData = bytes.TrimSpace(encodedData)
+	if len(encodedData) == 0 {
+		return nil, nil
+	}
+
+	if values {
+		return encoder.DecryptYamlData(encodedData)
+	}
+
+	return encoder.Decrypt(encodedData)
+}
+
+func printSecretFileDiff(oldData, newData []byte, oldDesc, newDesc string, maskValues bool) error {
+	if bytes.Equal(oldData, newData) {
+		return nil
+	}
+
+	if maskValues {
+		fmt.Printf("Secret files %s and %s differ\n", oldDesc, newDesc)
+		return nil
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(strings.TrimSuffix(string(oldData), "\n")),
+		B:        difflib.SplitLines(strings.TrimSuffix(string(newData), "\n")),
+		FromFile: oldDesc,
+		ToFile:   newDesc,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to generate diff: %w", err)
+	}
+
+	fmt.Print(diff)
+
+	return nil
+}
@@ -36,6 +36,18 @@ func SecretEdit(ctx context.Context, m *secrets_manager.SecretsManager, workingD
 		return err
 	}
 
+	// Changed values of the multi-recipient file are encrypted with its data key, and the recipients are kept as is
+	var envelope *secret.YamlEnvelope
+	if values && len(encodedData) > 0 {
+		if envelope, _, err = secret.GetYamlEnvelope(encodedData); err != nil {
+			return err
+		} else if envelope != nil {
+			if encoder, err = encoder.GetYamlEnvelopeDataEncoder(envelope); err != nil {
+				return err
+			}
+		}
+	}
+
 	tmpFilePath := filepath.Join(werf.GetTmpDir(), fmt.Sprintf("werf-edit-secret-%s.yaml", uuid.NewV4().String()))
 	defer os.RemoveAll(tmpFilePath)
 
@@ -86,6 +98,12 @@ func SecretEdit(ctx context.Context, m *secrets_manager.SecretsManager, workingD
 				if err != nil {
 					return fmt.Errorf("unable to merge changed values of encoded yaml: %w", err)
 				}
+
+				if envelope != nil {
+					if newEncodedData, err = secret.SetYamlEnvelope(newEncodedData, envelope); err != nil {
+						return err
+					}
+				}
 			}
 
 			if err := SaveGeneratedData(filePath, newEncodedData); err != nil {
@@ -0,0 +1,142 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/deploy/secrets_manager"
+	"github.com/werf/werf/pkg/secret"
+)
+
+func SecretValuesAddRecipient(ctx context.Context, m *secrets_manager.SecretsManager, workingDir, filePath string, recipientKey []byte, recipientName string) error {
+	encoder, err := m.GetYamlEncoder(ctx, workingDir)
+	if err != nil {
+		return err
+	}
+
+	recipientEncoder, recipientID, err := m.GetRecipientEncoder(recipientKey)
+	if err != nil {
+		return err
+	}
+
+	encodedData, err := ReadFileData(filePath)
+	if err != nil {
+		return err
+	}
+	encodedData = bytes.TrimSpace(encodedData)
+
+	envelope, payload, err := secret.GetYamlEnvelope(encodedData)
+	if err != nil {
+		return err
+	}
+
+	var dataKey []byte
+	if envelope == nil {
+		// The values are re-encrypted only once, when the file is converted to the envelope format
+		if err := logboek.Context(ctx).Default().LogProcess("Converting secret values file %q to the multi-recipient format", filePath).DoError(func() error {
+			data, err := encoder.DecryptYamlData(encodedData)
+			if err != nil {
+				return err
+			}
+
+			ownRecipientEncoder, ownRecipientID, err := m.GetOwnRecipientEncoder(workingDir)
+			if err != nil {
+				return err
+			}
+
+			envelope, dataKey, err = secret.NewYamlEnvelope(ownRecipientID, "", ownRecipientEncoder)
+			if err != nil {
+				return err
+			}
+
+			dataEncoder, err := secret.NewAesEncoder(dataKey)
+			if err != nil {
+				return err
+			}
+
+			payload, err = secret.NewYamlEncoder(dataEncoder).EncryptYamlData(data)
+			return err
+		}); err != nil {
+			return err
+		}
+	} else {
+		dataKey, err = envelope.UnwrapDataKey(encoder.Encoder)
+		if err != nil {
+			return fmt.Errorf("unable to decrypt data key: %w", err)
+		}
+	}
+
+	if err := envelope.AddRecipient(recipientID, recipientName, dataKey, recipientEncoder); err != nil {
+		return err
+	}
+
+	if err := saveSecretValuesEnvelope(filePath, payload, envelope); err != nil {
+		return err
+	}
+
+	logboek.Context(ctx).Default().LogFDetails("Recipient %q added to %q\n", recipientID, filePath)
+
+	return nil
+}
+
+func SecretValuesRemoveRecipient(ctx context.Context, filePath, recipientID string) error {
+	encodedData, err := ReadFileData(filePath)
+	if err != nil {
+		return err
+	}
+
+	envelope, payload, err := secret.GetYamlEnvelope(bytes.TrimSpace(encodedData))
+	if err != nil {
+		return err
+	} else if envelope == nil {
+		return fmt.Errorf("secret values file %q has no recipients", filePath)
+	}
+
+	if err := envelope.RemoveRecipient(recipientID); err != nil {
+		return err
+	}
+
+	if err := saveSecretValuesEnvelope(filePath, payload, envelope); err != nil {
+		return err
+	}
+
+	logboek.Context(ctx).Default().LogFDetails("Recipient %q removed from %q\n", recipientID, filePath)
+	logboek.Context(ctx).Warn().LogLn("NOTE: the data key of the values is not changed and can still be obtained with the removed key from previous revisions of the file: change the secret values themselves if the key is compromised")
+
+	return nil
+}
+
+func SecretValuesListRecipients(filePath string) error {
+	encodedData, err := ReadFileData(filePath)
+	if err != nil {
+		return err
+	}
+
+	envelope, _, err := secret.GetYamlEnvelope(bytes.TrimSpace(encodedData))
+	if err != nil {
+		return err
+	} else if envelope == nil {
+		return fmt.Errorf("secret values file %q has no recipients", filePath)
+	}
+
+	for _, recipient := range envelope.Recipients {
+		if recipient.Name != "" {
+			fmt.Printf("%s %s\n", recipient.ID, recipient.Name)
+		} else {
+			fmt.Println(recipient.ID)
+		}
+	}
+
+	return nil
+}
+
+func saveSecretValuesEnvelope(filePath string, payload []byte, envelope *secret.YamlEnvelope) error {
+	resultData, err := secret.SetYamlEnvelope(payload, envelope)
+	if err != nil {
+		return err
+	}
+
+	return SaveGeneratedData(filePath, resultData)
+}
@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/deploy/secrets_manager"
+	"github.com/werf/werf/pkg/true_git"
+)
+
+// SetupSecretsManagerOptions sets up options required to load the secrets backend settings from the project werf.yaml.
+func SetupSecretsManagerOptions(cmdData *common.CmdData, cmd *cobra.Command) {
+	common.SetupGitWorkTree(cmdData, cmd)
+	common.SetupConfigTemplatesDir(cmdData, cmd)
+	common.SetupConfigPath(cmdData, cmd)
+	common.SetupEnvironment(cmdData, cmd)
+}
+
+// GetSecretsManager returns secrets manager configured with the secrets backend from the project werf.yaml.
+// The default backend is used when the command is running outside of a git work tree or there is no werf.yaml.
+func GetSecretsManager(ctx context.Context, cmdData *common.CmdData) (*secrets_manager.SecretsManager, error) {
+	opts := secrets_manager.SecretsManagerOptions{}
+
+	if err := true_git.Init(ctx, true_git.Options{LiveGitOutput: *cmdData.LogDebug}); err != nil {
+		return nil, err
+	}
+
+	workTree := *cmdData.GitWorkTree
+	if workTree == "" {
+		if found, foundWorkTree, err := true_git.UpwardLookupAndVerifyWorkTree(ctx, common.GetWorkingDir(cmdData)); err != nil {
+			return nil, err
+		} else if !found {
+			return secrets_manager.NewSecretsManager(opts), nil
+		} else {
+			workTree = foundWorkTree
+		}
+	}
+
+	// There is no werf.yaml to read in a repository without commits
+	if _, err := true_git.ResolveCommit(ctx, workTree, "HEAD"); err != nil {
+		return secrets_manager.NewSecretsManager(opts), nil
+	}
+
+	giterminismManager, err := common.GetGiterminismManager(ctx, cmdData)
+	if err != nil {
+		return nil, err
+	}
+
+	_, werfConfig, err := common.GetOptionalWerfConfig(ctx, cmdData, giterminismManager, common.GetWerfConfigOptions(cmdData, false))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load werf config: %w", err)
+	}
+
+	if werfConfig != nil {
+		opts.BackendConfig = werfConfig.Meta.Secrets
+	}
+
+	return secrets_manager.NewSecretsManager(opts), nil
+}
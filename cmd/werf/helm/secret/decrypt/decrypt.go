@@ -61,6 +61,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
 
 	common.SetupGiterminismOptions(&commonCmdData, cmd)
+	secret_common.SetupSecretsManagerOptions(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 
@@ -85,7 +86,12 @@ func runSecretDecrypt(ctx context.Context) error {
 
 	workingDir := common.GetWorkingDir(&commonCmdData)
 
-	return secretDecrypt(ctx, secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{}), workingDir)
+	secretsManager, err := secret_common.GetSecretsManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	return secretDecrypt(ctx, secretsManager, workingDir)
 }
 
 func secretDecrypt(ctx context.Context, m *secrets_manager.SecretsManager, workingDir string) error {
@@ -0,0 +1,122 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/cmd/werf/docs/replacers/helm"
+	secret_common "github.com/werf/werf/cmd/werf/helm/secret/common"
+	"github.com/werf/werf/pkg/git_repo"
+	"github.com/werf/werf/pkg/git_repo/gitdata"
+	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/werf"
+)
+
+var cmdData struct {
+	From       string
+	To         string
+	MaskValues bool
+	Textconv   bool
+}
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "diff FILE_PATH",
+		DisableFlagsInUseLine: true,
+		Short:                 "Show decrypted changes of secret file or secret values file between git revisions",
+		Long:                  common.GetLongCommandDescription(helm.GetHelmSecretDiffDocs().Long),
+		Example: `  # Show changes of secret values file between HEAD and the working tree
+  $ werf helm secret diff .helm/secret-values.yaml
+  --- .helm/secret-values.yaml (HEAD)
+  +++ .helm/secret-values.yaml (working tree)
+  ~ mysql.password: root -> qwerty
+  + mysql.user: admin
+
+  # Show changes of secret values file between two git revisions without values
+  $ werf helm secret diff --from main --to my-feature --mask-values .helm/secret-values.yaml
+  --- .helm/secret-values.yaml (main)
+  +++ .helm/secret-values.yaml (my-feature)
+  ~ mysql.password: *** -> ***
+
+  # Configure git to show decrypted changes with git diff
+  $ git config diff.werf-secret.textconv "werf helm secret diff --textconv"
+  $ echo ".helm/secret-values.yaml diff=werf-secret" >> .gitattributes`,
+		Annotations: map[string]string{
+			common.CmdEnvAnno: common.EnvsDescription(common.WerfSecretKey),
+			common.DocsLongMD: helm.GetHelmSecretDiffDocs().LongMD,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			if err := common.ValidateArgumentCount(1, args, cmd); err != nil {
+				return err
+			}
+
+			return runSecretDiff(ctx, args[0])
+		},
+	})
+
+	common.SetupDir(&commonCmdData, cmd)
+	common.SetupTmpDir(&commonCmdData, cmd, common.SetupTmpDirOptions{})
+	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
+
+	common.SetupGiterminismOptions(&commonCmdData, cmd)
+	secret_common.SetupSecretsManagerOptions(&commonCmdData, cmd)
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+
+	cmd.Flags().StringVarP(&cmdData.From, "from", "", "HEAD", "Git revision of the old version of the file")
+	cmd.Flags().StringVarP(&cmdData.To, "to", "", "", "Git revision of the new version of the file (default: the working tree version)")
+	cmd.Flags().BoolVarP(&cmdData.MaskValues, "mask-values", "", util.GetBoolEnvironmentDefaultFalse("WERF_MASK_VALUES"), "Hide decrypted values and show only paths of the changed values (default $WERF_MASK_VALUES)")
+	cmd.Flags().BoolVarP(&cmdData.Textconv, "textconv", "", false, "Print decrypted content of FILE_PATH to use the command as git textconv program")
+
+	return cmd
+}
+
+func runSecretDiff(ctx context.Context, filePath string) error {
+	if err := werf.Init(*commonCmdData.TmpDir, *commonCmdData.HomeDir); err != nil {
+		return fmt.Errorf("initialization error: %w", err)
+	}
+
+	gitDataManager, err := gitdata.GetHostGitDataManager(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting host git data manager: %w", err)
+	}
+
+	if err := git_repo.Init(gitDataManager); err != nil {
+		return err
+	}
+
+	workingDir := common.GetWorkingDir(&commonCmdData)
+
+	secretsManager, err := secret_common.GetSecretsManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	if cmdData.Textconv {
+		return secret_common.SecretTextconv(ctx, secretsManager, workingDir, filePath)
+	}
+
+	giterminismManager, err := common.GetGiterminismManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	return secret_common.SecretDiff(ctx, secretsManager, workingDir, giterminismManager, filePath, secret_common.SecretDiffOptions{
+		FromRevision: cmdData.From,
+		ToRevision:   cmdData.To,
+		MaskValues:   cmdData.MaskValues,
+	})
+}
@@ -60,6 +60,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
 
 	common.SetupGiterminismOptions(&commonCmdData, cmd)
+	secret_common.SetupSecretsManagerOptions(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 
@@ -84,7 +85,12 @@ func runSecretEncrypt(ctx context.Context) error {
 
 	workingDir := common.GetWorkingDir(&commonCmdData)
 
-	return secretEncrypt(ctx, secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{}), workingDir)
+	secretsManager, err := secret_common.GetSecretsManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	return secretEncrypt(ctx, secretsManager, workingDir)
 }
 
 func secretEncrypt(ctx context.Context, m *secrets_manager.SecretsManager, workingDir string) error {
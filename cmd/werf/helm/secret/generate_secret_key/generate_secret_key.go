@@ -3,14 +3,20 @@ package secret
 import (
 	"context"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/werf/werf/cmd/werf/common"
 	"github.com/werf/werf/cmd/werf/docs/replacers/helm"
+	"github.com/werf/werf/pkg/config"
 	"github.com/werf/werf/pkg/deploy/secrets_manager"
 )
 
+var cmdData struct {
+	Backend string
+}
+
 var commonCmdData common.CmdData
 
 func NewCmd(ctx context.Context) *cobra.Command {
@@ -27,7 +33,10 @@ func NewCmd(ctx context.Context) *cobra.Command {
   $ export WERF_SECRET_KEY=$(werf helm secret generate-secret-key)
 
   # Save encryption key in .werf_secret_key file
-  $ werf helm secret generate-secret-key > .werf_secret_key`,
+  $ werf helm secret generate-secret-key > .werf_secret_key
+
+  # Generate personal identity for the x25519 secrets backend (the recipient is printed to stderr)
+  $ werf helm secret generate-secret-key --backend x25519 > ~/.werf/global_secret_key`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
 				common.PrintHelp(cmd)
@@ -40,16 +49,31 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 
+	cmd.Flags().StringVarP(&cmdData.Backend, "backend", "", os.Getenv("WERF_SECRET_BACKEND"), fmt.Sprintf("Generate key for the specified secrets backend: %q or %q (default %q or $WERF_SECRET_BACKEND)", config.MetaSecretsBackendAes, config.MetaSecretsBackendX25519, config.MetaSecretsBackendAes))
+
 	return cmd
 }
 
 func runGenerateSecretKey() error {
-	key, err := secrets_manager.GenerateSecretKey()
-	if err != nil {
-		return err
-	}
+	switch cmdData.Backend {
+	case "", config.MetaSecretsBackendAes:
+		key, err := secrets_manager.GenerateSecretKey()
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(key))
+	case config.MetaSecretsBackendX25519:
+		identity, recipient, err := secrets_manager.GenerateX25519SecretKey()
+		if err != nil {
+			return err
+		}
 
-	fmt.Println(string(key))
+		fmt.Fprintf(os.Stderr, "Recipient: %s\n", string(recipient))
+		fmt.Println(string(identity))
+	default:
+		return fmt.Errorf("unable to generate secret key for the %q secrets backend", cmdData.Backend)
+	}
 
 	return nil
 }
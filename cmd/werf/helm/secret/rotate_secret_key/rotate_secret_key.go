@@ -22,6 +22,10 @@ import (
 	"github.com/werf/werf/pkg/werf"
 )
 
+var cmdData struct {
+	MigrateFormat bool
+}
+
 var commonCmdData common.CmdData
 
 func NewCmd(ctx context.Context) *cobra.Command {
@@ -60,6 +64,8 @@ func NewCmd(ctx context.Context) *cobra.Command {
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 
+	cmd.Flags().BoolVarP(&cmdData.MigrateFormat, "migrate-format", "", util.GetBoolEnvironmentDefaultFalse("WERF_MIGRATE_FORMAT"), "Regenerate secret files with the current secret key to migrate them to the latest encryption format, $WERF_OLD_SECRET_KEY is not required (default $WERF_MIGRATE_FORMAT)")
+
 	return cmd
 }
 
@@ -96,7 +102,7 @@ func runRotateSecretKey(ctx context.Context, cmd *cobra.Command, secretValuesPat
 		return fmt.Errorf("getting helm chart dir failed: %w", err)
 	}
 
-	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{})
+	secretsManager := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{BackendConfig: werfConfig.Meta.Secrets})
 
 	newEncoder, err := secretsManager.GetYamlEncoder(ctx, giterminismManager.ProjectDir())
 	if err != nil {
@@ -104,10 +110,13 @@ func runRotateSecretKey(ctx context.Context, cmd *cobra.Command, secretValuesPat
 		return err
 	}
 
-	oldEncoder, err := secretsManager.GetYamlEncoderForOldKey(ctx)
-	if err != nil {
-		common.PrintHelp(cmd)
-		return err
+	oldEncoder := newEncoder
+	if !cmdData.MigrateFormat {
+		oldEncoder, err = secretsManager.GetYamlEncoderForOldKey(ctx)
+		if err != nil {
+			common.PrintHelp(cmd)
+			return err
+		}
 	}
 
 	return secretsRegenerate(newEncoder, oldEncoder, helmChartDir, secretValuesPaths...)
@@ -184,6 +193,14 @@ func secretsRegenerate(newEncoder, oldEncoder *secret.YamlEncoder, helmChartDir
 		return err
 	}
 
+	for filePath, fileData := range secretValuesFilesData {
+		if envelope, _, err := secret.GetYamlEnvelope(fileData); err != nil {
+			return fmt.Errorf("unable to read secret values file %q: %w", filePath, err)
+		} else if envelope != nil {
+			return fmt.Errorf("secret values file %q has multiple recipients: use `werf helm secret values add-recipient` and `werf helm secret values remove-recipient` commands to change its keys", filePath)
+		}
+	}
+
 	if err := regenerateSecrets(secretValuesFilesData, regeneratedFilesData, oldEncoder.DecryptYamlData, newEncoder.EncryptYamlData); err != nil {
 		return err
 	}
@@ -0,0 +1,94 @@
+package secret
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/cmd/werf/docs/replacers/helm"
+	secret_common "github.com/werf/werf/cmd/werf/helm/secret/common"
+	"github.com/werf/werf/pkg/deploy/secrets_manager"
+	"github.com/werf/werf/pkg/git_repo"
+	"github.com/werf/werf/pkg/git_repo/gitdata"
+	"github.com/werf/werf/pkg/werf"
+)
+
+var cmdData struct {
+	Name string
+}
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "add-recipient FILE_PATH",
+		DisableFlagsInUseLine: true,
+		Short:                 "Allow one more secret key to decrypt secret values file",
+		Long:                  common.GetLongCommandDescription(helm.GetHelmSecretValuesAddRecipientDocs().Long),
+		Example: `  # Allow production key to decrypt secret values file
+  $ WERF_RECIPIENT_SECRET_KEY=$(cat production_secret_key) werf helm secret values add-recipient .helm/secret-values.yaml --name production`,
+		Annotations: map[string]string{
+			common.CmdEnvAnno: common.EnvsDescription(common.WerfSecretKey, common.WerfRecipientSecretKey),
+			common.DocsLongMD: helm.GetHelmSecretValuesAddRecipientDocs().LongMD,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			if err := common.ValidateArgumentCount(1, args, cmd); err != nil {
+				return err
+			}
+
+			return runSecretAddRecipient(ctx, args[0])
+		},
+	})
+
+	common.SetupDir(&commonCmdData, cmd)
+	common.SetupTmpDir(&commonCmdData, cmd, common.SetupTmpDirOptions{})
+	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
+
+	common.SetupGiterminismOptions(&commonCmdData, cmd)
+	secret_common.SetupSecretsManagerOptions(&commonCmdData, cmd)
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+
+	cmd.Flags().StringVarP(&cmdData.Name, "name", "", "", "Human-readable name of the recipient to show in the recipients list")
+
+	return cmd
+}
+
+func runSecretAddRecipient(ctx context.Context, filePath string) error {
+	if err := werf.Init(*commonCmdData.TmpDir, *commonCmdData.HomeDir); err != nil {
+		return fmt.Errorf("initialization error: %w", err)
+	}
+
+	gitDataManager, err := gitdata.GetHostGitDataManager(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting host git data manager: %w", err)
+	}
+
+	if err := git_repo.Init(gitDataManager); err != nil {
+		return err
+	}
+
+	workingDir := common.GetWorkingDir(&commonCmdData)
+
+	recipientKey, err := secrets_manager.GetRequiredRecipientSecretKey()
+	if err != nil {
+		return err
+	}
+
+	secretsManager, err := secret_common.GetSecretsManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	return secret_common.SecretValuesAddRecipient(ctx, secretsManager, workingDir, filePath, recipientKey, cmdData.Name)
+}
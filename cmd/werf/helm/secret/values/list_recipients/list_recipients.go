@@ -0,0 +1,42 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/cmd/werf/docs/replacers/helm"
+	secret_common "github.com/werf/werf/cmd/werf/helm/secret/common"
+)
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "list-recipients FILE_PATH",
+		DisableFlagsInUseLine: true,
+		Short:                 "List secret keys allowed to decrypt secret values file",
+		Long:                  common.GetLongCommandDescription(helm.GetHelmSecretValuesListRecipientsDocs().Long),
+		Annotations: map[string]string{
+			common.DocsLongMD: helm.GetHelmSecretValuesListRecipientsDocs().LongMD,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			if err := common.ValidateArgumentCount(1, args, cmd); err != nil {
+				return err
+			}
+
+			return secret_common.SecretValuesListRecipients(args[0])
+		},
+	})
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+
+	return cmd
+}
@@ -0,0 +1,49 @@
+package secret
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/cmd/werf/docs/replacers/helm"
+	secret_common "github.com/werf/werf/cmd/werf/helm/secret/common"
+)
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "remove-recipient FILE_PATH RECIPIENT_ID",
+		DisableFlagsInUseLine: true,
+		Short:                 "Disallow secret key to decrypt secret values file",
+		Long:                  common.GetLongCommandDescription(helm.GetHelmSecretValuesRemoveRecipientDocs().Long),
+		Example: `  # List recipients and remove one of them
+  $ werf helm secret values list-recipients .helm/secret-values.yaml
+  4f2a0d7c9b1e3a56 production
+  9c81e2b4d07f5a13
+  $ werf helm secret values remove-recipient .helm/secret-values.yaml 4f2a0d7c9b1e3a56`,
+		Annotations: map[string]string{
+			common.DocsLongMD: helm.GetHelmSecretValuesRemoveRecipientDocs().LongMD,
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			if err := common.ValidateArgumentCount(2, args, cmd); err != nil {
+				return err
+			}
+
+			return secret_common.SecretValuesRemoveRecipient(ctx, args[0], args[1])
+		},
+	})
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+
+	return cmd
+}
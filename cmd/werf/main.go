@@ -31,6 +31,7 @@ import (
 	"github.com/werf/werf/cmd/werf/converge"
 	cr_login "github.com/werf/werf/cmd/werf/cr/login"
 	cr_logout "github.com/werf/werf/cmd/werf/cr/logout"
+	"github.com/werf/werf/cmd/werf/dev"
 	"github.com/werf/werf/cmd/werf/dismiss"
 	"github.com/werf/werf/cmd/werf/docs"
 	kubectl2 "github.com/werf/werf/cmd/werf/docs/replacers/kubectl"
@@ -49,6 +50,8 @@ import (
 	"github.com/werf/werf/cmd/werf/slugify"
 	stage_image "github.com/werf/werf/cmd/werf/stage/image"
 	"github.com/werf/werf/cmd/werf/synchronization"
+	synchronization_locks_list "github.com/werf/werf/cmd/werf/synchronization/locks/list"
+	synchronization_locks_release "github.com/werf/werf/cmd/werf/synchronization/locks/release"
 	"github.com/werf/werf/cmd/werf/version"
 	"github.com/werf/werf/pkg/process_exterminator"
 	"github.com/werf/werf/pkg/telemetry"
@@ -80,6 +83,7 @@ func main() {
 	}
 
 	setupTelemetryInit(rootCmd)
+	setupTracing(rootCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		if helm_v3.IsPluginError(err) {
@@ -140,6 +144,7 @@ Find more information at https://werf.io`),
 				run.NewCmd(ctx),
 				kube_run.NewCmd(ctx),
 				dockerComposeCmd(ctx),
+				devCmd(ctx),
 				slugify.NewCmd(ctx),
 				render.NewCmd(ctx),
 			},
@@ -158,7 +163,7 @@ Find more information at https://werf.io`),
 		{
 			Message: "Other commands",
 			Commands: []*cobra.Command{
-				synchronization.NewCmd(ctx),
+				synchronizationCmd(ctx),
 				completion.NewCmd(ctx, rootCmd),
 				version.NewCmd(ctx),
 				docs.NewCmd(ctx, groups),
@@ -188,6 +193,20 @@ func dockerComposeCmd(ctx context.Context) *cobra.Command {
 	return cmd
 }
 
+func devCmd(ctx context.Context) *cobra.Command {
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:   "dev",
+		Short: "Run local development environment from the compose file without docker-compose",
+	})
+	cmd.AddCommand(
+		dev.NewUpCmd(ctx),
+		dev.NewDownCmd(ctx),
+		dev.NewLogsCmd(ctx),
+	)
+
+	return cmd
+}
+
 func crCmd(ctx context.Context) *cobra.Command {
 	cmd := common.SetCommandContext(ctx, &cobra.Command{
 		Use:   "cr",
@@ -232,6 +251,26 @@ func configCmd(ctx context.Context) *cobra.Command {
 	return cmd
 }
 
+func synchronizationCmd(ctx context.Context) *cobra.Command {
+	cmd := synchronization.NewCmd(ctx)
+	cmd.AddCommand(synchronizationLocksCmd(ctx))
+
+	return cmd
+}
+
+func synchronizationLocksCmd(ctx context.Context) *cobra.Command {
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:   "locks",
+		Short: "Work with stage locks of the synchronization: list held locks and release stale ones",
+	})
+	cmd.AddCommand(
+		synchronization_locks_list.NewCmd(ctx),
+		synchronization_locks_release.NewCmd(ctx),
+	)
+
+	return cmd
+}
+
 func managedImagesCmd(ctx context.Context) *cobra.Command {
 	cmd := common.SetCommandContext(ctx, &cobra.Command{
 		Use:   "managed-images",
@@ -305,3 +344,24 @@ func setupTelemetryInit(rootCmd *cobra.Command) {
 		}
 	}
 }
+
+func setupTracing(rootCmd *cobra.Command) {
+	commandsQueue := []*cobra.Command{rootCmd}
+
+	for len(commandsQueue) > 0 {
+		cmd := commandsQueue[0]
+		commandsQueue = commandsQueue[1:]
+
+		commandsQueue = append(commandsQueue, cmd.Commands()...)
+
+		if cmd.Runnable() && cmd.RunE != nil {
+			oldRunE := cmd.RunE
+
+			cmd.RunE = func(cmd *cobra.Command, args []string) error {
+				return common.RunWithTracing(cmd, func() error {
+					return oldRunE(cmd, args)
+				})
+			}
+		}
+	}
+}
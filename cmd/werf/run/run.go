@@ -2,8 +2,10 @@ package run
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"strings"
 	"time"
 
@@ -140,6 +142,7 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	common.SetupInsecureRegistry(&commonCmdData, cmd)
 	common.SetupInsecureHelmDependencies(&commonCmdData, cmd)
 	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
 
 	common.SetupLogOptions(&commonCmdData, cmd)
 	common.SetupLogProjectDir(&commonCmdData, cmd)
@@ -273,6 +276,10 @@ func runMain(ctx context.Context) error {
 	}()
 
 	if *commonCmdData.Follow {
+		if _, isDockerServer := containerBackend.(*container_backend.DockerServerBackend); !isDockerServer {
+			return fmt.Errorf("follow mode is supported only by the docker server backend")
+		}
+
 		if cmdData.Shell || cmdData.Bash {
 			return fmt.Errorf("follow mode does not work with --shell and --bash options")
 		}
@@ -322,6 +329,11 @@ func runMain(ctx context.Context) error {
 				common.TerminateWithError(err.Error(), statusErr.StatusCode)
 			}
 
+			var exitErr *exec.ExitError
+			if errors.As(err, &exitErr) {
+				common.TerminateWithError(err.Error(), exitErr.ExitCode())
+			}
+
 			return err
 		}
 
@@ -412,6 +424,10 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 		return err
 	}
 
+	if _, isDockerServer := containerBackend.(*container_backend.DockerServerBackend); !isDockerServer {
+		return runWithContainerBackend(ctx, containerBackend, dockerImageName)
+	}
+
 	var dockerRunArgs []string
 	dockerRunArgs = append(dockerRunArgs, cmdData.DockerOptions...)
 	dockerRunArgs = append(dockerRunArgs, dockerImageName)
@@ -429,6 +445,28 @@ func run(ctx context.Context, containerBackend container_backend.ContainerBacken
 	}
 }
 
+// runWithContainerBackend runs the container with the backend other than the docker server (e.g. buildah), only the
+// common subset of docker run options is supported.
+func runWithContainerBackend(ctx context.Context, containerBackend container_backend.ContainerBackend, dockerImageName string) error {
+	opts, err := container_backend.ParseDockerRunOptions(cmdData.DockerOptions)
+	if err != nil {
+		return fmt.Errorf("unable to parse docker options: %w", err)
+	}
+	opts.Command = cmdData.DockerCommand
+
+	if *commonCmdData.DryRun {
+		// Podman uses the same local storage as the buildah backend and accepts docker run options.
+		fmt.Printf("podman run %s\n", strings.Join(opts.DockerRunArgs(dockerImageName), " "))
+		return nil
+	}
+
+	return logboek.Streams().DoErrorWithoutProxyStreamDataFormatting(func() error {
+		return common.WithoutTerminationSignalsTrap(func() error {
+			return containerBackend.RunContainer(ctx, dockerImageName, opts)
+		})
+	})
+}
+
 func safeDockerCliRmFunc(ctx context.Context, containerName string) error {
 	if exist, err := docker.ContainerExist(ctx, containerName); err != nil {
 		return fmt.Errorf("unable to check container %s existence: %w", containerName, err)
@@ -5,9 +5,12 @@ import "github.com/werf/werf/cmd/werf/docs/structs"
 func GetRunDocs() structs.DocsStruct {
 	var docs structs.DocsStruct
 
-	docs.Long = `Run container for specified project image from werf.yaml (build if needed).`
+	docs.Long = `Run container for specified project image from werf.yaml (build if needed).
 
-	docs.LongMD = "Run container for specified project image from `werf.yaml` (build if needed)."
+With the Buildah backend ($WERF_BUILDAH_MODE) the container is run by buildah without Docker server. Only the following docker options are supported then: -i, -t, --rm, --name, -e/--env, -v/--volume (host paths), -p/--publish (the same host and container port, the container uses the host network), -w/--workdir, -u/--user, --entrypoint, --network (host, none or default) and --platform. Detached containers and follow mode are not supported.`
+
+	docs.LongMD = "Run container for specified project image from `werf.yaml` (build if needed).\n\n" +
+		"With the Buildah backend (`$WERF_BUILDAH_MODE`) the container is run by buildah without Docker server. Only the following docker options are supported then: `-i`, `-t`, `--rm`, `--name`, `-e/--env`, `-v/--volume` (host paths), `-p/--publish` (the same host and container port, the container uses the host network), `-w/--workdir`, `-u/--user`, `--entrypoint`, `--network` (`host`, `none` or `default`) and `--platform`. Detached containers and follow mode are not supported."
 
 	return docs
 }
@@ -0,0 +1,200 @@
+package list
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gookit/color"
+	"github.com/rodaine/table"
+	"github.com/spf13/cobra"
+
+	"github.com/werf/logboek"
+	"github.com/werf/logboek/pkg/level"
+	"github.com/werf/werf/cmd/werf/common"
+	"github.com/werf/werf/pkg/git_repo"
+	"github.com/werf/werf/pkg/git_repo/gitdata"
+	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/storage/lrumeta"
+	"github.com/werf/werf/pkg/tmp_manager"
+	"github.com/werf/werf/pkg/true_git"
+	"github.com/werf/werf/pkg/werf"
+)
+
+var commonCmdData common.CmdData
+
+func NewCmd(ctx context.Context) *cobra.Command {
+	ctx = common.NewContextWithCmdData(ctx, &commonCmdData)
+	cmd := common.SetCommandContext(ctx, &cobra.Command{
+		Use:                   "list",
+		Aliases:               []string{"ls"},
+		DisableFlagsInUseLine: true,
+		Short:                 "List stage locks of the project, which are held in the synchronization.",
+		Long: common.GetLongCommandDescription(`List stage locks of the project, which are held in the synchronization.
+
+For each lock the owner client ID of the werf process holding it (HOSTNAME/PID), the acquisition time and the time left till the lock expires unless its holder renews the lease are printed.
+
+Locks of the :local synchronization cannot be listed.`),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			if err := common.ProcessLogOptions(&commonCmdData); err != nil {
+				common.PrintHelp(cmd)
+				return err
+			}
+
+			return run(ctx)
+		},
+	})
+
+	common.SetupDir(&commonCmdData, cmd)
+	common.SetupGitWorkTree(&commonCmdData, cmd)
+	common.SetupConfigTemplatesDir(&commonCmdData, cmd)
+	common.SetupConfigPath(&commonCmdData, cmd)
+	common.SetupEnvironment(&commonCmdData, cmd)
+
+	common.SetupGiterminismOptions(&commonCmdData, cmd)
+
+	common.SetupTmpDir(&commonCmdData, cmd, common.SetupTmpDirOptions{})
+	common.SetupHomeDir(&commonCmdData, cmd, common.SetupHomeDirOptions{})
+	common.SetupSSHKey(&commonCmdData, cmd)
+
+	common.SetupRepoOptions(&commonCmdData, cmd, common.RepoDataOptions{})
+
+	common.SetupDockerConfig(&commonCmdData, cmd, "Command needs granted permissions to read the synchronization client ID from the specified repo")
+	common.SetupInsecureRegistry(&commonCmdData, cmd)
+	common.SetupSkipTlsVerifyRegistry(&commonCmdData, cmd)
+	common.SetupRegistryMaxConcurrentRequests(&commonCmdData, cmd)
+
+	common.SetupLogOptions(&commonCmdData, cmd)
+	common.SetupLogProjectDir(&commonCmdData, cmd)
+
+	common.SetupSynchronization(&commonCmdData, cmd)
+	common.SetupKubeConfig(&commonCmdData, cmd)
+	common.SetupKubeConfigBase64(&commonCmdData, cmd)
+	common.SetupKubeContext(&commonCmdData, cmd)
+
+	commonCmdData.SetupPlatform(cmd)
+
+	return cmd
+}
+
+func run(ctx context.Context) error {
+	if err := werf.Init(*commonCmdData.TmpDir, *commonCmdData.HomeDir); err != nil {
+		return fmt.Errorf("initialization error: %w", err)
+	}
+
+	containerBackend, processCtx, err := common.InitProcessContainerBackend(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+	ctx = processCtx
+
+	if logboek.Context(ctx).IsAcceptedLevel(level.Default) {
+		logboek.Context(ctx).SetAcceptedLevel(level.Error)
+	}
+
+	gitDataManager, err := gitdata.GetHostGitDataManager(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting host git data manager: %w", err)
+	}
+
+	if err := git_repo.Init(gitDataManager); err != nil {
+		return err
+	}
+
+	if err := true_git.Init(ctx, true_git.Options{LiveGitOutput: *commonCmdData.LogDebug}); err != nil {
+		return err
+	}
+
+	if err := image.Init(); err != nil {
+		return err
+	}
+
+	if err := lrumeta.Init(); err != nil {
+		return err
+	}
+
+	if err := common.DockerRegistryInit(ctx, &commonCmdData); err != nil {
+		return err
+	}
+
+	projectTmpDir, err := tmp_manager.CreateProjectDir(ctx)
+	if err != nil {
+		return fmt.Errorf("getting project tmp dir failed: %w", err)
+	}
+	defer tmp_manager.ReleaseProjectDir(projectTmpDir)
+
+	giterminismManager, err := common.GetGiterminismManager(ctx, &commonCmdData)
+	if err != nil {
+		return err
+	}
+
+	_, werfConfig, err := common.GetOptionalWerfConfig(ctx, &commonCmdData, giterminismManager, common.GetWerfConfigOptions(&commonCmdData, false))
+	if err != nil {
+		return fmt.Errorf("unable to load werf config: %w", err)
+	}
+
+	var projectName string
+	if werfConfig != nil {
+		projectName = werfConfig.Meta.Project
+	} else {
+		return fmt.Errorf("run command in the project directory with werf.yaml")
+	}
+
+	// The repo is only needed to get the client ID of the http synchronization
+	var stagesStorage storage.StagesStorage
+	if *commonCmdData.Repo.Address != "" {
+		if stagesStorage, err = common.GetStagesStorage(ctx, containerBackend, &commonCmdData); err != nil {
+			return err
+		}
+	} else {
+		stagesStorage = common.GetLocalStagesStorage(containerBackend)
+	}
+
+	synchronization, err := common.GetSynchronization(ctx, &commonCmdData, projectName, stagesStorage)
+	if err != nil {
+		return err
+	}
+	if err := common.CheckLocksInspectionSupported(synchronization); err != nil {
+		return err
+	}
+	storageLockManager, err := common.GetStorageLockManager(ctx, synchronization)
+	if err != nil {
+		return err
+	}
+
+	locks, err := storageLockManager.ListLocks(ctx, projectName)
+	if err != nil {
+		return fmt.Errorf("unable to list locks of project %q: %w", projectName, err)
+	}
+
+	if len(locks) == 0 {
+		return nil
+	}
+
+	tbl := table.New("Lock", "Owner", "Acquired", "TTL", "Holders")
+	tbl.WithHeaderFormatter(func(format string, a ...interface{}) string {
+		return logboek.ColorizeF(color.New(color.OpUnderscore), format, a...)
+	})
+	for _, lock := range locks {
+		owner, acquired := "-", "-"
+		if lock.OwnerClientID != "" {
+			owner = lock.OwnerClientID
+		}
+		if !lock.AcquiredAt.IsZero() {
+			acquired = lock.AcquiredAt.Local().Format(time.RFC3339)
+		}
+
+		holders := "1"
+		if lock.IsShared {
+			holders = fmt.Sprintf("%d (shared)", lock.SharedHoldersCount)
+		}
+
+		tbl.AddRow(lock.Name, owner, acquired, lock.TTL().Round(time.Second), holders)
+	}
+	tbl.Print()
+
+	return nil
+}
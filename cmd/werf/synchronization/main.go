@@ -29,10 +29,19 @@ var cmdData struct {
 	Local                          bool
 	LocalLockManagerBaseDir        string
 	LocalStagesStorageCacheBaseDir string
+	LocalStagesStorageCacheDb      bool
+	LocalLockManagerDb             bool
 
 	TTL  string
 	Host string
 	Port string
+
+	AuthConfig      string
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+
+	MetricsAddress string
 }
 
 var commonCmdData common.CmdData
@@ -44,7 +53,9 @@ func NewCmd(ctx context.Context) *cobra.Command {
 		Short:                 "Run synchronization server",
 		Long:                  common.GetLongCommandDescription(`Run synchronization server`),
 		DisableFlagsInUseLine: true,
-		Annotations:           map[string]string{},
+		Annotations: map[string]string{
+			common.DocsRunnableCommandGroupAnno: "",
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
@@ -73,6 +84,8 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().BoolVarP(&cmdData.Local, "local", "", util.GetBoolEnvironmentDefaultTrue("WERF_LOCAL"), "Use file lock-manager and file stages-storage-cache (true by default or $WERF_LOCAL)")
 	cmd.Flags().StringVarP(&cmdData.LocalLockManagerBaseDir, "local-lock-manager-base-dir", "", os.Getenv("WERF_LOCAL_LOCK_MANAGER_BASE_DIR"), "Use specified directory as base for file lock-manager (~/.werf/synchronization_server/lock_manager by default or $WERF_LOCAL_LOCK_MANAGER_BASE_DIR)")
 	cmd.Flags().StringVarP(&cmdData.LocalStagesStorageCacheBaseDir, "local-stages-storage-cache-base-dir", "", os.Getenv("WERF_LOCAL_STAGES_STORAGE_CACHE_BASE_DIR"), "Use specified directory as base for file stages-storage-cache (~/.werf/synchronization_server/stages_storage_cache by default or $WERF_LOCAL_STAGES_STORAGE_CACHE_BASE_DIR)")
+	cmd.Flags().BoolVarP(&cmdData.LocalStagesStorageCacheDb, "local-stages-storage-cache-db", "", util.GetBoolEnvironmentDefaultFalse("WERF_LOCAL_STAGES_STORAGE_CACHE_DB"), "Keep file stages-storage-cache in the embedded database file in the --local-stages-storage-cache-base-dir instead of separate json files (default $WERF_LOCAL_STAGES_STORAGE_CACHE_DB)")
+	cmd.Flags().BoolVarP(&cmdData.LocalLockManagerDb, "local-lock-manager-db", "", util.GetBoolEnvironmentDefaultFalse("WERF_LOCAL_LOCK_MANAGER_DB"), "Keep lock-manager locks in the embedded database file in the --local-lock-manager-base-dir, so that locks survive the server restart. Locks are kept in memory otherwise (default $WERF_LOCAL_LOCK_MANAGER_DB)")
 
 	cmd.Flags().BoolVarP(&cmdData.Kubernetes, "kubernetes", "", util.GetBoolEnvironmentDefaultFalse("WERF_KUBERNETES"), "Use kubernetes lock-manager stages-storage-cache (default $WERF_KUBERNETES)")
 	cmd.Flags().StringVarP(&cmdData.KubernetesNamespacePrefix, "kubernetes-namespace-prefix", "", os.Getenv("WERF_KUBERNETES_NAMESPACE_PREFIX"), "Use specified prefix for namespaces created for lock-manager and stages-storage-cache (defaults to 'werf-synchronization-' when --kubernetes option is used or $WERF_KUBERNETES_NAMESPACE_PREFIX)")
@@ -81,6 +94,13 @@ func NewCmd(ctx context.Context) *cobra.Command {
 	cmd.Flags().StringVarP(&cmdData.Host, "host", "", os.Getenv("WERF_HOST"), "Bind synchronization server to the specified host (default localhost or $WERF_HOST)")
 	cmd.Flags().StringVarP(&cmdData.Port, "port", "", os.Getenv("WERF_PORT"), "Bind synchronization server to the specified port (default 55581 or $WERF_PORT)")
 
+	cmd.Flags().StringVarP(&cmdData.AuthConfig, "auth-config", "", os.Getenv("WERF_AUTH_CONFIG"), "Require clients to authenticate with the bearer token or the client certificate of the project listed in the specified YAML file. Synchronization data of each project is isolated. Authentication is disabled by default (default $WERF_AUTH_CONFIG)")
+	cmd.Flags().StringVarP(&cmdData.TLSCertFile, "tls-cert-file", "", os.Getenv("WERF_TLS_CERT_FILE"), "Serve HTTPS using the specified certificate file (default $WERF_TLS_CERT_FILE)")
+	cmd.Flags().StringVarP(&cmdData.TLSKeyFile, "tls-key-file", "", os.Getenv("WERF_TLS_KEY_FILE"), "Private key file of the --tls-cert-file certificate (default $WERF_TLS_KEY_FILE)")
+	cmd.Flags().StringVarP(&cmdData.TLSClientCAFile, "tls-client-ca-file", "", os.Getenv("WERF_TLS_CLIENT_CA_FILE"), "Verify client certificates with the specified CA bundle, the common name of the verified certificate authenticates the project from the --auth-config (default $WERF_TLS_CLIENT_CA_FILE)")
+
+	cmd.Flags().StringVarP(&cmdData.MetricsAddress, "metrics-address", "", os.Getenv("WERF_METRICS_ADDRESS"), "Serve Prometheus metrics on the specified HOST:PORT without authentication instead of the /metrics endpoint of the main address, which requires authentication when --auth-config is specified (default $WERF_METRICS_ADDRESS)")
+
 	return cmd
 }
 
@@ -98,6 +118,28 @@ func runSynchronization(ctx context.Context) error {
 		return err
 	}
 
+	if (cmdData.TLSCertFile == "") != (cmdData.TLSKeyFile == "") {
+		return fmt.Errorf("both --tls-cert-file and --tls-key-file should be specified")
+	}
+	if cmdData.TLSClientCAFile != "" && cmdData.TLSCertFile == "" {
+		return fmt.Errorf("--tls-client-ca-file requires --tls-cert-file and --tls-key-file")
+	}
+
+	serverOpts := synchronization_server.SynchronizationServerOptions{
+		TLSCertFile:     cmdData.TLSCertFile,
+		TLSKeyFile:      cmdData.TLSKeyFile,
+		TLSClientCAFile: cmdData.TLSClientCAFile,
+		MetricsAddress:  cmdData.MetricsAddress,
+	}
+
+	if cmdData.AuthConfig != "" {
+		authConfig, err := synchronization_server.LoadAuthConfig(cmdData.AuthConfig)
+		if err != nil {
+			return err
+		}
+		serverOpts.AuthConfig = authConfig
+	}
+
 	host, port := cmdData.Host, cmdData.Port
 	if host == "" {
 		host = "localhost"
@@ -151,15 +193,27 @@ func runSynchronization(ctx context.Context) error {
 			stagesStorageCacheBaseDir = filepath.Join(werf.GetHomeDir(), "synchronization_server", "stages_storage_cache")
 		}
 
+		lockManagerBaseDir := cmdData.LocalLockManagerBaseDir
+		if lockManagerBaseDir == "" {
+			lockManagerBaseDir = filepath.Join(werf.GetHomeDir(), "synchronization_server", "lock_manager")
+		}
+
 		distributedLockerBackendFactoryFunc = func(clientID string) (distributed_locker.DistributedLockerBackend, error) {
+			if cmdData.LocalLockManagerDb {
+				return storage.NewBoltLockerBackend(filepath.Join(lockManagerBaseDir, storage.BoltSynchronizationDatabaseFileName), clientID), nil
+			}
+
 			store := optimistic_locking_store.NewInMemoryStore()
 			return distributed_locker.NewOptimisticLockingStorageBasedBackend(store), nil
 		}
 
 		stagesStorageCacheFactoryFunc = func(clientID string) (synchronization_server.StagesStorageCacheInterface, error) {
+			if cmdData.LocalStagesStorageCacheDb {
+				return storage.NewBoltStagesStorageCache(filepath.Join(stagesStorageCacheBaseDir, storage.BoltSynchronizationDatabaseFileName), clientID), nil
+			}
 			return storage.NewFileStagesStorageCache(filepath.Join(stagesStorageCacheBaseDir, clientID)), nil
 		}
 	}
 
-	return synchronization_server.RunSynchronizationServer(ctx, host, port, distributedLockerBackendFactoryFunc, stagesStorageCacheFactoryFunc)
+	return synchronization_server.RunSynchronizationServer(ctx, host, port, distributedLockerBackendFactoryFunc, stagesStorageCacheFactoryFunc, serverOpts)
 }
@@ -0,0 +1,134 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/werf/werf/pkg/docker_registry"
+)
+
+// ReportArtifactRecord describes the artifact (provenance, SBOM) attached to the image in the repo.
+type ReportArtifactRecord struct {
+	ArtifactType      string
+	DockerImageName   string
+	DockerImageDigest string
+	Document          json.RawMessage `json:",omitempty"`
+}
+
+type attachArtifactOptions struct {
+	ArtifactType   string
+	LayerMediaType string
+	// NewDocument returns the artifact content for the image with the specified manifest digest.
+	NewDocument func(subjectDigest string) ([]byte, error)
+}
+
+// attachArtifact pushes the artifact referencing the image manifest as the subject (OCI referrers).
+// The artifact is pushed by digest, so it is kept by the registry as long as it is referenced by the image.
+// The existing artifact of the same type is reused, thus the artifact is created only once for the image.
+func attachArtifact(ctx context.Context, registry docker_registry.GenericApiInterface, repository, imageName string, opts attachArtifactOptions) (*ReportArtifactRecord, []byte, error) {
+	subject, err := registry.GetDescriptor(ctx, imageName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	referrers, err := registry.GetReferrers(ctx, fmt.Sprintf("%s@%s", repository, subject.Digest), opts.ArtifactType)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, referrer := range referrers {
+		artifactReference := fmt.Sprintf("%s@%s", repository, referrer.Digest)
+		artifact, err := registry.TryGetArtifact(ctx, artifactReference)
+		if err != nil {
+			return nil, nil, err
+		}
+		if artifact == nil {
+			continue
+		}
+
+		data, err := readArtifactDocument(artifact, opts.LayerMediaType)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to read artifact %s: %w", artifactReference, err)
+		}
+		if data == nil {
+			continue
+		}
+
+		return newReportArtifactRecord(opts.ArtifactType, repository, referrer.Digest), data, nil
+	}
+
+	data, err := opts.NewDocument(subject.Digest.String())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	artifact := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	artifact = mutate.ConfigMediaType(artifact, types.MediaType(opts.ArtifactType))
+	artifact, err = mutate.Append(artifact, mutate.Addendum{
+		Layer: static.NewLayer(data, types.MediaType(opts.LayerMediaType)),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to add artifact layer: %w", err)
+	}
+
+	withSubject := mutate.Subject(artifact, v1.Descriptor{MediaType: subject.MediaType, Size: subject.Size, Digest: subject.Digest})
+	artifact, ok := withSubject.(v1.Image)
+	if !ok {
+		return nil, nil, fmt.Errorf("unexpected artifact type %T", withSubject)
+	}
+
+	artifactDigest, err := artifact.Digest()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to get artifact digest: %w", err)
+	}
+
+	if err := registry.PushArtifact(ctx, fmt.Sprintf("%s@%s", repository, artifactDigest), artifact); err != nil {
+		return nil, nil, err
+	}
+
+	return newReportArtifactRecord(opts.ArtifactType, repository, artifactDigest), data, nil
+}
+
+func newReportArtifactRecord(artifactType, repository string, digest v1.Hash) *ReportArtifactRecord {
+	return &ReportArtifactRecord{
+		ArtifactType:      artifactType,
+		DockerImageName:   fmt.Sprintf("%s@%s", repository, digest),
+		DockerImageDigest: digest.String(),
+	}
+}
+
+func readArtifactDocument(artifact v1.Image, layerMediaType string) ([]byte, error) {
+	manifest, err := artifact.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, layerDesc := range manifest.Layers {
+		if string(layerDesc.MediaType) != layerMediaType {
+			continue
+		}
+
+		layer, err := artifact.LayerByDigest(layerDesc.Digest)
+		if err != nil {
+			return nil, err
+		}
+
+		rc, err := layer.Compressed()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+
+		return io.ReadAll(rc)
+	}
+
+	return nil, nil
+}
@@ -15,6 +15,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/werf/logboek"
 	"github.com/werf/logboek/pkg/style"
@@ -28,9 +29,12 @@ import (
 	"github.com/werf/werf/pkg/git_repo"
 	imagePkg "github.com/werf/werf/pkg/image"
 	"github.com/werf/werf/pkg/logging"
+	"github.com/werf/werf/pkg/sbom"
+	"github.com/werf/werf/pkg/signing"
 	"github.com/werf/werf/pkg/stapel"
 	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/storage/manager"
+	"github.com/werf/werf/pkg/tracing"
 	"github.com/werf/werf/pkg/util"
 	"github.com/werf/werf/pkg/werf"
 )
@@ -49,6 +53,13 @@ type BuildOptions struct {
 
 	SkipImageMetadataPublication bool
 	CustomTagFuncList            []imagePkg.CustomTagFunc
+
+	// Signer signs the final images in the repo if set.
+	Signer *signing.Signer
+	// Provenance enables SLSA provenance attestations for the final images in the repo.
+	Provenance bool
+	// SBOMFormat enables the SBOM generation for the final images in the repo if set.
+	SBOMFormat sbom.Format
 }
 
 type IntrospectOptions struct {
@@ -75,6 +86,7 @@ func NewBuildPhase(c *Conveyor, opts BuildPhaseOptions) *BuildPhase {
 		BasePhase:         BasePhase{c},
 		BuildPhaseOptions: opts,
 		ImagesReport:      NewImagesReport(),
+		stagesReport:      newStagesReportRecorder(),
 	}
 }
 
@@ -86,11 +98,19 @@ type BuildPhase struct {
 	ImagesReport   *ImagesReport
 
 	buildContextArchive container_backend.BuildContextArchiver
+
+	stagesReport *stagesReportRecorder
+
+	buildStartedAt    time.Time
+	provenanceRecords map[string]*ReportArtifactRecord
+	sbomRecords       map[string]*ReportArtifactRecord
 }
 
 const (
-	ReportJSON    ReportFormat = "json"
-	ReportEnvFile ReportFormat = "envfile"
+	ReportJSON     ReportFormat = "json"
+	ReportEnvFile  ReportFormat = "envfile"
+	ReportJUnit    ReportFormat = "junit"
+	ReportMarkdown ReportFormat = "markdown"
 )
 
 type ReportFormat string
@@ -174,6 +194,11 @@ type ReportImageRecord struct {
 	DockerImageDigest string
 	DockerImageName   string
 	Rebuilt           bool
+	// Stages are the stages of the image in the build order (of all platforms for the multiplatform image).
+	Stages []ReportStageRecord `json:",omitempty"`
+
+	Provenance *ReportArtifactRecord `json:",omitempty"`
+	SBOM       *ReportArtifactRecord `json:",omitempty"`
 }
 
 func (phase *BuildPhase) Name() string {
@@ -181,6 +206,8 @@ func (phase *BuildPhase) Name() string {
 }
 
 func (phase *BuildPhase) BeforeImages(ctx context.Context) error {
+	phase.buildStartedAt = time.Now()
+
 	if err := phase.Conveyor.StorageManager.InitCache(ctx); err != nil {
 		return fmt.Errorf("unable to init storage manager cache: %w", err)
 	}
@@ -291,9 +318,68 @@ func (phase *BuildPhase) AfterImages(ctx context.Context) error {
 		}
 	}
 
+	if phase.Signer != nil {
+		if err := phase.signImages(ctx); err != nil {
+			return err
+		}
+	}
+
+	if phase.Provenance {
+		if err := phase.attachProvenance(ctx); err != nil {
+			return err
+		}
+	}
+
+	if phase.SBOMFormat != "" {
+		if err := phase.attachSBOM(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := phase.Conveyor.exportCacheMounts(ctx); err != nil {
+		return err
+	}
+
 	return phase.createReport(ctx)
 }
 
+func (phase *BuildPhase) signImages(ctx context.Context) error {
+	if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); isLocal {
+		return fmt.Errorf("signing images requires the repo: specify --repo or disable signing by unsetting --sign-key")
+	}
+
+	return logboek.Context(ctx).Default().LogProcess("Signing images").DoError(func() error {
+		for _, desc := range phase.Conveyor.imagesTree.GetImagesByName(true) {
+			name, images := desc.Unpair()
+			stageDesc := phase.getFinalImageStageDescription(name, images)
+
+			logboek.Context(ctx).Default().LogF("Signing image %q: %s\n", name, stageDesc.Info.Name)
+			if err := signing.SignImage(ctx, docker_registry.API(), phase.Signer, stageDesc.Info.Name); err != nil {
+				return fmt.Errorf("unable to sign image %q: %w", name, err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// getFinalImageStageDescription returns the description of the image (or the image index for the multiplatform image) published in the repo.
+func (phase *BuildPhase) getFinalImageStageDescription(name string, images []*image.Image) *imagePkg.StageDescription {
+	if len(images) == 1 {
+		stageImage := images[0].GetLastNonEmptyStage().GetStageImage().Image
+		if desc := stageImage.GetFinalStageDescription(); desc != nil {
+			return desc
+		}
+		return stageImage.GetStageDescription()
+	}
+
+	img := phase.Conveyor.imagesTree.GetMultiplatformImage(name)
+	if desc := img.GetFinalStageDescription(); desc != nil {
+		return desc
+	}
+	return img.GetStageDescription()
+}
+
 func (phase *BuildPhase) publishFinalImage(ctx context.Context, name string, img *image.Image, finalStagesStorage storage.StagesStorage) error {
 	stg := img.GetLastNonEmptyStage()
 
@@ -469,12 +555,15 @@ func (phase *BuildPhase) createReport(ctx context.Context) error {
 				DockerImageDigest: desc.Info.GetDigest(),
 				DockerImageName:   desc.Info.Name,
 				Rebuilt:           img.GetRebuilt(),
+				Stages:            phase.getImageStagesReport(img, false),
 			}
 
 			if os.Getenv("WERF_ENABLE_REPORT_BY_PLATFORM") == "1" {
 				phase.ImagesReport.SetImageByPlatformRecord(img.TargetPlatform, img.GetName(), record)
 			}
 			if len(targetPlatforms) == 1 {
+				record.Provenance = phase.provenanceRecords[img.Name]
+				record.SBOM = phase.sbomRecords[img.Name]
 				phase.ImagesReport.SetImageRecord(img.Name, record)
 			}
 		}
@@ -484,8 +573,10 @@ func (phase *BuildPhase) createReport(ctx context.Context) error {
 				img := phase.Conveyor.imagesTree.GetMultiplatformImage(name)
 
 				isRebuilt := false
+				var stages []ReportStageRecord
 				for _, pImg := range img.Images {
 					isRebuilt = (isRebuilt || pImg.GetRebuilt())
+					stages = append(stages, phase.getImageStagesReport(pImg, true)...)
 				}
 
 				desc := img.GetFinalStageDescription()
@@ -501,6 +592,9 @@ func (phase *BuildPhase) createReport(ctx context.Context) error {
 					DockerImageDigest: desc.Info.GetDigest(),
 					DockerImageName:   desc.Info.Name,
 					Rebuilt:           isRebuilt,
+					Stages:            stages,
+					Provenance:        phase.provenanceRecords[img.Name],
+					SBOM:              phase.sbomRecords[img.Name],
 				}
 				phase.ImagesReport.SetImageRecord(img.Name, record)
 			}
@@ -522,6 +616,14 @@ func (phase *BuildPhase) createReport(ctx context.Context) error {
 		case ReportEnvFile:
 			data = phase.ImagesReport.ToEnvFileData()
 			logboek.Context(ctx).Debug().LogF("Writing envfile report to the %q:\n%s", phase.ReportPath, data)
+		case ReportJUnit:
+			if data, err = phase.ImagesReport.ToJUnitData(); err != nil {
+				return fmt.Errorf("unable to prepare report junit xml: %w", err)
+			}
+			logboek.Context(ctx).Debug().LogF("Writing junit report to the %q:\n%s", phase.ReportPath, data)
+		case ReportMarkdown:
+			data = phase.ImagesReport.ToMarkdownData()
+			logboek.Context(ctx).Debug().LogF("Writing markdown report to the %q:\n%s", phase.ReportPath, data)
 		default:
 			panic(fmt.Sprintf("unknown report format %q", phase.ReportFormat))
 		}
@@ -534,6 +636,42 @@ func (phase *BuildPhase) createReport(ctx context.Context) error {
 	return nil
 }
 
+// getImageStagesReport returns the records of the stages the image consists of, empty stages are skipped.
+func (phase *BuildPhase) getImageStagesReport(img *image.Image, withPlatform bool) []ReportStageRecord {
+	var res []ReportStageRecord
+
+	for _, stg := range img.GetStages() {
+		record, ok := phase.stagesReport.Get(stg)
+		if !ok || stg.GetStageImage() == nil || stg.GetStageImage().Image.GetStageDescription() == nil {
+			continue
+		}
+
+		desc := stg.GetStageImage().Image.GetStageDescription()
+		record.Name = string(stg.Name())
+		record.Digest = stg.GetDigest()
+		record.DockerImageName = desc.Info.Name
+		record.Size = desc.Info.Size
+		if withPlatform {
+			record.Platform = img.TargetPlatform
+		}
+
+		if record.Source == ReportStageSourceRepo {
+			if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); isLocal {
+				record.Source = ReportStageSourceLocal
+			} else if fetchSource := phase.Conveyor.StorageManager.GetStageFetchSource(desc.Info.Name); fetchSource != nil && fetchSource != storage.StagesStorage(phase.Conveyor.StorageManager.GetStagesStorage()) {
+				record.Source = ReportStageSourceCacheRepo
+				record.SourceRepo = fetchSource.String()
+			} else {
+				record.SourceRepo = phase.Conveyor.StorageManager.GetStagesStorage().String()
+			}
+		}
+
+		res = append(res, record)
+	}
+
+	return res
+}
+
 func (phase *BuildPhase) ImageProcessingShouldBeStopped(_ context.Context, _ *image.Image) bool {
 	return false
 }
@@ -724,6 +862,7 @@ func (phase *BuildPhase) onImageStage(ctx context.Context, img *image.Image, stg
 		}
 	}
 
+	calculationStartedAt := time.Now()
 	foundSuitableStage, cleanupFunc, err := phase.calculateStage(ctx, img, stg)
 	if cleanupFunc != nil {
 		defer cleanupFunc()
@@ -731,8 +870,15 @@ func (phase *BuildPhase) onImageStage(ctx context.Context, img *image.Image, stg
 	if err != nil {
 		return err
 	}
+	phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+		record.CalculationSeconds = durationSeconds(time.Since(calculationStartedAt))
+	})
 
 	if foundSuitableStage {
+		phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+			record.Source = ReportStageSourceRepo
+		})
+
 		logboek.Context(ctx).Default().LogFHighlight("Use previously built image for %s\n", stg.LogDetailedName())
 		container_backend.LogImageInfo(ctx, stg.GetStageImage().Image, phase.getPrevNonEmptyStageImageSize(), img.ShouldLogPlatform())
 
@@ -868,9 +1014,15 @@ ScanSecondaryStagesStorageList:
 			if secondaryStageDesc, err := storageManager.SelectSuitableStage(ctx, phase.Conveyor, stg, secondaryStages); err != nil {
 				return false, err
 			} else if secondaryStageDesc != nil {
+				copyStartedAt := time.Now()
 				if err := atomicCopySuitableStageFromSecondaryStagesStorage(secondaryStageDesc, secondaryStagesStorage); err != nil {
 					return false, fmt.Errorf("unable to copy suitable stage %s from secondary stages storage %s: %w", secondaryStageDesc.StageID.String(), secondaryStagesStorage.String(), err)
 				}
+				phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+					record.Source = ReportStageSourceSecondaryRepo
+					record.SourceRepo = secondaryStagesStorage.String()
+					record.FetchSeconds = durationSeconds(time.Since(copyStartedAt))
+				})
 				foundSuitableStage = true
 				break ScanSecondaryStagesStorageList
 			}
@@ -882,7 +1034,15 @@ ScanSecondaryStagesStorageList:
 
 func (phase *BuildPhase) fetchBaseImageForStage(ctx context.Context, img *image.Image, stg stage.Interface) error {
 	if stg.HasPrevStage() {
-		return phase.Conveyor.StorageManager.FetchStage(ctx, phase.Conveyor.ContainerBackend, phase.StagesIterator.PrevBuiltStage)
+		prevBuiltStage := phase.StagesIterator.PrevBuiltStage
+		fetchStartedAt := time.Now()
+		if err := phase.Conveyor.StorageManager.FetchStage(ctx, phase.Conveyor.ContainerBackend, prevBuiltStage); err != nil {
+			return err
+		}
+		phase.stagesReport.Update(prevBuiltStage, func(record *ReportStageRecord) {
+			record.FetchSeconds += durationSeconds(time.Since(fetchStartedAt))
+		})
+		return nil
 	} else {
 		if err := img.FetchBaseImage(ctx); err != nil {
 			return fmt.Errorf("unable to fetch base image %q for stage %s: %w", img.GetBaseStageImage().Image.Name(), stg.LogDetailedName(), err)
@@ -1089,13 +1249,20 @@ func (phase *BuildPhase) atomicBuildStageImage(ctx context.Context, img *image.I
 		time.Sleep(time.Duration(seconds) * time.Second)
 	}
 
+	buildStartedAt := time.Now()
 	if err := logboek.Context(ctx).Streams().DoErrorWithTag(fmt.Sprintf("%s/%s", img.LogName(), stg.Name()), img.LogTagStyle(), func() error {
 		opts := phase.ImageBuildOptions
 		opts.TargetPlatform = img.TargetPlatform
-		return stageImage.Builder.Build(ctx, opts)
+		return tracing.Do(ctx, "build.stage.build", []attribute.KeyValue{tracing.StageDigestKey.String(stg.GetDigest())}, func(ctx context.Context) error {
+			return stageImage.Builder.Build(ctx, opts)
+		})
 	}); err != nil {
 		return fmt.Errorf("failed to build image for stage %s with digest %s: %w", stg.Name(), stg.GetDigest(), err)
 	}
+	phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+		record.Source = ReportStageSourceBuilt
+		record.BuildSeconds = durationSeconds(time.Since(buildStartedAt))
+	})
 
 	if v := os.Getenv("WERF_TEST_ATOMIC_STAGE_BUILD__SLEEP_SECONDS_BEFORE_STAGE_SAVE"); v != "" {
 		seconds := 0
@@ -1137,6 +1304,10 @@ func (phase *BuildPhase) atomicBuildStageImage(ctx context.Context, img *image.I
 			i.Image.SetStageDescription(stageDesc)
 			stg.SetStageImage(i)
 
+			phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+				record.Source = ReportStageSourceRepo
+			})
+
 			return nil
 		}
 
@@ -1146,8 +1317,17 @@ func (phase *BuildPhase) atomicBuildStageImage(ctx context.Context, img *image.I
 		stageImage.Image.SetName(newStageImageName)
 		phase.Conveyor.SetStageImage(stageImage)
 
+		pushStartedAt := time.Now()
+		defer func() {
+			phase.stagesReport.Update(stg, func(record *ReportStageRecord) {
+				record.PushSeconds = durationSeconds(time.Since(pushStartedAt))
+			})
+		}()
+
 		if err := logboek.Context(ctx).Default().LogProcess("Store stage into %s", phase.Conveyor.StorageManager.GetStagesStorage().String()).DoError(func() error {
-			if err := phase.Conveyor.StorageManager.GetStagesStorage().StoreImage(ctx, stageImage.Image); err != nil {
+			if err := tracing.Do(ctx, "build.stage.store", []attribute.KeyValue{tracing.StageDigestKey.String(stg.GetDigest())}, func(ctx context.Context) error {
+				return phase.Conveyor.StorageManager.GetStagesStorage().StoreImage(ctx, stageImage.Image)
+			}); err != nil {
 				return fmt.Errorf("unable to store stage %s digest %s image %s into repo %s: %w", stg.LogDetailedName(), stg.GetDigest(), stageImage.Image.Name(), phase.Conveyor.StorageManager.GetStagesStorage().String(), err)
 			}
 
@@ -0,0 +1,145 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/werf/werf/pkg/config"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/deploy/secrets_manager"
+	"github.com/werf/werf/pkg/secret"
+	"github.com/werf/werf/pkg/util"
+)
+
+// buildSecrets holds the secret files written during the conveyor run, the files are removed on the conveyor termination.
+type buildSecrets struct {
+	dir     string
+	files   map[string]string
+	encoder *secret.YamlEncoder
+}
+
+func (c *Conveyor) GetBuildSecrets(ctx context.Context, secrets []*config.Secret, filesOnly bool) ([]container_backend.BuildSecret, error) {
+	c.buildSecretsMutex.Lock()
+	defer c.buildSecretsMutex.Unlock()
+
+	var res []container_backend.BuildSecret
+	for _, s := range secrets {
+		buildSecret, err := c.resolveBuildSecret(ctx, s, filesOnly)
+		if err != nil {
+			return nil, fmt.Errorf("unable to resolve secret %q: %w", s.ID, err)
+		}
+		res = append(res, buildSecret)
+	}
+
+	return res, nil
+}
+
+func (c *Conveyor) resolveBuildSecret(ctx context.Context, s *config.Secret, filesOnly bool) (container_backend.BuildSecret, error) {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return container_backend.BuildSecret{}, fmt.Errorf("environment variable %s is not set", s.Env)
+		}
+
+		if !filesOnly {
+			return container_backend.BuildSecret{ID: s.ID, Env: s.Env}, nil
+		}
+
+		path, err := c.writeBuildSecretFile(s.ID, "env\x00"+s.Env, []byte(value))
+		if err != nil {
+			return container_backend.BuildSecret{}, err
+		}
+		return container_backend.BuildSecret{ID: s.ID, Src: path}, nil
+	case s.Src != "":
+		path := s.Src
+		if !strings.HasPrefix(path, "~") && !filepath.IsAbs(path) {
+			path = filepath.Join(c.projectDir, path)
+		}
+		path = util.ExpandPath(path)
+
+		exist, err := util.RegularFileExists(path)
+		if err != nil {
+			return container_backend.BuildSecret{}, fmt.Errorf("unable to check file %q: %w", path, err)
+		}
+		if !exist {
+			return container_backend.BuildSecret{}, fmt.Errorf("file %q not found", path)
+		}
+
+		return container_backend.BuildSecret{ID: s.ID, Src: path}, nil
+	default:
+		if c.buildSecrets.encoder == nil {
+			encoder, err := secrets_manager.NewSecretsManager(secrets_manager.SecretsManagerOptions{
+				BackendConfig: c.werfConfig.Meta.Secrets,
+			}).GetYamlEncoder(ctx, c.projectDir)
+			if err != nil {
+				return container_backend.BuildSecret{}, err
+			}
+			c.buildSecrets.encoder = encoder
+		}
+
+		data, err := c.buildSecrets.encoder.Decrypt([]byte(s.Value))
+		if err != nil {
+			return container_backend.BuildSecret{}, fmt.Errorf("unable to decrypt value: %w", err)
+		}
+
+		path, err := c.writeBuildSecretFile(s.ID, "value\x00"+s.Value, data)
+		if err != nil {
+			return container_backend.BuildSecret{}, err
+		}
+		return container_backend.BuildSecret{ID: s.ID, Src: path}, nil
+	}
+}
+
+// writeBuildSecretFile writes the secret to the memory-backed /dev/shm when it is available, so the decrypted
+// secrets do not hit the disk.
+func (c *Conveyor) writeBuildSecretFile(id, source string, data []byte) (string, error) {
+	key := id + "\x00" + source
+	if path, ok := c.buildSecrets.files[key]; ok {
+		return path, nil
+	}
+
+	if c.buildSecrets.dir == "" {
+		baseDir := c.tmpDir
+		if exist, _ := util.DirExists("/dev/shm"); exist {
+			baseDir = "/dev/shm"
+		}
+
+		if err := os.MkdirAll(baseDir, os.ModePerm); err != nil {
+			return "", fmt.Errorf("unable to create dir %q: %w", baseDir, err)
+		}
+
+		dir, err := os.MkdirTemp(baseDir, "werf-secrets-")
+		if err != nil {
+			return "", fmt.Errorf("unable to create secrets dir: %w", err)
+		}
+
+		c.buildSecrets.dir = dir
+		c.buildSecrets.files = map[string]string{}
+		c.onTerminateFuncs = append(c.onTerminateFuncs, func() error {
+			return os.RemoveAll(dir)
+		})
+	}
+
+	f, err := os.CreateTemp(c.buildSecrets.dir, id+"-")
+	if err != nil {
+		return "", fmt.Errorf("unable to create secret file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(data); err != nil {
+		return "", fmt.Errorf("unable to write secret file %q: %w", f.Name(), err)
+	}
+
+	// The file is read by the build container user.
+	if err := f.Chmod(0o444); err != nil {
+		return "", fmt.Errorf("unable to chmod secret file %q: %w", f.Name(), err)
+	}
+
+	c.buildSecrets.files[key] = f.Name()
+
+	return f.Name(), nil
+}
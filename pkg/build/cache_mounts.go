@@ -0,0 +1,112 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/build/cache_mounts"
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/werf"
+)
+
+func (c *Conveyor) GetCacheMountDir(ctx context.Context, id string) (string, error) {
+	c.cacheMountsMutex.Lock()
+	defer c.cacheMountsMutex.Unlock()
+
+	// The lock is held until the conveyor termination to protect the dir from the host cleanup.
+	if _, locked := c.cacheMountLocks[id]; !locked {
+		lock, err := cache_mounts.AcquireDirLock(ctx, c.ProjectName(), id)
+		if err != nil {
+			return "", err
+		}
+
+		if c.cacheMountLocks == nil {
+			c.cacheMountLocks = map[string]lockgate.LockHandle{}
+		}
+		c.cacheMountLocks[id] = lock
+		c.AppendOnTerminateFunc(func() error {
+			return werf.ReleaseHostLock(lock)
+		})
+	}
+
+	dataDir, created, err := cache_mounts.PrepareDir(c.ProjectName(), id)
+	if err != nil {
+		return "", err
+	}
+
+	repo, shouldSync := c.cacheMountsRepo()
+	if !shouldSync {
+		return dataDir, nil
+	}
+
+	if c.usedCacheMounts == nil {
+		c.usedCacheMounts = map[string]string{}
+	}
+
+	if _, used := c.usedCacheMounts[id]; !used && created {
+		reference := cache_mounts.RepoReference(repo, id)
+		if err := logboek.Context(ctx).Default().LogProcess("Importing cache mount %q", id).DoError(func() error {
+			imported, err := cache_mounts.Import(ctx, docker_registry.API(), reference, dataDir)
+			if err != nil {
+				return err
+			}
+			if !imported {
+				logboek.Context(ctx).Default().LogF("Cache mount %s not found in the repo\n", reference)
+			}
+			return nil
+		}); err != nil {
+			return "", fmt.Errorf("unable to import cache mount %q: %w", id, err)
+		}
+	}
+
+	c.usedCacheMounts[id] = dataDir
+
+	return dataDir, nil
+}
+
+// exportCacheMounts pushes the cache mounts used during the build to the repo.
+func (c *Conveyor) exportCacheMounts(ctx context.Context) error {
+	repo, shouldSync := c.cacheMountsRepo()
+	if !shouldSync || len(c.usedCacheMounts) == 0 {
+		return nil
+	}
+
+	var ids []string
+	for id := range c.usedCacheMounts {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	return logboek.Context(ctx).Default().LogProcess("Exporting cache mounts").DoError(func() error {
+		for _, id := range ids {
+			reference := cache_mounts.RepoReference(repo, id)
+			exported, err := cache_mounts.Export(ctx, docker_registry.API(), reference, c.usedCacheMounts[id], cache_mounts.ExportOptions{MaxSize: c.SyncCacheMountsMaxSize})
+			if err != nil {
+				return fmt.Errorf("unable to export cache mount %q: %w", id, err)
+			}
+			if exported {
+				logboek.Context(ctx).Default().LogF("Cache mount %q: %s\n", id, reference)
+			} else {
+				logboek.Context(ctx).Default().LogF("Cache mount %q: skipped\n", id)
+			}
+		}
+		return nil
+	})
+}
+
+func (c *Conveyor) cacheMountsRepo() (string, bool) {
+	if !c.SyncCacheMounts {
+		return "", false
+	}
+
+	stagesStorage := c.StorageManager.GetStagesStorage()
+	if _, isLocal := stagesStorage.(*storage.LocalStagesStorage); isLocal {
+		return "", false
+	}
+
+	return stagesStorage.Address(), true
+}
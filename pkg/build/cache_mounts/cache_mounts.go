@@ -0,0 +1,94 @@
+package cache_mounts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/werf/pkg/slug"
+	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/util/timestamps"
+	"github.com/werf/werf/pkg/werf"
+)
+
+// CacheVersion should be changed on the incompatible change of the cache dir layout, the dirs of the other versions
+// are removed by the host cleanup.
+const CacheVersion = "1"
+
+const (
+	dataDirName          = "data"
+	lastAccessAtFileName = "last_access_at"
+	syncedDigestFileName = "synced_digest"
+)
+
+func GetCacheRootDir() string {
+	return filepath.Join(werf.GetLocalCacheDir(), "build_cache_mounts")
+}
+
+func GetCacheDir() string {
+	return filepath.Join(GetCacheRootDir(), CacheVersion)
+}
+
+func getEntryDir(projectName, id string) string {
+	return filepath.Join(GetCacheDir(), projectName, slug.LimitedSlug(id, slug.DefaultSlugMaxSize))
+}
+
+func getEntryLockName(entryDir string) string {
+	return fmt.Sprintf("build_cache_mount_%s", entryDir)
+}
+
+// AcquireDirLock takes the shared lock of the cache mount with the id, which prevents the removal of the dir by the GC
+// while the dir is used by the build.
+func AcquireDirLock(ctx context.Context, projectName, id string) (lockgate.LockHandle, error) {
+	_, lock, err := werf.AcquireHostLock(ctx, getEntryLockName(getEntryDir(projectName, id)), lockgate.AcquireOptions{Shared: true})
+	if err != nil {
+		return lockgate.LockHandle{}, fmt.Errorf("unable to lock cache mount %q: %w", id, err)
+	}
+	return lock, nil
+}
+
+// PrepareDir returns the host dir which is mounted instead of the RUN --mount=type=cache volume with the id and
+// updates the last access time of the cache mount. The created flag is set if the dir has not existed.
+func PrepareDir(projectName, id string) (string, bool, error) {
+	entryDir := getEntryDir(projectName, id)
+	dataDir := filepath.Join(entryDir, dataDirName)
+
+	exist, err := util.DirExists(dataDir)
+	if err != nil {
+		return "", false, fmt.Errorf("unable to check dir %q: %w", dataDir, err)
+	}
+
+	if !exist {
+		if err := os.MkdirAll(dataDir, 0o755); err != nil {
+			return "", false, fmt.Errorf("unable to create dir %q: %w", dataDir, err)
+		}
+	}
+
+	lastAccessAtPath := filepath.Join(entryDir, lastAccessAtFileName)
+	if err := timestamps.WriteTimestampFile(lastAccessAtPath, time.Now()); err != nil {
+		return "", false, fmt.Errorf("unable to update last access timestamp file %q: %w", lastAccessAtPath, err)
+	}
+
+	return dataDir, !exist, nil
+}
+
+// readSyncedDigest returns the digest of the cache mount content, which was last imported from or exported to the repo.
+func readSyncedDigest(dataDir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(dataDir), syncedDigestFileName))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", fmt.Errorf("unable to read synced digest: %w", err)
+	}
+	return string(data), nil
+}
+
+func writeSyncedDigest(dataDir, digest string) error {
+	if err := os.WriteFile(filepath.Join(filepath.Dir(dataDir), syncedDigestFileName), []byte(digest), 0o644); err != nil {
+		return fmt.Errorf("unable to write synced digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package cache_mounts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/util/timestamps"
+	"github.com/werf/werf/pkg/volumeutils"
+	"github.com/werf/werf/pkg/werf"
+)
+
+// preserveLastAccessPeriod protects the cache mounts which might be used by the running builds.
+const preserveLastAccessPeriod = 3 * time.Hour
+
+type cacheMountDesc struct {
+	Path         string
+	Size         uint64
+	LastAccessAt time.Time
+}
+
+// RunGC removes the cache mounts of the old cache versions and the least recently used cache mounts until the local
+// cache volume usage is lower than the allowed percentage minus the margin.
+func RunGC(ctx context.Context, allowedVolumeUsagePercentage, allowedVolumeUsageMarginPercentage float64) error {
+	_, lock, err := werf.AcquireHostLock(ctx, "build_cache_mounts_gc", lockgate.AcquireOptions{})
+	if err != nil {
+		return err
+	}
+	defer werf.ReleaseHostLock(lock)
+
+	if err := wipeOldCacheVersions(ctx); err != nil {
+		return err
+	}
+
+	vu, err := volumeutils.GetVolumeUsageByPath(ctx, werf.GetLocalCacheDir())
+	if err != nil {
+		return fmt.Errorf("error getting volume usage by path %q: %w", werf.GetLocalCacheDir(), err)
+	}
+
+	if vu.Percentage <= allowedVolumeUsagePercentage {
+		logboek.Context(ctx).Default().LogF("Volume usage %0.2f%% <= %0.2f%%, nothing to remove\n", vu.Percentage, allowedVolumeUsagePercentage)
+		return nil
+	}
+
+	targetVolumeUsagePercentage := allowedVolumeUsagePercentage - allowedVolumeUsageMarginPercentage
+	if targetVolumeUsagePercentage < 0 {
+		targetVolumeUsagePercentage = 0
+	}
+	bytesToFree := uint64((float64(vu.TotalBytes) / 100.0) * (vu.Percentage - targetVolumeUsagePercentage))
+
+	logboek.Context(ctx).Default().LogF("Volume usage %0.2f%% > %0.2f%%, needed to free %s\n", vu.Percentage, allowedVolumeUsagePercentage, humanize.Bytes(bytesToFree))
+
+	cacheMounts, err := getExistingCacheMounts(GetCacheDir())
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(cacheMounts, func(i, j int) bool {
+		return cacheMounts[i].LastAccessAt.Before(cacheMounts[j].LastAccessAt)
+	})
+
+	var freedBytes uint64
+	for _, desc := range cacheMounts {
+		if freedBytes >= bytesToFree {
+			break
+		}
+
+		if time.Since(desc.LastAccessAt) < preserveLastAccessPeriod {
+			continue
+		}
+
+		removed, err := removeCacheMount(ctx, desc)
+		if err != nil {
+			return err
+		}
+		if removed {
+			freedBytes += desc.Size
+		}
+	}
+
+	return nil
+}
+
+// removeCacheMount removes the cache mount if it is not used by the running builds, which hold the shared entry lock.
+func removeCacheMount(ctx context.Context, desc *cacheMountDesc) (bool, error) {
+	acquired, lock, err := werf.AcquireHostLock(ctx, getEntryLockName(desc.Path), lockgate.AcquireOptions{NonBlocking: true})
+	if err != nil {
+		return false, fmt.Errorf("unable to lock %q: %w", desc.Path, err)
+	}
+	if !acquired {
+		logboek.Context(ctx).Default().LogF("Skipping %q: used by the running build\n", desc.Path)
+		return false, nil
+	}
+	defer werf.ReleaseHostLock(lock)
+
+	logboek.Context(ctx).Default().LogF("Removing %q (%s)\n", desc.Path, humanize.Bytes(desc.Size))
+	if err := os.RemoveAll(desc.Path); err != nil {
+		return false, fmt.Errorf("unable to remove %q: %w", desc.Path, err)
+	}
+
+	return true, nil
+}
+
+func wipeOldCacheVersions(ctx context.Context) error {
+	entries, err := os.ReadDir(GetCacheRootDir())
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading dir %q: %w", GetCacheRootDir(), err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name() == CacheVersion {
+			continue
+		}
+
+		path := filepath.Join(GetCacheRootDir(), entry.Name())
+		logboek.Context(ctx).Debug().LogF("Removing old cache version %q\n", path)
+		if err := os.RemoveAll(path); err != nil {
+			return fmt.Errorf("unable to remove %q: %w", path, err)
+		}
+	}
+
+	return nil
+}
+
+func getExistingCacheMounts(cacheDir string) ([]*cacheMountDesc, error) {
+	projectDirs, err := os.ReadDir(cacheDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading dir %q: %w", cacheDir, err)
+	}
+
+	var res []*cacheMountDesc
+	for _, projectDir := range projectDirs {
+		projectPath := filepath.Join(cacheDir, projectDir.Name())
+
+		entries, err := os.ReadDir(projectPath)
+		if err != nil {
+			return nil, fmt.Errorf("error reading dir %q: %w", projectPath, err)
+		}
+
+		for _, entry := range entries {
+			entryPath := filepath.Join(projectPath, entry.Name())
+
+			size, err := volumeutils.DirSizeBytes(entryPath)
+			if err != nil {
+				return nil, fmt.Errorf("error getting dir %q size: %w", entryPath, err)
+			}
+
+			lastAccessAtPath := filepath.Join(entryPath, lastAccessAtFileName)
+			lastAccessAt, err := timestamps.ReadTimestampFile(lastAccessAtPath)
+			if err != nil {
+				return nil, fmt.Errorf("error reading last access timestamp file %q: %w", lastAccessAtPath, err)
+			}
+
+			res = append(res, &cacheMountDesc{
+				Path:         entryPath,
+				Size:         size,
+				LastAccessAt: lastAccessAt,
+			})
+		}
+	}
+
+	return res, nil
+}
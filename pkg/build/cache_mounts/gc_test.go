@@ -0,0 +1,50 @@
+package cache_mounts
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/werf/werf/pkg/werf"
+)
+
+func TestRemoveCacheMountSkipsLockedDir(t *testing.T) {
+	ctx := context.Background()
+
+	if err := werf.Init(t.TempDir(), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	dataDir, _, err := PrepareDir("project", "/root/.cache/go-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := &cacheMountDesc{Path: getEntryDir("project", "/root/.cache/go-build")}
+
+	lock, err := AcquireDirLock(ctx, "project", "/root/.cache/go-build")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, err := removeCacheMount(ctx, desc); err != nil {
+		t.Fatal(err)
+	} else if removed {
+		t.Fatalf("expected the locked cache mount not to be removed")
+	}
+	if _, err := os.Stat(dataDir); err != nil {
+		t.Fatalf("expected the locked cache mount to exist: %s", err)
+	}
+
+	if err := werf.ReleaseHostLock(lock); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed, err := removeCacheMount(ctx, desc); err != nil {
+		t.Fatal(err)
+	} else if !removed {
+		t.Fatalf("expected the cache mount to be removed")
+	}
+	if _, err := os.Stat(dataDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the cache mount to be removed")
+	}
+}
@@ -0,0 +1,217 @@
+package cache_mounts
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/werf"
+)
+
+// ArtifactType is the config media type of the cache mount artifact in the repo.
+const ArtifactType = "application/vnd.werf.cache-mount.v1+json"
+
+// RepoReference returns the reference of the cache mount artifact in the repo, the tag does not match the stage tags
+// format and is ignored by the cleanup.
+func RepoReference(repo, id string) string {
+	return fmt.Sprintf("%s:cache-mount-%s", repo, util.Sha256Hash(id)[:40])
+}
+
+var errMaxSizeExceeded = errors.New("max size exceeded")
+
+type ExportOptions struct {
+	// MaxSize is the max size of the cache mount archive, the larger cache mounts are not exported. 0 means no limit.
+	MaxSize uint64
+}
+
+// Export pushes the content of the cache mount dir to the repo as the artifact with the single layer.
+// The export is skipped and false is returned if the content has not changed since the last import or export
+// or if the archive exceeds the max size.
+func Export(ctx context.Context, registry docker_registry.GenericApiInterface, reference, dataDir string, opts ExportOptions) (bool, error) {
+	archive, err := os.CreateTemp(werf.GetTmpDir(), "cache-mount-*.tar")
+	if err != nil {
+		return false, fmt.Errorf("unable to create archive: %w", err)
+	}
+	defer os.Remove(archive.Name())
+
+	hash := sha256.New()
+	w := io.MultiWriter(archive, hash)
+	if opts.MaxSize > 0 {
+		w = &maxSizeWriter{Writer: w, left: opts.MaxSize}
+	}
+
+	if err := writeDirArchive(dataDir, w); errors.Is(err, errMaxSizeExceeded) {
+		archive.Close()
+		logboek.Context(ctx).Warn().LogF("WARNING: Cache mount %s is not exported: the archive is larger than %s\n", dataDir, humanize.IBytes(opts.MaxSize))
+		return false, nil
+	} else if err != nil {
+		archive.Close()
+		return false, fmt.Errorf("unable to archive dir %q: %w", dataDir, err)
+	}
+	if err := archive.Close(); err != nil {
+		return false, fmt.Errorf("unable to close archive %q: %w", archive.Name(), err)
+	}
+
+	digest := fmt.Sprintf("sha256:%x", hash.Sum(nil))
+	if syncedDigest, err := readSyncedDigest(dataDir); err != nil {
+		return false, err
+	} else if syncedDigest == digest {
+		return false, nil
+	}
+
+	layer, err := tarball.LayerFromFile(archive.Name(), tarball.WithMediaType(types.OCILayer))
+	if err != nil {
+		return false, fmt.Errorf("unable to create layer: %w", err)
+	}
+
+	artifact := mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+	artifact = mutate.ConfigMediaType(artifact, ArtifactType)
+	artifact, err = mutate.Append(artifact, mutate.Addendum{Layer: layer})
+	if err != nil {
+		return false, fmt.Errorf("unable to add artifact layer: %w", err)
+	}
+
+	if err := registry.PushArtifact(ctx, reference, artifact); err != nil {
+		return false, err
+	}
+
+	return true, writeSyncedDigest(dataDir, digest)
+}
+
+type maxSizeWriter struct {
+	io.Writer
+	left uint64
+}
+
+func (w *maxSizeWriter) Write(p []byte) (int, error) {
+	if uint64(len(p)) > w.left {
+		return 0, errMaxSizeExceeded
+	}
+	w.left -= uint64(len(p))
+	return w.Writer.Write(p)
+}
+
+// Import extracts the cache mount artifact from the repo into the cache mount dir, false is returned if there is no
+// artifact in the repo.
+func Import(ctx context.Context, registry docker_registry.GenericApiInterface, reference, dataDir string) (bool, error) {
+	artifact, err := registry.TryGetArtifact(ctx, reference)
+	if err != nil {
+		return false, err
+	}
+	if artifact == nil {
+		return false, nil
+	}
+
+	layers, err := artifact.Layers()
+	if err != nil {
+		return false, fmt.Errorf("unable to get artifact layers: %w", err)
+	}
+	if len(layers) != 1 {
+		return false, fmt.Errorf("unexpected artifact %q: expected 1 layer, got %d", reference, len(layers))
+	}
+
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		return false, fmt.Errorf("unable to read artifact layer: %w", err)
+	}
+	defer rc.Close()
+
+	if err := extractDirArchive(rc, dataDir); err != nil {
+		return false, fmt.Errorf("unable to extract artifact %q: %w", reference, err)
+	}
+
+	// The layer is the uncompressed archive written by the Export, so its diff id is the content digest.
+	diffID, err := layers[0].DiffID()
+	if err != nil {
+		return false, fmt.Errorf("unable to get artifact layer diff id: %w", err)
+	}
+
+	return true, writeSyncedDigest(dataDir, diffID.String())
+}
+
+// extractDirArchive extracts the archive into the dir, which is accessed on the host: the entries are confined to the dir
+// and the symlinks which might point outside of it are skipped. The numeric
+// ownership from the archive is restored.
+func extractDirArchive(r io.Reader, dir string) error {
+	return util.ExtractTar(r, dir, util.ExtractTarOptions{SkipHostEscapingSymlinks: true, PreserveOwnership: true})
+}
+
+// writeDirArchive writes the dir as tar with the symlinks preserved, the special files are skipped.
+// Timestamps and user and group names are not saved, so the archive of the same content is the same. Numeric uid and
+// gid are kept: the cache mounts of the non-root build users must stay writable by them after the import.
+func writeDirArchive(dir string, w io.Writer) error {
+	tw := tar.NewWriter(w)
+
+	if err := filepath.Walk(dir, func(path string, info fs.FileInfo, err error) error {
+		if err != nil {
+			return fmt.Errorf("error accessing %q: %w", path, err)
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if relPath == "." {
+			return nil
+		}
+
+		var link string
+		switch {
+		case info.Mode()&os.ModeSymlink != 0:
+			if link, err = os.Readlink(path); err != nil {
+				return fmt.Errorf("unable to read link %q: %w", path, err)
+			}
+		case info.IsDir(), info.Mode().IsRegular():
+		default:
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("unable to create tar header for %q: %w", path, err)
+		}
+		header.Name = filepath.ToSlash(relPath)
+		header.ModTime, header.AccessTime, header.ChangeTime = time.Unix(0, 0), time.Time{}, time.Time{}
+		header.Uname, header.Gname = "", ""
+
+		if err := tw.WriteHeader(header); err != nil {
+			return fmt.Errorf("unable to write tar header for %q: %w", path, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return fmt.Errorf("unable to open %q: %w", path, err)
+		}
+		defer f.Close()
+
+		if _, err := io.Copy(tw, f); err != nil {
+			return fmt.Errorf("unable to write %q into tar: %w", path, err)
+		}
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return tw.Close()
+}
@@ -0,0 +1,231 @@
+package cache_mounts
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/registry"
+
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/werf"
+)
+
+func TestWriteDirArchive(t *testing.T) {
+	srcDir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(srcDir, "pkg", "mod"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDir, "pkg", "mod", "cache.bin"), []byte("cached"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("pkg/mod/cache.bin", filepath.Join(srcDir, "latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	var archive bytes.Buffer
+	if err := writeDirArchive(srcDir, &archive); err != nil {
+		t.Fatalf("writeDirArchive() error: %s", err)
+	}
+
+	dstDir := t.TempDir()
+	if err := util.ExtractTar(&archive, dstDir, util.ExtractTarOptions{}); err != nil {
+		t.Fatalf("ExtractTar() error: %s", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dstDir, "pkg", "mod", "cache.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "cached" {
+		t.Errorf("unexpected file content %q", data)
+	}
+
+	link, err := os.Readlink(filepath.Join(dstDir, "latest"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link != "pkg/mod/cache.bin" {
+		t.Errorf("unexpected symlink target %q", link)
+	}
+}
+
+func TestExtractDirArchive(t *testing.T) {
+	var archive bytes.Buffer
+	tw := tar.NewWriter(&archive)
+	for _, hdr := range []*tar.Header{
+		{Name: "../../escaped", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "absolute", Typeflag: tar.TypeSymlink, Linkname: "/etc"},
+		{Name: "relative", Typeflag: tar.TypeSymlink, Linkname: "../../../etc"},
+		{Name: "relative/passwd", Typeflag: tar.TypeReg, Mode: 0o644},
+		{Name: "inside", Typeflag: tar.TypeSymlink, Linkname: "escaped"},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	parentDir := t.TempDir()
+	dataDir := filepath.Join(parentDir, "entry", "data")
+	if err := extractDirArchive(&archive, dataDir); err != nil {
+		t.Fatalf("extractDirArchive() error: %s", err)
+	}
+
+	for _, p := range []string{filepath.Join(parentDir, "escaped"), filepath.Join(dataDir, "absolute")} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %q not to exist", p)
+		}
+	}
+
+	if info, err := os.Lstat(filepath.Join(dataDir, "relative")); err != nil || !info.IsDir() {
+		t.Errorf("expected the escaping symlink to be skipped and the dir to be created instead")
+	}
+
+	for _, p := range []string{"escaped", "relative/passwd", "inside"} {
+		if _, err := os.Lstat(filepath.Join(dataDir, p)); err != nil {
+			t.Errorf("expected %q to be extracted: %s", p, err)
+		}
+	}
+}
+
+func TestExportAndImport(t *testing.T) {
+	ctx := context.Background()
+
+	if err := werf.Init(t.TempDir(), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := docker_registry.Init(ctx, true, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	api := docker_registry.API()
+
+	reference := RepoReference(serverURL.Host+"/test/project", "/root/.cache/go-build")
+
+	srcDataDir := filepath.Join(t.TempDir(), "src", dataDirName)
+	if err := os.MkdirAll(filepath.Join(srcDataDir, "pkg"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(srcDataDir, "pkg", "cache.bin"), []byte("cached"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if exported, err := Export(ctx, api, reference, srcDataDir, ExportOptions{MaxSize: 512}); err != nil {
+		t.Fatalf("Export() error: %s", err)
+	} else if exported {
+		t.Fatalf("expected the cache mount larger than the max size not to be exported")
+	}
+
+	if exported, err := Export(ctx, api, reference, srcDataDir, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error: %s", err)
+	} else if !exported {
+		t.Fatalf("expected the cache mount to be exported")
+	}
+
+	if exported, err := Export(ctx, api, reference, srcDataDir, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error: %s", err)
+	} else if exported {
+		t.Fatalf("expected the unchanged cache mount not to be exported again")
+	}
+
+	dstDataDir := filepath.Join(t.TempDir(), "dst", dataDirName)
+	if imported, err := Import(ctx, api, reference, dstDataDir); err != nil {
+		t.Fatalf("Import() error: %s", err)
+	} else if !imported {
+		t.Fatalf("expected the cache mount to be imported")
+	}
+
+	if exported, err := Export(ctx, api, reference, dstDataDir, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error: %s", err)
+	} else if exported {
+		t.Fatalf("expected the imported cache mount not to be exported again")
+	}
+
+	if err := os.WriteFile(filepath.Join(dstDataDir, "pkg", "cache.bin"), []byte("changed"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if exported, err := Export(ctx, api, reference, dstDataDir, ExportOptions{}); err != nil {
+		t.Fatalf("Export() error: %s", err)
+	} else if !exported {
+		t.Fatalf("expected the changed cache mount to be exported")
+	}
+}
+
+func TestRepoReference(t *testing.T) {
+	ref := RepoReference("registry.example.com/project", "/root/.cache/go-build")
+	if !strings.HasPrefix(ref, "registry.example.com/project:cache-mount-") {
+		t.Errorf("unexpected reference %q", ref)
+	}
+
+	// The tag must not be taken for the stage tag by the cleanup.
+	tag := strings.TrimPrefix(ref, "registry.example.com/project:")
+	if len(tag) == 56 || len(tag) == 70 {
+		t.Errorf("tag %q has the length of the stage tag", tag)
+	}
+
+	if ref != RepoReference("registry.example.com/project", "/root/.cache/go-build") {
+		t.Errorf("reference is not stable")
+	}
+}
+
+func TestDirArchiveKeepsOwnership(t *testing.T) {
+	if os.Geteuid() != 0 {
+		t.Skip("changing the file owner requires root")
+	}
+
+	srcDir := t.TempDir()
+	for _, p := range []string{"npm", "npm/_cacache", "npm/_cacache/index"} {
+		path := filepath.Join(srcDir, p)
+		if p == "npm/_cacache/index" {
+			if err := os.WriteFile(path, []byte("index"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		} else if err := os.Mkdir(path, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Lchown(path, 1000, 1001); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var archive bytes.Buffer
+	if err := writeDirArchive(srcDir, &archive); err != nil {
+		t.Fatalf("writeDirArchive() error: %s", err)
+	}
+
+	dataDir := filepath.Join(t.TempDir(), "data")
+	if err := extractDirArchive(&archive, dataDir); err != nil {
+		t.Fatalf("extractDirArchive() error: %s", err)
+	}
+
+	for _, p := range []string{"npm", "npm/_cacache", "npm/_cacache/index"} {
+		info, err := os.Lstat(filepath.Join(dataDir, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stat := info.Sys().(*syscall.Stat_t)
+		if stat.Uid != 1000 || stat.Gid != 1001 {
+			t.Errorf("expected %q to be owned by 1000:1001, got %d:%d", p, stat.Uid, stat.Gid)
+		}
+	}
+}
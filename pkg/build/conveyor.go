@@ -11,6 +11,9 @@ import (
 	"sync"
 	"time"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
 	stylePkg "github.com/werf/logboek/pkg/style"
 	"github.com/werf/logboek/pkg/types"
@@ -25,6 +28,7 @@ import (
 	imagePkg "github.com/werf/werf/pkg/image"
 	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/storage/manager"
+	"github.com/werf/werf/pkg/tracing"
 	"github.com/werf/werf/pkg/util"
 	"github.com/werf/werf/pkg/util/parallel"
 )
@@ -59,6 +63,13 @@ type Conveyor struct {
 	onTerminateFuncs []func() error
 	importServers    map[string]import_server.ImportServer
 
+	buildSecrets      buildSecrets
+	buildSecretsMutex sync.Mutex
+
+	usedCacheMounts  map[string]string
+	cacheMountLocks  map[string]lockgate.LockHandle
+	cacheMountsMutex sync.Mutex
+
 	ConveyorOptions
 
 	mutex            sync.Mutex
@@ -72,6 +83,10 @@ type ConveyorOptions struct {
 	LocalGitRepoVirtualMergeOptions stage.VirtualMergeOptions
 	TargetPlatforms                 []string
 	DeferBuildLog                   bool
+	// SyncCacheMounts enables the import of the RUN --mount=type=cache volumes from the repo and the export to the repo.
+	SyncCacheMounts bool
+	// SyncCacheMountsMaxSize is the max size of the exported cache mount archive, 0 means no limit.
+	SyncCacheMountsMaxSize uint64
 
 	ImagesToProcess
 }
@@ -570,7 +585,7 @@ func (c *Conveyor) runPhases(ctx context.Context, phases []Phase, logImages bool
 	for _, phase := range phases {
 		logProcess := logboek.Context(ctx).Debug().LogProcess("Phase %s -- BeforeImages()", phase.Name())
 		logProcess.Start()
-		if err := phase.BeforeImages(ctx); err != nil {
+		if err := tracing.Do(ctx, fmt.Sprintf("%s.before_images", phase.Name()), []attribute.KeyValue{tracing.PhaseKey.String(phase.Name())}, phase.BeforeImages); err != nil {
 			logProcess.Fail()
 			return fmt.Errorf("phase %s before images handler failed: %w", phase.Name(), err)
 		}
@@ -584,7 +599,7 @@ func (c *Conveyor) runPhases(ctx context.Context, phases []Phase, logImages bool
 	for _, phase := range phases {
 		if err := logboek.Context(ctx).Debug().LogProcess(fmt.Sprintf("Phase %s -- AfterImages()", phase.Name())).
 			DoError(func() error {
-				if err := phase.AfterImages(ctx); err != nil {
+				if err := tracing.Do(ctx, fmt.Sprintf("%s.after_images", phase.Name()), []attribute.KeyValue{tracing.PhaseKey.String(phase.Name())}, phase.AfterImages); err != nil {
 					return fmt.Errorf("phase %s after images handler failed: %w", phase.Name(), err)
 				}
 
@@ -694,7 +709,10 @@ func (c *Conveyor) doImagesInParallel(ctx context.Context, phases []Phase, logIm
 	return nil
 }
 
-func (c *Conveyor) doImage(ctx context.Context, img *image.Image, phases []Phase) error {
+func (c *Conveyor) doImage(ctx context.Context, img *image.Image, phases []Phase) (err error) {
+	ctx, span := tracing.Start(ctx, "image", tracing.ImageNameKey.String(img.GetName()), tracing.TargetPlatformKey.String(img.TargetPlatform))
+	defer func() { tracing.End(span, err) }()
+
 	return logboek.Context(ctx).LogProcess(img.LogDetailedName()).
 		Options(func(options types.LogProcessOptionsInterface) {
 			options.Style(img.LogProcessStyle())
@@ -717,7 +735,7 @@ func (c *Conveyor) doImage(ctx context.Context, img *image.Image, phases []Phase
 				logProcess.Start()
 				for _, stg := range img.GetStages() {
 					logboek.Context(ctx).Debug().LogF("Phase %s -- OnImageStage() %s %s\n", phase.Name(), img.GetLogName(), stg.LogDetailedName())
-					if err := phase.OnImageStage(ctx, img, stg); err != nil {
+					if err := c.doImageStage(ctx, phase, img, stg); err != nil {
 						logProcess.Fail()
 						return fmt.Errorf("phase %s on image %s stage %s handler failed: %w", phase.Name(), img.GetLogName(), stg.Name(), err)
 					}
@@ -745,6 +763,23 @@ func (c *Conveyor) doImage(ctx context.Context, img *image.Image, phases []Phase
 		})
 }
 
+func (c *Conveyor) doImageStage(ctx context.Context, phase Phase, img *image.Image, stg stage.Interface) error {
+	ctx, span := tracing.Start(ctx, fmt.Sprintf("%s.stage", phase.Name()),
+		tracing.PhaseKey.String(phase.Name()),
+		tracing.ImageNameKey.String(img.GetName()),
+		tracing.TargetPlatformKey.String(img.TargetPlatform),
+		tracing.StageNameKey.String(string(stg.Name())),
+	)
+
+	err := phase.OnImageStage(ctx, img, stg)
+	if digest := stg.GetDigest(); digest != "" {
+		span.SetAttributes(tracing.StageDigestKey.String(digest))
+	}
+	tracing.End(span, err)
+
+	return err
+}
+
 func (c *Conveyor) ProjectName() string {
 	return c.werfConfig.Meta.Project
 }
@@ -805,13 +840,22 @@ func (c *Conveyor) GetImage(targetPlatform, name string) *image.Image {
 		panic("assertion: targetPlatform should not be empty")
 	}
 
+	if img := c.findImage(targetPlatform, name); img != nil {
+		return img
+	}
+
+	panic(fmt.Sprintf("Image %q not found!", name))
+}
+
+// findImage is like GetImage, but returns nil when the image is not in the images tree.
+func (c *Conveyor) findImage(targetPlatform, name string) *image.Image {
 	for _, img := range c.imagesTree.GetImages() {
 		if img.GetName() == name && img.TargetPlatform == targetPlatform {
 			return img
 		}
 	}
 
-	panic(fmt.Sprintf("Image %q not found!", name))
+	return nil
 }
 
 func (c *Conveyor) GetImageStageContentDigest(targetPlatform, imageName, stageName string) string {
@@ -25,24 +25,41 @@ import (
 
 func MapDockerfileConfigToImagesSets(ctx context.Context, dockerfileImageConfig *config.ImageFromDockerfile, targetPlatform string, opts CommonImageOptions) (ImagesSets, error) {
 	if dockerfileImageConfig.Staged {
-		relDockerfilePath := filepath.Join(dockerfileImageConfig.Context, dockerfileImageConfig.Dockerfile)
-		dockerfileData, err := opts.GiterminismManager.FileReader().ReadDockerfile(ctx, relDockerfilePath)
-		if err != nil {
-			return nil, fmt.Errorf("unable to read dockerfile %s: %w", relDockerfilePath, err)
-		}
-
-		dockerfileID := util.Sha256Hash(filepath.Clean(relDockerfilePath))
-
-		d, err := frontend.ParseDockerfileWithBuildkit(dockerfileID, dockerfileData, dockerfileImageConfig.Name, dockerfile.DockerfileOptions{
+		dockerfileOpts := dockerfile.DockerfileOptions{
 			Target:               dockerfileImageConfig.Target,
 			BuildArgs:            util.MapStringInterfaceToMapStringString(dockerfileImageConfig.Args),
 			AddHost:              dockerfileImageConfig.AddHost,
 			Network:              dockerfileImageConfig.Network,
 			SSH:                  dockerfileImageConfig.SSH,
 			DependenciesArgsKeys: stage.GetDependenciesArgsKeys(dockerfileImageConfig.Dependencies),
-		})
-		if err != nil {
-			return nil, fmt.Errorf("unable to parse dockerfile %s: %w", relDockerfilePath, err)
+		}
+
+		var d *dockerfile.Dockerfile
+		if dockerfileImageConfig.DockerfileYaml != nil {
+			dockerfileID := util.Sha256Hash(string(dockerfileImageConfig.DockerfileYaml))
+
+			var err error
+			d, err = frontend.ParseDockerfileYamlWithBuildkit(dockerfileID, dockerfileImageConfig.DockerfileYaml, dockerfileImageConfig.Name, dockerfileOpts)
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse dockerfile yaml of image %q: %w", dockerfileImageConfig.Name, err)
+			}
+		} else {
+			relDockerfilePath := filepath.Join(dockerfileImageConfig.Context, dockerfileImageConfig.Dockerfile)
+			dockerfileData, err := opts.GiterminismManager.FileReader().ReadDockerfile(ctx, relDockerfilePath)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read dockerfile %s: %w", relDockerfilePath, err)
+			}
+
+			dockerfileID := util.Sha256Hash(filepath.Clean(relDockerfilePath))
+
+			if dockerfileImageConfig.IsDockerfileYaml() {
+				d, err = frontend.ParseDockerfileYamlWithBuildkit(dockerfileID, dockerfileData, dockerfileImageConfig.Name, dockerfileOpts)
+			} else {
+				d, err = frontend.ParseDockerfileWithBuildkit(dockerfileID, dockerfileData, dockerfileImageConfig.Name, dockerfileOpts)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("unable to parse dockerfile %s: %w", relDockerfilePath, err)
+			}
 		}
 
 		return mapDockerfileToImagesSets(ctx, d, dockerfileImageConfig, targetPlatform, opts)
@@ -159,6 +176,7 @@ func mapDockerfileToImagesSets(ctx context.Context, cfg *dockerfile.Dockerfile,
 			ImageTmpDir:      img.TmpDir,
 			ContainerWerfDir: img.ContainerWerfDir,
 			ProjectName:      opts.ProjectName,
+			Secrets:          dockerfileImageConfig.Secrets,
 		}
 
 		var instrNum int
@@ -299,6 +317,7 @@ func mapLegacyDockerfileToImage(ctx context.Context, dockerfileImageConfig *conf
 		TargetPlatform: targetPlatform,
 		ImageName:      dockerfileImageConfig.Name,
 		ProjectName:    opts.ProjectName,
+		Secrets:        dockerfileImageConfig.Secrets,
 	}
 
 	dockerfileStage := stage.GenerateFullDockerfileStage(
@@ -72,6 +72,7 @@ func initStages(ctx context.Context, image *Image, metaConfig *config.Meta, stap
 		ImageTmpDir:      filepath.Join(opts.TmpDir, "image", imageBaseConfig.Name),
 		ContainerWerfDir: opts.ContainerWerfDir,
 		ProjectName:      opts.ProjectName,
+		Secrets:          imageBaseConfig.Secrets,
 	}
 
 	gitArchiveStageOptions := &stage.NewGitArchiveStageOptions{
@@ -0,0 +1,190 @@
+package build
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/build/image"
+	"github.com/werf/werf/pkg/config"
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/provenance"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/werf"
+)
+
+func (phase *BuildPhase) attachProvenance(ctx context.Context) error {
+	if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); isLocal {
+		return nil
+	}
+
+	phase.provenanceRecords = make(map[string]*ReportArtifactRecord)
+
+	return logboek.Context(ctx).Default().LogProcess("Attaching provenance").DoError(func() error {
+		for _, desc := range phase.Conveyor.imagesTree.GetImagesByName(true) {
+			name, images := desc.Unpair()
+			stageDesc := phase.getFinalImageStageDescription(name, images)
+
+			record, document, err := attachArtifact(ctx, docker_registry.API(), stageDesc.Info.Repository, stageDesc.Info.Name, attachArtifactOptions{
+				ArtifactType:   provenance.MediaType,
+				LayerMediaType: provenance.MediaType,
+				NewDocument: func(subjectDigest string) ([]byte, error) {
+					statement := provenance.NewStatement(stageDesc.Info.Repository, subjectDigest, phase.newProvenance(ctx, name, images))
+					return json.Marshal(statement)
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("unable to attach provenance to image %q: %w", name, err)
+			}
+			record.Document = document
+
+			logboek.Context(ctx).Default().LogF("Image %q provenance: %s\n", name, record.DockerImageName)
+			phase.provenanceRecords[name] = record
+		}
+
+		return nil
+	})
+}
+
+func (phase *BuildPhase) newProvenance(ctx context.Context, name string, images []*image.Image) *provenance.Provenance {
+	buildFinishedAt := time.Now()
+
+	p := &provenance.Provenance{
+		Builder:   provenance.Builder{ID: fmt.Sprintf("%s@%s", provenance.BuilderIDBase, werf.Version)},
+		BuildType: provenance.BuildType,
+		Invocation: provenance.Invocation{
+			ConfigSource: provenance.ConfigSource{EntryPoint: provenance.WerfConfigPath},
+			Parameters:   provenance.Parameters{Image: name},
+		},
+		BuildConfig: provenance.BuildConfig{
+			Platforms: make(map[string][]provenance.Stage),
+		},
+		Metadata: provenance.Metadata{
+			BuildFinishedOn: &buildFinishedAt,
+			Completeness:    provenance.Completeness{Parameters: true},
+		},
+	}
+	if !phase.buildStartedAt.IsZero() {
+		p.Metadata.BuildStartedOn = &phase.buildStartedAt
+	}
+
+	if gm := phase.Conveyor.GiterminismManager(); gm != nil && gm.HeadCommit() != "" {
+		p.Invocation.ConfigSource.Digest = provenance.DigestSet{"sha1": gm.HeadCommit()}
+
+		if remoteURL, err := gm.LocalGitRepo().RemoteOriginUrl(ctx); err != nil {
+			logboek.Context(ctx).Debug().LogF("Unable to get git remote origin url: %s\n", err)
+		} else if remoteURL != "" {
+			p.Invocation.ConfigSource.URI = "git+" + remoteURL
+		}
+
+		if p.Invocation.ConfigSource.URI != "" {
+			p.AddMaterial(provenance.Material{URI: p.Invocation.ConfigSource.URI, Digest: p.Invocation.ConfigSource.Digest})
+		}
+	}
+
+	for _, img := range images {
+		p.Invocation.Parameters.Platforms = append(p.Invocation.Parameters.Platforms, img.TargetPlatform)
+
+		if img.DockerfileImageConfig != nil && len(img.DockerfileImageConfig.Args) > 0 {
+			p.Invocation.Parameters.BuildArgs = img.DockerfileImageConfig.Args
+		}
+
+		for _, stg := range img.GetStages() {
+			stageImage := stg.GetStageImage()
+			if stageImage == nil || stageImage.Image == nil || stageImage.Image.GetStageDescription() == nil {
+				continue
+			}
+
+			info := stageImage.Image.GetStageDescription().Info
+			p.BuildConfig.Platforms[img.TargetPlatform] = append(p.BuildConfig.Platforms[img.TargetPlatform], provenance.Stage{
+				Name:        string(stg.Name()),
+				Digest:      stg.GetDigest(),
+				Image:       info.Name,
+				ImageDigest: repoDigestToDigest(info.RepoDigest),
+			})
+		}
+
+		if baseImageReference := img.GetBaseImageReference(); baseImageReference != "" && phase.Conveyor.werfConfig.GetImage(baseImageReference) == nil {
+			p.AddMaterial(provenance.Material{
+				URI:    fmt.Sprintf("pkg:docker/%s?platform=%s", baseImageReference, url.QueryEscape(img.TargetPlatform)),
+				Digest: provenance.ParseDigest(repoDigestToDigest(img.GetBaseImageRepoDigest())),
+			})
+		}
+
+		for _, dependency := range phase.getImageDependencies(name) {
+			if phase.Conveyor.findImage(img.TargetPlatform, dependency.imageName) == nil {
+				continue
+			}
+
+			dependencyStageImage := phase.Conveyor.getImageStage(img.TargetPlatform, dependency.imageName, dependency.stageName).GetStageImage()
+			if dependencyStageImage == nil || dependencyStageImage.Image == nil || dependencyStageImage.Image.GetStageDescription() == nil {
+				continue
+			}
+			stageImage := dependencyStageImage.Image
+
+			p.AddMaterial(provenance.Material{
+				URI:    fmt.Sprintf("pkg:docker/%s?platform=%s", stageImage.Name(), url.QueryEscape(img.TargetPlatform)),
+				Digest: provenance.ParseDigest(repoDigestToDigest(stageImage.GetStageDescription().Info.RepoDigest)),
+			})
+		}
+	}
+
+	return p
+}
+
+type imageDependency struct {
+	imageName string
+	stageName string
+}
+
+// getImageDependencies returns werf images the image is built from, imports files from or depends on.
+func (phase *BuildPhase) getImageDependencies(name string) []imageDependency {
+	var res []imageDependency
+
+	if imageConfig := phase.Conveyor.werfConfig.GetStapelImage(name); imageConfig != nil {
+		res = append(res, getStapelImageDependencies(imageConfig.StapelImageBase)...)
+	} else if imageConfig := phase.Conveyor.werfConfig.GetDockerfileImage(name); imageConfig != nil {
+		for _, dep := range imageConfig.Dependencies {
+			res = append(res, imageDependency{imageName: dep.ImageName})
+		}
+	}
+
+	return res
+}
+
+func getStapelImageDependencies(imageConfig *config.StapelImageBase) []imageDependency {
+	var res []imageDependency
+
+	if imageConfig.FromImageName != "" {
+		res = append(res, imageDependency{imageName: imageConfig.FromImageName})
+	}
+	if imageConfig.FromArtifactName != "" {
+		res = append(res, imageDependency{imageName: imageConfig.FromArtifactName})
+	}
+
+	for _, imp := range imageConfig.Import {
+		importImageName := imp.ImageName
+		if importImageName == "" {
+			importImageName = imp.ArtifactName
+		}
+		res = append(res, imageDependency{imageName: importImageName, stageName: imp.Stage})
+	}
+
+	for _, dep := range imageConfig.Dependencies {
+		res = append(res, imageDependency{imageName: dep.ImageName})
+	}
+
+	return res
+}
+
+// repoDigestToDigest returns the digest part of the REPO@DIGEST reference.
+func repoDigestToDigest(repoDigest string) string {
+	if i := strings.LastIndex(repoDigest, "@"); i != -1 {
+		return repoDigest[i+1:]
+	}
+	return ""
+}
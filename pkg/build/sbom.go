@@ -0,0 +1,90 @@
+package build
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/build/image"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/sbom"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/werf"
+)
+
+func (phase *BuildPhase) attachSBOM(ctx context.Context) error {
+	if _, isLocal := phase.Conveyor.StorageManager.GetStagesStorage().(*storage.LocalStagesStorage); isLocal {
+		return nil
+	}
+
+	phase.sbomRecords = make(map[string]*ReportArtifactRecord)
+
+	return logboek.Context(ctx).Default().LogProcess("Generating SBOM").DoError(func() error {
+		for _, desc := range phase.Conveyor.imagesTree.GetImagesByName(true) {
+			name, images := desc.Unpair()
+			stageDesc := phase.getFinalImageStageDescription(name, images)
+
+			record, _, err := attachArtifact(ctx, docker_registry.API(), stageDesc.Info.Repository, stageDesc.Info.Name, attachArtifactOptions{
+				ArtifactType:   phase.SBOMFormat.MediaType(),
+				LayerMediaType: phase.SBOMFormat.MediaType(),
+				NewDocument: func(_ string) ([]byte, error) {
+					doc, err := phase.newSBOMDocument(ctx, name, images)
+					if err != nil {
+						return nil, err
+					}
+					return doc.Marshal(phase.SBOMFormat)
+				},
+			})
+			if err != nil {
+				return fmt.Errorf("unable to attach SBOM to image %q: %w", name, err)
+			}
+
+			logboek.Context(ctx).Default().LogF("Image %q SBOM: %s\n", name, record.DockerImageName)
+			phase.sbomRecords[name] = record
+		}
+
+		return nil
+	})
+}
+
+// newSBOMDocument scans the filesystem of the image built for each target platform.
+func (phase *BuildPhase) newSBOMDocument(ctx context.Context, name string, images []*image.Image) (*sbom.Document, error) {
+	doc := sbom.NewDocument(name, werf.Version)
+
+	for _, img := range images {
+		stg := img.GetLastNonEmptyStage()
+		if err := phase.Conveyor.StorageManager.FetchStage(ctx, phase.Conveyor.ContainerBackend, stg); err != nil {
+			return nil, fmt.Errorf("unable to fetch stage %s: %w", stg.LogDetailedName(), err)
+		}
+
+		result, err := scanImageFilesystem(ctx, phase.Conveyor.ContainerBackend, stg.GetStageImage().Image.Name(), img.TargetPlatform)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan image for platform %q: %w", img.TargetPlatform, err)
+		}
+
+		for _, reason := range result.IncompleteReasons {
+			logboek.Context(ctx).Warn().LogF("WARNING: SBOM of image %q for platform %q is incomplete: %s\n", name, img.TargetPlatform, reason)
+			doc.MarkIncomplete(fmt.Sprintf("%s: %s", img.TargetPlatform, reason))
+		}
+
+		logboek.Context(ctx).Info().LogF("Found %d packages in image %q for platform %q\n", len(result.Packages), name, img.TargetPlatform)
+		doc.AddPackages(img.TargetPlatform, result.Packages)
+	}
+
+	return doc, nil
+}
+
+func scanImageFilesystem(ctx context.Context, containerBackend container_backend.ContainerBackend, ref, targetPlatform string) (result *sbom.ScanResult, err error) {
+	root, release, err := containerBackend.MountImage(ctx, ref, container_backend.MountImageOpts{TargetPlatform: targetPlatform})
+	if err != nil {
+		return nil, fmt.Errorf("unable to mount image %q: %w", ref, err)
+	}
+	defer func() {
+		if releaseErr := release(); releaseErr != nil && err == nil {
+			err = fmt.Errorf("unable to release image %q filesystem: %w", ref, releaseErr)
+		}
+	}()
+
+	return sbom.Scan(root)
+}
@@ -0,0 +1,184 @@
+package stage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ulikunitz/xz"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/config"
+	"github.com/werf/werf/pkg/giterminism_manager"
+	"github.com/werf/werf/pkg/stapel"
+)
+
+func getArchives(imageBaseConfig *config.StapelImageBase, options *getImportsOptions) []*config.Archive {
+	var archives []*config.Archive
+	for _, elm := range imageBaseConfig.Archive {
+		switch {
+		case elm.Before != "" && string(options.Before) == elm.Before:
+			archives = append(archives, elm)
+		case elm.After != "" && string(options.After) == elm.After:
+			archives = append(archives, elm)
+		}
+	}
+
+	return archives
+}
+
+// getArchiveApplyCommands returns the legacy stapel builder commands to extract the tar into the container.
+func getArchiveApplyCommands(archive *config.Archive, containerTarPath string) []string {
+	var credentialsOpts []string
+	if archive.Owner != "" {
+		credentialsOpts = append(credentialsOpts, fmt.Sprintf("--owner=%s", archive.Owner))
+	}
+	if archive.Group != "" {
+		credentialsOpts = append(credentialsOpts, fmt.Sprintf("--group=%s", archive.Group))
+	}
+
+	installCommand := strings.Join(append(append([]string{stapel.InstallBinPath()}, credentialsOpts...), "-d", quoteShellArg(archive.To)), " ")
+
+	tarCommand := strings.TrimLeft(fmt.Sprintf(
+		"%s %s --no-same-owner -xf %s -C %s",
+		stapel.OptionalSudoCommand(archive.Owner, archive.Group),
+		stapel.TarBinPath(),
+		quoteShellArg(containerTarPath),
+		quoteShellArg(archive.To),
+	), " ")
+
+	return []string{installCommand, tarCommand}
+}
+
+func getArchiveDigestArgs(archive *config.Archive) []string {
+	return []string{"Archive", archive.Sha256, archive.To, archive.Owner, archive.Group}
+}
+
+// prepareArchiveTar fetches the archive, verifies the checksum and writes the decompressed tar into the dir.
+// The local archive is read from the project according to the giterminism rules.
+func prepareArchiveTar(ctx context.Context, giterminismManager giterminism_manager.Interface, archive *config.Archive, dir string) (string, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("unable to create dir %q: %w", dir, err)
+	}
+
+	fetchedPath := filepath.Join(dir, archive.Sha256)
+	tarPath := fetchedPath + ".tar"
+
+	if err := logboek.Context(ctx).Info().LogProcess("Fetching archive %s", archive.Source()).DoError(func() error {
+		return fetchArchive(ctx, giterminismManager, archive, fetchedPath)
+	}); err != nil {
+		return "", fmt.Errorf("unable to fetch archive %s: %w", archive.Source(), err)
+	}
+	defer os.Remove(fetchedPath)
+
+	if err := decompressArchive(fetchedPath, tarPath); err != nil {
+		return "", fmt.Errorf("unable to decompress archive %s: %w", archive.Source(), err)
+	}
+
+	return tarPath, nil
+}
+
+func fetchArchive(ctx context.Context, giterminismManager giterminism_manager.Interface, archive *config.Archive, destPath string) error {
+	var src io.ReadCloser
+	if archive.Url != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, archive.Url, nil)
+		if err != nil {
+			return fmt.Errorf("unable to create request: %w", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("request failed: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return fmt.Errorf("unexpected response status %q", resp.Status)
+		}
+
+		src = resp.Body
+	} else {
+		data, err := giterminismManager.FileReader().ReadArchive(ctx, archive.Path)
+		if err != nil {
+			return err
+		}
+
+		src = io.NopCloser(bytes.NewReader(data))
+	}
+	defer src.Close()
+
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", destPath, err)
+	}
+	defer dest.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dest, hash), src); err != nil {
+		return fmt.Errorf("unable to write file %q: %w", destPath, err)
+	}
+
+	if checksum := hex.EncodeToString(hash.Sum(nil)); checksum != archive.Sha256 {
+		return fmt.Errorf("sha256 checksum mismatch: expected %s, got %s", archive.Sha256, checksum)
+	}
+
+	return dest.Close()
+}
+
+// decompressArchive writes the tar from the plain, gzip, bzip2 or xz compressed archive into the destPath.
+func decompressArchive(archivePath, destPath string) error {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return fmt.Errorf("unable to open file %q: %w", archivePath, err)
+	}
+	defer f.Close()
+
+	br := bufio.NewReader(f)
+	magic, err := br.Peek(6)
+	if err != nil && err != io.EOF {
+		return fmt.Errorf("unable to read file %q: %w", archivePath, err)
+	}
+
+	var r io.Reader
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gr, err := gzip.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("unable to read gzip data: %w", err)
+		}
+		defer gr.Close()
+		r = gr
+	case bytes.HasPrefix(magic, []byte("BZh")):
+		r = bzip2.NewReader(br)
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		xr, err := xz.NewReader(br)
+		if err != nil {
+			return fmt.Errorf("unable to read xz data: %w", err)
+		}
+		r = xr
+	default:
+		r = br
+	}
+
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return fmt.Errorf("unable to create file %q: %w", destPath, err)
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, r); err != nil {
+		return fmt.Errorf("unable to write file %q: %w", destPath, err)
+	}
+
+	return dest.Close()
+}
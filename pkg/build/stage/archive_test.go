@@ -0,0 +1,81 @@
+package stage
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/pkg/config"
+)
+
+var _ = Describe("Archive", func() {
+	var archivePath, archiveSha256 string
+	var tarData []byte
+	var giterminismManager *GiterminismManagerStub
+
+	BeforeEach(func() {
+		var tarBuf bytes.Buffer
+		tw := tar.NewWriter(&tarBuf)
+		Expect(tw.WriteHeader(&tar.Header{Name: "bin/app", Mode: 0o755, Size: 3, Typeflag: tar.TypeReg})).To(Succeed())
+		_, err := tw.Write([]byte("app"))
+		Expect(err).To(Succeed())
+		Expect(tw.Close()).To(Succeed())
+		tarData = tarBuf.Bytes()
+
+		var gzBuf bytes.Buffer
+		gw := gzip.NewWriter(&gzBuf)
+		_, err = gw.Write(tarData)
+		Expect(err).To(Succeed())
+		Expect(gw.Close()).To(Succeed())
+
+		archivePath = "vendor/vendor.tar.gz"
+		giterminismManager = &GiterminismManagerStub{fileReader: NewGiterminismFileReaderStub(map[string][]byte{archivePath: gzBuf.Bytes()})}
+
+		sum := sha256.Sum256(gzBuf.Bytes())
+		archiveSha256 = hex.EncodeToString(sum[:])
+	})
+
+	It("should fetch local archive, verify checksum and decompress it", func() {
+		tarPath, err := prepareArchiveTar(context.Background(), giterminismManager, &config.Archive{Path: archivePath, Sha256: archiveSha256, To: "/opt"}, GinkgoT().TempDir())
+		Expect(err).To(Succeed())
+
+		data, err := os.ReadFile(tarPath)
+		Expect(err).To(Succeed())
+		Expect(data).To(Equal(tarData))
+	})
+
+	It("should fail on checksum mismatch", func() {
+		_, err := prepareArchiveTar(context.Background(), giterminismManager, &config.Archive{Path: archivePath, Sha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", To: "/opt"}, GinkgoT().TempDir())
+		Expect(err).To(MatchError(ContainSubstring("sha256 checksum mismatch")))
+	})
+
+	It("should read local archive only through the giterminism file reader", func() {
+		_, err := prepareArchiveTar(context.Background(), giterminismManager, &config.Archive{Path: "vendor/uncommitted.tar.gz", Sha256: archiveSha256, To: "/opt"}, GinkgoT().TempDir())
+		Expect(err).To(MatchError(ContainSubstring("file not found")))
+	})
+
+	It("should change stage digest depending on archive checksum and destination", func() {
+		ctx := context.Background()
+		baseStageOptions := &BaseStageOptions{ImageName: "example-image", ProjectName: "example-project"}
+		archive := &config.Archive{Path: archivePath, Sha256: archiveSha256, To: "/opt", Before: "install"}
+		otherPathArchive := &config.Archive{Path: "other/vendor.tar.gz", Sha256: archiveSha256, To: "/opt", Before: "install"}
+		otherToArchive := &config.Archive{Path: archivePath, Sha256: archiveSha256, To: "/usr/local", Before: "install"}
+
+		getDigest := func(archives []*config.Archive) string {
+			digest, err := newDependenciesStage(nil, nil, archives, "example-stage", baseStageOptions).GetDependencies(ctx, nil, nil, nil, nil, nil)
+			Expect(err).To(Succeed())
+			return digest
+		}
+
+		Expect(getDigest([]*config.Archive{archive})).NotTo(Equal(getDigest(nil)))
+		Expect(getDigest([]*config.Archive{archive})).To(Equal(getDigest([]*config.Archive{otherPathArchive})))
+		Expect(getDigest([]*config.Archive{archive})).NotTo(Equal(getDigest([]*config.Archive{otherToArchive})))
+	})
+})
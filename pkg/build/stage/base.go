@@ -81,6 +81,7 @@ type BaseStageOptions struct {
 	ImageTmpDir      string
 	ContainerWerfDir string
 	ProjectName      string
+	Secrets          []*config.Secret
 }
 
 func NewBaseStage(name StageName, options *BaseStageOptions) *BaseStage {
@@ -93,6 +94,7 @@ func NewBaseStage(name StageName, options *BaseStageOptions) *BaseStage {
 	s.imageTmpDir = options.ImageTmpDir
 	s.containerWerfDir = options.ContainerWerfDir
 	s.projectName = options.ProjectName
+	s.secrets = options.Secrets
 	return s
 }
 
@@ -109,6 +111,7 @@ type BaseStage struct {
 	containerWerfDir string
 	configMounts     []*config.Mount
 	projectName      string
+	secrets          []*config.Secret
 }
 
 func (s *BaseStage) HasPrevStage() bool {
@@ -296,6 +299,41 @@ func (s *BaseStage) PrepareImage(ctx context.Context, c Conveyor, cb container_b
 		return fmt.Errorf("error adding mounts volumes: %w", err)
 	}
 
+	if err := s.addSecrets(ctx, c, cb, stageImage); err != nil {
+		return fmt.Errorf("error adding secrets: %w", err)
+	}
+
+	return nil
+}
+
+// GetBuildSecrets resolves the image secrets, the secrets from environment variables are written to files when
+// filesOnly is set. The secrets are not taken into account in the stage digest.
+func (s *BaseStage) GetBuildSecrets(ctx context.Context, c Conveyor, filesOnly bool) ([]container_backend.BuildSecret, error) {
+	if len(s.secrets) == 0 {
+		return nil, nil
+	}
+	return c.GetBuildSecrets(ctx, s.secrets, filesOnly)
+}
+
+func (s *BaseStage) addSecrets(ctx context.Context, c Conveyor, cr container_backend.ContainerBackend, stageImage *StageImage) error {
+	if c.UseLegacyStapelBuilder(cr) {
+		secrets, err := s.GetBuildSecrets(ctx, c, true)
+		if err != nil {
+			return err
+		}
+
+		for _, secret := range secrets {
+			stageImage.Builder.LegacyStapelStageBuilder().Container().RunOptions().AddVolume(fmt.Sprintf("%s:%s:ro", secret.Src, secret.Target()))
+		}
+	} else {
+		secrets, err := s.GetBuildSecrets(ctx, c, false)
+		if err != nil {
+			return err
+		}
+
+		stageImage.Builder.StapelStageBuilder().AddSecrets(secrets...)
+	}
+
 	return nil
 }
 
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/werf/werf/pkg/build/import_server"
+	"github.com/werf/werf/pkg/config"
 	"github.com/werf/werf/pkg/container_backend"
 	"github.com/werf/werf/pkg/giterminism_manager"
 	"github.com/werf/werf/pkg/storage"
@@ -32,6 +33,12 @@ type Conveyor interface {
 	GiterminismManager() giterminism_manager.Interface
 
 	UseLegacyStapelBuilder(cb container_backend.ContainerBackend) bool
+
+	// GetBuildSecrets resolves the secrets from werf.yaml, the secrets from environment variables are written to
+	// files when filesOnly is set.
+	GetBuildSecrets(ctx context.Context, secrets []*config.Secret, filesOnly bool) ([]container_backend.BuildSecret, error)
+	// GetCacheMountDir returns the host dir persisting the RUN --mount=type=cache volume with the id between builds.
+	GetCacheMountDir(ctx context.Context, id string) (string, error)
 }
 
 type VirtualMergeOptions struct {
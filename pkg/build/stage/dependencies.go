@@ -52,10 +52,11 @@ func getDependencies(imageBaseConfig *config.StapelImageBase, options *getImport
 	return dependencies
 }
 
-func newDependenciesStage(imports []*config.Import, dependencies []*config.Dependency, name StageName, baseStageOptions *BaseStageOptions) *DependenciesStage {
+func newDependenciesStage(imports []*config.Import, dependencies []*config.Dependency, archives []*config.Archive, name StageName, baseStageOptions *BaseStageOptions) *DependenciesStage {
 	s := &DependenciesStage{}
 	s.imports = imports
 	s.dependencies = dependencies
+	s.archives = archives
 	s.BaseStage = NewBaseStage(name, baseStageOptions)
 	return s
 }
@@ -65,6 +66,7 @@ type DependenciesStage struct {
 
 	imports      []*config.Import
 	dependencies []*config.Dependency
+	archives     []*config.Archive
 }
 
 func (s *DependenciesStage) GetDependencies(ctx context.Context, c Conveyor, cb container_backend.ContainerBackend, prevImage, prevBuiltImage *StageImage, buildContextArchive container_backend.BuildContextArchiver) (string, error) {
@@ -92,6 +94,10 @@ func (s *DependenciesStage) GetDependencies(ctx context.Context, c Conveyor, cb
 		}
 	}
 
+	for _, archive := range s.archives {
+		args = append(args, getArchiveDigestArgs(archive)...)
+	}
+
 	return util.Sha256Hash(args...), nil
 }
 
@@ -156,6 +162,22 @@ func (s *DependenciesStage) prepareImageWithLegacyStapelBuilder(ctx context.Cont
 		}
 	}
 
+	if len(s.archives) > 0 {
+		archivesHostDir := filepath.Join(s.imageTmpDir, string(s.Name()), "archives")
+		archivesContainerDir := path.Join(s.containerWerfDir, "archives")
+
+		for _, archive := range s.archives {
+			tarPath, err := prepareArchiveTar(ctx, c.GiterminismManager(), archive, archivesHostDir)
+			if err != nil {
+				return err
+			}
+
+			stageImage.Builder.LegacyStapelStageBuilder().Container().AddServiceRunCommands(getArchiveApplyCommands(archive, path.Join(archivesContainerDir, filepath.Base(tarPath)))...)
+		}
+
+		stageImage.Builder.LegacyStapelStageBuilder().Container().RunOptions().AddVolume(fmt.Sprintf("%s:%s:ro", archivesHostDir, archivesContainerDir))
+	}
+
 	return nil
 }
 
@@ -216,6 +238,23 @@ func (s *DependenciesStage) prepareImage(ctx context.Context, c Conveyor, cr con
 		}
 	}
 
+	for _, archive := range s.archives {
+		tarPath, err := prepareArchiveTar(ctx, c.GiterminismManager(), archive, filepath.Join(s.imageTmpDir, string(s.Name()), "archives"))
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(tarPath)
+		if err != nil {
+			return fmt.Errorf("unable to open archive %q: %w", tarPath, err)
+		}
+
+		stageImage.Builder.StapelStageBuilder().AddDataArchive(f, container_backend.DirectoryArchive, archive.To, container_backend.AddDataArchiveOptions{
+			Owner: archive.Owner,
+			Group: archive.Group,
+		})
+	}
+
 	return nil
 }
 
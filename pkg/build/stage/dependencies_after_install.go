@@ -5,16 +5,17 @@ import "github.com/werf/werf/pkg/config"
 func GenerateDependenciesAfterInstallStage(imageBaseConfig *config.StapelImageBase, baseStageOptions *BaseStageOptions) *DependenciesAfterInstallStage {
 	imports := getImports(imageBaseConfig, &getImportsOptions{After: Install})
 	dependencies := getDependencies(imageBaseConfig, &getImportsOptions{After: Install})
-	if len(imports)+len(dependencies) > 0 {
-		return newDependenciesAfterInstallStage(imports, dependencies, baseStageOptions)
+	archives := getArchives(imageBaseConfig, &getImportsOptions{After: Install})
+	if len(imports)+len(dependencies)+len(archives) > 0 {
+		return newDependenciesAfterInstallStage(imports, dependencies, archives, baseStageOptions)
 	}
 
 	return nil
 }
 
-func newDependenciesAfterInstallStage(imports []*config.Import, dependencies []*config.Dependency, baseStageOptions *BaseStageOptions) *DependenciesAfterInstallStage {
+func newDependenciesAfterInstallStage(imports []*config.Import, dependencies []*config.Dependency, archives []*config.Archive, baseStageOptions *BaseStageOptions) *DependenciesAfterInstallStage {
 	s := &DependenciesAfterInstallStage{}
-	s.DependenciesStage = newDependenciesStage(imports, dependencies, DependenciesAfterInstall, baseStageOptions)
+	s.DependenciesStage = newDependenciesStage(imports, dependencies, archives, DependenciesAfterInstall, baseStageOptions)
 	return s
 }
 
@@ -5,16 +5,17 @@ import "github.com/werf/werf/pkg/config"
 func GenerateDependenciesAfterSetupStage(imageBaseConfig *config.StapelImageBase, baseStageOptions *BaseStageOptions) *DependenciesAfterSetupStage {
 	imports := getImports(imageBaseConfig, &getImportsOptions{After: Setup})
 	dependencies := getDependencies(imageBaseConfig, &getImportsOptions{After: Setup})
-	if len(imports)+len(dependencies) > 0 {
-		return newDependenciesAfterSetupStage(imports, dependencies, baseStageOptions)
+	archives := getArchives(imageBaseConfig, &getImportsOptions{After: Setup})
+	if len(imports)+len(dependencies)+len(archives) > 0 {
+		return newDependenciesAfterSetupStage(imports, dependencies, archives, baseStageOptions)
 	}
 
 	return nil
 }
 
-func newDependenciesAfterSetupStage(imports []*config.Import, dependencies []*config.Dependency, baseStageOptions *BaseStageOptions) *DependenciesAfterSetupStage {
+func newDependenciesAfterSetupStage(imports []*config.Import, dependencies []*config.Dependency, archives []*config.Archive, baseStageOptions *BaseStageOptions) *DependenciesAfterSetupStage {
 	s := &DependenciesAfterSetupStage{}
-	s.DependenciesStage = newDependenciesStage(imports, dependencies, DependenciesAfterSetup, baseStageOptions)
+	s.DependenciesStage = newDependenciesStage(imports, dependencies, archives, DependenciesAfterSetup, baseStageOptions)
 	return s
 }
 
@@ -5,16 +5,17 @@ import "github.com/werf/werf/pkg/config"
 func GenerateDependenciesBeforeInstallStage(imageBaseConfig *config.StapelImageBase, baseStageOptions *BaseStageOptions) *DependenciesBeforeInstallStage {
 	imports := getImports(imageBaseConfig, &getImportsOptions{Before: Install})
 	dependencies := getDependencies(imageBaseConfig, &getImportsOptions{Before: Install})
-	if len(imports)+len(dependencies) > 0 {
-		return newDependenciesBeforeInstallStage(imports, dependencies, baseStageOptions)
+	archives := getArchives(imageBaseConfig, &getImportsOptions{Before: Install})
+	if len(imports)+len(dependencies)+len(archives) > 0 {
+		return newDependenciesBeforeInstallStage(imports, dependencies, archives, baseStageOptions)
 	}
 
 	return nil
 }
 
-func newDependenciesBeforeInstallStage(imports []*config.Import, dependencies []*config.Dependency, baseStageOptions *BaseStageOptions) *DependenciesBeforeInstallStage {
+func newDependenciesBeforeInstallStage(imports []*config.Import, dependencies []*config.Dependency, archives []*config.Archive, baseStageOptions *BaseStageOptions) *DependenciesBeforeInstallStage {
 	s := &DependenciesBeforeInstallStage{}
-	s.DependenciesStage = newDependenciesStage(imports, dependencies, DependenciesBeforeInstall, baseStageOptions)
+	s.DependenciesStage = newDependenciesStage(imports, dependencies, archives, DependenciesBeforeInstall, baseStageOptions)
 	return s
 }
 
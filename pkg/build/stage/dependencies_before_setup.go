@@ -5,16 +5,17 @@ import "github.com/werf/werf/pkg/config"
 func GenerateDependenciesBeforeSetupStage(imageBaseConfig *config.StapelImageBase, baseStageOptions *BaseStageOptions) *DependenciesBeforeSetupStage {
 	imports := getImports(imageBaseConfig, &getImportsOptions{Before: Setup})
 	dependencies := getDependencies(imageBaseConfig, &getImportsOptions{Before: Setup})
-	if len(imports)+len(dependencies) > 0 {
-		return newDependenciesBeforeSetupStage(imports, dependencies, baseStageOptions)
+	archives := getArchives(imageBaseConfig, &getImportsOptions{Before: Setup})
+	if len(imports)+len(dependencies)+len(archives) > 0 {
+		return newDependenciesBeforeSetupStage(imports, dependencies, archives, baseStageOptions)
 	}
 
 	return nil
 }
 
-func newDependenciesBeforeSetupStage(imports []*config.Import, dependencies []*config.Dependency, baseStageOptions *BaseStageOptions) *DependenciesBeforeSetupStage {
+func newDependenciesBeforeSetupStage(imports []*config.Import, dependencies []*config.Dependency, archives []*config.Archive, baseStageOptions *BaseStageOptions) *DependenciesBeforeSetupStage {
 	s := &DependenciesBeforeSetupStage{}
-	s.DependenciesStage = newDependenciesStage(imports, dependencies, DependenciesBeforeSetup, baseStageOptions)
+	s.DependenciesStage = newDependenciesStage(imports, dependencies, archives, DependenciesBeforeSetup, baseStageOptions)
 	return s
 }
 
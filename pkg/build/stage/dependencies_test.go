@@ -19,7 +19,7 @@ var _ = Describe("DependenciesStage", func() {
 			conveyor := NewConveyorStubForDependencies(NewGiterminismManagerStub(NewLocalGitRepoStub("9d8059842b6fde712c58315ca0ab4713d90761c0"), NewGiterminismInspectorStub()), data.Dependencies)
 			containerBackend := NewContainerBackendStub()
 
-			stage := newDependenciesStage(nil, GetConfigDependencies(data.Dependencies), "example-stage", &BaseStageOptions{
+			stage := newDependenciesStage(nil, GetConfigDependencies(data.Dependencies), nil, "example-stage", &BaseStageOptions{
 				ImageName:   "example-image",
 				ProjectName: "example-project",
 			})
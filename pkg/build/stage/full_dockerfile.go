@@ -684,6 +684,12 @@ func (s *FullDockerfileStage) PrepareImage(ctx context.Context, c Conveyor, cb c
 
 	stageImage.Builder.DockerfileBuilder().SetBuildContextArchive(buildContextArchive)
 
+	secrets, err := s.GetBuildSecrets(ctx, c, false)
+	if err != nil {
+		return fmt.Errorf("unable to get secrets: %w", err)
+	}
+	stageImage.Builder.DockerfileBuilder().AppendSecrets(secrets...)
+
 	stageImage.Builder.DockerfileBuilder().AppendLabels(fmt.Sprintf("%s=%s", image.WerfProjectRepoCommitLabel, c.GiterminismManager().HeadCommit()))
 
 	if c.GiterminismManager().Dev() {
@@ -30,6 +30,10 @@ func (stg *Add) GetDependencies(ctx context.Context, c stage.Conveyor, cb contai
 	args = append(args, "Dest", stg.instruction.Data.DestPath)
 	args = append(args, "Chown", stg.instruction.Data.Chown)
 	args = append(args, "Chmod", stg.instruction.Data.Chmod)
+	if stg.instruction.Data.Link {
+		args = append(args, "Link", "true")
+	}
+	args = append(args, sourceContentsDigestArgs(stg.instruction.Data.SourceContents)...)
 
 	var fileGlobSrc []string
 	for _, src := range stg.instruction.Data.SourcePaths {
@@ -49,7 +53,6 @@ func (stg *Add) GetDependencies(ctx context.Context, c stage.Conveyor, cb contai
 	// TODO(staged-dockerfile): support http src and --checksum option: https://docs.docker.com/engine/reference/builder/#verifying-a-remote-file-checksum-add---checksumchecksum-http-src-dest
 	// TODO(staged-dockerfile): support git ref: https://docs.docker.com/engine/reference/builder/#adding-a-git-repository-add-git-ref-dir
 	// TODO(staged-dockerfile): support --keep-git-dir for git: https://docs.docker.com/engine/reference/builder/#adding-a-git-repository-add-git-ref-dir
-	// TODO(staged-dockerfile): support --link
 
 	return util.Sha256Hash(args...), nil
 }
@@ -19,7 +19,9 @@ type Copy struct {
 }
 
 func NewCopy(i *dockerfile.DockerfileStageInstruction[*instructions.CopyCommand], dependencies []*config.Dependency, hasPrevStage bool, opts *stage.BaseStageOptions) *Copy {
-	return &Copy{Base: NewBase(i, backend_instruction.NewCopy(*i.Data), dependencies, hasPrevStage, opts)}
+	backendInstruction := backend_instruction.NewCopy(*i.Data)
+	backendInstruction.Parents = i.Parents
+	return &Copy{Base: NewBase(i, backendInstruction, dependencies, hasPrevStage, opts)}
 }
 
 func (stg *Copy) ExpandDependencies(ctx context.Context, c stage.Conveyor, baseEnv map[string]string) error {
@@ -50,6 +52,13 @@ func (stg *Copy) GetDependencies(ctx context.Context, c stage.Conveyor, cb conta
 	args = append(args, "Chown", stg.instruction.Data.Chown)
 	args = append(args, "Chmod", stg.instruction.Data.Chmod)
 	args = append(args, "ExpandedFrom", stg.backendInstruction.From)
+	if stg.instruction.Data.Link {
+		args = append(args, "Link", "true")
+	}
+	if stg.instruction.Parents {
+		args = append(args, "Parents", "true")
+	}
+	args = append(args, sourceContentsDigestArgs(stg.instruction.Data.SourceContents)...)
 
 	if stg.UsesBuildContext() {
 		if srcChecksum, err := buildContextArchive.CalculateGlobsChecksum(ctx, stg.instruction.Data.SourcePaths, false); err != nil {
@@ -61,7 +70,21 @@ func (stg *Copy) GetDependencies(ctx context.Context, c stage.Conveyor, cb conta
 
 	// TODO(ilya-lesikov): should checksum of files from other image be calculated if --from specified?
 
-	// TODO(staged-dockerfile): support --link option: https://docs.docker.com/engine/reference/builder/#copy---link
-
 	return util.Sha256Hash(args...), nil
 }
+
+// sourceContentsDigestArgs returns the digest args of the COPY and ADD heredocs, the args are empty without heredocs
+// to keep the digests of the existing instructions.
+func sourceContentsDigestArgs(contents []instructions.SourceContent) []string {
+	if len(contents) == 0 {
+		return nil
+	}
+
+	args := []string{"SourceContents"}
+	for _, content := range contents {
+		args = append(args, "Path", content.Path)
+		args = append(args, "Data", content.Data)
+		args = append(args, "Expand", fmt.Sprintf("%v", content.Expand))
+	}
+	return args
+}
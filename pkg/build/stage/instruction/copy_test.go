@@ -224,4 +224,105 @@ var _ = DescribeTable("COPY digest",
 			},
 		},
 	)),
+
+	Entry("COPY with link", NewTestData(
+		NewCopy(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.CopyCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:    "/app",
+						SourcePaths: []string{"src/", "doc/"},
+					},
+					Link: true,
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"5e407497c5aa8b878fe1c00e46c99623d1355d9918b7838079c7d569cd202b99",
+		TestDataOptions{
+			Files: []*FileData{
+				{Name: "src/main/java/worker/Worker.java", Data: []byte(`package worker;`)},
+				{Name: "src/Worker/Program.cs", Data: []byte(`namespace Worker {}`)},
+				{Name: "doc/README.md", Data: []byte(`# README.md`)},
+			},
+		},
+	)),
+
+	Entry("COPY with parents", NewTestData(
+		NewCopy(
+			func() *dockerfile.DockerfileStageInstruction[*instructions.CopyCommand] {
+				i := dockerfile.NewDockerfileStageInstruction(
+					&instructions.CopyCommand{
+						SourcesAndDest: instructions.SourcesAndDest{
+							DestPath:    "/app",
+							SourcePaths: []string{"src/", "doc/"},
+						},
+					},
+					dockerfile.DockerfileStageInstructionOptions{},
+				)
+				i.Parents = true
+				return i
+			}(),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"0a8b7bddb92409d2dbf6901e093ce2500bd5ae88da15e9fabbec0bcc4f734191",
+		TestDataOptions{
+			Files: []*FileData{
+				{Name: "src/main/java/worker/Worker.java", Data: []byte(`package worker;`)},
+				{Name: "src/Worker/Program.cs", Data: []byte(`namespace Worker {}`)},
+				{Name: "doc/README.md", Data: []byte(`# README.md`)},
+			},
+		},
+	)),
+
+	Entry("COPY heredoc", NewTestData(
+		NewCopy(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.CopyCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:       "/app/config.yaml",
+						SourceContents: []instructions.SourceContent{{Path: "EOF", Data: "debug: true\n", Expand: true}},
+					},
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"ba01a0b1b6fc335212dbbbbe58070deeea0a41ac6e6bd6f78f36ad20dcb480ab",
+		TestDataOptions{},
+	)),
+
+	Entry("COPY heredoc with changed content", NewTestData(
+		NewCopy(
+			dockerfile.NewDockerfileStageInstruction(
+				&instructions.CopyCommand{
+					SourcesAndDest: instructions.SourcesAndDest{
+						DestPath:       "/app/config.yaml",
+						SourceContents: []instructions.SourceContent{{Path: "EOF", Data: "debug: false\n", Expand: true}},
+					},
+				},
+				dockerfile.DockerfileStageInstructionOptions{},
+			),
+			nil, false,
+			&stage.BaseStageOptions{
+				ImageName:   "example-image",
+				ProjectName: "example-project",
+			},
+		),
+		"fd07e62c49a019d656107e9b038441a6f5c4e46e4f18c9e46e838d9bfa4f20e7",
+		TestDataOptions{},
+	)),
 )
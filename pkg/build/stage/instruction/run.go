@@ -36,6 +36,31 @@ func (stg *Run) ExpandInstruction(c stage.Conveyor, env map[string]string) error
 	return nil
 }
 
+func (stg *Run) PrepareImage(ctx context.Context, c stage.Conveyor, cb container_backend.ContainerBackend, prevBuiltImage, stageImage *stage.StageImage, buildContextArchive container_backend.BuildContextArchiver) error {
+	secrets, err := stg.GetBuildSecrets(ctx, c, false)
+	if err != nil {
+		return fmt.Errorf("unable to get secrets: %w", err)
+	}
+	stg.backendInstruction.Secrets = secrets
+
+	cacheMounts := map[string]string{}
+	for _, mnt := range instructions.GetMounts(stg.instruction.Data) {
+		if mnt.Type != instructions.MountTypeCache || mnt.From != "" {
+			continue
+		}
+
+		id := backend_instruction.CacheMountID(mnt)
+		dir, err := c.GetCacheMountDir(ctx, id)
+		if err != nil {
+			return fmt.Errorf("unable to prepare cache mount %q: %w", id, err)
+		}
+		cacheMounts[id] = dir
+	}
+	stg.backendInstruction.CacheMounts = cacheMounts
+
+	return stg.Base.PrepareImage(ctx, c, cb, prevBuiltImage, stageImage, buildContextArchive)
+}
+
 func (stg *Run) GetDependencies(ctx context.Context, c stage.Conveyor, cb container_backend.ContainerBackend, prevImage, prevBuiltImage *stage.StageImage, buildContextArchive container_backend.BuildContextArchiver) (string, error) {
 	var args []string
 
@@ -46,6 +71,14 @@ func (stg *Run) GetDependencies(ctx context.Context, c stage.Conveyor, cb contai
 	args = append(args, append([]string{"Env"}, EnvToSortedArr(stg.GetExpandedEnv(c))...)...)
 	args = append(args, append([]string{"Command"}, stg.instruction.Data.CmdLine...)...)
 	args = append(args, "PrependShell", fmt.Sprintf("%v", stg.instruction.Data.PrependShell))
+	if len(stg.instruction.Data.Files) > 0 {
+		args = append(args, "Files")
+		for _, file := range stg.instruction.Data.Files {
+			args = append(args, "Name", file.Name)
+			args = append(args, "Data", file.Data)
+			args = append(args, "Chomp", fmt.Sprintf("%v", file.Chomp))
+		}
+	}
 	args = append(args, "Network", network)
 	args = append(args, "Security", security)
 
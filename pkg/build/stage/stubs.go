@@ -2,6 +2,7 @@ package stage
 
 import (
 	"context"
+	"fmt"
 
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	. "github.com/onsi/gomega"
@@ -112,10 +113,29 @@ func (inspector *GiterminismInspectorStub) InspectBuildContextFiles(ctx context.
 	return nil
 }
 
+type GiterminismFileReaderStub struct {
+	giterminism_manager.FileReader
+
+	archives map[string][]byte
+}
+
+func NewGiterminismFileReaderStub(archives map[string][]byte) *GiterminismFileReaderStub {
+	return &GiterminismFileReaderStub{archives: archives}
+}
+
+func (reader *GiterminismFileReaderStub) ReadArchive(ctx context.Context, relPath string) ([]byte, error) {
+	data, ok := reader.archives[relPath]
+	if !ok {
+		return nil, fmt.Errorf("unable to read archive %q: file not found", relPath)
+	}
+	return data, nil
+}
+
 type GiterminismManagerStub struct {
 	giterminism_manager.Interface
 
 	inspector    giterminism_manager.Inspector
+	fileReader   giterminism_manager.FileReader
 	localGitRepo git_repo.GitRepo
 }
 
@@ -148,6 +168,10 @@ func (manager *GiterminismManagerStub) Inspector() giterminism_manager.Inspector
 	return manager.inspector
 }
 
+func (manager *GiterminismManagerStub) FileReader() giterminism_manager.FileReader {
+	return manager.fileReader
+}
+
 type LocalGitRepoStub struct {
 	git_repo.GitRepo
 
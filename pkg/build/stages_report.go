@@ -0,0 +1,266 @@
+package build
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/docker/go-units"
+
+	"github.com/werf/werf/pkg/build/stage"
+)
+
+type ReportStageSource string
+
+const (
+	// ReportStageSourceBuilt means the stage has been built by the current werf run.
+	ReportStageSourceBuilt ReportStageSource = "built"
+	// ReportStageSourceLocal means the stage has been found in the local stages storage (no --repo specified).
+	ReportStageSourceLocal ReportStageSource = "local"
+	// ReportStageSourceRepo means the stage has been found in the primary repo.
+	ReportStageSourceRepo ReportStageSource = "repo"
+	// ReportStageSourceSecondaryRepo means the stage has been copied from the secondary repo into the primary repo.
+	ReportStageSourceSecondaryRepo ReportStageSource = "secondary-repo"
+	// ReportStageSourceCacheRepo means the stage has been found in the primary repo and fetched from the cache repo.
+	ReportStageSourceCacheRepo ReportStageSource = "cache-repo"
+)
+
+type ReportStageRecord struct {
+	Name            string
+	Platform        string `json:",omitempty"`
+	Digest          string
+	DockerImageName string
+	Source          ReportStageSource
+	// SourceRepo is the address of the repo the stage has been taken from.
+	SourceRepo string `json:",omitempty"`
+	Size       int64
+
+	CalculationSeconds float64
+	FetchSeconds       float64 `json:",omitempty"`
+	BuildSeconds       float64 `json:",omitempty"`
+	PushSeconds        float64 `json:",omitempty"`
+}
+
+func (record ReportStageRecord) TotalSeconds() float64 {
+	return record.CalculationSeconds + record.FetchSeconds + record.BuildSeconds + record.PushSeconds
+}
+
+// stagesReportRecorder collects the stage records while images are built (possibly in parallel).
+type stagesReportRecorder struct {
+	mux     sync.Mutex
+	records map[stage.Interface]*ReportStageRecord
+}
+
+func newStagesReportRecorder() *stagesReportRecorder {
+	return &stagesReportRecorder{records: make(map[stage.Interface]*ReportStageRecord)}
+}
+
+func (recorder *stagesReportRecorder) Update(stg stage.Interface, f func(record *ReportStageRecord)) {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	record, ok := recorder.records[stg]
+	if !ok {
+		record = &ReportStageRecord{}
+		recorder.records[stg] = record
+	}
+	f(record)
+}
+
+func (recorder *stagesReportRecorder) Get(stg stage.Interface) (ReportStageRecord, bool) {
+	recorder.mux.Lock()
+	defer recorder.mux.Unlock()
+
+	record, ok := recorder.records[stg]
+	if !ok {
+		return ReportStageRecord{}, false
+	}
+	return *record, true
+}
+
+func durationSeconds(d time.Duration) float64 {
+	return float64(d.Milliseconds()) / 1000
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	Name       string           `xml:"name,attr"`
+	Tests      int              `xml:"tests,attr"`
+	Time       string           `xml:"time,attr"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name       string          `xml:"name,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Time       string          `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property,omitempty"`
+	TestCases  []junitTestCase `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut *junitOutput  `xml:"system-out,omitempty"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+type junitOutput struct {
+	Data string `xml:",cdata"`
+}
+
+// ToJUnitData represents every image as a test suite and every stage of the image as a test case, so the slow stages can be found with the CI test reports UI.
+func (report *ImagesReport) ToJUnitData() ([]byte, error) {
+	report.mux.Lock()
+	defer report.mux.Unlock()
+
+	suites := junitTestSuites{Name: "werf build"}
+	var totalSeconds float64
+
+	for _, name := range report.sortedImageNames() {
+		record := report.Images[name]
+
+		suite := junitTestSuite{
+			Name: reportImageName(name),
+			Properties: []junitProperty{
+				{Name: "DockerImageName", Value: record.DockerImageName},
+				{Name: "DockerImageDigest", Value: record.DockerImageDigest},
+				{Name: "Rebuilt", Value: fmt.Sprintf("%t", record.Rebuilt)},
+			},
+		}
+
+		var suiteSeconds float64
+		for _, stageRecord := range record.Stages {
+			stageName := stageRecord.Name
+			if stageRecord.Platform != "" {
+				stageName = fmt.Sprintf("%s (%s)", stageRecord.Name, stageRecord.Platform)
+			}
+
+			testCase := junitTestCase{
+				Name:      stageName,
+				ClassName: reportImageName(name),
+				Time:      formatSeconds(stageRecord.TotalSeconds()),
+				SystemOut: &junitOutput{Data: fmt.Sprintf(
+					"digest: %s\nimage: %s\nsource: %s\nsize: %d\ncalculation: %ss\nfetch: %ss\nbuild: %ss\npush: %ss",
+					stageRecord.Digest, stageRecord.DockerImageName, formatStageSource(stageRecord), stageRecord.Size,
+					formatSeconds(stageRecord.CalculationSeconds), formatSeconds(stageRecord.FetchSeconds), formatSeconds(stageRecord.BuildSeconds), formatSeconds(stageRecord.PushSeconds),
+				)},
+			}
+			if stageRecord.Source == "" {
+				testCase.Skipped = &junitSkipped{Message: "stage has been neither built nor found in the stages storage"}
+			}
+
+			suite.TestCases = append(suite.TestCases, testCase)
+			suiteSeconds += stageRecord.TotalSeconds()
+		}
+
+		suite.Tests = len(suite.TestCases)
+		suite.Time = formatSeconds(suiteSeconds)
+		suites.TestSuites = append(suites.TestSuites, suite)
+		suites.Tests += suite.Tests
+		totalSeconds += suiteSeconds
+	}
+	suites.Time = formatSeconds(totalSeconds)
+
+	data, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// ToMarkdownData is a human-readable summary suitable for CI job summaries and merge request comments.
+func (report *ImagesReport) ToMarkdownData() []byte {
+	report.mux.Lock()
+	defer report.mux.Unlock()
+
+	buf := bytes.NewBuffer([]byte{})
+	buf.WriteString("# werf build report\n")
+
+	for _, name := range report.sortedImageNames() {
+		record := report.Images[name]
+
+		fmt.Fprintf(buf, "\n## %s\n\n", reportImageName(name))
+		fmt.Fprintf(buf, "- Image: `%s`\n", record.DockerImageName)
+		if record.DockerImageDigest != "" {
+			fmt.Fprintf(buf, "- Digest: `%s`\n", record.DockerImageDigest)
+		}
+		fmt.Fprintf(buf, "- Rebuilt: %t\n", record.Rebuilt)
+
+		if len(record.Stages) == 0 {
+			continue
+		}
+
+		buf.WriteString("\n| Stage | Source | Size | Calculation | Fetch | Build | Push | Total |\n")
+		buf.WriteString("|---|---|---:|---:|---:|---:|---:|---:|\n")
+
+		var totalSeconds float64
+		for _, stageRecord := range record.Stages {
+			stageName := stageRecord.Name
+			if stageRecord.Platform != "" {
+				stageName = fmt.Sprintf("%s (%s)", stageRecord.Name, stageRecord.Platform)
+			}
+
+			fmt.Fprintf(buf, "| %s | %s | %s | %ss | %ss | %ss | %ss | %ss |\n",
+				escapeMarkdownTableCell(stageName), escapeMarkdownTableCell(formatStageSource(stageRecord)), units.HumanSize(float64(stageRecord.Size)),
+				formatSeconds(stageRecord.CalculationSeconds), formatSeconds(stageRecord.FetchSeconds), formatSeconds(stageRecord.BuildSeconds), formatSeconds(stageRecord.PushSeconds),
+				formatSeconds(stageRecord.TotalSeconds()),
+			)
+			totalSeconds += stageRecord.TotalSeconds()
+		}
+
+		fmt.Fprintf(buf, "| **Total** | | | | | | | **%ss** |\n", formatSeconds(totalSeconds))
+	}
+
+	return buf.Bytes()
+}
+
+func (report *ImagesReport) sortedImageNames() []string {
+	var names []string
+	for name := range report.Images {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func reportImageName(name string) string {
+	if name == "" {
+		return "~"
+	}
+	return name
+}
+
+func formatStageSource(record ReportStageRecord) string {
+	if record.Source == "" {
+		return "skipped"
+	}
+	if record.SourceRepo == "" {
+		return string(record.Source)
+	}
+	return fmt.Sprintf("%s (%s)", record.Source, record.SourceRepo)
+}
+
+// escapeMarkdownTableCell escapes the characters that break the table row or start the inline code in the cell.
+func escapeMarkdownTableCell(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "|", "\\|", "`", "\\`", "\n", " ").Replace(value)
+}
+
+func formatSeconds(seconds float64) string {
+	return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", seconds), "0"), ".")
+}
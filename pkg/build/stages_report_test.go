@@ -0,0 +1,138 @@
+package build
+
+import (
+	"strings"
+	"testing"
+)
+
+func newTestImagesReport(stages ...ReportStageRecord) *ImagesReport {
+	report := NewImagesReport()
+	report.SetImageRecord("app", ReportImageRecord{
+		WerfImageName:     "app",
+		DockerImageName:   "registry.example.com/app:tag",
+		DockerImageDigest: "sha256:0123",
+		Rebuilt:           true,
+		Stages:            stages,
+	})
+	return report
+}
+
+func TestImagesReportToMarkdownData(t *testing.T) {
+	tests := []struct {
+		name     string
+		stages   []ReportStageRecord
+		expected []string
+	}{
+		{
+			name: "built and repo stages",
+			stages: []ReportStageRecord{
+				{Name: "from", Source: ReportStageSourceRepo, SourceRepo: "registry.example.com/app", Size: 1000, CalculationSeconds: 0.5},
+				{Name: "install", Source: ReportStageSourceBuilt, Size: 2000, CalculationSeconds: 0.25, BuildSeconds: 10, PushSeconds: 1.5},
+			},
+			expected: []string{
+				"| from | repo (registry.example.com/app) | 1kB | 0.5s | 0s | 0s | 0s | 0.5s |\n",
+				"| install | built | 2kB | 0.25s | 0s | 10s | 1.5s | 11.75s |\n",
+				"| **Total** | | | | | | | **12.25s** |\n",
+			},
+		},
+		{
+			name: "special characters are escaped",
+			stages: []ReportStageRecord{
+				{Name: "a|b`c`", Source: ReportStageSourceRepo, SourceRepo: "dir|repo\\"},
+			},
+			expected: []string{
+				"| a\\|b\\`c\\` | repo (dir\\|repo\\\\) | 0B |",
+			},
+		},
+		{
+			name: "skipped stage",
+			stages: []ReportStageRecord{
+				{Name: "setup", CalculationSeconds: 0.1},
+			},
+			expected: []string{
+				"| setup | skipped | 0B | 0.1s | 0s | 0s | 0s | 0.1s |\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := string(newTestImagesReport(tt.stages...).ToMarkdownData())
+
+			if !strings.Contains(data, "## app\n\n- Image: `registry.example.com/app:tag`\n- Digest: `sha256:0123`\n- Rebuilt: true\n") {
+				t.Errorf("unexpected image summary:\n%s", data)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(data, expected) {
+					t.Errorf("expected %q in markdown:\n%s", expected, data)
+				}
+			}
+		})
+	}
+}
+
+func TestImagesReportToJUnitData(t *testing.T) {
+	tests := []struct {
+		name       string
+		stages     []ReportStageRecord
+		expected   []string
+		unexpected []string
+	}{
+		{
+			name: "built and repo stages",
+			stages: []ReportStageRecord{
+				{Name: "from", Platform: "linux/amd64", Source: ReportStageSourceRepo, CalculationSeconds: 0.5},
+				{Name: "install", Source: ReportStageSourceBuilt, BuildSeconds: 10, PushSeconds: 1.5},
+			},
+			expected: []string{
+				`<testsuites name="werf build" tests="2" time="12">`,
+				`<testsuite name="app" tests="2" time="12">`,
+				`<property name="DockerImageName" value="registry.example.com/app:tag"></property>`,
+				`<testcase name="from (linux/amd64)" classname="app" time="0.5">`,
+				`<testcase name="install" classname="app" time="11.5">`,
+				"source: built\nsize: 0\ncalculation: 0s\nfetch: 0s\nbuild: 10s\npush: 1.5s",
+			},
+			unexpected: []string{"<skipped"},
+		},
+		{
+			name: "special characters are escaped",
+			stages: []ReportStageRecord{
+				{Name: `a<b>&"c"`, Source: ReportStageSourceBuilt},
+			},
+			expected: []string{
+				`<testcase name="a&lt;b&gt;&amp;&#34;c&#34;" classname="app" time="0">`,
+			},
+		},
+		{
+			name: "skipped stage",
+			stages: []ReportStageRecord{
+				{Name: "setup", CalculationSeconds: 0.1},
+			},
+			expected: []string{
+				`<testcase name="setup" classname="app" time="0.1">`,
+				`<skipped message="stage has been neither built nor found in the stages storage"></skipped>`,
+				"source: skipped",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := newTestImagesReport(tt.stages...).ToJUnitData()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, expected := range tt.expected {
+				if !strings.Contains(string(data), expected) {
+					t.Errorf("expected %q in junit xml:\n%s", expected, data)
+				}
+			}
+			for _, unexpected := range tt.unexpected {
+				if strings.Contains(string(data), unexpected) {
+					t.Errorf("unexpected %q in junit xml:\n%s", unexpected, data)
+				}
+			}
+		})
+	}
+}
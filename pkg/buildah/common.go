@@ -48,6 +48,8 @@ type BuildFromDockerfileOpts struct {
 	BuildArgs  map[string]string
 	Target     string
 	Labels     []string
+	// Secrets are available to the RUN --mount=type=secret instructions.
+	Secrets []string // {"id=ID,src=PATH", "id=ID,env=ENV", ...}
 }
 
 type RunMount struct {
@@ -73,6 +75,13 @@ type RunCommandOpts struct {
 	GlobalMounts []*specs.Mount
 	// Mounts as allowed in Dockerfile RUN --mount option. Have more restrictions than GlobalMounts (e.g. Source of bind-mount can't be outside of ContextDir or container root).
 	RunMounts []*instructions.Mount
+	// Secrets are available to the secret RunMounts.
+	Secrets []string // {"id=ID,src=PATH", "id=ID,env=ENV", ...}
+	// Stdin is attached to the command (e.g. for werf run -i).
+	Stdin io.Reader
+	// Stderr receives the stderr of the command instead of the LogWriter, the stderr is not included into the error then.
+	Stderr   io.Writer
+	Terminal bool
 }
 
 type RmiOpts struct {
@@ -298,6 +298,9 @@ func (b *NativeBuildah) BuildFromDockerfile(ctx context.Context, dockerfile stri
 		return "", err
 	}
 
+	commonBuildOpts := b.defaultCommonBuildOptions
+	commonBuildOpts.Secrets = opts.Secrets
+
 	buildOpts := define.BuildOptions{
 		Isolation:               define.Isolation(b.Isolation),
 		Args:                    opts.BuildArgs,
@@ -306,7 +309,7 @@ func (b *NativeBuildah) BuildFromDockerfile(ctx context.Context, dockerfile stri
 		OutputFormat:            buildah.Dockerv2ImageManifest,
 		SystemContext:           sysCtx,
 		ConfigureNetwork:        define.NetworkEnabled,
-		CommonBuildOpts:         &b.defaultCommonBuildOptions,
+		CommonBuildOpts:         &commonBuildOpts,
 		Target:                  opts.Target,
 		Platforms:               targetPlatforms,
 		MaxPullPushRetries:      MaxPullPushRetries,
@@ -370,7 +373,14 @@ func (b *NativeBuildah) RunCommand(ctx context.Context, container string, comman
 	nsOpts, netPolicy := generateNamespaceOptionsAndNetworkPolicy(opts.NetworkType)
 	globalMounts := generateGlobalMounts(opts.GlobalMounts)
 	runMounts := generateRunMounts(opts.RunMounts)
+	secrets, err := parse.Secrets(opts.Secrets)
+	if err != nil {
+		return fmt.Errorf("unable to parse secrets: %w", err)
+	}
 	stdout, stderr, stderrBuf := generateStdoutStderr(opts.LogWriter)
+	if opts.Stderr != nil {
+		stderr, stderrBuf = opts.Stderr, &bytes.Buffer{}
+	}
 	command = prependShellToCommand(opts.PrependShell, opts.Shell, command, builder)
 
 	sysCtx, err := b.getSystemContext(opts.TargetPlatform)
@@ -395,12 +405,16 @@ func (b *NativeBuildah) RunCommand(ctx context.Context, container string, comman
 		Cmd:              []string{},
 		Mounts:           globalMounts,
 		RunMounts:        runMounts,
-		// TODO(ilya-lesikov):
-		Secrets: nil,
+		Stdin:            opts.Stdin,
+		Secrets:          secrets,
 		// TODO(ilya-lesikov):
 		SSHSources: nil,
 	}
 
+	if opts.Terminal {
+		runOpts.Terminal = buildah.WithTerminal
+	}
+
 	if err := builder.Run(command, runOpts); err != nil {
 		return fmt.Errorf("RunCommand failed:\n%s\n%w", stderrBuf.String(), err)
 	}
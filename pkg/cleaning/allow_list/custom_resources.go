@@ -0,0 +1,127 @@
+package allow_list
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/jsonpath"
+
+	"github.com/werf/logboek"
+)
+
+// CustomResource describes the kind of custom resources, which images are used in the Kubernetes (e.g. Argo Rollouts or Knative Services).
+type CustomResource struct {
+	APIVersion string
+	Kind       string
+	// ImageFields are JSONPath templates in the kubectl format, e.g. {.spec.template.spec.containers[*].image}
+	ImageFields []string
+}
+
+func (r *CustomResource) matches(obj *unstructured.Unstructured) bool {
+	return obj.GetAPIVersion() == r.APIVersion && obj.GetKind() == r.Kind
+}
+
+func getCustomResourcesImages(ctx context.Context, kubernetesClient kubernetes.Interface, kubernetesNamespace string, customResource *CustomResource) ([]*DeployedImage, error) {
+	gv, err := schema.ParseGroupVersion(customResource.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("invalid apiVersion %q: %w", customResource.APIVersion, err)
+	}
+
+	resourceList, err := kubernetesClient.Discovery().ServerResourcesForGroupVersion(customResource.APIVersion)
+	if apierrors.IsNotFound(err) {
+		logboek.Context(ctx).Info().LogF("Ignore %s %s: the resource is not served by the cluster\n", customResource.APIVersion, customResource.Kind)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var resourceName string
+	var namespaced bool
+	for _, apiResource := range resourceList.APIResources {
+		if apiResource.Kind == customResource.Kind && !strings.Contains(apiResource.Name, "/") {
+			resourceName = apiResource.Name
+			namespaced = apiResource.Namespaced
+			break
+		}
+	}
+
+	if resourceName == "" {
+		logboek.Context(ctx).Info().LogF("Ignore %s %s: the resource is not served by the cluster\n", customResource.APIVersion, customResource.Kind)
+		return nil, nil
+	}
+
+	restClient := kubernetesClient.Discovery().RESTClient()
+	if restClient == nil {
+		return nil, fmt.Errorf("kubernetes client does not support raw requests")
+	}
+
+	pathParts := []string{"/apis", gv.Group, gv.Version}
+	if gv.Group == "" {
+		pathParts = []string{"/api", gv.Version}
+	}
+
+	if namespaced && kubernetesNamespace != "" {
+		pathParts = append(pathParts, "namespaces", kubernetesNamespace)
+	}
+	pathParts = append(pathParts, resourceName)
+
+	data, err := restClient.Get().AbsPath(pathParts...).DoRaw(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &unstructured.UnstructuredList{}
+	if err := list.UnmarshalJSON(data); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal %s list: %w", resourceName, err)
+	}
+
+	var images []*DeployedImage
+	for i := range list.Items {
+		objImages, err := getObjectImagesByImageFields(&list.Items[i], customResource.ImageFields)
+		if err != nil {
+			return nil, err
+		}
+
+		images = AppendDeployedImages(images, objImages...)
+	}
+
+	return images, nil
+}
+
+func getObjectImagesByImageFields(obj *unstructured.Unstructured, imageFields []string) ([]*DeployedImage, error) {
+	var images []*DeployedImage
+
+	for _, imageField := range imageFields {
+		j := jsonpath.New(imageField)
+		j.AllowMissingKeys(true)
+		if err := j.Parse(imageField); err != nil {
+			return nil, fmt.Errorf("invalid JSONPath %q: %w", imageField, err)
+		}
+
+		results, err := j.FindResults(obj.Object)
+		if err != nil {
+			return nil, fmt.Errorf("unable to find %q in %s/%s: %w", imageField, strings.ToLower(obj.GetKind()), obj.GetName(), err)
+		}
+
+		for _, result := range results {
+			for _, value := range result {
+				name, ok := value.Interface().(string)
+				if !ok || name == "" {
+					continue
+				}
+
+				images = AppendDeployedImages(images, &DeployedImage{
+					Name:           name,
+					ResourcesNames: []string{fmt.Sprintf("ns/%s %s/%s field/%s", obj.GetNamespace(), strings.ToLower(obj.GetKind()), obj.GetName(), imageField)},
+				})
+			}
+		}
+	}
+
+	return images, nil
+}
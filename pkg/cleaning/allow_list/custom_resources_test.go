@@ -0,0 +1,144 @@
+package allow_list
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const testRolloutsResourceList = `{
+  "kind": "APIResourceList",
+  "apiVersion": "v1",
+  "groupVersion": "argoproj.io/v1alpha1",
+  "resources": [
+    {"name": "rollouts/status", "namespaced": true, "kind": "Rollout", "verbs": ["get"]},
+    {"name": "rollouts", "namespaced": true, "kind": "Rollout", "verbs": ["list"]}
+  ]
+}`
+
+const testRolloutsList = `{
+  "apiVersion": "argoproj.io/v1alpha1",
+  "kind": "RolloutList",
+  "metadata": {},
+  "items": [
+    {
+      "apiVersion": "argoproj.io/v1alpha1",
+      "kind": "Rollout",
+      "metadata": {"name": "app", "namespace": "ns"},
+      "spec": {"template": {"spec": {"containers": [
+        {"name": "app", "image": "registry.example.com/app:v1"},
+        {"name": "sidecar", "image": "registry.example.com/sidecar:v1"}
+      ]}}}
+    },
+    {
+      "apiVersion": "argoproj.io/v1alpha1",
+      "kind": "Rollout",
+      "metadata": {"name": "worker", "namespace": "ns"},
+      "spec": {"template": {"spec": {"containers": [
+        {"name": "worker", "image": "registry.example.com/app:v1"}
+      ]}}}
+    }
+  ]
+}`
+
+func newTestKubernetesClient(t *testing.T, responses map[string]string) kubernetes.Interface {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := responses[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestGetCustomResourcesImages(t *testing.T) {
+	rollout := &CustomResource{
+		APIVersion:  "argoproj.io/v1alpha1",
+		Kind:        "Rollout",
+		ImageFields: []string{"{.spec.template.spec.containers[*].image}"},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		responses      map[string]string
+		customResource *CustomResource
+		expectedImages []string
+		expectedError  string
+	}{
+		{
+			name: "served resource",
+			responses: map[string]string{
+				"/apis/argoproj.io/v1alpha1":                        testRolloutsResourceList,
+				"/apis/argoproj.io/v1alpha1/namespaces/ns/rollouts": testRolloutsList,
+			},
+			customResource: rollout,
+			expectedImages: []string{"registry.example.com/app:v1", "registry.example.com/sidecar:v1"},
+		},
+		{
+			name:           "group version is not served",
+			responses:      map[string]string{},
+			customResource: rollout,
+		},
+		{
+			name: "kind is not served",
+			responses: map[string]string{
+				"/apis/argoproj.io/v1alpha1": testRolloutsResourceList,
+			},
+			customResource: &CustomResource{APIVersion: "argoproj.io/v1alpha1", Kind: "AnalysisRun", ImageFields: rollout.ImageFields},
+		},
+		{
+			name: "invalid image field",
+			responses: map[string]string{
+				"/apis/argoproj.io/v1alpha1":                        testRolloutsResourceList,
+				"/apis/argoproj.io/v1alpha1/namespaces/ns/rollouts": testRolloutsList,
+			},
+			customResource: &CustomResource{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", ImageFields: []string{"{.spec.containers["}},
+			expectedError:  "invalid JSONPath",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			images, err := getCustomResourcesImages(context.Background(), newTestKubernetesClient(t, tt.responses), "ns", tt.customResource)
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, image := range images {
+				names = append(names, image.Name)
+			}
+			sort.Strings(names)
+
+			if strings.Join(names, " ") != strings.Join(tt.expectedImages, " ") {
+				t.Fatalf("expected images %v, got %v", tt.expectedImages, names)
+			}
+
+			for _, image := range images {
+				if image.Name == "registry.example.com/app:v1" && len(image.ResourcesNames) != 2 {
+					t.Errorf("expected app image to be used by 2 rollouts, got %v", image.ResourcesNames)
+				}
+			}
+		})
+	}
+}
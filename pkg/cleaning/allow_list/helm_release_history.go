@@ -0,0 +1,180 @@
+package allow_list
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// getHelmReleaseHistoryImages returns images of all revisions of the Helm releases (stored in Secrets or ConfigMaps),
+// so that the images needed to rollback a release are not deleted. A release that cannot be decoded or parsed fails
+// the cleanup, otherwise the images of the release would not be kept.
+func getHelmReleaseHistoryImages(ctx context.Context, kubernetesClient kubernetes.Interface, kubernetesNamespace string, customResources []*CustomResource) ([]*DeployedImage, error) {
+	listOptions := metav1.ListOptions{LabelSelector: "owner=helm"}
+
+	secrets, err := kubernetesClient.CoreV1().Secrets(kubernetesNamespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get releases stored in Secrets: %w", err)
+	}
+
+	var releases []*release.Release
+	for _, secret := range secrets.Items {
+		rel, err := decodeHelmRelease(string(secret.Data["release"]))
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode release stored in Secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		releases = append(releases, rel)
+	}
+
+	configMaps, err := kubernetesClient.CoreV1().ConfigMaps(kubernetesNamespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get releases stored in ConfigMaps: %w", err)
+	}
+
+	for _, configMap := range configMaps.Items {
+		rel, err := decodeHelmRelease(configMap.Data["release"])
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode release stored in ConfigMap %s/%s: %w", configMap.Namespace, configMap.Name, err)
+		}
+		releases = append(releases, rel)
+	}
+
+	var images []*DeployedImage
+	for _, rel := range releases {
+		releaseImages, err := getManifestImages(rel.Manifest, customResources)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get images of release %q revision %d: %w", rel.Name, rel.Version, err)
+		}
+
+		for _, image := range releaseImages {
+			var resourcesNames []string
+			for _, resourceName := range image.ResourcesNames {
+				resourcesNames = append(resourcesNames, fmt.Sprintf("ns/%s release/%s revision/%d %s", rel.Namespace, rel.Name, rel.Version, resourceName))
+			}
+
+			images = AppendDeployedImages(images, &DeployedImage{Name: image.Name, ResourcesNames: resourcesNames})
+		}
+	}
+
+	return images, nil
+}
+
+// decodeHelmRelease decodes the release the same way the Helm storage drivers do. The drivers skip the releases that
+// cannot be decoded silently, so they are not used to list releases.
+func decodeHelmRelease(data string) (*release.Release, error) {
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(b) > 3 && bytes.Equal(b[0:3], []byte{0x1f, 0x8b, 0x08}) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+
+		if b, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+
+	rel := &release.Release{}
+	if err := json.Unmarshal(b, rel); err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+func getManifestImages(manifest string, customResources []*CustomResource) ([]*DeployedImage, error) {
+	var images []*DeployedImage
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		obj := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal manifest: %w", err)
+		}
+
+		if len(obj.Object) == 0 {
+			continue
+		}
+
+		objName := fmt.Sprintf("%s/%s", strings.ToLower(obj.GetKind()), obj.GetName())
+		for _, container := range findContainers(obj.Object) {
+			images = AppendDeployedImages(images, &DeployedImage{
+				Name:           container.image,
+				ResourcesNames: []string{fmt.Sprintf("%s container/%s", objName, container.name)},
+			})
+		}
+
+		for _, customResource := range customResources {
+			if !customResource.matches(obj) {
+				continue
+			}
+
+			customResourceImages, err := getObjectImagesByImageFields(obj, customResource.ImageFields)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, image := range customResourceImages {
+				images = AppendDeployedImages(images, &DeployedImage{
+					Name:           image.Name,
+					ResourcesNames: []string{fmt.Sprintf("%s field/%s", objName, strings.Join(customResource.ImageFields, ","))},
+				})
+			}
+		}
+	}
+
+	return images, nil
+}
+
+type container struct {
+	name  string
+	image string
+}
+
+// findContainers finds containers in any pod spec of the object (pods, workloads, pod templates of custom resources).
+func findContainers(value interface{}) []container {
+	var result []container
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			switch key {
+			case "containers", "initContainers", "ephemeralContainers":
+				if list, ok := field.([]interface{}); ok {
+					for _, item := range list {
+						if c, ok := item.(map[string]interface{}); ok {
+							image, _ := c["image"].(string)
+							name, _ := c["name"].(string)
+							if image != "" {
+								result = append(result, container{name: name, image: image})
+							}
+						}
+					}
+				}
+			default:
+				result = append(result, findContainers(field)...)
+			}
+		}
+	case []interface{}:
+		for _, item := range v {
+			result = append(result, findContainers(item)...)
+		}
+	}
+
+	return result
+}
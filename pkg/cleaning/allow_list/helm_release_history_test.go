@@ -0,0 +1,147 @@
+package allow_list
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testReleaseManifest = `---
+# Source: app/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      initContainers:
+      - name: migrate
+        image: registry.example.com/app:migrations-%[1]s
+      containers:
+      - name: app
+        image: registry.example.com/app:%[1]s
+---
+# Source: app/templates/rollout.yaml
+apiVersion: argoproj.io/v1alpha1
+kind: Rollout
+metadata:
+  name: app
+spec:
+  workloadRef:
+    name: app
+  sidecar:
+    image: registry.example.com/sidecar:%[1]s
+`
+
+func TestGetHelmReleaseHistoryImages(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	secrets := driver.NewSecrets(client.CoreV1().Secrets("ns"))
+
+	for version, tag := range []string{"v1", "v2"} {
+		rel := &release.Release{
+			Name:      "app",
+			Namespace: "ns",
+			Version:   version + 1,
+			Info:      &release.Info{Status: release.StatusSuperseded},
+			Manifest:  strings.ReplaceAll(testReleaseManifest, "%[1]s", tag),
+		}
+
+		if err := secrets.Create(fmt.Sprintf("sh.helm.release.v1.app.v%d", rel.Version), rel); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	customResources := []*CustomResource{
+		{APIVersion: "argoproj.io/v1alpha1", Kind: "Rollout", ImageFields: []string{"{.spec.sidecar.image}"}},
+	}
+
+	images, err := getHelmReleaseHistoryImages(context.Background(), client, "ns", customResources)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, image := range images {
+		names = append(names, image.Name)
+	}
+	sort.Strings(names)
+
+	expected := []string{
+		"registry.example.com/app:migrations-v1",
+		"registry.example.com/app:migrations-v2",
+		"registry.example.com/app:v1",
+		"registry.example.com/app:v2",
+		"registry.example.com/sidecar:v1",
+		"registry.example.com/sidecar:v2",
+	}
+
+	if strings.Join(names, " ") != strings.Join(expected, " ") {
+		t.Fatalf("expected images %v, got %v", expected, names)
+	}
+
+	for _, image := range images {
+		if image.Name == "registry.example.com/app:v1" {
+			if len(image.ResourcesNames) != 1 || image.ResourcesNames[0] != "ns/ns release/app revision/1 deployment/app container/app" {
+				t.Errorf("unexpected resources names %v", image.ResourcesNames)
+			}
+		}
+	}
+}
+
+func TestGetHelmReleaseHistoryImagesFailsOnBrokenRelease(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		secret        *corev1.Secret
+		release       *release.Release
+		expectedError string
+	}{
+		{
+			name: "undecodable release",
+			secret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "sh.helm.release.v1.app.v1", Namespace: "ns", Labels: map[string]string{"owner": "helm"}},
+				Data:       map[string][]byte{"release": []byte("not a release")},
+			},
+			expectedError: "unable to decode release stored in Secret ns/sh.helm.release.v1.app.v1",
+		},
+		{
+			name: "unparsable manifest",
+			release: &release.Release{
+				Name:      "app",
+				Namespace: "ns",
+				Version:   1,
+				Info:      &release.Info{Status: release.StatusDeployed},
+				Manifest:  "---\nkind: [Deployment\n",
+			},
+			expectedError: `unable to get images of release "app" revision 1`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			client := fake.NewSimpleClientset()
+
+			if tt.secret != nil {
+				if _, err := client.CoreV1().Secrets("ns").Create(context.Background(), tt.secret, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if tt.release != nil {
+				if err := driver.NewConfigMaps(client.CoreV1().ConfigMaps("ns")).Create("sh.helm.release.v1.app.v1", tt.release); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			_, err := getHelmReleaseHistoryImages(context.Background(), client, "ns", nil)
+			if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+				t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+			}
+		})
+	}
+}
@@ -44,7 +44,13 @@ AppendNewImages:
 	return
 }
 
-func DeployedDockerImages(ctx context.Context, kubernetesClient kubernetes.Interface, kubernetesNamespace string) ([]*DeployedImage, error) {
+type DeployedDockerImagesOptions struct {
+	// HelmReleaseHistory enables scanning of all revisions of the Helm releases
+	HelmReleaseHistory bool
+	CustomResources    []*CustomResource
+}
+
+func DeployedDockerImages(ctx context.Context, kubernetesClient kubernetes.Interface, kubernetesNamespace string, opts DeployedDockerImagesOptions) ([]*DeployedImage, error) {
 	var deployedDockerImages []*DeployedImage
 
 	images, err := getPodsImages(kubernetesClient, kubernetesNamespace)
@@ -95,6 +101,22 @@ func DeployedDockerImages(ctx context.Context, kubernetesClient kubernetes.Inter
 	}
 	deployedDockerImages = AppendDeployedImages(deployedDockerImages, images...)
 
+	for _, customResource := range opts.CustomResources {
+		images, err = getCustomResourcesImages(ctx, kubernetesClient, kubernetesNamespace, customResource)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get %s %s images: %w", customResource.APIVersion, customResource.Kind, err)
+		}
+		deployedDockerImages = AppendDeployedImages(deployedDockerImages, images...)
+	}
+
+	if opts.HelmReleaseHistory {
+		images, err = getHelmReleaseHistoryImages(ctx, kubernetesClient, kubernetesNamespace, opts.CustomResources)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get Helm release history images: %w", err)
+		}
+		deployedDockerImages = AppendDeployedImages(deployedDockerImages, images...)
+	}
+
 	return deployedDockerImages, nil
 }
 
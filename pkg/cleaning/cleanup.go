@@ -10,6 +10,7 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/gookit/color"
 	"github.com/rodaine/table"
+	"go.opentelemetry.io/otel/attribute"
 
 	"github.com/werf/kubedog/pkg/kube"
 	"github.com/werf/logboek"
@@ -22,6 +23,7 @@ import (
 	"github.com/werf/werf/pkg/logging"
 	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/storage/manager"
+	"github.com/werf/werf/pkg/tracing"
 	"github.com/werf/werf/pkg/util"
 )
 
@@ -34,15 +36,35 @@ type CleanupOptions struct {
 	ConfigMetaCleanup                       config.MetaCleanup
 	KeepStagesBuiltWithinLastNHours         uint64
 	DryRun                                  bool
+	SavePlanFile                            string // save the plan of what was (or would be in the dry run mode) deleted
+	PlanFile                                string // apply the previously saved plan
 }
 
 func Cleanup(ctx context.Context, projectName string, storageManager *manager.StorageManager, options CleanupOptions) error {
-	return newCleanupManager(projectName, storageManager, options).run(ctx)
+	if options.PlanFile != "" {
+		return applyCleanupPlan(ctx, projectName, storageManager, options)
+	}
+
+	m := newCleanupManager(projectName, storageManager, options)
+	if err := m.run(ctx); err != nil {
+		return err
+	}
+
+	if options.SavePlanFile != "" {
+		if err := m.plan.Save(options.SavePlanFile); err != nil {
+			return err
+		}
+
+		logboek.Context(ctx).Default().LogFDetails("Cleanup plan saved to %s\n", options.SavePlanFile)
+	}
+
+	return nil
 }
 
-func newCleanupManager(projectName string, storageManager *manager.StorageManager, options CleanupOptions) *cleanupManager {
+func newCleanupManager(projectName string, storageManager manager.StorageManagerInterface, options CleanupOptions) *cleanupManager {
 	return &cleanupManager{
 		stageManager:                            stage_manager.NewManager(),
+		plan:                                    newCleanupPlan(projectName, storageManager),
 		ProjectName:                             projectName,
 		StorageManager:                          storageManager,
 		ImageNameList:                           options.ImageNameList,
@@ -60,6 +82,7 @@ type cleanupManager struct {
 	stageManager stage_manager.Manager
 
 	nonexistentImportMetadataIDs []string
+	plan                         *CleanupPlan
 
 	ProjectName                             string
 	StorageManager                          manager.StorageManagerInterface
@@ -112,7 +135,9 @@ func (m *cleanupManager) init(ctx context.Context) error {
 
 func (m *cleanupManager) run(ctx context.Context) error {
 	if err := logboek.Context(ctx).LogProcess("Fetching manifests and metadata").DoError(func() error {
-		return m.init(ctx)
+		return m.traceStep(ctx, "init", func(ctx context.Context) error {
+			return m.init(ctx)
+		})
 	}); err != nil {
 		return err
 	}
@@ -122,19 +147,27 @@ func (m *cleanupManager) run(ctx context.Context) error {
 			return fmt.Errorf("no kubernetes configs found to skip images being used in the Kubernetes, pass --without-kube option (or WERF_WITHOUT_KUBE env var) to suppress this error")
 		}
 
-		deployedDockerImages, err := m.deployedDockerImages(ctx)
+		var deployedDockerImages []*DeployedDockerImage
+		err := m.traceStep(ctx, "deployed_images", func(ctx context.Context) (err error) {
+			deployedDockerImages, err = m.deployedDockerImages(ctx)
+			return err
+		})
 		if err != nil {
 			return fmt.Errorf("error getting deployed docker images names from Kubernetes: %w", err)
 		}
 
 		if err := logboek.Context(ctx).LogProcess("Skipping repo tags that are being used in Kubernetes").DoError(func() error {
-			return m.skipStageIDsThatAreUsedInKubernetes(ctx, deployedDockerImages)
+			return m.traceStep(ctx, "skip_used_in_kubernetes", func(ctx context.Context) error {
+				return m.skipStageIDsThatAreUsedInKubernetes(ctx, deployedDockerImages)
+			})
 		}); err != nil {
 			return err
 		}
 
 		if err := logboek.Context(ctx).LogProcess("Skipping final repo tags that are being used in Kubernetes").DoError(func() error {
-			return m.skipFinalStageIDsThatAreUsedInKubernetes(ctx, deployedDockerImages)
+			return m.traceStep(ctx, "skip_final_used_in_kubernetes", func(ctx context.Context) error {
+				return m.skipFinalStageIDsThatAreUsedInKubernetes(ctx, deployedDockerImages)
+			})
 		}); err != nil {
 			return err
 		}
@@ -142,21 +175,27 @@ func (m *cleanupManager) run(ctx context.Context) error {
 
 	if !m.ConfigMetaCleanup.DisableGitHistoryBasedPolicy {
 		if err := logboek.Context(ctx).LogProcess("Git history-based cleanup").DoError(func() error {
-			return m.gitHistoryBasedCleanup(ctx)
+			return m.traceStep(ctx, "git_history_based", func(ctx context.Context) error {
+				return m.gitHistoryBasedCleanup(ctx)
+			})
 		}); err != nil {
 			return err
 		}
 	}
 
 	if err := logboek.Context(ctx).LogProcess("Cleanup unused stages").DoError(func() error {
-		return m.cleanupUnusedStages(ctx)
+		return m.traceStep(ctx, "unused_stages", func(ctx context.Context) error {
+			return m.cleanupUnusedStages(ctx)
+		})
 	}); err != nil {
 		return err
 	}
 
 	if m.StorageManager.GetFinalStagesStorage() != nil {
 		if err := logboek.Context(ctx).LogProcess("Cleanup final stages").DoError(func() error {
-			return m.cleanupFinalStages(ctx)
+			return m.traceStep(ctx, "final_stages", func(ctx context.Context) error {
+				return m.cleanupFinalStages(ctx)
+			})
 		}); err != nil {
 			return err
 		}
@@ -165,6 +204,20 @@ func (m *cleanupManager) run(ctx context.Context) error {
 	return nil
 }
 
+func (m *cleanupManager) traceStep(ctx context.Context, step string, f func(ctx context.Context) error) error {
+	return tracing.Do(ctx, fmt.Sprintf("cleanup.%s", step), []attribute.KeyValue{tracing.ProjectKey.String(m.ProjectName)}, f)
+}
+
+// unusedStageReasons returns the reasons why the unprotected stages are deleted
+func (m *cleanupManager) unusedStageReasons() []string {
+	var reasons []string
+	if !(m.WithoutKube || m.ConfigMetaCleanup.DisableKubernetesBasedPolicy) {
+		reasons = append(reasons, CleanupPlanReasonNotInKubernetes)
+	}
+
+	return append(reasons, CleanupPlanReasonPolicy)
+}
+
 func (m *cleanupManager) skipStageIDsThatAreUsedInKubernetes(ctx context.Context, deployedDockerImages []*DeployedDockerImage) error {
 	handledDeployedStages := map[string]bool{}
 	handleTagFunc := func(tag, stageID string, f func()) {
@@ -291,11 +344,20 @@ AppendNewImages:
 }
 
 func (m *cleanupManager) deployedDockerImages(ctx context.Context) ([]*DeployedDockerImage, error) {
+	opts := allow_list.DeployedDockerImagesOptions{HelmReleaseHistory: m.ConfigMetaCleanup.KeepImagesUsedInHelmReleaseHistory}
+	for _, customResource := range m.ConfigMetaCleanup.KubernetesCustomResources {
+		opts.CustomResources = append(opts.CustomResources, &allow_list.CustomResource{
+			APIVersion:  customResource.APIVersion,
+			Kind:        customResource.Kind,
+			ImageFields: customResource.ImageFields,
+		})
+	}
+
 	var deployedDockerImages []*DeployedDockerImage
 	for _, contextClient := range m.KubernetesContextClients {
 		if err := logboek.Context(ctx).LogProcessInline("Getting deployed docker images (context %s)", contextClient.ContextName).
 			DoError(func() error {
-				contextDeployedImages, err := allow_list.DeployedDockerImages(ctx, contextClient.Client, m.KubernetesNamespaceRestrictionByContext[contextClient.ContextName])
+				contextDeployedImages, err := allow_list.DeployedDockerImages(ctx, contextClient.Client, m.KubernetesNamespaceRestrictionByContext[contextClient.ContextName], opts)
 				if err != nil {
 					return fmt.Errorf("cannot get deployed imagesStageList: %w", err)
 				}
@@ -540,7 +602,7 @@ func (m *cleanupManager) cleanupImageMetadata(ctx context.Context, imageName str
 			}
 
 			if err := logProcessDoError(func() error {
-				return m.deleteImageMetadata(ctx, imageName, stageIDCommitListToDelete)
+				return m.deleteImageMetadata(ctx, imageName, stageIDCommitListToDelete, CleanupPlanReasonPolicy)
 			}); err != nil {
 				return err
 			}
@@ -557,7 +619,7 @@ func (m *cleanupManager) cleanupImageMetadata(ctx context.Context, imageName str
 		}
 
 		if err := logProcessDoError(func() error {
-			return m.deleteImageMetadata(ctx, imageName, nonexistentStageIDCommitList)
+			return m.deleteImageMetadata(ctx, imageName, nonexistentStageIDCommitList, CleanupPlanReasonNonexistentStage)
 		}); err != nil {
 			return err
 		}
@@ -573,7 +635,7 @@ func (m *cleanupManager) cleanupImageMetadata(ctx context.Context, imageName str
 		}
 
 		if err := logProcessDoError(func() error {
-			return m.deleteImageMetadata(ctx, imageName, stageIDNonexistentCommitList)
+			return m.deleteImageMetadata(ctx, imageName, stageIDNonexistentCommitList, CleanupPlanReasonNotInGit)
 		}); err != nil {
 			return err
 		}
@@ -606,7 +668,7 @@ func (m *cleanupManager) cleanupNonexistentImageMetadata(ctx context.Context) er
 
 	return logboek.Context(ctx).Default().LogProcess("Deleting metadata for nonexistent images (%d)", counter).DoError(func() error {
 		for imageName, stageIDCommitList := range stageIDCommitListByNonexistentImage {
-			if err := m.deleteImageMetadata(ctx, imageName, stageIDCommitList); err != nil {
+			if err := m.deleteImageMetadata(ctx, imageName, stageIDCommitList, CleanupPlanReasonNonexistentImage); err != nil {
 				return err
 			}
 		}
@@ -615,7 +677,9 @@ func (m *cleanupManager) cleanupNonexistentImageMetadata(ctx context.Context) er
 	})
 }
 
-func (m *cleanupManager) deleteImageMetadata(ctx context.Context, imageName string, stageIDCommitList map[string][]string) error {
+func (m *cleanupManager) deleteImageMetadata(ctx context.Context, imageName string, stageIDCommitList map[string][]string, reason string) error {
+	m.plan.addImageMetadata(imageName, stageIDCommitList, reason)
+
 	if err := deleteImageMetadata(ctx, m.ProjectName, m.StorageManager, imageName, stageIDCommitList, m.DryRun); err != nil {
 		return err
 	}
@@ -732,6 +796,8 @@ func (m *cleanupManager) cleanupUnusedStages(ctx context.Context) error {
 	}
 
 	if len(stageDescriptionListToDelete) != 0 {
+		m.plan.addStages(stageDescriptionListToDelete, false, m.unusedStageReasons()...)
+
 		if err := logboek.Context(ctx).Default().LogProcess("Deleting stages tags (%d/%d)", len(stageDescriptionListToDelete), stageDescriptionListCount).DoError(func() error {
 			return m.deleteStages(ctx, stageDescriptionListToDelete, false)
 		}); err != nil {
@@ -747,7 +813,7 @@ func (m *cleanupManager) cleanupUnusedStages(ctx context.Context) error {
 
 	if len(m.nonexistentImportMetadataIDs) != 0 {
 		if err := logboek.Context(ctx).Default().LogProcess("Cleaning imports metadata (%d)", len(m.nonexistentImportMetadataIDs)).DoError(func() error {
-			return m.deleteImportsMetadata(ctx, m.nonexistentImportMetadataIDs)
+			return m.deleteImportsMetadata(ctx, m.nonexistentImportMetadataIDs, CleanupPlanReasonNonexistentStage)
 		}); err != nil {
 			return err
 		}
@@ -777,6 +843,8 @@ FilterOutFinalStages:
 	}
 
 	if len(finalStagesDescriptionListToDelete) != 0 {
+		m.plan.addStages(finalStagesDescriptionListToDelete, true, append(m.unusedStageReasons(), CleanupPlanReasonNonexistentStage)...)
+
 		if err := logboek.Context(ctx).Default().LogProcess("Deleting final stages tags (%d/%d)", len(finalStagesDescriptionListToDelete), finalStageDescriptionListFullCount).DoError(func() error {
 			return m.deleteStages(ctx, finalStagesDescriptionListToDelete, true)
 		}); err != nil {
@@ -804,7 +872,7 @@ func (m *cleanupManager) initImportsMetadata(ctx context.Context, stageDescripti
 		if metadata == nil {
 			if err := logboek.Context(ctx).Warn().LogProcess("Deleting invalid import metadata %s", metadataID).
 				DoError(func() error {
-					return m.deleteImportsMetadata(ctx, []string{metadataID})
+					return m.deleteImportsMetadata(ctx, []string{metadataID}, CleanupPlanReasonInvalid)
 				}); err != nil {
 				return fmt.Errorf("unable to delete import metadata %s: %w", metadataID, err)
 			}
@@ -826,7 +894,9 @@ func (m *cleanupManager) initImportsMetadata(ctx context.Context, stageDescripti
 	})
 }
 
-func (m *cleanupManager) deleteImportsMetadata(ctx context.Context, importMetadataIDs []string) error {
+func (m *cleanupManager) deleteImportsMetadata(ctx context.Context, importMetadataIDs []string, reason string) error {
+	m.plan.addImportMetadata(importMetadataIDs, reason)
+
 	return deleteImportsMetadata(ctx, m.ProjectName, m.StorageManager, importMetadataIDs, m.DryRun)
 }
 
@@ -963,6 +1033,7 @@ func (m *cleanupManager) deleteUnusedCustomTags(ctx context.Context) error {
 		numberOfCustomTags += len(customTagList)
 		if !m.stageManager.IsStageExist(stageID) {
 			customTagListToDelete = append(customTagListToDelete, customTagList...)
+			m.plan.addCustomTags(stageID, customTagList, CleanupPlanReasonNonexistentStage)
 		} else {
 			customTagListToKeep = append(customTagListToKeep, customTagList...)
 		}
@@ -1027,6 +1098,24 @@ You should specify a token with delete:packages and read:packages scopes to remo
 Check --repo-github-token option.
 Be aware that the token provided to GitHub Actions workflow is not enough to remove package versions.
 Read more details here https://werf.io/documentation/usage/cleanup/cr_cleanup.html#github-packages`, err)
+	case docker_registry.IsGiteaUnauthorizedErr(err):
+		return fmt.Errorf(`%w
+
+You should specify a Gitea (Forgejo) token with the write:package scope to remove container package versions.
+Check --repo-gitea-token option.
+Read more details here https://werf.io/documentation/usage/cleanup/cr_cleanup.html#gitea-and-forgejo`, err)
+	case docker_registry.IsNexusUnauthorizedErr(err):
+		return fmt.Errorf(`%w
+
+You should specify Nexus username and password of a user with the permission to delete components to remove tags with Nexus REST API.
+Check --repo-nexus-username, --repo-nexus-password and --repo-nexus-url options.
+Read more details here https://werf.io/documentation/usage/cleanup/cr_cleanup.html#nexus`, err)
+	case docker_registry.IsArtifactoryUnauthorizedErr(err):
+		return fmt.Errorf(`%w
+
+You should specify Artifactory username and password (API key or identity token) of a user with the delete permission to remove tags with Artifactory REST API.
+Check --repo-artifactory-username and --repo-artifactory-password options.
+Read more details here https://werf.io/documentation/usage/cleanup/cr_cleanup.html#jfrog-artifactory`, err)
 	case docker_registry.IsSelectelUnauthorizedErr(err):
 		return fmt.Errorf(`%w
 
@@ -0,0 +1,359 @@
+package cleaning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/storage/manager"
+)
+
+const (
+	// CleanupPlanReasonPolicy means that the object is not kept by any cleanup policy (keep policies, keepImagesBuiltWithinLastNHours)
+	CleanupPlanReasonPolicy = "policy"
+	// CleanupPlanReasonNotInGit means that the commit the image metadata refers to does not exist in the git repository
+	CleanupPlanReasonNotInGit = "not-in-git"
+	// CleanupPlanReasonNotInKubernetes means that the image is not used in the Kubernetes
+	CleanupPlanReasonNotInKubernetes = "not-in-k8s"
+	// CleanupPlanReasonNonexistentStage means that the stage the object refers to does not exist or is being deleted
+	CleanupPlanReasonNonexistentStage = "nonexistent-stage"
+	// CleanupPlanReasonNonexistentImage means that the image the metadata refers to is not defined in the werf.yaml
+	CleanupPlanReasonNonexistentImage = "nonexistent-image"
+	// CleanupPlanReasonInvalid means that the metadata cannot be read
+	CleanupPlanReasonInvalid = "invalid"
+)
+
+// CleanupPlan is a machine-readable list of everything that the cleanup deletes with the reasons.
+// A plan saved with the dry run can be reviewed and applied later with the verification that nothing in it has become used.
+type CleanupPlan struct {
+	ProjectName    string                       `json:"projectName"`
+	Repo           string                       `json:"repo"`
+	FinalRepo      string                       `json:"finalRepo,omitempty"`
+	CreatedAt      time.Time                    `json:"createdAt"`
+	Stages         []*CleanupPlanStage          `json:"stages"`
+	FinalStages    []*CleanupPlanStage          `json:"finalStages"`
+	CustomTags     []*CleanupPlanCustomTag      `json:"customTags"`
+	ImageMetadata  []*CleanupPlanImageMetadata  `json:"imageMetadata"`
+	ImportMetadata []*CleanupPlanImportMetadata `json:"importMetadata"`
+}
+
+type CleanupPlanStage struct {
+	Tag       string    `json:"tag"`
+	CreatedAt time.Time `json:"createdAt"`
+	Reasons   []string  `json:"reasons"`
+
+	description *image.StageDescription
+}
+
+type CleanupPlanCustomTag struct {
+	Tag     string   `json:"tag"`
+	StageID string   `json:"stageID"`
+	Reasons []string `json:"reasons"`
+}
+
+type CleanupPlanImageMetadata struct {
+	ImageName string   `json:"imageName"`
+	StageID   string   `json:"stageID"`
+	Commits   []string `json:"commits"`
+	Reasons   []string `json:"reasons"`
+}
+
+type CleanupPlanImportMetadata struct {
+	ImportMetadataID string   `json:"importMetadataID"`
+	Reasons          []string `json:"reasons"`
+}
+
+func newCleanupPlan(projectName string, storageManager manager.StorageManagerInterface) *CleanupPlan {
+	plan := &CleanupPlan{
+		ProjectName: projectName,
+		Repo:        storageManager.GetStagesStorage().String(),
+		CreatedAt:   time.Now().UTC(),
+	}
+
+	if storageManager.GetFinalStagesStorage() != nil {
+		plan.FinalRepo = storageManager.GetFinalStagesStorage().String()
+	}
+
+	return plan
+}
+
+func LoadCleanupPlan(path string) (*CleanupPlan, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cleanup plan file %q: %w", path, err)
+	}
+
+	plan := &CleanupPlan{}
+	if err := json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal cleanup plan file %q: %w", path, err)
+	}
+
+	return plan, nil
+}
+
+func (p *CleanupPlan) Save(path string) error {
+	p.sort()
+
+	data, err := json.MarshalIndent(p, "", "\t")
+	if err != nil {
+		return fmt.Errorf("unable to marshal cleanup plan: %w", err)
+	}
+	data = append(data, '\n')
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("unable to write cleanup plan file %q: %w", path, err)
+	}
+
+	return nil
+}
+
+func (p *CleanupPlan) sort() {
+	sort.Slice(p.Stages, func(i, j int) bool { return p.Stages[i].Tag < p.Stages[j].Tag })
+	sort.Slice(p.FinalStages, func(i, j int) bool { return p.FinalStages[i].Tag < p.FinalStages[j].Tag })
+	sort.Slice(p.CustomTags, func(i, j int) bool { return p.CustomTags[i].Tag < p.CustomTags[j].Tag })
+	sort.Slice(p.ImageMetadata, func(i, j int) bool {
+		if p.ImageMetadata[i].ImageName != p.ImageMetadata[j].ImageName {
+			return p.ImageMetadata[i].ImageName < p.ImageMetadata[j].ImageName
+		}
+		return p.ImageMetadata[i].StageID < p.ImageMetadata[j].StageID
+	})
+	sort.Slice(p.ImportMetadata, func(i, j int) bool {
+		return p.ImportMetadata[i].ImportMetadataID < p.ImportMetadata[j].ImportMetadataID
+	})
+}
+
+func (p *CleanupPlan) addStages(stages []*image.StageDescription, isFinal bool, reasons ...string) {
+	for _, stg := range stages {
+		planStage := &CleanupPlanStage{
+			Tag:         stg.Info.Tag,
+			CreatedAt:   stg.Info.GetCreatedAt().UTC(),
+			Reasons:     reasons,
+			description: stg,
+		}
+
+		if isFinal {
+			p.FinalStages = append(p.FinalStages, planStage)
+		} else {
+			p.Stages = append(p.Stages, planStage)
+		}
+	}
+}
+
+func (p *CleanupPlan) addCustomTags(stageID string, customTagList []string, reasons ...string) {
+	for _, customTag := range customTagList {
+		p.CustomTags = append(p.CustomTags, &CleanupPlanCustomTag{Tag: customTag, StageID: stageID, Reasons: reasons})
+	}
+}
+
+func (p *CleanupPlan) addImageMetadata(imageName string, stageIDCommitList map[string][]string, reasons ...string) {
+	for stageID, commitList := range stageIDCommitList {
+		if len(commitList) == 0 {
+			continue
+		}
+
+		p.ImageMetadata = append(p.ImageMetadata, &CleanupPlanImageMetadata{
+			ImageName: imageName,
+			StageID:   stageID,
+			Commits:   commitList,
+			Reasons:   reasons,
+		})
+	}
+}
+
+func (p *CleanupPlan) addImportMetadata(importMetadataIDs []string, reasons ...string) {
+	for _, importMetadataID := range importMetadataIDs {
+		p.ImportMetadata = append(p.ImportMetadata, &CleanupPlanImportMetadata{ImportMetadataID: importMetadataID, Reasons: reasons})
+	}
+}
+
+// verify checks that each object of the plan is still planned for deletion by the actual plan.
+// The stage descriptions, which are needed for deletion, are taken from the actual plan.
+func (p *CleanupPlan) verify(actualPlan *CleanupPlan) error {
+	var conflicts []string
+
+	verifyStages := func(stages, actualStages []*CleanupPlanStage, kind string) {
+		actualStageByTag := map[string]*CleanupPlanStage{}
+		for _, stg := range actualStages {
+			actualStageByTag[stg.Tag] = stg
+		}
+
+		for _, stg := range stages {
+			if actualStage, ok := actualStageByTag[stg.Tag]; ok {
+				stg.description = actualStage.description
+			} else {
+				conflicts = append(conflicts, fmt.Sprintf("%s %s", kind, stg.Tag))
+			}
+		}
+	}
+
+	verifyStages(p.Stages, actualPlan.Stages, "stage")
+	verifyStages(p.FinalStages, actualPlan.FinalStages, "final stage")
+
+	actualCustomTags := map[string]bool{}
+	for _, customTag := range actualPlan.CustomTags {
+		actualCustomTags[customTag.Tag] = true
+	}
+
+	for _, customTag := range p.CustomTags {
+		if !actualCustomTags[customTag.Tag] {
+			conflicts = append(conflicts, fmt.Sprintf("custom tag %s", customTag.Tag))
+		}
+	}
+
+	actualImageMetadata := map[string]bool{}
+	for _, im := range actualPlan.ImageMetadata {
+		for _, commit := range im.Commits {
+			actualImageMetadata[strings.Join([]string{im.ImageName, im.StageID, commit}, "/")] = true
+		}
+	}
+
+	for _, im := range p.ImageMetadata {
+		for _, commit := range im.Commits {
+			if !actualImageMetadata[strings.Join([]string{im.ImageName, im.StageID, commit}, "/")] {
+				conflicts = append(conflicts, fmt.Sprintf("image %q metadata stage ID %s commit %s", im.ImageName, im.StageID, commit))
+			}
+		}
+	}
+
+	actualImportMetadata := map[string]bool{}
+	for _, importMetadata := range actualPlan.ImportMetadata {
+		actualImportMetadata[importMetadata.ImportMetadataID] = true
+	}
+
+	for _, importMetadata := range p.ImportMetadata {
+		if !actualImportMetadata[importMetadata.ImportMetadataID] {
+			conflicts = append(conflicts, fmt.Sprintf("import metadata %s", importMetadata.ImportMetadataID))
+		}
+	}
+
+	if len(conflicts) != 0 {
+		return fmt.Errorf("cleanup plan is outdated, the following objects are not planned for deletion anymore (they have become used or have already been deleted):\n  %s\n\nprepare and review a new cleanup plan", strings.Join(conflicts, "\n  "))
+	}
+
+	return nil
+}
+
+func applyCleanupPlan(ctx context.Context, projectName string, storageManager manager.StorageManagerInterface, options CleanupOptions) error {
+	plan, err := LoadCleanupPlan(options.PlanFile)
+	if err != nil {
+		return err
+	}
+
+	actualPlan := newCleanupPlan(projectName, storageManager)
+	if plan.ProjectName != actualPlan.ProjectName {
+		return fmt.Errorf("cleanup plan is prepared for project %q, not %q", plan.ProjectName, actualPlan.ProjectName)
+	}
+
+	if plan.Repo != actualPlan.Repo || plan.FinalRepo != actualPlan.FinalRepo {
+		return fmt.Errorf("cleanup plan is prepared for repo %q and final repo %q, not %q and %q", plan.Repo, plan.FinalRepo, actualPlan.Repo, actualPlan.FinalRepo)
+	}
+
+	verifyOptions := options
+	verifyOptions.DryRun = true
+	verifyOptions.PlanFile = ""
+	verifyOptions.SavePlanFile = ""
+
+	m := newCleanupManager(projectName, storageManager, verifyOptions)
+	if err := logboek.Context(ctx).LogProcess("Verifying cleanup plan %s", options.PlanFile).DoError(func() error {
+		if err := m.run(ctx); err != nil {
+			return err
+		}
+
+		return plan.verify(m.plan)
+	}); err != nil {
+		return err
+	}
+
+	return logboek.Context(ctx).LogProcess("Applying cleanup plan %s", options.PlanFile).DoError(func() error {
+		return plan.apply(ctx, storageManager, options.DryRun)
+	})
+}
+
+func (p *CleanupPlan) apply(ctx context.Context, storageManager manager.StorageManagerInterface, dryRun bool) error {
+	imageStageIDCommitList := map[string]map[string][]string{}
+	for _, im := range p.ImageMetadata {
+		if _, ok := imageStageIDCommitList[im.ImageName]; !ok {
+			imageStageIDCommitList[im.ImageName] = map[string][]string{}
+		}
+		imageStageIDCommitList[im.ImageName][im.StageID] = append(imageStageIDCommitList[im.ImageName][im.StageID], im.Commits...)
+	}
+
+	if len(imageStageIDCommitList) != 0 {
+		if err := logboek.Context(ctx).Default().LogProcess("Deleting image metadata (%d)", len(p.ImageMetadata)).DoError(func() error {
+			for imageName, stageIDCommitList := range imageStageIDCommitList {
+				if err := deleteImageMetadata(ctx, p.ProjectName, storageManager, imageName, stageIDCommitList, dryRun); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+
+	deleteStageOptions := manager.ForEachDeleteStageOptions{
+		FilterStagesAndProcessRelatedDataOptions: storage.FilterStagesAndProcessRelatedDataOptions{
+			SkipUsedImage: true,
+		},
+	}
+
+	for _, desc := range []struct {
+		header  string
+		stages  []*CleanupPlanStage
+		isFinal bool
+	}{
+		{"Deleting stages tags", p.Stages, false},
+		{"Deleting final stages tags", p.FinalStages, true},
+	} {
+		if len(desc.stages) == 0 {
+			continue
+		}
+
+		var stages []*image.StageDescription
+		for _, stg := range desc.stages {
+			stages = append(stages, stg.description)
+		}
+
+		if err := logboek.Context(ctx).Default().LogProcess("%s (%d)", desc.header, len(stages)).DoError(func() error {
+			return deleteStages(ctx, storageManager, dryRun, deleteStageOptions, stages, desc.isFinal)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(p.CustomTags) != 0 {
+		var customTagList []string
+		for _, customTag := range p.CustomTags {
+			customTagList = append(customTagList, customTag.Tag)
+		}
+
+		if err := logboek.Context(ctx).Default().LogProcess("Deleting custom tags (%d)", len(customTagList)).DoError(func() error {
+			return deleteCustomTags(ctx, storageManager, customTagList, dryRun)
+		}); err != nil {
+			return err
+		}
+	}
+
+	if len(p.ImportMetadata) != 0 {
+		var importMetadataIDs []string
+		for _, importMetadata := range p.ImportMetadata {
+			importMetadataIDs = append(importMetadataIDs, importMetadata.ImportMetadataID)
+		}
+
+		if err := logboek.Context(ctx).Default().LogProcess("Cleaning imports metadata (%d)", len(importMetadataIDs)).DoError(func() error {
+			return deleteImportsMetadata(ctx, p.ProjectName, storageManager, importMetadataIDs, dryRun)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,64 @@
+package cleaning
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+func TestCleanupPlanVerify(t *testing.T) {
+	newPlan := func(stageTags []string, commits []string) *CleanupPlan {
+		plan := &CleanupPlan{ProjectName: "project", Repo: "registry.example.com/project"}
+
+		var stages []*image.StageDescription
+		for _, tag := range stageTags {
+			stages = append(stages, &image.StageDescription{Info: &image.Info{Tag: tag}})
+		}
+		plan.addStages(stages, false, CleanupPlanReasonNotInKubernetes, CleanupPlanReasonPolicy)
+		plan.addImageMetadata("app", map[string][]string{"stage-1": commits}, CleanupPlanReasonPolicy)
+		plan.addCustomTags("stage-1", []string{"custom-1"}, CleanupPlanReasonNonexistentStage)
+		plan.addImportMetadata([]string{"import-1"}, CleanupPlanReasonNonexistentStage)
+
+		return plan
+	}
+
+	path := filepath.Join(t.TempDir(), "plan.json")
+	if err := newPlan([]string{"stage-1", "stage-2"}, []string{"commit-1", "commit-2"}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	plan, err := LoadCleanupPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(plan.Stages) != 2 || plan.Stages[0].Tag != "stage-1" || strings.Join(plan.Stages[0].Reasons, ",") != "not-in-k8s,policy" {
+		t.Fatalf("unexpected loaded plan stages %+v", plan.Stages)
+	}
+
+	actualPlan := newPlan([]string{"stage-1", "stage-2", "stage-3"}, []string{"commit-1", "commit-2", "commit-3"})
+	if err := plan.verify(actualPlan); err != nil {
+		t.Fatalf("unexpected verification error: %s", err)
+	}
+
+	if plan.Stages[0].description != actualPlan.Stages[0].description {
+		t.Errorf("expected stage description to be taken from the actual plan")
+	}
+
+	err = plan.verify(newPlan([]string{"stage-2"}, []string{"commit-2"}))
+	if err == nil {
+		t.Fatal("expected verification error")
+	}
+
+	for _, conflict := range []string{"stage stage-1", `image "app" metadata stage ID stage-1 commit commit-1`} {
+		if !strings.Contains(err.Error(), conflict) {
+			t.Errorf("expected verification error to contain %q, got: %s", conflict, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "stage-2") {
+		t.Errorf("unexpected conflict in verification error: %s", err)
+	}
+}
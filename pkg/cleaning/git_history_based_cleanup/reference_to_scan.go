@@ -177,8 +177,14 @@ func ReferencesToScan(ctx context.Context, gitRepository *git.Repository, keepPo
 			policyRefs = selectBranchReferencesByRegexp(branchesRefs, policy.References.BranchRegexp)
 			policyRefs = applyCleanupKeepPolicy(policyRefs, policy)
 			resultBranchesRefs = mergeReferences(resultBranchesRefs, policyRefs)
-		} else if policy.References.TagRegexp != nil {
-			policyRefs = selectTagReferencesByRegexp(tagsRefs, policy.References.TagRegexp)
+		} else if policy.References.TagRegexp != nil || policy.References.Semver != nil {
+			policyRefs = tagsRefs
+			if policy.References.TagRegexp != nil {
+				policyRefs = selectTagReferencesByRegexp(policyRefs, policy.References.TagRegexp)
+			}
+			if policy.References.Semver != nil {
+				policyRefs = selectTagReferencesBySemver(policyRefs, policy.References.Semver)
+			}
 			policyRefs = applyCleanupKeepPolicy(policyRefs, policy)
 			resultTagsRefs = mergeReferences(resultTagsRefs, policyRefs)
 		}
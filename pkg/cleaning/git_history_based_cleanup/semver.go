@@ -0,0 +1,121 @@
+package git_history_based_cleanup
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/Masterminds/semver"
+
+	"github.com/werf/werf/pkg/config"
+)
+
+// Full semver 2.0.0 with optional "v" prefix, the partial versions like "v1" or "1.2" are not considered as releases
+var semverTagRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+type semverReference struct {
+	*ReferenceToScan
+	version *semver.Version
+}
+
+// selectTagReferencesBySemver selects the tags of the last patch versions of the last minor versions of the last major versions.
+// Tags that are not semantic versions are skipped.
+func selectTagReferencesBySemver(tagsRefs []*ReferenceToScan, policy *config.MetaCleanupKeepPolicySemver) []*ReferenceToScan {
+	var semverRefs []*semverReference
+	for _, tagRef := range tagsRefs {
+		name := tagRef.Name().Short()
+		if !semverTagRegexp.MatchString(name) {
+			continue
+		}
+
+		version, err := semver.NewVersion(name)
+		if err != nil {
+			continue
+		}
+
+		if version.Prerelease() != "" && !policy.IncludePrereleases {
+			continue
+		}
+
+		if policy.Constraints != nil && !policy.Constraints.Check(version) {
+			continue
+		}
+
+		semverRefs = append(semverRefs, &semverReference{ReferenceToScan: tagRef, version: version})
+	}
+
+	sort.SliceStable(semverRefs, func(i, j int) bool {
+		return semverRefs[i].version.GreaterThan(semverRefs[j].version)
+	})
+
+	semverRefs = filterSemverReferencesByLast(semverRefs, policy.LastMajorVersions, func(v *semver.Version) [3]int64 {
+		return [3]int64{v.Major()}
+	})
+	semverRefs = filterSemverReferencesByLast(semverRefs, policy.LastMinorVersions, func(v *semver.Version) [3]int64 {
+		return [3]int64{v.Major(), v.Minor()}
+	})
+	semverRefs = filterSemverReferencesByLastPerMinor(semverRefs, policy.LastPatchVersions)
+
+	var result []*ReferenceToScan
+	for _, ref := range semverRefs {
+		result = append(result, ref.ReferenceToScan)
+	}
+
+	return result
+}
+
+// filterSemverReferencesByLast keeps the references of the last groups, refs must be sorted by version in descending order.
+func filterSemverReferencesByLast(refs []*semverReference, last *int, groupKey func(v *semver.Version) [3]int64) []*semverReference {
+	if last == nil || *last == -1 {
+		return refs
+	}
+
+	var result []*semverReference
+	var groups int
+	var prevKey *[3]int64
+	for _, ref := range refs {
+		key := groupKey(ref.version)
+		if prevKey == nil || *prevKey != key {
+			groups++
+			prevKey = &key
+		}
+
+		if groups > *last {
+			break
+		}
+
+		result = append(result, ref)
+	}
+
+	return result
+}
+
+// filterSemverReferencesByLastPerMinor keeps the references of the last patch versions of each minor version.
+// Several tags with the same version (e.g. "1.2.3" and "v1.2.3") are counted once.
+func filterSemverReferencesByLastPerMinor(refs []*semverReference, last *int) []*semverReference {
+	if last == nil || *last == -1 {
+		return refs
+	}
+
+	var result []*semverReference
+	var minorRefs []*semverReference
+	flushMinor := func() {
+		result = append(result, filterSemverReferencesByLast(minorRefs, last, func(v *semver.Version) [3]int64 {
+			return [3]int64{v.Major(), v.Minor(), v.Patch()}
+		})...)
+		minorRefs = nil
+	}
+
+	for _, ref := range refs {
+		if len(minorRefs) != 0 {
+			prev := minorRefs[0].version
+			if prev.Major() != ref.version.Major() || prev.Minor() != ref.version.Minor() {
+				flushMinor()
+			}
+		}
+
+		minorRefs = append(minorRefs, ref)
+	}
+	flushMinor()
+
+	return result
+}
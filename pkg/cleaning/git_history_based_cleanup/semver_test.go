@@ -0,0 +1,83 @@
+package git_history_based_cleanup
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Masterminds/semver"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/werf/werf/pkg/config"
+)
+
+func TestSelectTagReferencesBySemver(t *testing.T) {
+	tags := []string{
+		"v2.1.0", "v2.0.1", "v2.0.0", "v2.2.0-rc.1",
+		"v1.3.2", "1.3.2", "v1.3.1", "v1.3.0", "v1.2.5", "v1.2.4", "v1.1.0",
+		"v0.9.0", "latest", "v1.2", "release-1.0.0",
+	}
+
+	var refs []*ReferenceToScan
+	for _, tag := range tags {
+		refs = append(refs, &ReferenceToScan{Reference: plumbing.NewHashReference(plumbing.NewTagReferenceName(tag), plumbing.ZeroHash)})
+	}
+
+	intPtr := func(i int) *int { return &i }
+
+	for _, tc := range []struct {
+		name     string
+		policy   *config.MetaCleanupKeepPolicySemver
+		expected []string
+	}{
+		{
+			name:     "all",
+			policy:   &config.MetaCleanupKeepPolicySemver{},
+			expected: []string{"v2.1.0", "v2.0.1", "v2.0.0", "v1.3.2", "1.3.2", "v1.3.1", "v1.3.0", "v1.2.5", "v1.2.4", "v1.1.0", "v0.9.0"},
+		},
+		{
+			name:     "last major",
+			policy:   &config.MetaCleanupKeepPolicySemver{LastMajorVersions: intPtr(1)},
+			expected: []string{"v2.1.0", "v2.0.1", "v2.0.0"},
+		},
+		{
+			name:     "last patch of last minors",
+			policy:   &config.MetaCleanupKeepPolicySemver{LastMinorVersions: intPtr(3), LastPatchVersions: intPtr(1)},
+			expected: []string{"v2.1.0", "v2.0.1", "v1.3.2", "1.3.2"},
+		},
+		{
+			name:     "last patches of last minors of last majors",
+			policy:   &config.MetaCleanupKeepPolicySemver{LastMajorVersions: intPtr(2), LastMinorVersions: intPtr(-1), LastPatchVersions: intPtr(2)},
+			expected: []string{"v2.1.0", "v2.0.1", "v2.0.0", "v1.3.2", "1.3.2", "v1.3.1", "v1.2.5", "v1.2.4", "v1.1.0"},
+		},
+		{
+			name:     "prereleases",
+			policy:   &config.MetaCleanupKeepPolicySemver{LastMinorVersions: intPtr(1), IncludePrereleases: true},
+			expected: []string{"v2.2.0-rc.1"},
+		},
+		{
+			name:     "constraint",
+			policy:   &config.MetaCleanupKeepPolicySemver{Constraints: mustConstraint(t, "~1.2"), LastPatchVersions: intPtr(1)},
+			expected: []string{"v1.2.5"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			for _, ref := range selectTagReferencesBySemver(refs, tc.policy) {
+				got = append(got, ref.Name().Short())
+			}
+
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func mustConstraint(t *testing.T, constraint string) *semver.Constraints {
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
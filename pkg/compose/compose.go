@@ -0,0 +1,264 @@
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/google/shlex"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFileNames are looked up in the project directory when the compose file is not specified.
+var DefaultFileNames = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// Project is the subset of the compose specification (https://github.com/compose-spec/compose-spec) which is
+// needed to run the local development environment, unsupported keys are ignored.
+type Project struct {
+	Services map[string]*Service `yaml:"services"`
+
+	// Dir is the directory of the compose file, relative volume paths are resolved against it.
+	Dir string `yaml:"-"`
+}
+
+type Service struct {
+	Name        string      `yaml:"-"`
+	Image       string      `yaml:"image"`
+	Command     ShellList   `yaml:"command"`
+	Entrypoint  *ShellList  `yaml:"entrypoint"`
+	Environment Environment `yaml:"environment"`
+	Ports       []string    `yaml:"ports"`
+	Volumes     []string    `yaml:"volumes"`
+	WorkingDir  string      `yaml:"working_dir"`
+	User        string      `yaml:"user"`
+	DependsOn   DependsOn   `yaml:"depends_on"`
+	NetworkMode string      `yaml:"network_mode"`
+}
+
+// ShellList is the list of strings or the string which is split like a shell does.
+type ShellList []string
+
+func (l *ShellList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		parts, err := shlex.Split(node.Value)
+		if err != nil {
+			return fmt.Errorf("unable to split %q: %w", node.Value, err)
+		}
+		*l = append(ShellList{}, parts...)
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*l = append(ShellList{}, list...)
+	return nil
+}
+
+// Environment is the mapping or the list of KEY=VALUE strings, the key without value is taken from the werf environment.
+type Environment []string
+
+func (e *Environment) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var mapping map[string]*string
+		if err := node.Decode(&mapping); err != nil {
+			return err
+		}
+
+		var keys []string
+		for key := range mapping {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if mapping[key] == nil {
+				*e = append(*e, key)
+			} else {
+				*e = append(*e, fmt.Sprintf("%s=%s", key, *mapping[key]))
+			}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*e = append(Environment{}, list...)
+	return nil
+}
+
+// DependsOn is the list of services or the mapping of services to conditions (conditions are not supported and ignored).
+type DependsOn []string
+
+func (d *DependsOn) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		var mapping map[string]interface{}
+		if err := node.Decode(&mapping); err != nil {
+			return err
+		}
+
+		for service := range mapping {
+			*d = append(*d, service)
+		}
+		sort.Strings(*d)
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+	*d = append(DependsOn{}, list...)
+	return nil
+}
+
+// FindFile returns the first default compose file which exists in the dir.
+func FindFile(dir string) (string, error) {
+	for _, name := range DefaultFileNames {
+		path := filepath.Join(dir, name)
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("compose file not found in %q: one of %s expected", dir, strings.Join(DefaultFileNames, ", "))
+}
+
+// Load reads the compose file, the variables are substituted with the values returned by lookupEnv.
+func Load(path string, lookupEnv func(name string) (string, bool)) (*Project, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read compose file %q: %w", path, err)
+	}
+
+	project, err := Parse(data, lookupEnv)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse compose file %q: %w", path, err)
+	}
+
+	project.Dir, err = filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+func Parse(data []byte, lookupEnv func(name string) (string, bool)) (*Project, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	if err := interpolateNode(&root, lookupEnv); err != nil {
+		return nil, err
+	}
+
+	project := &Project{}
+	if err := root.Decode(project); err != nil {
+		return nil, err
+	}
+
+	if len(project.Services) == 0 {
+		return nil, fmt.Errorf("no services defined")
+	}
+
+	for name, service := range project.Services {
+		if service == nil {
+			return nil, fmt.Errorf("service %q: image is required", name)
+		}
+		service.Name = name
+
+		if service.Image == "" {
+			return nil, fmt.Errorf("service %q: image is required", name)
+		}
+
+		for _, dependency := range service.DependsOn {
+			if _, ok := project.Services[dependency]; !ok {
+				return nil, fmt.Errorf("service %q depends on undefined service %q", name, dependency)
+			}
+		}
+	}
+
+	if _, err := project.StartOrder(nil); err != nil {
+		return nil, err
+	}
+
+	return project, nil
+}
+
+// StartOrder returns the services in the order of dependencies, the dependencies of the selected services are included.
+// All services are returned if no names specified.
+func (project *Project) StartOrder(names []string) ([]*Service, error) {
+	if len(names) == 0 {
+		for name := range project.Services {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var res []*Service
+	visited := map[string]bool{}
+	inProgress := map[string]bool{}
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		service, ok := project.Services[name]
+		if !ok {
+			return fmt.Errorf("service %q is not defined", name)
+		}
+
+		if visited[name] {
+			return nil
+		}
+		if inProgress[name] {
+			return fmt.Errorf("circular dependency between services: %s", strings.Join(append(path, name), " -> "))
+		}
+		inProgress[name] = true
+
+		for _, dependency := range service.DependsOn {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+
+		inProgress[name] = false
+		visited[name] = true
+		res = append(res, service)
+		return nil
+	}
+
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// VolumeSpec returns the volume in the SOURCE:DESTINATION[:OPTIONS] format with the relative host path resolved
+// against the compose file directory.
+func (project *Project) VolumeSpec(volume string) string {
+	source, rest, found := strings.Cut(volume, ":")
+	if !found {
+		return volume
+	}
+
+	if strings.HasPrefix(source, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			source = filepath.Join(home, source[2:])
+		}
+	} else if strings.HasPrefix(source, ".") {
+		source = filepath.Join(project.Dir, source)
+	}
+
+	return fmt.Sprintf("%s:%s", source, rest)
+}
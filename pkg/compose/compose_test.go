@@ -0,0 +1,115 @@
+package compose
+
+import (
+	"reflect"
+	"testing"
+)
+
+func lookupEnv(env map[string]string) func(name string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestParse(t *testing.T) {
+	data := []byte(`
+services:
+  backend:
+    image: $WERF_BACKEND_DOCKER_IMAGE_NAME
+    command: ./server --port "8080"
+    environment:
+      DB_HOST: db
+      TOKEN:
+    ports:
+      - 8080:8080
+    volumes:
+      - ./data:/data:ro
+    depends_on:
+      db:
+        condition: service_started
+  db:
+    image: postgres:${POSTGRES_VERSION:-15}
+    entrypoint: ["docker-entrypoint.sh"]
+    environment:
+      - POSTGRES_PASSWORD=$${NOT_INTERPOLATED}
+`)
+
+	project, err := Parse(data, lookupEnv(map[string]string{"WERF_BACKEND_DOCKER_IMAGE_NAME": "backend:abc"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	project.Dir = "/project"
+
+	backend := project.Services["backend"]
+	if backend.Image != "backend:abc" {
+		t.Errorf("unexpected image %q", backend.Image)
+	}
+	if !reflect.DeepEqual([]string(backend.Command), []string{"./server", "--port", "8080"}) {
+		t.Errorf("unexpected command %q", backend.Command)
+	}
+	if backend.Entrypoint != nil {
+		t.Errorf("unexpected entrypoint %q", *backend.Entrypoint)
+	}
+	if !reflect.DeepEqual([]string(backend.Environment), []string{"DB_HOST=db", "TOKEN"}) {
+		t.Errorf("unexpected environment %q", backend.Environment)
+	}
+	if !reflect.DeepEqual([]string(backend.DependsOn), []string{"db"}) {
+		t.Errorf("unexpected dependencies %q", backend.DependsOn)
+	}
+	if spec := project.VolumeSpec(backend.Volumes[0]); spec != "/project/data:/data:ro" {
+		t.Errorf("unexpected volume %q", spec)
+	}
+
+	db := project.Services["db"]
+	if db.Image != "postgres:15" {
+		t.Errorf("unexpected image %q", db.Image)
+	}
+	if db.Entrypoint == nil || !reflect.DeepEqual([]string(*db.Entrypoint), []string{"docker-entrypoint.sh"}) {
+		t.Errorf("unexpected entrypoint %v", db.Entrypoint)
+	}
+	if !reflect.DeepEqual([]string(db.Environment), []string{"POSTGRES_PASSWORD=${NOT_INTERPOLATED}"}) {
+		t.Errorf("unexpected environment %q", db.Environment)
+	}
+
+	services, err := project.StartOrder([]string{"backend"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 2 || services[0].Name != "db" || services[1].Name != "backend" {
+		t.Errorf("unexpected start order")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"no image":             "services:\n  app:\n    command: run\n",
+		"undefined dependency": "services:\n  app:\n    image: app\n    depends_on: [db]\n",
+		"circular dependency":  "services:\n  a:\n    image: a\n    depends_on: [b]\n  b:\n    image: b\n    depends_on: [a]\n",
+		"required variable":    "services:\n  app:\n    image: ${IMAGE:?image is required}\n",
+	} {
+		if _, err := Parse([]byte(data), lookupEnv(nil)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestInterpolate(t *testing.T) {
+	env := lookupEnv(map[string]string{"A": "a", "EMPTY": ""})
+
+	for value, expected := range map[string]string{
+		"$A-${A}":           "a-a",
+		"${EMPTY:-default}": "default",
+		"${EMPTY-default}":  "",
+		"${UNSET-default}":  "default",
+		"$$A $":             "$A $",
+		"$UNSET.":           ".",
+	} {
+		res, err := Interpolate(value, env)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", value, err)
+		} else if res != expected {
+			t.Errorf("%q: expected %q, got %q", value, expected, res)
+		}
+	}
+}
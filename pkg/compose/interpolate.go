@@ -0,0 +1,122 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+func interpolateNode(node *yaml.Node, lookupEnv func(name string) (string, bool)) error {
+	if node.Kind == yaml.ScalarNode {
+		value, err := Interpolate(node.Value, lookupEnv)
+		if err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+		node.Value = value
+		return nil
+	}
+
+	for i, child := range node.Content {
+		// Mapping keys are not interpolated.
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+
+		if err := interpolateNode(child, lookupEnv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Interpolate substitutes $VAR, ${VAR}, ${VAR:-default}, ${VAR-default}, ${VAR:?error} and ${VAR?error} the way
+// compose does, $$ is the escaped $.
+func Interpolate(value string, lookupEnv func(name string) (string, bool)) (string, error) {
+	var b strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			b.WriteByte(value[i])
+			continue
+		}
+
+		next := value[i+1]
+		switch {
+		case next == '$':
+			b.WriteByte('$')
+			i++
+		case next == '{':
+			end := strings.IndexByte(value[i:], '}')
+			if end == -1 {
+				return "", fmt.Errorf("invalid interpolation format in %q: closing brace expected", value)
+			}
+
+			substitution, err := substitute(value[i+2:i+end], lookupEnv)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(substitution)
+			i += end
+		case isNameChar(next, true):
+			end := i + 1
+			for end < len(value) && isNameChar(value[end], false) {
+				end++
+			}
+
+			v, _ := lookupEnv(value[i+1 : end])
+			b.WriteString(v)
+			i = end - 1
+		default:
+			b.WriteByte('$')
+		}
+	}
+
+	return b.String(), nil
+}
+
+func substitute(expr string, lookupEnv func(name string) (string, bool)) (string, error) {
+	nameEnd := 0
+	for nameEnd < len(expr) && isNameChar(expr[nameEnd], nameEnd == 0) {
+		nameEnd++
+	}
+	if nameEnd == 0 {
+		return "", fmt.Errorf("invalid interpolation format: invalid variable name in ${%s}", expr)
+	}
+
+	name, modifier := expr[:nameEnd], expr[nameEnd:]
+	value, isSet := lookupEnv(name)
+
+	switch {
+	case modifier == "":
+		return value, nil
+	case strings.HasPrefix(modifier, ":-"):
+		if value == "" {
+			return modifier[2:], nil
+		}
+	case strings.HasPrefix(modifier, "-"):
+		if !isSet {
+			return modifier[1:], nil
+		}
+	case strings.HasPrefix(modifier, ":?"):
+		if value == "" {
+			return "", fmt.Errorf("required variable %s is not set or empty: %s", name, modifier[2:])
+		}
+	case strings.HasPrefix(modifier, "?"):
+		if !isSet {
+			return "", fmt.Errorf("required variable %s is not set: %s", name, modifier[1:])
+		}
+	default:
+		return "", fmt.Errorf("invalid interpolation format: unsupported modifier in ${%s}", expr)
+	}
+
+	return value, nil
+}
+
+func isNameChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
@@ -0,0 +1,77 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var sha256Regexp = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+// Archive is the tar archive, which is fetched from the url or read from the project path, verified by the sha256 checksum and
+// extracted into the image. The checksum is used in the stage digest instead of the archive content.
+type Archive struct {
+	Url    string
+	Path   string
+	Sha256 string
+	To     string
+	Owner  string
+	Group  string
+	Before string
+	After  string
+
+	raw *rawArchive
+}
+
+func (c *Archive) GetRaw() interface{} {
+	return c.raw
+}
+
+// Source returns the url or the local path of the archive.
+func (c *Archive) Source() string {
+	if c.Url != "" {
+		return c.Url
+	}
+	return c.Path
+}
+
+func (c *Archive) validate() error {
+	switch {
+	case c.Url == "" && c.Path == "":
+		return newDetailedConfigError("archive source is not specified with `url: URL` or `path: PATH`!", c.raw, c.raw.rawStapelImage.doc)
+	case c.Url != "" && c.Path != "":
+		return newDetailedConfigError("specify only one archive source using `url: URL` or `path: PATH`!", c.raw, c.raw.rawStapelImage.doc)
+	case c.Path != "" && (path.IsAbs(c.Path) || c.Path == ".." || strings.HasPrefix(c.Path, "../")):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive `path: %s`: the path relative to the project directory required!", c.Path), c.raw, c.raw.rawStapelImage.doc)
+	case c.Url != "" && !isHttpUrl(c.Url):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive `url: %s`: expected http or https url!", c.Url), c.raw, c.raw.rawStapelImage.doc)
+	case c.Sha256 == "":
+		return newDetailedConfigError("archive checksum `sha256: CHECKSUM` required!", c.raw, c.raw.rawStapelImage.doc)
+	case !sha256Regexp.MatchString(c.Sha256):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive `sha256: %s`: expected 64 hex characters!", c.Sha256), c.raw, c.raw.rawStapelImage.doc)
+	case c.To == "":
+		return newDetailedConfigError("archive destination `to: PATH` required!", c.raw, c.raw.rawStapelImage.doc)
+	case !path.IsAbs(c.To):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive `to: %s`: absolute path required!", c.To), c.raw, c.raw.rawStapelImage.doc)
+	case c.Before != "" && c.After != "":
+		return newDetailedConfigError("specify only one archive stage using `before: install|setup` or `after: install|setup`!", c.raw, c.raw.rawStapelImage.doc)
+	case c.Before == "" && c.After == "":
+		return newDetailedConfigError("archive stage is not specified with `before: install|setup` or `after: install|setup`!", c.raw, c.raw.rawStapelImage.doc)
+	case c.Before != "" && checkInvalidRelation(c.Before):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive stage `before: %s`: expected install or setup!", c.Before), c.raw, c.raw.rawStapelImage.doc)
+	case c.After != "" && checkInvalidRelation(c.After):
+		return newDetailedConfigError(fmt.Sprintf("invalid archive stage `after: %s`: expected install or setup!", c.After), c.raw, c.raw.rawStapelImage.doc)
+	default:
+		return nil
+	}
+}
+
+func isHttpUrl(rawUrl string) bool {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
@@ -3,13 +3,16 @@ package config
 import (
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/werf/werf/pkg/giterminism_manager"
 )
 
 type ImageFromDockerfile struct {
-	Name            string
-	Dockerfile      string
+	Name       string
+	Dockerfile string
+	// DockerfileYaml is the inline Dockerfile.yaml, which is specified instead of the dockerfile path.
+	DockerfileYaml  []byte
 	Context         string
 	ContextAddFiles []string
 	Target          string
@@ -18,6 +21,7 @@ type ImageFromDockerfile struct {
 	Network         string
 	SSH             string
 	Dependencies    []*Dependency
+	Secrets         []*Secret
 	Staged          bool
 	Platform        []string
 
@@ -53,6 +57,21 @@ func (c *ImageFromDockerfile) validate(giterminismManager giterminism_manager.In
 	return nil
 }
 
+// IsDockerfileYaml returns true if the stages are described with the inline Dockerfile.yaml or with the file with
+// the .yaml or .yml extension instead of the Dockerfile.
+func (c *ImageFromDockerfile) IsDockerfileYaml() bool {
+	if c.DockerfileYaml != nil {
+		return true
+	}
+
+	switch strings.ToLower(filepath.Ext(c.Dockerfile)) {
+	case ".yaml", ".yml":
+		return true
+	default:
+		return false
+	}
+}
+
 func (c *ImageFromDockerfile) GetName() string {
 	return c.Name
 }
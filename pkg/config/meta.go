@@ -7,4 +7,5 @@ type Meta struct {
 	Cleanup       MetaCleanup
 	GitWorktree   MetaGitWorktree
 	Build         MetaBuild
+	Secrets       MetaSecrets
 }
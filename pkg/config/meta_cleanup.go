@@ -5,6 +5,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/Masterminds/semver"
 )
 
 type MetaCleanup struct {
@@ -13,6 +15,16 @@ type MetaCleanup struct {
 	DisableBuiltWithinLastNHoursPolicy bool
 	KeepImagesBuiltWithinLastNHours    uint64
 	KeepPolicies                       []*MetaCleanupKeepPolicy
+	KeepImagesUsedInHelmReleaseHistory bool
+	KubernetesCustomResources          []*MetaCleanupKubernetesCustomResource
+}
+
+// MetaCleanupKubernetesCustomResource describes the kind of custom resources, which images are used in the Kubernetes.
+// ImageFields are JSONPath templates in the kubectl format, e.g. {.spec.template.spec.containers[*].image}.
+type MetaCleanupKubernetesCustomResource struct {
+	APIVersion  string
+	Kind        string
+	ImageFields []string
 }
 
 type MetaCleanupKeepPolicy struct {
@@ -35,7 +47,9 @@ func (p *MetaCleanupKeepPolicy) String() string {
 type MetaCleanupKeepPolicyReferences struct {
 	TagRegexp    *regexp.Regexp
 	BranchRegexp *regexp.Regexp
-	Limit        *MetaCleanupKeepPolicyLimit
+	// Semver selects tags by semantic version, tags are additionally filtered by the TagRegexp if specified
+	Semver *MetaCleanupKeepPolicySemver
+	Limit  *MetaCleanupKeepPolicyLimit
 }
 
 func (c *MetaCleanupKeepPolicyReferences) String() string {
@@ -43,10 +57,14 @@ func (c *MetaCleanupKeepPolicyReferences) String() string {
 
 	if c.TagRegexp != nil {
 		parts = append(parts, fmt.Sprintf("tag=%s", c.TagRegexp.String()))
-	} else {
+	} else if c.BranchRegexp != nil {
 		parts = append(parts, fmt.Sprintf("branch=%s", c.BranchRegexp.String()))
 	}
 
+	if c.Semver != nil {
+		parts = append(parts, fmt.Sprintf("semver={%s}", c.Semver.String()))
+	}
+
 	if c.Limit != nil {
 		parts = append(parts, fmt.Sprintf("limit={%s}", c.Limit.String()))
 	}
@@ -54,6 +72,43 @@ func (c *MetaCleanupKeepPolicyReferences) String() string {
 	return strings.Join(parts, " ")
 }
 
+// MetaCleanupKeepPolicySemver selects the latest patch versions of the latest minor versions of the latest major versions.
+// Nil or -1 limit means all versions.
+type MetaCleanupKeepPolicySemver struct {
+	LastMajorVersions  *int
+	LastMinorVersions  *int
+	LastPatchVersions  *int
+	Constraint         string
+	Constraints        *semver.Constraints
+	IncludePrereleases bool
+}
+
+func (c *MetaCleanupKeepPolicySemver) String() string {
+	var parts []string
+
+	if c.LastMajorVersions != nil {
+		parts = append(parts, fmt.Sprintf("lastMajorVersions=%d", *c.LastMajorVersions))
+	}
+
+	if c.LastMinorVersions != nil {
+		parts = append(parts, fmt.Sprintf("lastMinorVersions=%d", *c.LastMinorVersions))
+	}
+
+	if c.LastPatchVersions != nil {
+		parts = append(parts, fmt.Sprintf("lastPatchVersions=%d", *c.LastPatchVersions))
+	}
+
+	if c.Constraint != "" {
+		parts = append(parts, fmt.Sprintf("constraint=%q", c.Constraint))
+	}
+
+	if c.IncludePrereleases {
+		parts = append(parts, "includePrereleases=true")
+	}
+
+	return strings.Join(parts, " ")
+}
+
 type MetaCleanupKeepPolicyImagesPerReference struct {
 	MetaCleanupKeepPolicyLimit
 }
@@ -0,0 +1,27 @@
+package config
+
+const (
+	MetaSecretsBackendAes          = "aes"
+	MetaSecretsBackendX25519       = "x25519"
+	MetaSecretsBackendVaultTransit = "vaultTransit"
+)
+
+type MetaSecrets struct {
+	Backend          string
+	X25519Recipients []string
+	VaultTransit     *MetaSecretsVaultTransit
+}
+
+type MetaSecretsVaultTransit struct {
+	Address   string
+	MountPath string
+	KeyName   string
+}
+
+func (obj MetaSecrets) GetBackend() string {
+	if obj.Backend == "" {
+		return MetaSecretsBackendAes
+	}
+
+	return obj.Backend
+}
@@ -0,0 +1,66 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+type rawArchive struct {
+	Url    string `yaml:"url,omitempty"`
+	Path   string `yaml:"path,omitempty"`
+	Sha256 string `yaml:"sha256,omitempty"`
+	To     string `yaml:"to,omitempty"`
+	Owner  string `yaml:"owner,omitempty"`
+	Group  string `yaml:"group,omitempty"`
+	Before string `yaml:"before,omitempty"`
+	After  string `yaml:"after,omitempty"`
+
+	rawStapelImage *rawStapelImage `yaml:"-"` // parent
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+func (c *rawArchive) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawStapelImage); ok {
+		c.rawStapelImage = parent
+	}
+
+	type plain rawArchive
+	if err := unmarshal((*plain)(c)); err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, c, c.rawStapelImage.doc); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *rawArchive) toDirective() (*Archive, error) {
+	archive := &Archive{
+		Url:    c.Url,
+		Path:   c.Path,
+		Sha256: strings.ToLower(c.Sha256),
+		To:     c.To,
+		Owner:  c.Owner,
+		Group:  c.Group,
+		Before: c.Before,
+		After:  c.After,
+		raw:    c,
+	}
+
+	if archive.Path != "" {
+		archive.Path = filepath.ToSlash(filepath.Clean(archive.Path))
+	}
+
+	if archive.To != "" {
+		archive.To = filepath.ToSlash(filepath.Clean(archive.To))
+	}
+
+	if err := archive.validate(); err != nil {
+		return nil, err
+	}
+
+	return archive, nil
+}
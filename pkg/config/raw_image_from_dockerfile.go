@@ -3,13 +3,15 @@ package config
 import (
 	"fmt"
 
+	"gopkg.in/yaml.v2"
+
 	"github.com/werf/werf/pkg/giterminism_manager"
 	"github.com/werf/werf/pkg/util"
 )
 
 type rawImageFromDockerfile struct {
 	Images          []string               `yaml:"-"`
-	Dockerfile      string                 `yaml:"dockerfile,omitempty"`
+	Dockerfile      interface{}            `yaml:"dockerfile,omitempty"`
 	Context         string                 `yaml:"context,omitempty"`
 	ContextAddFile  interface{}            `yaml:"contextAddFile,omitempty"`
 	ContextAddFiles interface{}            `yaml:"contextAddFiles,omitempty"`
@@ -19,6 +21,7 @@ type rawImageFromDockerfile struct {
 	Network         string                 `yaml:"network,omitempty"`
 	SSH             string                 `yaml:"ssh,omitempty"`
 	RawDependencies []*rawDependency       `yaml:"dependencies,omitempty"`
+	RawSecrets      []*rawSecret           `yaml:"secrets,omitempty"`
 	Staged          bool                   `yaml:"staged,omitempty"`
 	Platform        []string               `yaml:"platform,omitempty"`
 
@@ -86,7 +89,17 @@ func (c *rawImageFromDockerfile) toImageFromDockerfileDirectives(giterminismMana
 func (c *rawImageFromDockerfile) toImageFromDockerfileDirective(giterminismManager giterminism_manager.Interface, imageName string) (image *ImageFromDockerfile, err error) {
 	image = &ImageFromDockerfile{}
 	image.Name = imageName
-	image.Dockerfile = c.Dockerfile
+	switch dockerfile := c.Dockerfile.(type) {
+	case nil:
+	case string:
+		image.Dockerfile = dockerfile
+	case map[interface{}]interface{}:
+		if image.DockerfileYaml, err = yaml.Marshal(dockerfile); err != nil {
+			return nil, newDetailedConfigError(fmt.Sprintf("unable to marshal dockerfile yaml: %s", err), nil, c.doc)
+		}
+	default:
+		return nil, newDetailedConfigError("`dockerfile: PATH|DOCKERFILE_YAML` should be a path or a map with the Dockerfile.yaml stages!", nil, c.doc)
+	}
 	image.Context = c.Context
 
 	contextAddFile, err := InterfaceToStringArray(c.ContextAddFile, nil, c.doc)
@@ -128,7 +141,12 @@ func (c *rawImageFromDockerfile) toImageFromDockerfileDirective(giterminismManag
 		image.Dependencies = append(image.Dependencies, dependencyDirective)
 	}
 
-	image.Staged = c.Staged || util.GetBoolEnvironmentDefaultFalse("WERF_FORCE_STAGED_DOCKERFILE")
+	if image.Secrets, err = rawSecretsToDirectives(c.RawSecrets, c.doc); err != nil {
+		return nil, err
+	}
+
+	// Dockerfile.yaml is supported only by the staged builder.
+	image.Staged = c.Staged || image.IsDockerfileYaml() || util.GetBoolEnvironmentDefaultFalse("WERF_FORCE_STAGED_DOCKERFILE")
 	image.Platform = append([]string{}, c.Platform...)
 	image.raw = c
 
@@ -298,4 +298,87 @@ var _ = Describe("rawImageFromDockerfile", func() {
 			},
 		),
 	)
+
+	DescribeTable("unmarshal and convert to directive produce expected Secrets",
+		func(secrets []map[string]string, expected []*Secret, expectedErr bool) {
+			rawYaml, err := yaml.Marshal(map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": "Dockerfile",
+				"secrets":    secrets,
+			})
+			Expect(err).To(Succeed())
+
+			doc := &doc{Content: rawYaml}
+			rawDockerfileImage := &rawImageFromDockerfile{doc: doc}
+
+			Expect(yaml.UnmarshalStrict(doc.Content, rawDockerfileImage)).To(Succeed())
+
+			dockerfileImage, err := rawDockerfileImage.toImageFromDockerfileDirective(giterminismManager, "image1")
+			if expectedErr {
+				var errConf *configError
+				Expect(errors.As(err, &errConf)).To(BeTrue())
+				return
+			}
+			Expect(err).To(Succeed())
+
+			Expect(dockerfileImage.Secrets).To(HaveLen(len(expected)))
+			for i, expectedSecret := range expected {
+				Expect(dockerfileImage.Secrets[i].ID).To(Equal(expectedSecret.ID))
+				Expect(dockerfileImage.Secrets[i].Env).To(Equal(expectedSecret.Env))
+				Expect(dockerfileImage.Secrets[i].Src).To(Equal(expectedSecret.Src))
+				Expect(dockerfileImage.Secrets[i].Value).To(Equal(expectedSecret.Value))
+			}
+		},
+		Entry(
+			"with default ids",
+			[]map[string]string{{"env": "NPM_TOKEN"}, {"src": "~/.aws/credentials"}},
+			[]*Secret{{ID: "NPM_TOKEN", Env: "NPM_TOKEN"}, {ID: "credentials", Src: "~/.aws/credentials"}},
+			false,
+		),
+		Entry(
+			"with custom id and encrypted value",
+			[]map[string]string{{"id": "token", "value": "1000abcd"}},
+			[]*Secret{{ID: "token", Value: "1000abcd"}},
+			false,
+		),
+		Entry("with value without id", []map[string]string{{"value": "1000abcd"}}, nil, true),
+		Entry("with several sources", []map[string]string{{"id": "token", "env": "TOKEN", "src": "token"}}, nil, true),
+		Entry("with duplicate ids", []map[string]string{{"env": "TOKEN"}, {"id": "TOKEN", "src": "token"}}, nil, true),
+		Entry("with invalid id", []map[string]string{{"id": "a/b", "env": "TOKEN"}}, nil, true),
+	)
+
+	DescribeTable("unmarshal and convert to directive produce expected Dockerfile.yaml",
+		func(dockerfile interface{}, expectedDockerfile string, expectedDockerfileYaml bool) {
+			rawYaml, err := yaml.Marshal(map[string]interface{}{
+				"image":      "image1",
+				"dockerfile": dockerfile,
+			})
+			Expect(err).To(Succeed())
+
+			doc := &doc{Content: rawYaml}
+			rawDockerfileImage := &rawImageFromDockerfile{doc: doc}
+
+			Expect(yaml.UnmarshalStrict(doc.Content, rawDockerfileImage)).To(Succeed())
+
+			dockerfileImage, err := rawDockerfileImage.toImageFromDockerfileDirective(giterminismManager, "image1")
+			Expect(err).To(Succeed())
+
+			Expect(dockerfileImage.Dockerfile).To(Equal(expectedDockerfile))
+			Expect(dockerfileImage.IsDockerfileYaml()).To(Equal(expectedDockerfileYaml))
+			Expect(dockerfileImage.Staged).To(Equal(expectedDockerfileYaml))
+		},
+		Entry("with Dockerfile path", "Dockerfile", "Dockerfile", false),
+		Entry("with Dockerfile.yaml path", "Dockerfile.yaml", "Dockerfile.yaml", true),
+		Entry(
+			"with inline Dockerfile.yaml",
+			map[string]interface{}{
+				"stages": []map[string]interface{}{{
+					"from":         "alpine",
+					"instructions": []map[string]interface{}{{"RUN": "echo hello"}},
+				}},
+			},
+			"",
+			true,
+		),
+	)
 })
@@ -14,6 +14,7 @@ type rawMeta struct {
 	Deploy        *rawMetaDeploy      `yaml:"deploy,omitempty"`
 	Cleanup       *rawMetaCleanup     `yaml:"cleanup,omitempty"`
 	GitWorktree   *rawMetaGitWorktree `yaml:"gitWorktree,omitempty"`
+	Secrets       *rawMetaSecrets     `yaml:"secrets,omitempty"`
 
 	doc *doc `yaml:"-"` // parent
 
@@ -80,5 +81,9 @@ func (c *rawMeta) toMeta() *Meta {
 		meta.GitWorktree = c.GitWorktree.toMetaGitWorktree()
 	}
 
+	if c.Secrets != nil {
+		meta.Secrets = c.Secrets.toMetaSecrets()
+	}
+
 	return meta
 }
@@ -5,19 +5,33 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/Masterminds/semver"
+	"k8s.io/client-go/util/jsonpath"
 )
 
 type rawMetaCleanup struct {
-	DisableKubernetesBasedPolicy       bool                        `yaml:"disableKubernetesBasedPolicy,omitempty"`
-	DisableGitHistoryBasedPolicy       bool                        `yaml:"disableGitHistoryBasedPolicy,omitempty"`
-	DisableBuiltWithinLastNHoursPolicy bool                        `yaml:"disableBuiltWithinLastNHoursPolicy,omitempty"`
-	KeepPolicies                       []*rawMetaCleanupKeepPolicy `yaml:"keepPolicies,omitempty"`
-	KeepImagesBuiltWithinLastNHours    *uint64                     `yaml:"keepImagesBuiltWithinLastNHours,omitempty"`
+	DisableKubernetesBasedPolicy       bool                                      `yaml:"disableKubernetesBasedPolicy,omitempty"`
+	DisableGitHistoryBasedPolicy       bool                                      `yaml:"disableGitHistoryBasedPolicy,omitempty"`
+	DisableBuiltWithinLastNHoursPolicy bool                                      `yaml:"disableBuiltWithinLastNHoursPolicy,omitempty"`
+	KeepPolicies                       []*rawMetaCleanupKeepPolicy               `yaml:"keepPolicies,omitempty"`
+	KeepImagesBuiltWithinLastNHours    *uint64                                   `yaml:"keepImagesBuiltWithinLastNHours,omitempty"`
+	KeepImagesUsedInHelmReleaseHistory bool                                      `yaml:"keepImagesUsedInHelmReleaseHistory,omitempty"`
+	KubernetesCustomResources          []*rawMetaCleanupKubernetesCustomResource `yaml:"kubernetesCustomResources,omitempty"`
 
 	rawMeta               *rawMeta
 	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
 }
 
+type rawMetaCleanupKubernetesCustomResource struct {
+	APIVersion  string   `yaml:"apiVersion,omitempty"`
+	Kind        string   `yaml:"kind,omitempty"`
+	ImageFields []string `yaml:"imageFields,omitempty"`
+
+	rawMetaCleanup        *rawMetaCleanup
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
 type rawMetaCleanupKeepPolicy struct {
 	References         *rawMetaCleanupKeepPolicyReferences         `yaml:"references,omitempty"`
 	ImagesPerReference *rawMetaCleanupKeepPolicyImagesPerReference `yaml:"imagesPerReference,omitempty"`
@@ -27,9 +41,10 @@ type rawMetaCleanupKeepPolicy struct {
 }
 
 type rawMetaCleanupKeepPolicyReferences struct {
-	Tag    string                                   `yaml:"tag,omitempty"`
-	Branch string                                   `yaml:"branch,omitempty"`
-	Limit  *rawMetaCleanupKeepPolicyReferencesLimit `yaml:"limit,omitempty"`
+	Tag    string                                    `yaml:"tag,omitempty"`
+	Branch string                                    `yaml:"branch,omitempty"`
+	Semver *rawMetaCleanupKeepPolicyReferencesSemver `yaml:"semver,omitempty"`
+	Limit  *rawMetaCleanupKeepPolicyReferencesLimit  `yaml:"limit,omitempty"`
 
 	TagRegexp    *regexp.Regexp `yaml:"-"`
 	BranchRegexp *regexp.Regexp `yaml:"-"`
@@ -38,6 +53,19 @@ type rawMetaCleanupKeepPolicyReferences struct {
 	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
 }
 
+type rawMetaCleanupKeepPolicyReferencesSemver struct {
+	LastMajorVersions  *int   `yaml:"lastMajorVersions,omitempty"`
+	LastMinorVersions  *int   `yaml:"lastMinorVersions,omitempty"`
+	LastPatchVersions  *int   `yaml:"lastPatchVersions,omitempty"`
+	Constraint         string `yaml:"constraint,omitempty"`
+	IncludePrereleases bool   `yaml:"includePrereleases,omitempty"`
+
+	Constraints *semver.Constraints `yaml:"-"`
+
+	rawMetaCleanup        *rawMetaCleanup
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
 type rawMetaCleanupKeepPolicyImagesPerReference rawMetaCleanupKeepPolicyReferencesLimit
 
 type rawMetaCleanupKeepPolicyReferencesLimit struct {
@@ -74,6 +102,44 @@ func (c *rawMetaCleanup) UnmarshalYAML(unmarshal func(interface{}) error) error
 	return nil
 }
 
+func (c *rawMetaCleanupKubernetesCustomResource) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawMetaCleanup); ok {
+		c.rawMetaCleanup = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawMetaCleanupKubernetesCustomResource
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, c, c.rawMetaCleanup.rawMeta.doc); err != nil {
+		return err
+	}
+
+	if c.APIVersion == "" || c.Kind == "" {
+		return newDetailedConfigError("apiVersion `apiVersion: string` and kind `kind: string` required for cleanup kubernetes custom resource!", c, c.rawMetaCleanup.rawMeta.doc)
+	}
+
+	if len(c.ImageFields) == 0 {
+		return newDetailedConfigError("at least one JSONPath `imageFields: [string, ...]` required for cleanup kubernetes custom resource!", c, c.rawMetaCleanup.rawMeta.doc)
+	}
+
+	for _, imageField := range c.ImageFields {
+		if !strings.HasPrefix(imageField, "{") || !strings.HasSuffix(imageField, "}") {
+			return newDetailedConfigError(fmt.Sprintf("invalid JSONPath %q in `imageFields: [string, ...]`, expected template like {.spec.template.spec.containers[*].image}!", imageField), c, c.rawMetaCleanup.rawMeta.doc)
+		}
+
+		if err := jsonpath.New("").Parse(imageField); err != nil {
+			return newDetailedConfigError(fmt.Sprintf("invalid JSONPath %q in `imageFields: [string, ...]`: %s!", imageField, err), c, c.rawMetaCleanup.rawMeta.doc)
+		}
+	}
+
+	return nil
+}
+
 func (c *rawMetaCleanupKeepPolicy) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if parent, ok := parentStack.Peek().(*rawMetaCleanup); ok {
 		c.rawMetaCleanup = parent
@@ -115,10 +181,12 @@ func (c *rawMetaCleanupKeepPolicyReferences) UnmarshalYAML(unmarshal func(interf
 		return err
 	}
 
-	if c.Tag == "" && c.Branch == "" {
-		return newDetailedConfigError("tag `tag: string|REGEX` or branch `branch: string|REGEX` required for cleanup keep policy!", c, c.rawMetaCleanup.rawMeta.doc)
+	if c.Tag == "" && c.Branch == "" && c.Semver == nil {
+		return newDetailedConfigError("tag `tag: string|REGEX`, semver `semver: {...}` or branch `branch: string|REGEX` required for cleanup keep policy!", c, c.rawMetaCleanup.rawMeta.doc)
 	} else if c.Tag != "" && c.Branch != "" {
 		return newDetailedConfigError("specify only tag `tag: string|REGEX` or branch `branch: string|REGEX` for cleanup keep policy!", c, c.rawMetaCleanup.rawMeta.doc)
+	} else if c.Semver != nil && c.Branch != "" {
+		return newDetailedConfigError("semver `semver: {...}` can only be used to select tags, not with branch `branch: string|REGEX` for cleanup keep policy!", c, c.rawMetaCleanup.rawMeta.doc)
 	}
 
 	if c.Branch != "" {
@@ -128,7 +196,7 @@ func (c *rawMetaCleanupKeepPolicyReferences) UnmarshalYAML(unmarshal func(interf
 		}
 
 		c.BranchRegexp = regex
-	} else {
+	} else if c.Tag != "" {
 		regex, err := c.processRegexpString("tag", c.Tag)
 		if err != nil {
 			return err
@@ -140,6 +208,48 @@ func (c *rawMetaCleanupKeepPolicyReferences) UnmarshalYAML(unmarshal func(interf
 	return nil
 }
 
+func (c *rawMetaCleanupKeepPolicyReferencesSemver) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawMetaCleanupKeepPolicyReferences); ok {
+		c.rawMetaCleanup = parent.rawMetaCleanup
+	}
+
+	parentStack.Push(c)
+	type plain rawMetaCleanupKeepPolicyReferencesSemver
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, c, c.rawMetaCleanup.rawMeta.doc); err != nil {
+		return err
+	}
+
+	for _, directive := range []struct {
+		name  string
+		value *int
+	}{
+		{"lastMajorVersions", c.LastMajorVersions},
+		{"lastMinorVersions", c.LastMinorVersions},
+		{"lastPatchVersions", c.LastPatchVersions},
+	} {
+		if directive.value != nil && *directive.value < 1 && *directive.value != -1 {
+			return newDetailedConfigError(fmt.Sprintf("invalid value %d for `%s: int`, expected positive number or -1!", *directive.value, directive.name), c, c.rawMetaCleanup.rawMeta.doc)
+		}
+	}
+
+	if c.Constraint != "" {
+		constraints, err := semver.NewConstraint(c.Constraint)
+		if err != nil {
+			return newDetailedConfigError(fmt.Sprintf("invalid value %q for `constraint: string`: %s!", c.Constraint, err), c, c.rawMetaCleanup.rawMeta.doc)
+		}
+
+		c.Constraints = constraints
+	}
+
+	return nil
+}
+
 func (c *rawMetaCleanupKeepPolicyReferencesLimit) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	if parent, ok := parentStack.Peek().(*rawMetaCleanupKeepPolicyReferences); ok {
 		c.rawMetaCleanup = parent.rawMetaCleanup
@@ -199,6 +309,15 @@ func (c *rawMetaCleanup) toMetaCleanup() MetaCleanup {
 	}
 
 	metaCleanup.KeepImagesBuiltWithinLastNHours = *c.KeepImagesBuiltWithinLastNHours
+	metaCleanup.KeepImagesUsedInHelmReleaseHistory = c.KeepImagesUsedInHelmReleaseHistory
+
+	for _, customResource := range c.KubernetesCustomResources {
+		metaCleanup.KubernetesCustomResources = append(metaCleanup.KubernetesCustomResources, &MetaCleanupKubernetesCustomResource{
+			APIVersion:  customResource.APIVersion,
+			Kind:        customResource.Kind,
+			ImageFields: customResource.ImageFields,
+		})
+	}
 
 	return metaCleanup
 }
@@ -222,6 +341,10 @@ func (c *rawMetaCleanupKeepPolicyReferences) toMetaCleanupKeepPolicyReferences()
 	references.BranchRegexp = c.BranchRegexp
 	references.TagRegexp = c.TagRegexp
 
+	if c.Semver != nil {
+		references.Semver = c.Semver.toMetaCleanupKeepPolicySemver()
+	}
+
 	if c.Limit != nil {
 		references.Limit = c.Limit.toMetaCleanupKeepPolicyLimit()
 	}
@@ -229,6 +352,17 @@ func (c *rawMetaCleanupKeepPolicyReferences) toMetaCleanupKeepPolicyReferences()
 	return references
 }
 
+func (c *rawMetaCleanupKeepPolicyReferencesSemver) toMetaCleanupKeepPolicySemver() *MetaCleanupKeepPolicySemver {
+	return &MetaCleanupKeepPolicySemver{
+		LastMajorVersions:  c.LastMajorVersions,
+		LastMinorVersions:  c.LastMinorVersions,
+		LastPatchVersions:  c.LastPatchVersions,
+		Constraint:         c.Constraint,
+		Constraints:        c.Constraints,
+		IncludePrereleases: c.IncludePrereleases,
+	}
+}
+
 func (c *rawMetaCleanupKeepPolicyReferencesLimit) toMetaCleanupKeepPolicyLimit() *MetaCleanupKeepPolicyLimit {
 	limit := &MetaCleanupKeepPolicyLimit{}
 	limit.Last = c.Last
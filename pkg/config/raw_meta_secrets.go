@@ -0,0 +1,158 @@
+package config
+
+import "fmt"
+
+type rawMetaSecrets struct {
+	Backend      *string                     `yaml:"backend,omitempty"`
+	X25519       *rawMetaSecretsX25519       `yaml:"x25519,omitempty"`
+	VaultTransit *rawMetaSecretsVaultTransit `yaml:"vaultTransit,omitempty"`
+
+	rawMeta *rawMeta
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+type rawMetaSecretsX25519 struct {
+	Recipients []string `yaml:"recipients,omitempty"`
+
+	rawMetaSecrets *rawMetaSecrets
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+type rawMetaSecretsVaultTransit struct {
+	Address   *string `yaml:"address,omitempty"`
+	MountPath *string `yaml:"mountPath,omitempty"`
+	KeyName   *string `yaml:"keyName,omitempty"`
+
+	rawMetaSecrets *rawMetaSecrets
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+func (c *rawMetaSecrets) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawMeta); ok {
+		c.rawMeta = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawMetaSecrets
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, nil, c.rawMeta.doc); err != nil {
+		return err
+	}
+
+	backend := c.backend()
+	switch backend {
+	case MetaSecretsBackendAes:
+	case MetaSecretsBackendX25519:
+	case MetaSecretsBackendVaultTransit:
+		if c.VaultTransit == nil {
+			return newDetailedConfigError("vaultTransit section required for the vaultTransit secrets backend!", nil, c.rawMeta.doc)
+		}
+	default:
+		return newDetailedConfigError(fmt.Sprintf("unsupported secrets backend %q: expected %q, %q or %q!", backend, MetaSecretsBackendAes, MetaSecretsBackendX25519, MetaSecretsBackendVaultTransit), nil, c.rawMeta.doc)
+	}
+
+	if c.X25519 != nil && backend != MetaSecretsBackendX25519 {
+		return newDetailedConfigError(fmt.Sprintf("x25519 section cannot be used with the %q secrets backend!", backend), nil, c.rawMeta.doc)
+	}
+
+	if c.VaultTransit != nil && backend != MetaSecretsBackendVaultTransit {
+		return newDetailedConfigError(fmt.Sprintf("vaultTransit section cannot be used with the %q secrets backend!", backend), nil, c.rawMeta.doc)
+	}
+
+	return nil
+}
+
+func (c *rawMetaSecrets) backend() string {
+	if c.Backend == nil {
+		return MetaSecretsBackendAes
+	}
+
+	return *c.Backend
+}
+
+func (c *rawMetaSecretsX25519) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawMetaSecrets); ok {
+		c.rawMetaSecrets = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawMetaSecretsX25519
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, nil, c.rawMetaSecrets.rawMeta.doc); err != nil {
+		return err
+	}
+
+	for _, recipient := range c.Recipients {
+		if recipient == "" {
+			return newDetailedConfigError("x25519 recipient cannot be empty!", nil, c.rawMetaSecrets.rawMeta.doc)
+		}
+	}
+
+	return nil
+}
+
+func (c *rawMetaSecretsVaultTransit) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	if parent, ok := parentStack.Peek().(*rawMetaSecrets); ok {
+		c.rawMetaSecrets = parent
+	}
+
+	parentStack.Push(c)
+	type plain rawMetaSecretsVaultTransit
+	err := unmarshal((*plain)(c))
+	parentStack.Pop()
+	if err != nil {
+		return err
+	}
+
+	if err := checkOverflow(c.UnsupportedAttributes, nil, c.rawMetaSecrets.rawMeta.doc); err != nil {
+		return err
+	}
+
+	if c.Address == nil || *c.Address == "" {
+		return newDetailedConfigError("vaultTransit.address field cannot be empty!", nil, c.rawMetaSecrets.rawMeta.doc)
+	}
+
+	if c.KeyName == nil || *c.KeyName == "" {
+		return newDetailedConfigError("vaultTransit.keyName field cannot be empty!", nil, c.rawMetaSecrets.rawMeta.doc)
+	}
+
+	if c.MountPath != nil && *c.MountPath == "" {
+		return newDetailedConfigError("vaultTransit.mountPath field cannot be empty!", nil, c.rawMetaSecrets.rawMeta.doc)
+	}
+
+	return nil
+}
+
+func (c *rawMetaSecrets) toMetaSecrets() MetaSecrets {
+	metaSecrets := MetaSecrets{Backend: c.backend()}
+
+	if c.X25519 != nil {
+		metaSecrets.X25519Recipients = c.X25519.Recipients
+	}
+
+	if c.VaultTransit != nil {
+		metaSecrets.VaultTransit = &MetaSecretsVaultTransit{
+			Address: *c.VaultTransit.Address,
+			KeyName: *c.VaultTransit.KeyName,
+		}
+
+		if c.VaultTransit.MountPath != nil {
+			metaSecrets.VaultTransit.MountPath = *c.VaultTransit.MountPath
+		}
+	}
+
+	return metaSecrets
+}
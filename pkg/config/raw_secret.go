@@ -0,0 +1,82 @@
+package config
+
+import "fmt"
+
+type rawSecret struct {
+	ID    string `yaml:"id,omitempty"`
+	Env   string `yaml:"env,omitempty"`
+	Src   string `yaml:"src,omitempty"`
+	Value string `yaml:"value,omitempty"`
+
+	rawStapelImage         *rawStapelImage         `yaml:"-"` // possible parent
+	rawImageFromDockerfile *rawImageFromDockerfile `yaml:"-"` // possible parent
+
+	UnsupportedAttributes map[string]interface{} `yaml:",inline"`
+}
+
+func (s *rawSecret) doc() *doc {
+	switch {
+	case s.rawStapelImage != nil:
+		return s.rawStapelImage.doc
+	case s.rawImageFromDockerfile != nil:
+		return s.rawImageFromDockerfile.doc
+	}
+
+	return nil
+}
+
+func (s *rawSecret) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	switch parent := parentStack.Peek().(type) {
+	case *rawStapelImage:
+		s.rawStapelImage = parent
+	case *rawImageFromDockerfile:
+		s.rawImageFromDockerfile = parent
+	}
+
+	type plain rawSecret
+	if err := unmarshal((*plain)(s)); err != nil {
+		return err
+	}
+
+	if err := checkOverflow(s.UnsupportedAttributes, s, s.doc()); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *rawSecret) toDirective() (*Secret, error) {
+	secret := &Secret{
+		ID:    s.ID,
+		Env:   s.Env,
+		Src:   s.Src,
+		Value: s.Value,
+		raw:   s,
+	}
+
+	if err := secret.validate(); err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
+
+func rawSecretsToDirectives(rawSecrets []*rawSecret, d *doc) ([]*Secret, error) {
+	var secrets []*Secret
+	ids := map[string]bool{}
+	for _, rawSecret := range rawSecrets {
+		secret, err := rawSecret.toDirective()
+		if err != nil {
+			return nil, err
+		}
+
+		if ids[secret.ID] {
+			return nil, newDetailedConfigError(fmt.Sprintf("duplicate secret id %q!", secret.ID), rawSecret, d)
+		}
+		ids[secret.ID] = true
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
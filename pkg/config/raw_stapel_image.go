@@ -15,12 +15,14 @@ type rawStapelImage struct {
 	FromImage        string           `yaml:"fromImage,omitempty"`
 	FromArtifact     string           `yaml:"fromArtifact,omitempty"`
 	RawGit           []*rawGit        `yaml:"git,omitempty"`
+	RawArchive       []*rawArchive    `yaml:"archive,omitempty"`
 	RawShell         *rawShell        `yaml:"shell,omitempty"`
 	RawAnsible       *rawAnsible      `yaml:"ansible,omitempty"`
 	RawMount         []*rawMount      `yaml:"mount,omitempty"`
 	RawDocker        *rawDocker       `yaml:"docker,omitempty"`
 	RawImport        []*rawImport     `yaml:"import,omitempty"`
 	RawDependencies  []*rawDependency `yaml:"dependencies,omitempty"`
+	RawSecrets       []*rawSecret     `yaml:"secrets,omitempty"`
 	Platform         []string         `yaml:"platform,omitempty"`
 
 	doc *doc `yaml:"-"` // parent
@@ -234,6 +236,14 @@ func (c *rawStapelImage) toStapelImageBaseDirective(giterminismManager gitermini
 		}
 	}
 
+	for _, rawArchive := range c.RawArchive {
+		if archive, err := rawArchive.toDirective(); err != nil {
+			return nil, err
+		} else {
+			imageBase.Archive = append(imageBase.Archive, archive)
+		}
+	}
+
 	if c.RawShell != nil {
 		if shell, err := c.RawShell.toDirective(); err != nil {
 			return nil, err
@@ -298,6 +308,10 @@ func (c *rawStapelImage) toBaseStapelImageBaseDirective(giterminismManager giter
 		}
 	}
 
+	if imageBase.Secrets, err = rawSecretsToDirectives(c.RawSecrets, c.doc); err != nil {
+		return nil, err
+	}
+
 	imageBase.Git = &GitManager{}
 
 	imageBase.raw = c
@@ -193,6 +193,68 @@ var _ = Describe("rawStapelImage", func() {
 		),
 	)
 
+	DescribeTable("unmarshal and convert to directive succeed and produce expected Archive",
+		func(yamlMap map[string]interface{}, expected []*Archive) {
+			rawYaml, err := yaml.Marshal(yamlMap)
+			Expect(err).To(Succeed())
+
+			doc := &doc{Content: rawYaml}
+			rawStapelImage := &rawStapelImage{doc: doc}
+			Expect(yaml.UnmarshalStrict(doc.Content, rawStapelImage)).To(Succeed())
+
+			stapelImage, err := rawStapelImage.toStapelImageDirective(giterminismManager, "image1")
+			Expect(err).To(Succeed())
+
+			Expect(stapelImage.Archive).To(HaveLen(len(expected)))
+			for i, expectedArchive := range expected {
+				expectedArchive.raw = stapelImage.Archive[i].raw
+				Expect(stapelImage.Archive[i]).To(Equal(expectedArchive))
+			}
+		},
+		Entry(
+			"with url archive",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/vendor.tar.gz",
+					"sha256": "9F86D081884C7D659A2FEAA0C55AD015A3BF4F1B2B0B822CD15D6C15B0F00A08",
+					"to":     "/opt/vendor/",
+					"owner":  "app",
+					"group":  "app",
+					"before": "install",
+				}},
+			},
+			[]*Archive{{
+				Url:    "https://example.com/vendor.tar.gz",
+				Sha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+				To:     "/opt/vendor",
+				Owner:  "app",
+				Group:  "app",
+				Before: "install",
+			}},
+		),
+		Entry(
+			"with local archive relative to the project dir",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"path":   "vendor/vendor.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt/vendor",
+					"after":  "setup",
+				}},
+			},
+			[]*Archive{{
+				Path:   "vendor/vendor.tar",
+				Sha256: "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+				To:     "/opt/vendor",
+				After:  "setup",
+			}},
+		),
+	)
+
 	DescribeTable("unmarshal and convert to directive fail with configError",
 		func(yamlMap map[string]interface{}) {
 			if len(yamlMap) == 0 {
@@ -282,5 +344,133 @@ var _ = Describe("rawStapelImage", func() {
 				}},
 			},
 		),
+		Entry(
+			"with archive without source",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with both url and path",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"path":   "a.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with absolute path",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"path":   "/etc/vendor.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with path outside of the project dir",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"path":   "vendor/../../vendor.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with non-http url",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "ftp://example.com/a.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive without sha256",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with invalid sha256",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"sha256": "abc",
+					"to":     "/opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive with relative destination",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "opt",
+					"before": "install",
+				}},
+			},
+		),
+		Entry(
+			"with archive without stage",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+				}},
+			},
+		),
+		Entry(
+			"with archive with invalid stage",
+			map[string]interface{}{
+				"image": "image1",
+				"from":  "alpine",
+				"archive": []map[string]interface{}{{
+					"url":    "https://example.com/a.tar",
+					"sha256": "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08",
+					"to":     "/opt",
+					"before": "beforeInstall",
+				}},
+			},
+		),
 	)
 })
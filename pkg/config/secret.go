@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Secret is the build secret, it is available to the build instructions in the /run/secrets/ID file, does not
+// influence stage digests and is not stored in the image layers.
+type Secret struct {
+	ID string
+	// Env is the name of the environment variable with the secret value.
+	Env string
+	// Src is the path to the file with the secret value.
+	Src string
+	// Value is the secret value encrypted with the werf secret key (werf helm secret encrypt).
+	Value string
+
+	raw *rawSecret
+}
+
+func (s *Secret) validate() error {
+	var sources int
+	for _, source := range []string{s.Env, s.Src, s.Value} {
+		if source != "" {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return newDetailedConfigError("exactly one of `env: NAME`, `src: PATH` or `value: ENCRYPTED_VALUE` required for secret!", s.raw, s.raw.doc())
+	}
+
+	if s.ID == "" {
+		switch {
+		case s.Env != "":
+			s.ID = s.Env
+		case s.Src != "":
+			s.ID = filepath.Base(s.Src)
+		default:
+			return newDetailedConfigError("`id: ID` required for secret with value!", s.raw, s.raw.doc())
+		}
+	}
+
+	if strings.ContainsAny(s.ID, "/,=") || s.ID == "." || s.ID == ".." {
+		return newDetailedConfigError(fmt.Sprintf("invalid secret id %q: id cannot contain `/`, `,` and `=` characters!", s.ID), s.raw, s.raw.doc())
+	}
+
+	return nil
+}
@@ -16,11 +16,13 @@ type StapelImageBase struct {
 	FromArtifactName string
 	FromCacheVersion string
 	Git              *GitManager
+	Archive          []*Archive
 	Shell            *Shell
 	Ansible          *Ansible
 	Mount            []*Mount
 	Import           []*Import
 	Dependencies     []*Dependency
+	Secrets          []*Secret
 	Platform         []string
 
 	raw *rawStapelImage
@@ -0,0 +1,31 @@
+package container_backend
+
+import "fmt"
+
+// BuildSecret is mounted to /run/secrets/ID during the build, the value is taken from the environment variable Env
+// or the host file Src.
+type BuildSecret struct {
+	ID  string
+	Env string
+	Src string
+}
+
+// String returns the secret in the id=ID,env=ENV or id=ID,src=SRC format of the docker and buildah --secret option.
+func (s BuildSecret) String() string {
+	if s.Env != "" {
+		return fmt.Sprintf("id=%s,env=%s", s.ID, s.Env)
+	}
+	return fmt.Sprintf("id=%s,src=%s", s.ID, s.Src)
+}
+
+func (s BuildSecret) Target() string {
+	return "/run/secrets/" + s.ID
+}
+
+func buildSecretsStrings(secrets []BuildSecret) []string {
+	var res []string
+	for _, s := range secrets {
+		res = append(res, s.String())
+	}
+	return res
+}
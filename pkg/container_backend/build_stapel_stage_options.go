@@ -22,6 +22,7 @@ type BuildStapelStageOptionsInterface interface {
 
 	AddBuildVolumes(volumes ...string) BuildStapelStageOptionsInterface
 	AddCommands(commands ...string) BuildStapelStageOptionsInterface
+	AddSecrets(secrets ...BuildSecret) BuildStapelStageOptionsInterface
 
 	AddDataArchive(archive io.ReadCloser, archiveType ArchiveType, to string, o AddDataArchiveOptions) BuildStapelStageOptionsInterface
 	RemoveData(removeType RemoveType, paths, keepParentDirs []string) BuildStapelStageOptionsInterface
@@ -43,6 +44,8 @@ type BuildStapelStageOptions struct {
 
 	BuildVolumes []string
 	Commands     []string
+	// Secrets are available to the commands in /run/secrets and are not stored in the image.
+	Secrets []BuildSecret
 
 	DataArchiveSpecs      []DataArchiveSpec
 	RemoveDataSpecs       []RemoveDataSpec
@@ -153,6 +156,11 @@ func (opts *BuildStapelStageOptions) AddCommands(commands ...string) BuildStapel
 	return opts
 }
 
+func (opts *BuildStapelStageOptions) AddSecrets(secrets ...BuildSecret) BuildStapelStageOptionsInterface {
+	opts.Secrets = append(opts.Secrets, secrets...)
+	return opts
+}
+
 func (opts *BuildStapelStageOptions) AddDataArchive(archive io.ReadCloser, archiveType ArchiveType, to string, o AddDataArchiveOptions) BuildStapelStageOptionsInterface {
 	opts.DataArchiveSpecs = append(opts.DataArchiveSpecs, DataArchiveSpec{
 		Archive: archive,
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/google/uuid"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 	"github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -154,7 +155,7 @@ fi
 `, strings.Join(scriptCommands, "\n")))
 }
 
-func (backend *BuildahBackend) applyCommands(ctx context.Context, container *containerDesc, buildVolumes, commands []string, opts CommonOpts) error {
+func (backend *BuildahBackend) applyCommands(ctx context.Context, container *containerDesc, buildVolumes, commands []string, secrets []BuildSecret, opts CommonOpts) error {
 	hostScriptPath := filepath.Join(backend.TmpDir, fmt.Sprintf("script-%s.sh", uuid.New().String()))
 	if err := os.WriteFile(hostScriptPath, makeScript(commands), os.FileMode(0o555)); err != nil {
 		return fmt.Errorf("unable to write script file %q: %w", hostScriptPath, err)
@@ -178,11 +179,23 @@ func (backend *BuildahBackend) applyCommands(ctx context.Context, container *con
 		mounts = append(mounts, m...)
 	}
 
+	// Secrets are mounted the same way as RUN --mount=type=secret, so the mount targets are not left in the image.
+	var runMounts []*instructions.Mount
+	for _, secret := range secrets {
+		runMounts = append(runMounts, &instructions.Mount{
+			Type:    instructions.MountTypeSecret,
+			CacheID: secret.ID,
+			Target:  secret.Target(),
+		})
+	}
+
 	if err := backend.buildah.RunCommand(ctx, container.Name, []string{"sh", destScriptPath}, buildah.RunCommandOpts{
 		CommonOpts:   backend.getBuildahCommonOpts(ctx, false, nil, opts.TargetPlatform),
 		User:         "0:0",
 		WorkingDir:   "/",
 		GlobalMounts: mounts,
+		RunMounts:    runMounts,
+		Secrets:      buildSecretsStrings(secrets),
 	}); err != nil {
 		return fmt.Errorf("unable to run commands script: %w", err)
 	}
@@ -277,7 +290,12 @@ func (backend *BuildahBackend) CalculateDependencyImportChecksum(ctx context.Con
 
 func (backend *BuildahBackend) applyDataArchives(ctx context.Context, container *containerDesc, dataArchives []DataArchiveSpec) error {
 	for _, archive := range dataArchives {
-		destPath := filepath.Join(container.RootMount, archive.To)
+		// Symlinks from the base image are resolved inside the container root, the destination itself is not followed.
+		destParentPath, err := securejoin.SecureJoin(container.RootMount, filepath.Dir(filepath.Clean("/"+archive.To)))
+		if err != nil {
+			return fmt.Errorf("unable to resolve container path %q: %w", archive.To, err)
+		}
+		destPath := filepath.Join(destParentPath, filepath.Base(filepath.Clean("/"+archive.To)))
 
 		var extractDestPath string
 		switch archive.Type {
@@ -301,7 +319,6 @@ func (backend *BuildahBackend) applyDataArchives(ctx context.Context, container
 			return fmt.Errorf("unknown archive type %q", archive.Type)
 		}
 
-		var err error
 		var uid, gid *uint32
 		uid, gid, err = getUIDAndGID(archive.Owner, archive.Group, container.RootMount)
 		if err != nil {
@@ -310,7 +327,7 @@ func (backend *BuildahBackend) applyDataArchives(ctx context.Context, container
 
 		logboek.Context(ctx).Debug().LogF("Extracting archive into container path %s\n", archive.To)
 
-		if err := util.ExtractTar(archive.Archive, extractDestPath, util.ExtractTarOptions{UID: uid, GID: gid}); err != nil {
+		if err := util.ExtractTar(archive.Archive, extractDestPath, util.ExtractTarOptions{UID: uid, GID: gid, RootDir: container.RootMount}); err != nil {
 			return fmt.Errorf("unable to extract data archive into %s: %w", archive.To, err)
 		}
 
@@ -529,7 +546,7 @@ func (backend *BuildahBackend) BuildStapelStage(ctx context.Context, baseImage s
 		}
 	}
 	if len(opts.Commands) > 0 {
-		if err := backend.applyCommands(ctx, container, opts.BuildVolumes, opts.Commands, commonOpts); err != nil {
+		if err := backend.applyCommands(ctx, container, opts.BuildVolumes, opts.Commands, opts.Secrets, commonOpts); err != nil {
 			return "", err
 		}
 	}
@@ -708,6 +725,7 @@ func (backend *BuildahBackend) BuildDockerfile(ctx context.Context, dockerfileCo
 		BuildArgs:  buildArgs,
 		Target:     opts.Target,
 		Labels:     opts.Labels,
+		Secrets:    buildSecretsStrings(opts.Secrets),
 	})
 }
 
@@ -855,6 +873,47 @@ func makeBuildahMounts(volumes []string) ([]*specs.Mount, error) {
 	return mounts, nil
 }
 
+func makeBuildahRunMounts(volumes []string) ([]*specs.Mount, error) {
+	var mounts []*specs.Mount
+
+	for _, volume := range volumes {
+		parts := strings.Split(volume, ":")
+		if len(parts) < 2 || len(parts) > 3 {
+			return nil, fmt.Errorf("invalid volume %q: expected SOURCE:DESTINATION[:OPTIONS] format", volume)
+		}
+
+		if !filepath.IsAbs(parts[0]) && !strings.HasPrefix(parts[0], ".") {
+			return nil, fmt.Errorf("invalid volume %q: named volumes are not supported by the buildah backend, host path expected", volume)
+		}
+
+		source, err := filepath.Abs(parts[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid volume %q: %w", volume, err)
+		}
+
+		mount := &specs.Mount{
+			Type:        "bind",
+			Source:      source,
+			Destination: parts[1],
+			Options:     []string{"rbind"},
+		}
+		if len(parts) == 3 {
+			for _, option := range strings.Split(parts[2], ",") {
+				switch option {
+				case "ro", "rw":
+					mount.Options = append(mount.Options, option)
+				default:
+					return nil, fmt.Errorf("invalid volume %q: unsupported option %q", volume, option)
+				}
+			}
+		}
+
+		mounts = append(mounts, mount)
+	}
+
+	return mounts, nil
+}
+
 func getUIDAndGID(userNameOrUID, groupNameOrGID, fsRoot string) (*uint32, *uint32, error) {
 	uid, err := getUID(userNameOrUID, fsRoot)
 	if err != nil {
@@ -1011,6 +1070,135 @@ func (backend *BuildahBackend) Containers(ctx context.Context, opts ContainersOp
 	return backend.buildah.Containers(ctx, containersOpts)
 }
 
+func (backend *BuildahBackend) SaveImageToStream(ctx context.Context, ref string) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("saving image to stream is not supported by the buildah backend yet")
+}
+
+func (backend *BuildahBackend) LoadImageFromStream(ctx context.Context, input io.Reader) error {
+	return fmt.Errorf("loading image from stream is not supported by the buildah backend yet")
+}
+
+func (backend *BuildahBackend) MountImage(ctx context.Context, ref string, opts MountImageOpts) (string, func() error, error) {
+	containers, err := backend.createContainers(ctx, []string{ref}, CommonOpts(opts))
+	if err != nil {
+		return "", nil, err
+	}
+
+	release := func() error {
+		if err := backend.unmountContainers(ctx, containers, CommonOpts(opts)); err != nil {
+			return fmt.Errorf("unable to unmount containers: %w", err)
+		}
+		return backend.removeContainers(ctx, containers, CommonOpts(opts))
+	}
+
+	if err := backend.mountContainers(ctx, containers, CommonOpts(opts)); err != nil {
+		if removeErr := backend.removeContainers(ctx, containers, CommonOpts(opts)); removeErr != nil {
+			logboek.Context(ctx).Warn().LogF("WARNING: unable to remove container: %s\n", removeErr)
+		}
+		return "", nil, err
+	}
+
+	return containers[0].RootMount, release, nil
+}
+
+func (backend *BuildahBackend) ContainerLogs(ctx context.Context, name string, opts ContainerLogsOpts) error {
+	return fmt.Errorf("container logs are not supported by the buildah backend")
+}
+
+func (backend *BuildahBackend) CreateNetwork(ctx context.Context, name string) error {
+	return fmt.Errorf("networks are not supported by the buildah backend")
+}
+
+func (backend *BuildahBackend) RemoveNetwork(ctx context.Context, name string) error {
+	return fmt.Errorf("networks are not supported by the buildah backend")
+}
+
+// RunContainer runs the container with buildah. Detached containers are not supported and the published ports are
+// available only in the host network namespace, so the host port should be the same as the container port.
+func (backend *BuildahBackend) RunContainer(ctx context.Context, ref string, opts RunContainerOpts) error {
+	if opts.Detach {
+		return fmt.Errorf("detached containers are not supported by the buildah backend")
+	}
+
+	network := opts.Network
+	switch network {
+	case "", "default", "bridge":
+		network = "default"
+	case "host", "none":
+	default:
+		return fmt.Errorf("network %q is not supported by the buildah backend: host, none or default network expected", network)
+	}
+
+	for _, p := range opts.Ports {
+		port, err := parsePublishedPort(p)
+		if err != nil {
+			return fmt.Errorf("invalid published port %q: %w", p, err)
+		}
+		if port.HostPort != port.ContainerPort {
+			return fmt.Errorf("invalid published port %q: the buildah backend runs the container in the host network, so the host port should be the same as the container port", p)
+		}
+		if network == "none" {
+			return fmt.Errorf("invalid published port %q: the network is disabled", p)
+		}
+		network = "host"
+	}
+
+	mounts, err := makeBuildahRunMounts(opts.Volumes)
+	if err != nil {
+		return err
+	}
+
+	info, err := backend.buildah.Inspect(ctx, ref)
+	if err != nil {
+		return fmt.Errorf("unable to inspect image %q: %w", ref, err)
+	}
+	if info == nil {
+		if err := backend.buildah.Pull(ctx, ref, buildah.PullOpts(backend.getBuildahCommonOpts(ctx, false, nil, opts.TargetPlatform))); err != nil {
+			return fmt.Errorf("unable to pull image %q: %w", ref, err)
+		}
+		if info, err = backend.buildah.Inspect(ctx, ref); err != nil {
+			return fmt.Errorf("unable to inspect image %q: %w", ref, err)
+		}
+	}
+
+	command := imageRunCommand(info.OCIv1.Config.Entrypoint, info.OCIv1.Config.Cmd, opts)
+	if len(command) == 0 {
+		return fmt.Errorf("no command specified for image %q", ref)
+	}
+
+	containerName := opts.Name
+	if containerName == "" {
+		containerName = fmt.Sprintf("werf-run-%s", uuid.New().String())
+	}
+
+	if _, err := backend.buildah.FromCommand(ctx, containerName, ref, buildah.FromCommandOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))); err != nil {
+		return fmt.Errorf("unable to create container %q for image %q: %w", containerName, ref, err)
+	}
+	if opts.Remove {
+		defer func() {
+			if err := backend.buildah.Rm(ctx, containerName, buildah.RmOpts(backend.getBuildahCommonOpts(ctx, true, nil, opts.TargetPlatform))); err != nil {
+				logboek.Context(ctx).Warn().LogF("WARNING: unable to remove container %q: %s\n", containerName, err)
+			}
+		}()
+	}
+
+	runOpts := buildah.RunCommandOpts{
+		CommonOpts:   backend.getBuildahCommonOpts(ctx, false, os.Stdout, opts.TargetPlatform),
+		NetworkType:  network,
+		WorkingDir:   opts.WorkingDir,
+		User:         opts.User,
+		Envs:         opts.Envs,
+		GlobalMounts: mounts,
+		Stderr:       os.Stderr,
+		Terminal:     opts.TTY,
+	}
+	if opts.Interactive {
+		runOpts.Stdin = os.Stdin
+	}
+
+	return backend.buildah.RunCommand(ctx, containerName, command, runOpts)
+}
+
 func (backend *BuildahBackend) Rm(ctx context.Context, name string, opts RmOpts) error {
 	return backend.buildah.Rm(ctx, name, buildah.RmOpts{})
 }
@@ -3,18 +3,21 @@ package container_backend
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/google/uuid"
 
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/docker"
 	"github.com/werf/werf/pkg/image"
 	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/werf"
 )
 
 type DockerServerBackend struct{}
@@ -70,6 +73,9 @@ func (backend *DockerServerBackend) BuildDockerfile(ctx context.Context, _ []byt
 	if opts.SSH != "" {
 		cliArgs = append(cliArgs, "--ssh", opts.SSH)
 	}
+	for _, secret := range opts.Secrets {
+		cliArgs = append(cliArgs, "--secret", secret.String())
+	}
 
 	for _, addHost := range opts.AddHost {
 		cliArgs = append(cliArgs, "--add-host", addHost)
@@ -240,6 +246,93 @@ func (backend *DockerServerBackend) Rm(ctx context.Context, ref string, opts RmO
 	return docker.ContainerRemove(ctx, ref, types.ContainerRemoveOptions{Force: opts.Force})
 }
 
+func (backend *DockerServerBackend) SaveImageToStream(ctx context.Context, ref string) (io.ReadCloser, error) {
+	return docker.ImageSave(ctx, ref)
+}
+
+func (backend *DockerServerBackend) LoadImageFromStream(ctx context.Context, input io.Reader) error {
+	return docker.ImageLoad(ctx, input)
+}
+
+// MountImage exports the image from the docker server and extracts the flattened image filesystem into the temporary directory.
+func (backend *DockerServerBackend) MountImage(ctx context.Context, ref string, opts MountImageOpts) (string, func() error, error) {
+	archive, err := os.CreateTemp(werf.GetTmpDir(), "image-archive-*.tar")
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create temporary archive file: %w", err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	rc, err := docker.ImageSave(ctx, ref)
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to save image %q: %w", ref, err)
+	}
+	_, err = io.Copy(archive, rc)
+	rc.Close()
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to save image %q: %w", ref, err)
+	}
+
+	dir, err := os.MkdirTemp(werf.GetTmpDir(), "image-fs-")
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to create temporary dir: %w", err)
+	}
+	release := func() error {
+		return os.RemoveAll(dir)
+	}
+
+	if err := extractImageArchiveFilesystem(archive.Name(), dir); err != nil {
+		release()
+		return "", nil, fmt.Errorf("unable to extract image %q filesystem: %w", ref, err)
+	}
+
+	return dir, release, nil
+}
+
+func (backend *DockerServerBackend) RunContainer(ctx context.Context, ref string, opts RunContainerOpts) error {
+	return docker.CliRun_LiveOutput(ctx, opts.DockerRunArgs(ref)...)
+}
+
+func (backend *DockerServerBackend) ContainerLogs(ctx context.Context, name string, opts ContainerLogsOpts) error {
+	rc, err := docker.ContainerLogs(ctx, name, types.ContainerLogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Tail:       opts.Tail,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to get logs of container %q: %w", name, err)
+	}
+	defer rc.Close()
+
+	if _, err := stdcopy.StdCopy(opts.Stdout, opts.Stderr, rc); err != nil {
+		return fmt.Errorf("unable to read logs of container %q: %w", name, err)
+	}
+	return nil
+}
+
+func (backend *DockerServerBackend) CreateNetwork(ctx context.Context, name string) error {
+	exist, err := docker.NetworkExist(ctx, name)
+	if err != nil {
+		return fmt.Errorf("unable to check network %q existence: %w", name, err)
+	}
+	if exist {
+		return nil
+	}
+
+	if err := docker.NetworkCreate(ctx, name, types.NetworkCreate{CheckDuplicate: true}); err != nil {
+		return fmt.Errorf("unable to create network %q: %w", name, err)
+	}
+	return nil
+}
+
+func (backend *DockerServerBackend) RemoveNetwork(ctx context.Context, name string) error {
+	if err := docker.NetworkRemove(ctx, name); err != nil && !client.IsErrNotFound(err) {
+		return fmt.Errorf("unable to remove network %q: %w", name, err)
+	}
+	return nil
+}
+
 func (backend *DockerServerBackend) PushImage(ctx context.Context, img LegacyImageInterface) error {
 	if err := logboek.Context(ctx).Info().LogProcess(fmt.Sprintf("Pushing %s", img.Name())).DoError(func() error {
 		return docker.CliPushWithRetries(ctx, img.Name())
@@ -332,6 +425,8 @@ func (backend *DockerServerBackend) Containers(ctx context.Context, opts Contain
 			ID:      container.ID,
 			ImageID: container.ImageID,
 			Names:   container.Names,
+			State:   container.State,
+			Labels:  container.Labels,
 		})
 	}
 
@@ -0,0 +1,102 @@
+package container_backend
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// extractImageArchiveFilesystem extracts the flattened filesystem of the image saved in the docker archive format.
+// Only directories, regular files, symlinks and hardlinks are extracted, ownership is not preserved.
+func extractImageArchiveFilesystem(archivePath, dir string) error {
+	img, err := tarball.ImageFromPath(archivePath, nil)
+	if err != nil {
+		return fmt.Errorf("unable to read image archive: %w", err)
+	}
+
+	rc := mutate.Extract(img)
+	defer rc.Close()
+
+	return extractTar(rc, dir)
+}
+
+// extractTar extracts the image filesystem into the dir. Every entry path and hardlink target is resolved
+// with securejoin inside the dir, so neither "../" entries nor symlinks from the image can escape the dir.
+// Symlinks are created as is: the extracted filesystem must be accessed only with paths resolved inside the dir.
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read tar: %w", err)
+		}
+
+		name := filepath.Clean("/" + hdr.Name)
+		if name == "/" {
+			continue
+		}
+
+		// The parent dir is resolved separately so that the entry itself is replaced, not followed.
+		parentDir, err := securejoin.SecureJoin(dir, filepath.Dir(name))
+		if err != nil {
+			return fmt.Errorf("unable to resolve %q: %w", hdr.Name, err)
+		}
+		path := filepath.Join(parentDir, filepath.Base(name))
+		mode := os.FileMode(hdr.Mode).Perm() | 0o200
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, mode|0o700); err != nil {
+				return err
+			}
+			continue
+		case tar.TypeReg, tar.TypeSymlink, tar.TypeLink:
+		default:
+			continue
+		}
+
+		if err := os.MkdirAll(parentDir, 0o755); err != nil {
+			return err
+		}
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+
+		switch hdr.Typeflag {
+		case tar.TypeReg:
+			f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, mode)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		case tar.TypeSymlink:
+			if err := os.Symlink(hdr.Linkname, path); err != nil {
+				return err
+			}
+		case tar.TypeLink:
+			target, err := securejoin.SecureJoin(dir, hdr.Linkname)
+			if err != nil {
+				return fmt.Errorf("unable to resolve link target %q: %w", hdr.Linkname, err)
+			}
+
+			// The link target might be missing in the flattened filesystem, such links are skipped.
+			_ = os.Link(target, path)
+		}
+	}
+}
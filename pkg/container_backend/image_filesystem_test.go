@@ -0,0 +1,45 @@
+package container_backend
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("extractTar", func() {
+	It("should keep entries and links inside the dir", func() {
+		outsideDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(outsideDir, "secret"), []byte("secret"), 0o644)).To(Succeed())
+
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		writeEntry := func(hdr *tar.Header, data string) {
+			hdr.Size = int64(len(data))
+			Expect(tw.WriteHeader(hdr)).To(Succeed())
+			_, err := tw.Write([]byte(data))
+			Expect(err).To(Succeed())
+		}
+		writeEntry(&tar.Header{Name: "../../escaped", Typeflag: tar.TypeReg, Mode: 0o644}, "escaped")
+		writeEntry(&tar.Header{Name: "etc", Typeflag: tar.TypeSymlink, Linkname: outsideDir}, "")
+		writeEntry(&tar.Header{Name: "etc/passwd", Typeflag: tar.TypeReg, Mode: 0o644}, "passwd")
+		writeEntry(&tar.Header{Name: "secret-link", Typeflag: tar.TypeLink, Linkname: "../" + filepath.Join(outsideDir, "secret")}, "")
+		Expect(tw.Close()).To(Succeed())
+
+		parentDir := GinkgoT().TempDir()
+		dir := filepath.Join(parentDir, "a", "b")
+		Expect(os.MkdirAll(dir, 0o755)).To(Succeed())
+		Expect(extractTar(&buf, dir)).To(Succeed())
+
+		Expect(filepath.Join(dir, "escaped")).To(BeARegularFile())
+		Expect(filepath.Join(parentDir, "escaped")).NotTo(BeAnExistingFile())
+
+		Expect(filepath.Join(outsideDir, "passwd")).NotTo(BeAnExistingFile())
+		Expect(filepath.Join(dir, outsideDir, "passwd")).To(BeARegularFile())
+
+		Expect(filepath.Join(dir, "secret-link")).NotTo(BeAnExistingFile())
+	})
+})
@@ -19,10 +19,32 @@ func NewAdd(i instructions.AddCommand) *Add {
 }
 
 func (i *Add) UsesBuildContext() bool {
-	return true
+	return len(i.SourcePaths) > 0
 }
 
+// Apply adds the sources and then copies the heredocs like buildkit does. The --link flag does not change the result
+// of the adding, the stage layer is committed separately anyway.
 func (i *Add) Apply(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
+	if len(i.SourcePaths) > 0 {
+		if err := i.addSources(ctx, containerName, drv, drvOpts, buildContextArchive); err != nil {
+			return err
+		}
+	}
+
+	if len(i.SourceContents) > 0 {
+		if err := copyHeredocs(ctx, containerName, drv, i.SourceContents, i.DestPath, buildah.CopyOpts{
+			CommonOpts: drvOpts,
+			Chown:      i.Chown,
+			Chmod:      i.Chmod,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Add) addSources(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
 	var contextDir string
 	if i.UsesBuildContext() {
 		var err error
@@ -3,7 +3,11 @@ package instruction
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/bmatcuk/doublestar"
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
 
 	"github.com/werf/werf/pkg/buildah"
@@ -12,6 +16,8 @@ import (
 
 type Copy struct {
 	instructions.CopyCommand
+	// Parents preserves the parent dirs of the sources relative to the context dir (COPY --parents).
+	Parents bool
 }
 
 func NewCopy(i instructions.CopyCommand) *Copy {
@@ -19,10 +25,34 @@ func NewCopy(i instructions.CopyCommand) *Copy {
 }
 
 func (i *Copy) UsesBuildContext() bool {
-	return i.From == ""
+	return i.From == "" && len(i.SourcePaths) > 0
 }
 
+// Apply copies the sources and then the heredocs like buildkit does. The --link flag does not change the result of
+// the copying, the stage layer is committed separately anyway.
 func (i *Copy) Apply(ctx context.Context, containerName string, drv buildah.Buildah, drvOpts buildah.CommonOpts, buildContextArchive container_backend.BuildContextArchiver) error {
+	copyOpts := buildah.CopyOpts{
+		CommonOpts: drvOpts,
+		Chown:      i.Chown,
+		Chmod:      i.Chmod,
+	}
+
+	if len(i.SourcePaths) > 0 {
+		if err := i.copySources(ctx, containerName, drv, copyOpts, buildContextArchive); err != nil {
+			return err
+		}
+	}
+
+	if len(i.SourceContents) > 0 {
+		if err := copyHeredocs(ctx, containerName, drv, i.SourceContents, i.DestPath, copyOpts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *Copy) copySources(ctx context.Context, containerName string, drv buildah.Buildah, copyOpts buildah.CopyOpts, buildContextArchive container_backend.BuildContextArchiver) error {
 	var contextDir string
 	if i.UsesBuildContext() {
 		var err error
@@ -42,13 +72,74 @@ func (i *Copy) Apply(ctx context.Context, containerName string, drv buildah.Buil
 		}
 	}
 
-	if err := drv.Copy(ctx, containerName, contextDir, i.SourcePaths, i.DestPath, buildah.CopyOpts{
-		CommonOpts: drvOpts,
-		Chown:      i.Chown,
-		Chmod:      i.Chmod,
-	}); err != nil {
+	if i.Parents {
+		return copyWithParents(ctx, containerName, drv, contextDir, i.SourcePaths, i.DestPath, copyOpts)
+	}
+
+	if err := drv.Copy(ctx, containerName, contextDir, i.SourcePaths, i.DestPath, copyOpts); err != nil {
 		return fmt.Errorf("error copying %v to %s for container %s: %w", i.SourcePaths, i.DestPath, containerName, err)
 	}
 
 	return nil
 }
+
+// copyWithParents copies the sources matched in the context dir into the destination dir under their parent dirs
+// relative to the context dir. A matched dir keeps its own name as well. The /./ pivot in a source path strips the
+// parent dirs before it, like buildkit does: "src/./app/main.go" is copied to "<dest>/app/main.go".
+func copyWithParents(ctx context.Context, containerName string, drv buildah.Buildah, contextDir string, sources []string, dest string, copyOpts buildah.CopyOpts) error {
+	var dests []string
+	sourcesByDest := map[string][]string{}
+	for _, src := range sources {
+		baseDir, pattern := contextDir, src
+		if parts := strings.SplitN(src, "/./", 2); len(parts) == 2 {
+			baseDir, pattern = filepath.Join(contextDir, parts[0]), parts[1]
+		}
+
+		matches, err := doublestar.Glob(filepath.Join(baseDir, pattern))
+		if err != nil {
+			return fmt.Errorf("unable to match %q: %w", src, err)
+		}
+		if len(matches) == 0 {
+			return fmt.Errorf("no source files were specified for %q", src)
+		}
+
+		for _, match := range matches {
+			contextPath, err := filepath.Rel(contextDir, match)
+			if err != nil {
+				return err
+			}
+			if contextPath == ".." || strings.HasPrefix(contextPath, "../") {
+				return fmt.Errorf("source %q is outside of the context", src)
+			}
+
+			relPath, err := filepath.Rel(baseDir, match)
+			if err != nil {
+				return err
+			}
+
+			stat, err := os.Stat(match)
+			if err != nil {
+				return fmt.Errorf("unable to stat %q: %w", match, err)
+			}
+
+			// Buildah copies the contents of a source dir, so the dir itself becomes the destination.
+			matchDest := filepath.Join(dest, filepath.Dir(relPath)) + "/"
+			if stat.IsDir() {
+				matchDest = filepath.Join(dest, relPath) + "/"
+			}
+
+			if _, ok := sourcesByDest[matchDest]; !ok {
+				dests = append(dests, matchDest)
+			}
+			sourcesByDest[matchDest] = append(sourcesByDest[matchDest], contextPath)
+		}
+	}
+
+	for _, matchDest := range dests {
+		if err := drv.Copy(ctx, containerName, contextDir, sourcesByDest[matchDest], matchDest, copyOpts); err != nil {
+			return fmt.Errorf("error copying %v to %s for container %s: %w", sourcesByDest[matchDest], matchDest, containerName, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package instruction
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+
+	"github.com/werf/werf/pkg/buildah"
+	"github.com/werf/werf/pkg/container_backend"
+)
+
+type copyCall struct {
+	Src []string
+	Dst string
+}
+
+type buildahStub struct {
+	buildah.Buildah
+	copyCalls []copyCall
+}
+
+func (b *buildahStub) Copy(_ context.Context, _, _ string, src []string, dst string, _ buildah.CopyOpts) error {
+	b.copyCalls = append(b.copyCalls, copyCall{Src: src, Dst: dst})
+	return nil
+}
+
+type buildContextArchiveStub struct {
+	container_backend.BuildContextArchiver
+	dir string
+}
+
+func (a *buildContextArchiveStub) ExtractOrGetExtractedDir(_ context.Context) (string, error) {
+	return a.dir, nil
+}
+
+func TestCopyApplyWithParents(t *testing.T) {
+	tmpDir := t.TempDir()
+	contextDir := filepath.Join(tmpDir, "context")
+	if err := os.WriteFile(filepath.Join(tmpDir, "outside"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"src/a/go.mod", "src/b/go.mod", "src/b/main.go", "app/cmd/main.go"} {
+		if err := os.MkdirAll(filepath.Join(contextDir, filepath.Dir(path)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(contextDir, path), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name          string
+		sources       []string
+		dest          string
+		expectedCalls []copyCall
+		expectedError string
+	}{
+		{
+			name:    "files keep parent dirs",
+			sources: []string{"src/*/go.mod"},
+			dest:    "/app",
+			expectedCalls: []copyCall{
+				{Src: []string{"src/a/go.mod"}, Dst: "/app/src/a/"},
+				{Src: []string{"src/b/go.mod"}, Dst: "/app/src/b/"},
+			},
+		},
+		{
+			name:    "dir keeps its name",
+			sources: []string{"src/"},
+			dest:    "/app/",
+			expectedCalls: []copyCall{
+				{Src: []string{"src"}, Dst: "/app/src/"},
+			},
+		},
+		{
+			name:    "pivot strips parent dirs",
+			sources: []string{"app/./cmd/main.go", "src/./b"},
+			dest:    "/app",
+			expectedCalls: []copyCall{
+				{Src: []string{"app/cmd/main.go"}, Dst: "/app/cmd/"},
+				{Src: []string{"src/b"}, Dst: "/app/b/"},
+			},
+		},
+		{
+			name:          "source outside of the context",
+			sources:       []string{"../outside"},
+			dest:          "/app",
+			expectedError: "is outside of the context",
+		},
+		{
+			name:          "no matches",
+			sources:       []string{"missing/*"},
+			dest:          "/app",
+			expectedError: "no source files were specified",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			drv := &buildahStub{}
+			i := &Copy{
+				CopyCommand: instructions.CopyCommand{SourcesAndDest: instructions.SourcesAndDest{SourcePaths: tt.sources, DestPath: tt.dest}},
+				Parents:     true,
+			}
+
+			err := i.Apply(context.Background(), "container", drv, buildah.CommonOpts{}, &buildContextArchiveStub{dir: contextDir})
+			if tt.expectedError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectedError) {
+					t.Fatalf("expected error %q, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(drv.copyCalls, tt.expectedCalls) {
+				t.Errorf("unexpected copy calls: %#v", drv.copyCalls)
+			}
+		})
+	}
+}
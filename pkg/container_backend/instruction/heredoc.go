@@ -0,0 +1,95 @@
+package instruction
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"github.com/opencontainers/runtime-spec/specs-go"
+
+	"github.com/werf/werf/pkg/buildah"
+	"github.com/werf/werf/pkg/werf"
+)
+
+// heredocScriptsDir is the container dir of the heredoc scripts, buildkit uses the same dir.
+const heredocScriptsDir = "/dev/pipes"
+
+// copyHeredocs copies the COPY and ADD heredocs into the container, the heredocs are written into the temporary dir,
+// which is used as the context dir.
+func copyHeredocs(ctx context.Context, containerName string, drv buildah.Buildah, contents []instructions.SourceContent, dest string, opts buildah.CopyOpts) error {
+	dir, err := os.MkdirTemp(werf.GetTmpDir(), "heredoc-")
+	if err != nil {
+		return fmt.Errorf("unable to create heredocs dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var sources []string
+	for _, content := range contents {
+		path := filepath.Join(dir, content.Path)
+		if err := os.WriteFile(path, []byte(content.Data), 0o664); err != nil {
+			return fmt.Errorf("unable to write heredoc %q: %w", content.Path, err)
+		}
+		if err := os.Chmod(path, 0o664); err != nil {
+			return fmt.Errorf("unable to chmod heredoc %q: %w", content.Path, err)
+		}
+		sources = append(sources, content.Path)
+	}
+
+	if err := drv.Copy(ctx, containerName, dir, sources, dest, opts); err != nil {
+		return fmt.Errorf("error copying heredocs %v to %s for container %s: %w", sources, dest, containerName, err)
+	}
+
+	return nil
+}
+
+// prepareHeredocCommand returns the RUN command with the heredocs applied the same way as buildkit does: the single
+// heredoc with the shebang is mounted into the container and run as the script, the single heredoc without the
+// shebang is passed to the shell and the other heredocs are passed to the shell along with the command.
+func prepareHeredocCommand(cmdLine []string, prependShell bool, files []instructions.ShellInlineFile, scriptsDir string) ([]string, []*specs.Mount, error) {
+	if len(files) == 0 {
+		return cmdLine, nil, nil
+	}
+
+	if len(cmdLine) != 1 || !prependShell {
+		return nil, nil, fmt.Errorf("parsing produced an invalid run command: %v", cmdLine)
+	}
+
+	if heredoc := parser.MustParseHeredoc(cmdLine[0]); heredoc == nil {
+		full := cmdLine[0]
+		for _, file := range files {
+			full += "\n" + file.Data + file.Name
+		}
+		return []string{full}, nil, nil
+	}
+
+	data := files[0].Data
+	if files[0].Chomp {
+		data = parser.ChompHeredocContent(data)
+	}
+
+	if !strings.HasPrefix(files[0].Data, "#!") {
+		return []string{data}, nil, nil
+	}
+
+	scriptPath := filepath.Join(scriptsDir, files[0].Name)
+	if err := os.WriteFile(scriptPath, []byte(data), 0o755); err != nil {
+		return nil, nil, fmt.Errorf("unable to write heredoc %q: %w", files[0].Name, err)
+	}
+	if err := os.Chmod(scriptPath, 0o755); err != nil {
+		return nil, nil, fmt.Errorf("unable to chmod heredoc %q: %w", files[0].Name, err)
+	}
+
+	return []string{path.Join(heredocScriptsDir, files[0].Name)}, []*specs.Mount{
+		{
+			Type:        "bind",
+			Source:      scriptsDir,
+			Destination: heredocScriptsDir,
+			Options:     []string{"bind", "ro"},
+		},
+	}, nil
+}
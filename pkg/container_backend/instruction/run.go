@@ -3,18 +3,28 @@ package instruction
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/opencontainers/runtime-spec/specs-go"
 
+	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/buildah"
 	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/util"
+	"github.com/werf/werf/pkg/werf"
 )
 
 type Run struct {
 	instructions.RunCommand
 	Envs []string
+	// Secrets are available to the RUN --mount=type=secret mounts.
+	Secrets []container_backend.BuildSecret
+	// CacheMounts are the host dirs by cache id, which are mounted instead of the RUN --mount=type=cache volumes
+	// to persist the cache between builds.
+	CacheMounts map[string]string
 }
 
 func NewRun(i instructions.RunCommand, envs []string) *Run {
@@ -35,6 +45,14 @@ func (i *Run) GetMounts() []*instructions.Mount {
 	return instructions.GetMounts(&i.RunCommand)
 }
 
+// CacheMountID returns the id of the cache mount, the target is used by default.
+func CacheMountID(mount *instructions.Mount) string {
+	if mount.CacheID != "" {
+		return mount.CacheID
+	}
+	return mount.Target
+}
+
 func (i *Run) GetSecurity() string {
 	return instructions.GetSecurity(&i.RunCommand)
 }
@@ -58,15 +76,54 @@ func (i *Run) Apply(ctx context.Context, containerName string, drv buildah.Build
 		addCapabilities = []string{"all"}
 	}
 
-	logboek.Context(ctx).Default().LogF("$ %s\n", strings.Join(i.CmdLine, " "))
+	// The locks acquired before the error are released as well.
+	runMounts, globalMounts, cacheMountLocks, err := i.prepareMounts(ctx)
+	defer func() {
+		for _, lock := range cacheMountLocks {
+			_ = werf.ReleaseHostLock(lock)
+		}
+	}()
+	if err != nil {
+		return err
+	}
+
+	var secrets []string
+	for _, secret := range i.Secrets {
+		secrets = append(secrets, secret.String())
+	}
+
+	cmdLine := []string(i.CmdLine)
+	if len(i.Files) > 0 {
+		scriptsDir, err := os.MkdirTemp(werf.GetTmpDir(), "heredoc-")
+		if err != nil {
+			return fmt.Errorf("unable to create heredocs dir: %w", err)
+		}
+		defer os.RemoveAll(scriptsDir)
+
+		// The script is run by the container user.
+		if err := os.Chmod(scriptsDir, 0o755); err != nil {
+			return fmt.Errorf("unable to chmod heredocs dir: %w", err)
+		}
+
+		var heredocMounts []*specs.Mount
+		cmdLine, heredocMounts, err = prepareHeredocCommand(i.CmdLine, i.PrependShell, i.Files, scriptsDir)
+		if err != nil {
+			return err
+		}
+		globalMounts = append(globalMounts, heredocMounts...)
+	}
+
+	logboek.Context(ctx).Default().LogF("$ %s\n", strings.Join(cmdLine, " "))
 
-	if err := drv.RunCommand(ctx, containerName, i.CmdLine, buildah.RunCommandOpts{
+	if err := drv.RunCommand(ctx, containerName, cmdLine, buildah.RunCommandOpts{
 		CommonOpts:      drvOpts,
 		ContextDir:      contextDir,
 		PrependShell:    i.PrependShell,
 		AddCapabilities: addCapabilities,
 		NetworkType:     i.GetNetwork(),
-		RunMounts:       i.GetMounts(),
+		RunMounts:       runMounts,
+		GlobalMounts:    globalMounts,
+		Secrets:         secrets,
 		Envs:            i.Envs,
 	}); err != nil {
 		return fmt.Errorf("error running command %v for container %s: %w", i.CmdLine, containerName, err)
@@ -74,3 +131,61 @@ func (i *Run) Apply(ctx context.Context, containerName string, drv buildah.Build
 
 	return nil
 }
+
+// prepareMounts replaces the cache mounts persisted by werf with the bind mounts of the host dirs. The cache mounts
+// with sharing=locked and sharing=private are locked while the command is running.
+func (i *Run) prepareMounts(ctx context.Context) ([]*instructions.Mount, []*specs.Mount, []lockgate.LockHandle, error) {
+	var runMounts []*instructions.Mount
+	var globalMounts []*specs.Mount
+	var locks []lockgate.LockHandle
+
+	for _, mount := range i.GetMounts() {
+		dir, ok := i.CacheMounts[CacheMountID(mount)]
+		if mount.Type != instructions.MountTypeCache || mount.From != "" || !ok {
+			runMounts = append(runMounts, mount)
+			continue
+		}
+
+		if mount.Mode != nil {
+			if err := os.Chmod(dir, os.FileMode(*mount.Mode)); err != nil {
+				return nil, nil, locks, fmt.Errorf("unable to chmod cache mount dir %q: %w", dir, err)
+			}
+		}
+		if mount.UID != nil || mount.GID != nil {
+			var uid, gid *uint32
+			if mount.UID != nil {
+				v := uint32(*mount.UID)
+				uid = &v
+			}
+			if mount.GID != nil {
+				v := uint32(*mount.GID)
+				gid = &v
+			}
+			if err := util.Chown(dir, uid, gid); err != nil {
+				return nil, nil, locks, fmt.Errorf("unable to chown cache mount dir %q: %w", dir, err)
+			}
+		}
+
+		if mount.CacheSharing == instructions.MountSharingLocked || mount.CacheSharing == instructions.MountSharingPrivate {
+			_, lock, err := werf.AcquireHostLock(ctx, fmt.Sprintf("build_cache_mount.%s", util.Sha256Hash(dir)), lockgate.AcquireOptions{})
+			if err != nil {
+				return nil, nil, locks, fmt.Errorf("unable to lock cache mount dir %q: %w", dir, err)
+			}
+			locks = append(locks, lock)
+		}
+
+		options := []string{"bind", "rw"}
+		if mount.ReadOnly {
+			options = []string{"bind", "ro"}
+		}
+
+		globalMounts = append(globalMounts, &specs.Mount{
+			Type:        "bind",
+			Source:      dir,
+			Destination: mount.Target,
+			Options:     options,
+		})
+	}
+
+	return runMounts, globalMounts, locks, nil
+}
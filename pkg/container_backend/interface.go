@@ -2,6 +2,7 @@ package container_backend
 
 import (
 	"context"
+	"io"
 
 	"github.com/werf/werf/pkg/image"
 	"github.com/werf/werf/pkg/util"
@@ -16,6 +17,7 @@ type (
 	PushOpts                          CommonOpts
 	PullOpts                          CommonOpts
 	GetImageInfoOpts                  CommonOpts
+	MountImageOpts                    CommonOpts
 	CalculateDependencyImportChecksum CommonOpts
 )
 
@@ -39,6 +41,7 @@ type BuildDockerfileOpts struct {
 	AddHost              []string
 	Network              string
 	SSH                  string
+	Secrets              []BuildSecret
 	Labels               []string
 	Tags                 []string
 }
@@ -64,6 +67,15 @@ type ContainersOptions struct {
 	Filters []image.ContainerFilter
 }
 
+type ContainerLogsOpts struct {
+	CommonOpts
+	Follow bool
+	// Tail is the number of lines to show from the end of the logs, all lines are shown when empty.
+	Tail   string
+	Stdout io.Writer
+	Stderr io.Writer
+}
+
 type PostManifestOpts struct {
 	CommonOpts
 	Labels    []string
@@ -91,6 +103,20 @@ type ContainerBackend interface {
 	Images(ctx context.Context, opts ImagesOptions) (image.ImagesList, error)
 	Containers(ctx context.Context, opts ContainersOptions) (image.ContainerList, error)
 
+	// SaveImageToStream exports the image in the docker archive format
+	SaveImageToStream(ctx context.Context, ref string) (io.ReadCloser, error)
+	// LoadImageFromStream imports the image from the docker archive format
+	LoadImageFromStream(ctx context.Context, input io.Reader) error
+	// MountImage makes the image filesystem available in the host directory for reading, the returned function releases the directory
+	MountImage(ctx context.Context, ref string, opts MountImageOpts) (string, func() error, error)
+	// RunContainer runs the container for the image with the stdio of the werf process attached
+	RunContainer(ctx context.Context, ref string, opts RunContainerOpts) error
+	// ContainerLogs writes the logs of the container, the stdout and stderr streams are written separately
+	ContainerLogs(ctx context.Context, name string, opts ContainerLogsOpts) error
+	// CreateNetwork creates the network for the containers unless it already exists
+	CreateNetwork(ctx context.Context, name string) error
+	RemoveNetwork(ctx context.Context, name string) error
+
 	ClaimTargetPlatforms(ctx context.Context, targetPlatforms []string)
 
 	String() string
@@ -2,6 +2,7 @@ package container_backend
 
 import (
 	"context"
+	"io"
 
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/image"
@@ -135,6 +136,62 @@ func (runtime *PerfCheckContainerBackend) RemoveImage(ctx context.Context, img L
 	return
 }
 
+func (runtime *PerfCheckContainerBackend) SaveImageToStream(ctx context.Context, ref string) (resRc io.ReadCloser, resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.SaveImageToStream %q", ref).
+		Do(func() {
+			resRc, resErr = runtime.ContainerBackend.SaveImageToStream(ctx, ref)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) LoadImageFromStream(ctx context.Context, input io.Reader) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.LoadImageFromStream").
+		Do(func() {
+			resErr = runtime.ContainerBackend.LoadImageFromStream(ctx, input)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) MountImage(ctx context.Context, ref string, opts MountImageOpts) (resDir string, resRelease func() error, resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.MountImage %q", ref).
+		Do(func() {
+			resDir, resRelease, resErr = runtime.ContainerBackend.MountImage(ctx, ref, opts)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) RunContainer(ctx context.Context, ref string, opts RunContainerOpts) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.RunContainer %q %v", ref, opts).
+		Do(func() {
+			resErr = runtime.ContainerBackend.RunContainer(ctx, ref, opts)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) ContainerLogs(ctx context.Context, name string, opts ContainerLogsOpts) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.ContainerLogs %q %v", name, opts).
+		Do(func() {
+			resErr = runtime.ContainerBackend.ContainerLogs(ctx, name, opts)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) CreateNetwork(ctx context.Context, name string) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.CreateNetwork %q", name).
+		Do(func() {
+			resErr = runtime.ContainerBackend.CreateNetwork(ctx, name)
+		})
+	return
+}
+
+func (runtime *PerfCheckContainerBackend) RemoveNetwork(ctx context.Context, name string) (resErr error) {
+	logboek.Context(ctx).Default().LogProcess("ContainerBackend.RemoveNetwork %q", name).
+		Do(func() {
+			resErr = runtime.ContainerBackend.RemoveNetwork(ctx, name)
+		})
+	return
+}
+
 func (runtime *PerfCheckContainerBackend) String() string {
 	return runtime.ContainerBackend.String()
 }
@@ -0,0 +1,219 @@
+package container_backend
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type RunContainerOpts struct {
+	CommonOpts
+
+	Name string
+	// Entrypoint overrides the image entrypoint when not nil, the empty entrypoint resets the image one.
+	Entrypoint []string
+	// Command overrides the image command when not empty.
+	Command    []string
+	Envs       []string // {"KEY1=VALUE1", "KEY2=VALUE2", ...}
+	Volumes    []string // {"SOURCE:DESTINATION[:ro]", ...}
+	Ports      []string // {"[HOST_IP:]HOST_PORT:CONTAINER_PORT[/PROTOCOL]", ...}
+	WorkingDir string
+	User       string
+	Network    string
+	// NetworkAliases are the additional names of the container in the user-defined network.
+	NetworkAliases []string
+	Labels         []string // {"KEY1=VALUE1", "KEY2=VALUE2", ...}
+
+	Interactive bool
+	TTY         bool
+	Remove      bool
+	Detach      bool
+}
+
+// ParseDockerRunOptions parses the subset of docker run options which is supported by all container backends.
+func ParseDockerRunOptions(args []string) (RunContainerOpts, error) {
+	var opts RunContainerOpts
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		name, value, hasValue := strings.Cut(arg, "=")
+		nextValue := func() (string, error) {
+			if hasValue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("option %q requires a value", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "-d", "--detach":
+			opts.Detach = true
+		case "--rm":
+			opts.Remove = true
+		case "-i", "--interactive":
+			opts.Interactive = true
+		case "-t", "--tty":
+			opts.TTY = true
+		case "-it", "-ti":
+			opts.Interactive = true
+			opts.TTY = true
+		case "--name", "-e", "--env", "-v", "--volume", "-p", "--publish", "-w", "--workdir", "-u", "--user", "--entrypoint", "--network", "--net", "--platform":
+			v, err := nextValue()
+			if err != nil {
+				return RunContainerOpts{}, err
+			}
+
+			switch name {
+			case "--name":
+				opts.Name = v
+			case "-e", "--env":
+				opts.Envs = append(opts.Envs, v)
+			case "-v", "--volume":
+				opts.Volumes = append(opts.Volumes, v)
+			case "-p", "--publish":
+				opts.Ports = append(opts.Ports, v)
+			case "-w", "--workdir":
+				opts.WorkingDir = v
+			case "-u", "--user":
+				opts.User = v
+			case "--entrypoint":
+				opts.Entrypoint = []string{}
+				if v != "" {
+					opts.Entrypoint = []string{v}
+				}
+			case "--network", "--net":
+				opts.Network = v
+			case "--platform":
+				opts.TargetPlatform = v
+			}
+		default:
+			return RunContainerOpts{}, fmt.Errorf("unsupported docker run option %q", arg)
+		}
+	}
+
+	return opts, nil
+}
+
+// DockerRunArgs returns the docker run arguments to run the container for the image.
+func (opts RunContainerOpts) DockerRunArgs(ref string) []string {
+	var args []string
+
+	if opts.Detach {
+		args = append(args, "--detach")
+	}
+	if opts.Remove {
+		args = append(args, "--rm")
+	}
+	if opts.Interactive {
+		args = append(args, "--interactive")
+	}
+	if opts.TTY {
+		args = append(args, "--tty")
+	}
+	if opts.Name != "" {
+		args = append(args, "--name", opts.Name)
+	}
+	if opts.TargetPlatform != "" {
+		args = append(args, "--platform", opts.TargetPlatform)
+	}
+	for _, env := range opts.Envs {
+		args = append(args, "--env", env)
+	}
+	for _, volume := range opts.Volumes {
+		args = append(args, "--volume", volume)
+	}
+	for _, port := range opts.Ports {
+		args = append(args, "--publish", port)
+	}
+	if opts.WorkingDir != "" {
+		args = append(args, "--workdir", opts.WorkingDir)
+	}
+	if opts.User != "" {
+		args = append(args, "--user", opts.User)
+	}
+	if opts.Network != "" {
+		args = append(args, "--network", opts.Network)
+	}
+	for _, alias := range opts.NetworkAliases {
+		args = append(args, "--network-alias", alias)
+	}
+	for _, label := range opts.Labels {
+		args = append(args, "--label", label)
+	}
+
+	var command []string
+	if opts.Entrypoint != nil {
+		// Docker supports only the executable in the --entrypoint option, the rest is passed as the command.
+		entrypoint := ""
+		if len(opts.Entrypoint) > 0 {
+			entrypoint = opts.Entrypoint[0]
+			command = append(command, opts.Entrypoint[1:]...)
+		}
+		args = append(args, fmt.Sprintf("--entrypoint=%s", entrypoint))
+	}
+	command = append(command, opts.Command...)
+
+	args = append(args, ref)
+	return append(args, command...)
+}
+
+type publishedPort struct {
+	HostIP        string
+	HostPort      int
+	ContainerPort int
+	Protocol      string
+}
+
+func parsePublishedPort(port string) (publishedPort, error) {
+	var res publishedPort
+
+	spec, protocol, hasProtocol := strings.Cut(port, "/")
+	res.Protocol = "tcp"
+	if hasProtocol {
+		res.Protocol = protocol
+	}
+
+	parts := strings.Split(spec, ":")
+	var hostPort, containerPort string
+	switch len(parts) {
+	case 1:
+		hostPort, containerPort = parts[0], parts[0]
+	case 2:
+		hostPort, containerPort = parts[0], parts[1]
+	case 3:
+		res.HostIP, hostPort, containerPort = parts[0], parts[1], parts[2]
+	default:
+		return publishedPort{}, fmt.Errorf("expected [HOST_IP:]HOST_PORT:CONTAINER_PORT[/PROTOCOL] format")
+	}
+
+	var err error
+	if res.ContainerPort, err = strconv.Atoi(containerPort); err != nil {
+		return publishedPort{}, fmt.Errorf("invalid container port %q", containerPort)
+	}
+	if hostPort == "" {
+		res.HostPort = res.ContainerPort
+	} else if res.HostPort, err = strconv.Atoi(hostPort); err != nil {
+		return publishedPort{}, fmt.Errorf("invalid host port %q", hostPort)
+	}
+
+	return res, nil
+}
+
+// imageRunCommand combines the image entrypoint and command the way docker run does.
+func imageRunCommand(imageEntrypoint, imageCommand []string, opts RunContainerOpts) []string {
+	entrypoint := imageEntrypoint
+	command := imageCommand
+	if opts.Entrypoint != nil {
+		entrypoint = opts.Entrypoint
+		command = nil
+	}
+	if len(opts.Command) > 0 {
+		command = opts.Command
+	}
+
+	return append(append([]string{}, entrypoint...), command...)
+}
@@ -0,0 +1,79 @@
+package container_backend
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RunContainerOpts", func() {
+	It("should parse the common subset of docker run options", func() {
+		opts, err := ParseDockerRunOptions([]string{"-ti", "--rm", "--entrypoint=/bin/sh", "--name", "app", "-e", "A=1", "--env=B=2", "-v", "/tmp:/data:ro", "-p", "8080:8080", "-w", "/app", "--user=1000"})
+		Expect(err).To(Succeed())
+
+		Expect(opts.Interactive).To(BeTrue())
+		Expect(opts.TTY).To(BeTrue())
+		Expect(opts.Remove).To(BeTrue())
+		Expect(opts.Entrypoint).To(Equal([]string{"/bin/sh"}))
+		Expect(opts.Name).To(Equal("app"))
+		Expect(opts.Envs).To(Equal([]string{"A=1", "B=2"}))
+		Expect(opts.Volumes).To(Equal([]string{"/tmp:/data:ro"}))
+		Expect(opts.Ports).To(Equal([]string{"8080:8080"}))
+		Expect(opts.WorkingDir).To(Equal("/app"))
+		Expect(opts.User).To(Equal("1000"))
+	})
+
+	It("should fail on unsupported and incomplete options", func() {
+		_, err := ParseDockerRunOptions([]string{"--restart=always"})
+		Expect(err).To(HaveOccurred())
+
+		_, err = ParseDockerRunOptions([]string{"--name"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should generate docker run args", func() {
+		opts := RunContainerOpts{
+			Remove:     true,
+			Entrypoint: []string{"/bin/sh", "-c"},
+			Command:    []string{"echo ok"},
+			Envs:       []string{"A=1"},
+			Ports:      []string{"80:8080"},
+		}
+
+		Expect(opts.DockerRunArgs("image:tag")).To(Equal([]string{
+			"--rm", "--env", "A=1", "--publish", "80:8080", "--entrypoint=/bin/sh", "image:tag", "-c", "echo ok",
+		}))
+	})
+
+	It("should generate docker run args for the detached container in the network", func() {
+		opts := RunContainerOpts{
+			Name:           "project-app",
+			Detach:         true,
+			Network:        "project-dev",
+			NetworkAliases: []string{"app"},
+			Labels:         []string{"key=value"},
+		}
+
+		Expect(opts.DockerRunArgs("image:tag")).To(Equal([]string{
+			"--detach", "--name", "project-app", "--network", "project-dev", "--network-alias", "app", "--label", "key=value", "image:tag",
+		}))
+	})
+
+	DescribeTable("should combine the image entrypoint and command",
+		func(opts RunContainerOpts, expected []string) {
+			Expect(imageRunCommand([]string{"/entrypoint.sh"}, []string{"serve"}, opts)).To(Equal(expected))
+		},
+		Entry("image defaults", RunContainerOpts{}, []string{"/entrypoint.sh", "serve"}),
+		Entry("command override", RunContainerOpts{Command: []string{"migrate"}}, []string{"/entrypoint.sh", "migrate"}),
+		Entry("entrypoint override resets image command", RunContainerOpts{Entrypoint: []string{"/bin/sh"}}, []string{"/bin/sh"}),
+		Entry("empty entrypoint", RunContainerOpts{Entrypoint: []string{}, Command: []string{"ls"}}, []string{"ls"}),
+	)
+
+	DescribeTable("should parse published ports",
+		func(port string, expected publishedPort) {
+			Expect(parsePublishedPort(port)).To(Equal(expected))
+		},
+		Entry("same port", "8080", publishedPort{HostPort: 8080, ContainerPort: 8080, Protocol: "tcp"}),
+		Entry("host and container port", "80:8080/udp", publishedPort{HostPort: 80, ContainerPort: 8080, Protocol: "udp"}),
+		Entry("host ip", "127.0.0.1:80:80", publishedPort{HostIP: "127.0.0.1", HostPort: 80, ContainerPort: 80, Protocol: "tcp"}),
+	)
+})
@@ -18,6 +18,7 @@ type DockerfileBuilderInterface interface {
 	AppendAddHost(addHost ...string)
 	SetNetwork(network string)
 	SetSSH(ssh string)
+	AppendSecrets(secrets ...container_backend.BuildSecret)
 	AppendLabels(labels ...string)
 	SetBuildContextArchive(buildContextArchive container_backend.BuildContextArchiver)
 }
@@ -102,6 +103,10 @@ func (b *DockerfileBuilder) SetSSH(ssh string) {
 	b.BuildDockerfileOptions.SSH = ssh
 }
 
+func (b *DockerfileBuilder) AppendSecrets(secrets ...container_backend.BuildSecret) {
+	b.BuildDockerfileOptions.Secrets = append(b.BuildDockerfileOptions.Secrets, secrets...)
+}
+
 func (b *DockerfileBuilder) AppendLabels(labels ...string) {
 	b.BuildDockerfileOptions.Labels = append(b.BuildDockerfileOptions.Labels, labels...)
 }
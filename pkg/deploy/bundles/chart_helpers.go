@@ -6,6 +6,9 @@ import (
 	"compress/gzip"
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
@@ -57,3 +60,73 @@ func SaveChartValues(ctx context.Context, ch *chart.Chart) error {
 
 	return nil
 }
+
+// GetBundleImages returns references of the werf images (.Values.werf.image) saved into the bundle values.
+func GetBundleImages(bundleDir string) ([]string, error) {
+	vals, err := chartutil.ReadValuesFile(filepath.Join(bundleDir, chartutil.ValuesfileName))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read bundle values: %w", err)
+	}
+
+	werfVals, err := vals.Table("werf")
+	if err != nil {
+		return nil, nil
+	}
+
+	var res []string
+	if images, err := werfVals.Table("image"); err == nil {
+		for _, ref := range images {
+			if refStr, ok := ref.(string); ok && refStr != "" {
+				res = append(res, refStr)
+			}
+		}
+	}
+	if ref, ok := werfVals["nameless_image"].(string); ok && ref != "" {
+		res = append(res, ref)
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// PinBundleImages replaces references of the werf images in the bundle values with the pinned ones (REPO:TAG@DIGEST).
+func PinBundleImages(bundleDir string, pinnedReferences map[string]string) error {
+	if len(pinnedReferences) == 0 {
+		return nil
+	}
+
+	valuesPath := filepath.Join(bundleDir, chartutil.ValuesfileName)
+	vals, err := chartutil.ReadValuesFile(valuesPath)
+	if err != nil {
+		return fmt.Errorf("unable to read bundle values: %w", err)
+	}
+
+	werfVals, err := vals.Table("werf")
+	if err != nil {
+		return nil
+	}
+
+	if images, err := werfVals.Table("image"); err == nil {
+		for name, ref := range images {
+			if pinnedRef, ok := pinnedReferences[fmt.Sprint(ref)]; ok {
+				images[name] = pinnedRef
+			}
+		}
+	}
+	if ref, ok := werfVals["nameless_image"].(string); ok {
+		if pinnedRef, ok := pinnedReferences[ref]; ok {
+			werfVals["nameless_image"] = pinnedRef
+		}
+	}
+
+	valuesRaw, err := vals.YAML()
+	if err != nil {
+		return fmt.Errorf("unable to marshal bundle values: %w", err)
+	}
+
+	if err := os.WriteFile(valuesPath, []byte(valuesRaw), 0o644); err != nil {
+		return fmt.Errorf("unable to write bundle values: %w", err)
+	}
+
+	return nil
+}
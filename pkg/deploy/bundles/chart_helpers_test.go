@@ -0,0 +1,53 @@
+package bundles
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetBundleImages", func() {
+	It("should return images from the bundle values", func() {
+		bundleDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(bundleDir, "values.yaml"), []byte(`
+werf:
+  image:
+    image-2: REPO:tag-2
+    image-1: REPO:tag-1
+  nameless_image: REPO:tag-3
+  repo: REPO
+`), 0o644)).To(Succeed())
+
+		Expect(GetBundleImages(bundleDir)).To(Equal([]string{"REPO:tag-1", "REPO:tag-2", "REPO:tag-3"}))
+	})
+
+	It("should return no images if the bundle has no werf values", func() {
+		bundleDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(bundleDir, "values.yaml"), []byte("replicas: 1\n"), 0o644)).To(Succeed())
+
+		Expect(GetBundleImages(bundleDir)).To(BeEmpty())
+	})
+})
+
+var _ = Describe("PinBundleImages", func() {
+	It("should replace images in the bundle values with the pinned references", func() {
+		bundleDir := GinkgoT().TempDir()
+		Expect(os.WriteFile(filepath.Join(bundleDir, "values.yaml"), []byte(`
+werf:
+  image:
+    image-1: REPO:tag-1
+    image-2: REPO:tag-2
+  nameless_image: REPO:tag-3
+  repo: REPO
+`), 0o644)).To(Succeed())
+
+		Expect(PinBundleImages(bundleDir, map[string]string{
+			"REPO:tag-1": "REPO:tag-1@sha256:1",
+			"REPO:tag-3": "REPO:tag-3@sha256:3",
+		})).To(Succeed())
+
+		Expect(GetBundleImages(bundleDir)).To(Equal([]string{"REPO:tag-1@sha256:1", "REPO:tag-2", "REPO:tag-3@sha256:3"}))
+	})
+})
@@ -69,7 +69,9 @@ func InitActionConfig(ctx context.Context, kubeInitializer KubeInitializer, rele
 
 	kubeClient := actionConfig.KubeClient.(*helm_kube.Client)
 	kubeClient.Namespace = namespace
-	kubeClient.ResourcesWaiter = NewResourcesWaiter(kubeInitializer, kubeClient, time.Now(), opts.StatusProgressPeriod, opts.HooksStatusProgressPeriod)
+	resourcesWaiter := NewResourcesWaiter(kubeInitializer, kubeClient, time.Now(), opts.StatusProgressPeriod, opts.HooksStatusProgressPeriod)
+	resourcesWaiter.TraceContext = ctx
+	kubeClient.ResourcesWaiter = resourcesWaiter
 	kubeClient.Extender = NewHelmKubeClientExtender()
 
 	actionConfig.Log = func(f string, a ...interface{}) {
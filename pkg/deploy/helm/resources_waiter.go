@@ -12,6 +12,7 @@ import (
 
 	flaggerv1beta1 "github.com/fluxcd/flagger/pkg/apis/flagger/v1beta1"
 	flaggerscheme "github.com/fluxcd/flagger/pkg/client/clientset/versioned/scheme"
+	"helm.sh/helm/v3/pkg/action"
 	helm_kube "helm.sh/helm/v3/pkg/kube"
 	appsv1 "k8s.io/api/apps/v1"
 	appsv1beta1 "k8s.io/api/apps/v1beta1"
@@ -32,6 +33,7 @@ import (
 	"github.com/werf/kubedog/pkg/trackers/rollout/multitrack"
 	"github.com/werf/kubedog/pkg/trackers/rollout/multitrack/generic"
 	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/tracing"
 )
 
 func init() {
@@ -44,6 +46,9 @@ type ResourcesWaiter struct {
 	LogsFromTime              time.Time
 	StatusProgressPeriod      time.Duration
 	HooksStatusProgressPeriod time.Duration
+
+	// TraceContext holds the parent span of the resources tracking, because helm calls the waiter with the context.Background().
+	TraceContext context.Context
 }
 
 func NewResourcesWaiter(kubeInitializer KubeInitializer, client *helm_kube.Client, logsFromTime time.Time, statusProgressPeriod, hooksStatusProgressPeriod time.Duration) *ResourcesWaiter {
@@ -56,6 +61,15 @@ func NewResourcesWaiter(kubeInitializer KubeInitializer, client *helm_kube.Clien
 	}
 }
 
+// SetResourcesWaiterTraceContext makes the resources tracking spans the children of the span from the context.
+func SetResourcesWaiterTraceContext(ctx context.Context, actionConfig *action.Configuration) {
+	if kubeClient, ok := actionConfig.KubeClient.(*helm_kube.Client); ok {
+		if waiter, ok := kubeClient.ResourcesWaiter.(*ResourcesWaiter); ok {
+			waiter.TraceContext = ctx
+		}
+	}
+}
+
 func extractSpecReplicas(specReplicas *int32) int {
 	if specReplicas != nil {
 		return int(*specReplicas)
@@ -63,11 +77,14 @@ func extractSpecReplicas(specReplicas *int32) int {
 	return 1
 }
 
-func (waiter *ResourcesWaiter) Wait(ctx context.Context, resources helm_kube.ResourceList, timeout time.Duration) error {
+func (waiter *ResourcesWaiter) Wait(ctx context.Context, resources helm_kube.ResourceList, timeout time.Duration) (err error) {
 	if os.Getenv("WERF_DISABLE_RESOURCES_WAITER") == "1" {
 		return nil
 	}
 
+	ctx, span := tracing.Start(tracing.ContextWithSpanFrom(ctx, waiter.TraceContext), "helm.stage.wait", tracing.ResourcesKey.Int(len(resources)))
+	defer func() { tracing.End(span, err) }()
+
 	if waiter.KubeInitializer != nil {
 		if err := waiter.KubeInitializer.Init(ctx); err != nil {
 			return fmt.Errorf("kube initializer failed: %w", err)
@@ -312,11 +329,14 @@ mainLoop:
 	return genericSpec, nil
 }
 
-func (waiter *ResourcesWaiter) WatchUntilReady(ctx context.Context, resources helm_kube.ResourceList, timeout time.Duration) error {
+func (waiter *ResourcesWaiter) WatchUntilReady(ctx context.Context, resources helm_kube.ResourceList, timeout time.Duration) (err error) {
 	if os.Getenv("WERF_DISABLE_RESOURCES_WAITER") == "1" {
 		return nil
 	}
 
+	ctx, span := tracing.Start(tracing.ContextWithSpanFrom(ctx, waiter.TraceContext), "helm.hooks.wait", tracing.ResourcesKey.Int(len(resources)))
+	defer func() { tracing.End(span, err) }()
+
 	if waiter.KubeInitializer != nil {
 		if err := waiter.KubeInitializer.Init(ctx); err != nil {
 			return fmt.Errorf("kube initializer failed: %w", err)
@@ -357,11 +377,14 @@ func asVersioned(info *resource.Info) runtime.Object {
 	return info.Object
 }
 
-func (waiter *ResourcesWaiter) WaitUntilDeleted(ctx context.Context, specs []*helm_kube.ResourcesWaiterDeleteResourceSpec, timeout time.Duration) error {
+func (waiter *ResourcesWaiter) WaitUntilDeleted(ctx context.Context, specs []*helm_kube.ResourcesWaiterDeleteResourceSpec, timeout time.Duration) (err error) {
 	if len(specs) == 0 {
 		return nil
 	}
 
+	ctx, span := tracing.Start(tracing.ContextWithSpanFrom(ctx, waiter.TraceContext), "helm.stage.wait_deleted", tracing.ResourcesKey.Int(len(specs)))
+	defer func() { tracing.End(span, err) }()
+
 	if waiter.KubeInitializer != nil {
 		if err := waiter.KubeInitializer.Init(ctx); err != nil {
 			return fmt.Errorf("kube initializer failed: %w", err)
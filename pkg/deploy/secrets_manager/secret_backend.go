@@ -0,0 +1,121 @@
+package secrets_manager
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/werf/werf/pkg/config"
+	"github.com/werf/werf/pkg/secret"
+)
+
+// GetVaultToken returns token for the vaultTransit secrets backend from WERF_SECRET_VAULT_TOKEN or VAULT_TOKEN environment variables.
+func GetVaultToken() string {
+	for _, envName := range []string{"WERF_SECRET_VAULT_TOKEN", "VAULT_TOKEN"} {
+		if token := os.Getenv(envName); token != "" {
+			return token
+		}
+	}
+
+	return ""
+}
+
+func isSecretKeyRequired(backendConfig config.MetaSecrets) bool {
+	return backendConfig.GetBackend() != config.MetaSecretsBackendVaultTransit
+}
+
+func newEncoder(backendConfig config.MetaSecrets, workingDir string) (secret.Encoder, error) {
+	switch backend := backendConfig.GetBackend(); backend {
+	case config.MetaSecretsBackendAes:
+		key, err := GetRequiredSecretKey(workingDir)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load secret key: %w", err)
+		}
+
+		enc, err := secret.NewAesEncoder(key)
+		if err != nil {
+			return nil, fmt.Errorf("check encryption key: %w", err)
+		}
+
+		return enc, nil
+
+	case config.MetaSecretsBackendX25519:
+		var recipients [][]byte
+		for _, recipient := range backendConfig.X25519Recipients {
+			recipients = append(recipients, []byte(recipient))
+		}
+
+		// Identity is not required when there are recipients to encrypt data for,
+		// the error about the missed key will be raised on decryption.
+		identity, err := GetRequiredSecretKey(workingDir)
+		if err != nil {
+			if _, missedKey := err.(*EncryptionKeyRequiredError); !missedKey || len(recipients) == 0 {
+				return nil, fmt.Errorf("unable to load secret identity: %w", err)
+			}
+		}
+
+		enc, err := secret.NewX25519Encoder(identity, recipients)
+		if err != nil {
+			return nil, fmt.Errorf("check x25519 identity and recipients: %w", err)
+		}
+
+		return enc, nil
+
+	case config.MetaSecretsBackendVaultTransit:
+		if backendConfig.VaultTransit == nil {
+			return nil, fmt.Errorf("vaultTransit secrets backend settings required")
+		}
+
+		enc, err := secret.NewVaultTransitEncoder(secret.VaultTransitEncoderOptions{
+			Address:   backendConfig.VaultTransit.Address,
+			MountPath: backendConfig.VaultTransit.MountPath,
+			KeyName:   backendConfig.VaultTransit.KeyName,
+			Token:     GetVaultToken(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("check vaultTransit secrets backend settings: %w", err)
+		}
+
+		return enc, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported secrets backend %q", backend)
+	}
+}
+
+func newRecipientEncoder(backendConfig config.MetaSecrets, recipientKey []byte) (secret.Encoder, string, error) {
+	switch backend := backendConfig.GetBackend(); backend {
+	case config.MetaSecretsBackendAes:
+		enc, err := secret.NewAesEncoder(recipientKey)
+		if err != nil {
+			return nil, "", fmt.Errorf("check recipient key: %w", err)
+		}
+
+		return enc, secret.YamlEnvelopeRecipientID(recipientKey), nil
+
+	case config.MetaSecretsBackendX25519:
+		enc, err := secret.NewX25519Encoder(nil, [][]byte{recipientKey})
+		if err != nil {
+			return nil, "", fmt.Errorf("check recipient key: %w", err)
+		}
+
+		return enc, secret.YamlEnvelopeRecipientID(recipientKey), nil
+
+	default:
+		return nil, "", fmt.Errorf("secret values recipients are not supported by the %q secrets backend", backend)
+	}
+}
+
+func newOwnRecipientEncoder(backendConfig config.MetaSecrets, workingDir string) (secret.Encoder, string, error) {
+	key, err := GetRequiredSecretKey(workingDir)
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to load secret key: %w", err)
+	}
+
+	if backendConfig.GetBackend() == config.MetaSecretsBackendX25519 {
+		if key, err = secret.X25519RecipientFromIdentity(key); err != nil {
+			return nil, "", fmt.Errorf("check x25519 identity: %w", err)
+		}
+	}
+
+	return newRecipientEncoder(backendConfig, key)
+}
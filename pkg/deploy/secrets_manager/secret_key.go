@@ -16,6 +16,11 @@ func GenerateSecretKey() ([]byte, error) {
 	return secret.GenerateAesSecretKey()
 }
 
+// GenerateX25519SecretKey returns new identity (secret key) and recipient (public key) for the x25519 secrets backend.
+func GenerateX25519SecretKey() ([]byte, []byte, error) {
+	return secret.GenerateX25519Identity()
+}
+
 func GetRequiredOldSecretKey() ([]byte, error) {
 	secretKey := []byte(os.Getenv("WERF_OLD_SECRET_KEY"))
 	if len(secretKey) == 0 {
@@ -24,6 +29,14 @@ func GetRequiredOldSecretKey() ([]byte, error) {
 	return secretKey, nil
 }
 
+func GetRequiredRecipientSecretKey() ([]byte, error) {
+	secretKey := []byte(os.Getenv("WERF_RECIPIENT_SECRET_KEY"))
+	if len(secretKey) == 0 {
+		return nil, fmt.Errorf("WERF_RECIPIENT_SECRET_KEY environment required")
+	}
+	return secretKey, nil
+}
+
 func GetRequiredSecretKey(workingDir string) ([]byte, error) {
 	var secretKey []byte
 	var werfSecretKeyPaths []string
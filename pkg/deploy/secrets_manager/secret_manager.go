@@ -5,22 +5,26 @@ import (
 	"fmt"
 
 	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/config"
 	"github.com/werf/werf/pkg/secret"
 )
 
 type SecretsManager struct {
 	DisableSecretsDecryption bool
+	BackendConfig            config.MetaSecrets
 
 	missedSecretKeyModeEnabled bool
 }
 
 type SecretsManagerOptions struct {
 	DisableSecretsDecryption bool
+	BackendConfig            config.MetaSecrets
 }
 
 func NewSecretsManager(opts SecretsManagerOptions) *SecretsManager {
 	return &SecretsManager{
 		DisableSecretsDecryption: opts.DisableSecretsDecryption,
+		BackendConfig:            opts.BackendConfig,
 	}
 }
 
@@ -29,6 +33,10 @@ func (manager *SecretsManager) IsMissedSecretKeyModeEnabled() bool {
 }
 
 func (manager *SecretsManager) AllowMissedSecretKeyMode(workingDir string) error {
+	if !isSecretKeyRequired(manager.BackendConfig) {
+		return nil
+	}
+
 	_, err := GetRequiredSecretKey(workingDir)
 	if err != nil {
 		if _, missedKey := err.(*EncryptionKeyRequiredError); missedKey {
@@ -50,10 +58,8 @@ func (manager *SecretsManager) GetYamlEncoder(ctx context.Context, workingDir st
 		return secret.NewYamlEncoder(nil), nil
 	}
 
-	if key, err := GetRequiredSecretKey(workingDir); err != nil {
-		return nil, fmt.Errorf("unable to load secret key: %w", err)
-	} else if enc, err := secret.NewAesEncoder(key); err != nil {
-		return nil, fmt.Errorf("check encryption key: %w", err)
+	if enc, err := newEncoder(manager.BackendConfig, workingDir); err != nil {
+		return nil, err
 	} else {
 		return secret.NewYamlEncoder(enc), nil
 	}
@@ -68,3 +74,13 @@ func (manager *SecretsManager) GetYamlEncoderForOldKey(ctx context.Context) (*se
 		return secret.NewYamlEncoder(enc), nil
 	}
 }
+
+// GetRecipientEncoder returns encoder to wrap the data key of the secret values envelope for the recipient key and the ID of the recipient.
+func (manager *SecretsManager) GetRecipientEncoder(recipientKey []byte) (secret.Encoder, string, error) {
+	return newRecipientEncoder(manager.BackendConfig, recipientKey)
+}
+
+// GetOwnRecipientEncoder returns encoder to wrap the data key of the secret values envelope for the current secret key and the ID of the recipient.
+func (manager *SecretsManager) GetOwnRecipientEncoder(workingDir string) (secret.Encoder, string, error) {
+	return newOwnRecipientEncoder(manager.BackendConfig, workingDir)
+}
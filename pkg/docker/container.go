@@ -28,6 +28,10 @@ func ContainerAttach(ctx context.Context, ref string, options types.ContainerAtt
 	return apiCli(ctx).ContainerAttach(ctx, ref, options)
 }
 
+func ContainerLogs(ctx context.Context, ref string, options types.ContainerLogsOptions) (io.ReadCloser, error) {
+	return apiCli(ctx).ContainerLogs(ctx, ref, options)
+}
+
 func ContainerInspect(ctx context.Context, ref string) (types.ContainerJSON, error) {
 	return apiCli(ctx).ContainerInspect(ctx, ref)
 }
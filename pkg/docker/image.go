@@ -13,6 +13,7 @@ import (
 	"github.com/docker/cli/cli/streams"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/jsonmessage"
 	"github.com/spf13/cobra"
 	"golang.org/x/net/context"
 
@@ -56,6 +57,20 @@ func ImageExist(ctx context.Context, ref string) (bool, error) {
 	return true, nil
 }
 
+func ImageSave(ctx context.Context, refs ...string) (io.ReadCloser, error) {
+	return apiCli(ctx).ImageSave(ctx, refs)
+}
+
+func ImageLoad(ctx context.Context, input io.Reader) error {
+	resp, err := apiCli(ctx).ImageLoad(ctx, input, true)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return jsonmessage.DisplayJSONMessagesStream(resp.Body, io.Discard, 0, false, nil)
+}
+
 func ImageInspect(ctx context.Context, ref string) (*types.ImageInspect, error) {
 	inspect, _, err := apiCli(ctx).ImageInspectWithRaw(ctx, ref)
 	if err != nil {
@@ -0,0 +1,31 @@
+package docker
+
+import (
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"golang.org/x/net/context"
+)
+
+func NetworkCreate(ctx context.Context, name string, options types.NetworkCreate) error {
+	_, err := apiCli(ctx).NetworkCreate(ctx, name, options)
+	return err
+}
+
+func NetworkRemove(ctx context.Context, name string) error {
+	return apiCli(ctx).NetworkRemove(ctx, name)
+}
+
+func NetworkExist(ctx context.Context, name string) (bool, error) {
+	networks, err := apiCli(ctx).NetworkList(ctx, types.NetworkListOptions{Filters: filters.NewArgs(filters.Arg("name", name))})
+	if err != nil {
+		return false, err
+	}
+
+	// The name filter matches the substring of the network name.
+	for _, network := range networks {
+		if network.Name == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/docker_registry/container_registry_extensions"
 	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/tracing"
 )
 
 type api struct {
@@ -532,7 +533,7 @@ func (api *api) getHttpTransport() (transport http.RoundTripper) {
 		transport = newTransport
 	}
 
-	return
+	return tracing.NewTransport(newRateLimitTransport(transport))
 }
 
 type referenceParts struct {
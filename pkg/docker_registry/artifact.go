@@ -0,0 +1,108 @@
+package docker_registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+)
+
+// GetDescriptor returns the descriptor of the image or image index manifest by the reference.
+func (api *api) GetDescriptor(ctx context.Context, reference string) (*v1.Descriptor, error) {
+	ref, err := name.ParseReference(reference, api.parseReferenceOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	desc, err := remote.Head(ref, api.defaultRemoteOptions(ctx)...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get descriptor of %q: %w", reference, err)
+	}
+
+	return desc, nil
+}
+
+// PushArtifact pushes the artifact manifest (signature, attestation, SBOM, etc.) by the reference.
+// If the artifact manifest has the subject, the registry referrers API or the referrers tag schema is updated to reference the artifact.
+func (api *api) PushArtifact(ctx context.Context, reference string, artifact v1.Image) error {
+	ref, err := name.ParseReference(reference, api.parseReferenceOptions()...)
+	if err != nil {
+		return fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	if err := api.writeToRemote(ctx, ref, artifact); err != nil {
+		return fmt.Errorf("unable to write artifact %q: %w", reference, err)
+	}
+
+	return nil
+}
+
+// TryGetArtifact returns the artifact manifest by the reference or nil if the artifact does not exist.
+func (api *api) TryGetArtifact(ctx context.Context, reference string) (v1.Image, error) {
+	ref, err := name.ParseReference(reference, api.parseReferenceOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	img, err := remote.Image(ref, api.defaultRemoteOptions(ctx)...)
+	if err != nil {
+		if IsStatusNotFoundErr(err) || IsImageNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get artifact %q: %w", reference, err)
+	}
+
+	return img, nil
+}
+
+// GetReferrers returns descriptors of the artifacts referencing the image manifest with the specified digest reference (REPO@sha256:...).
+// The result is filtered by the artifact type if specified.
+func (api *api) GetReferrers(ctx context.Context, digestReference, artifactType string) ([]v1.Descriptor, error) {
+	digest, err := name.NewDigest(digestReference, api.parseReferenceOptions()...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse digest reference %q: %w", digestReference, err)
+	}
+
+	options := api.defaultRemoteOptions(ctx)
+	if artifactType != "" {
+		options = append(options, remote.WithFilter("artifactType", artifactType))
+	}
+
+	manifest, err := remote.Referrers(digest, options...)
+	if err != nil {
+		// The registry does not support the referrers API and the referrers tag has not been created yet.
+		if IsStatusNotFoundErr(err) || IsImageNotFoundError(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to get referrers of %q: %w", digestReference, err)
+	}
+
+	var res []v1.Descriptor
+	for _, desc := range manifest.Manifests {
+		// The filter might not be applied by the registry.
+		if artifactType != "" && desc.ArtifactType != artifactType {
+			continue
+		}
+		res = append(res, desc)
+	}
+
+	return res, nil
+}
+
+func (api *genericApi) GetDescriptor(ctx context.Context, reference string) (*v1.Descriptor, error) {
+	return api.commonApi.GetDescriptor(ctx, reference)
+}
+
+func (api *genericApi) PushArtifact(ctx context.Context, reference string, artifact v1.Image) error {
+	return api.commonApi.PushArtifact(ctx, reference, artifact)
+}
+
+func (api *genericApi) TryGetArtifact(ctx context.Context, reference string) (v1.Image, error) {
+	return api.commonApi.TryGetArtifact(ctx, reference)
+}
+
+func (api *genericApi) GetReferrers(ctx context.Context, digestReference, artifactType string) ([]v1.Descriptor, error) {
+	return api.commonApi.GetReferrers(ctx, digestReference, artifactType)
+}
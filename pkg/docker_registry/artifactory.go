@@ -0,0 +1,111 @@
+package docker_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+const (
+	ArtifactoryImplementationName = "artifactory"
+
+	artifactoryUnauthorizedErrPrefix = "artifactory unauthorized: "
+)
+
+var artifactoryPatterns = []string{"^.*\\.jfrog\\.io", "^artifactory\\..*"}
+
+type ArtifactoryUnauthorizedErr apiError
+
+func NewArtifactoryUnauthorizedErr(err error) ArtifactoryUnauthorizedErr {
+	return ArtifactoryUnauthorizedErr{
+		error: errors.New(artifactoryUnauthorizedErrPrefix + err.Error()),
+	}
+}
+
+func IsArtifactoryUnauthorizedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), artifactoryUnauthorizedErrPrefix)
+}
+
+type artifactory struct {
+	*defaultImplementation
+	artifactoryApi
+	artifactoryCredentials
+}
+
+type artifactoryOptions struct {
+	defaultImplementationOptions
+	artifactoryCredentials
+}
+
+type artifactoryCredentials struct {
+	username string
+	// password can also be an API key or an identity token.
+	password string
+}
+
+func newArtifactory(options artifactoryOptions) (*artifactory, error) {
+	d, err := newDefaultAPIForImplementation(ArtifactoryImplementationName, options.defaultImplementationOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	artifactory := &artifactory{
+		defaultImplementation:  d,
+		artifactoryApi:         newArtifactoryApi(),
+		artifactoryCredentials: options.artifactoryCredentials,
+	}
+
+	return artifactory, nil
+}
+
+// DeleteRepoImage removes the tag folder from the docker repository with the Artifactory REST API.
+// Only the repository path access method is supported (<registry>/<repository key>/<image>).
+func (r *artifactory) DeleteRepoImage(ctx context.Context, repoImage *image.Info) error {
+	hostname, repositoryKey, imagePath, err := r.parseReference(repoImage.Repository)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.artifactoryApi.deleteItem(ctx, hostname, repositoryKey, imagePath+"/"+repoImage.Tag, r.artifactoryCredentials.username, r.artifactoryCredentials.password)
+	if err != nil {
+		return r.handleFailedApiResponse(resp, err)
+	}
+
+	return nil
+}
+
+func (r *artifactory) handleFailedApiResponse(resp *http.Response, err error) error {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return NewArtifactoryUnauthorizedErr(err)
+		}
+	}
+
+	return err
+}
+
+func (r *artifactory) String() string {
+	return ArtifactoryImplementationName
+}
+
+// parseReference returns the registry hostname, the Artifactory repository key and the image path inside the repository.
+func (r *artifactory) parseReference(reference string) (string, string, string, error) {
+	parsedReference, err := name.NewRepository(reference)
+	if err != nil {
+		return "", "", "", fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	parts := strings.SplitN(parsedReference.RepositoryStr(), "/", 2)
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("unexpected reference %s: expected <registry>/<repository key>/<image>", reference)
+	}
+
+	return parsedReference.RegistryStr(), parts[0], parts[1], nil
+}
@@ -0,0 +1,37 @@
+package docker_registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+)
+
+type artifactoryApi struct{}
+
+func newArtifactoryApi() artifactoryApi {
+	return artifactoryApi{}
+}
+
+func (api *artifactoryApi) deleteItem(ctx context.Context, hostname, repositoryKey, itemPath, username, password string) (*http.Response, error) {
+	url := artifactoryItemURL(hostname, repositoryKey, itemPath)
+	resp, _, err := doRequest(ctx, http.MethodDelete, url, nil, doRequestOptions{
+		BasicAuth: doRequestBasicAuth{
+			username: username,
+			password: password,
+		},
+		AcceptedCodes: []int{http.StatusNoContent, http.StatusOK},
+	})
+
+	return resp, err
+}
+
+func artifactoryItemURL(hostname, repositoryKey, itemPath string) string {
+	var escapedParts []string
+	for _, part := range strings.Split(itemPath, "/") {
+		escapedParts = append(escapedParts, neturl.PathEscape(part))
+	}
+
+	return fmt.Sprintf("https://%s/artifactory/%s/%s", hostname, neturl.PathEscape(repositoryKey), strings.Join(escapedParts, "/"))
+}
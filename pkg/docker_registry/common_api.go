@@ -12,8 +12,11 @@ import (
 	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
 
 	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/tracing"
 )
 
+var rateLimitHttpClient = &http.Client{Transport: tracing.NewTransport(newRateLimitTransport(http.DefaultTransport))}
+
 type apiError struct {
 	error
 }
@@ -44,7 +47,7 @@ func doRequest(ctx context.Context, method, url string, body io.Reader, options
 	}
 
 	logboek.Context(ctx).Debug().LogF("--> %s %s\n", method, url)
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := rateLimitHttpClient.Do(req)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -142,3 +142,31 @@ func (r *DockerRegistryTracer) GetRepoImageConfigFile(ctx context.Context, refer
 	})
 	return
 }
+
+func (r *DockerRegistryTracer) GetDescriptor(ctx context.Context, reference string) (res *v1.Descriptor, err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.GetDescriptor %q", reference).Do(func() {
+		res, err = r.DockerRegistryApi.GetDescriptor(ctx, reference)
+	})
+	return
+}
+
+func (r *DockerRegistryTracer) PushArtifact(ctx context.Context, reference string, artifact v1.Image) (err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.PushArtifact %q", reference).Do(func() {
+		err = r.DockerRegistryApi.PushArtifact(ctx, reference, artifact)
+	})
+	return
+}
+
+func (r *DockerRegistryTracer) TryGetArtifact(ctx context.Context, reference string) (res v1.Image, err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.TryGetArtifact %q", reference).Do(func() {
+		res, err = r.DockerRegistryApi.TryGetArtifact(ctx, reference)
+	})
+	return
+}
+
+func (r *DockerRegistryTracer) GetReferrers(ctx context.Context, digestReference, artifactType string) (res []v1.Descriptor, err error) {
+	logboek.Context(ctx).Default().LogProcess("DockerRegistryTracer.GetReferrers %q %q", digestReference, artifactType).Do(func() {
+		res, err = r.DockerRegistryApi.GetReferrers(ctx, digestReference, artifactType)
+	})
+	return
+}
@@ -12,12 +12,19 @@ import (
 type DockerRegistryOptions struct {
 	InsecureRegistry      bool
 	SkipTlsVerifyRegistry bool
+	ArtifactoryUsername   string
+	ArtifactoryPassword   string
 	DockerHubToken        string
 	DockerHubUsername     string
 	DockerHubPassword     string
 	GitHubToken           string
+	GiteaToken            string
 	HarborUsername        string
 	HarborPassword        string
+	NexusUsername         string
+	NexusPassword         string
+	NexusURL              string
+	NexusRepository       string
 	QuayToken             string
 	SelectelAccount       string
 	SelectelVPC           string
@@ -26,6 +33,16 @@ type DockerRegistryOptions struct {
 	SelectelPassword      string
 }
 
+func (o *DockerRegistryOptions) artifactoryOptions() artifactoryOptions {
+	return artifactoryOptions{
+		defaultImplementationOptions: o.defaultOptions(),
+		artifactoryCredentials: artifactoryCredentials{
+			username: o.ArtifactoryUsername,
+			password: o.ArtifactoryPassword,
+		},
+	}
+}
+
 func (o *DockerRegistryOptions) awsEcrOptions() awsEcrOptions {
 	return awsEcrOptions{
 		defaultImplementationOptions: o.defaultOptions(),
@@ -64,6 +81,15 @@ func (o *DockerRegistryOptions) gitHubPackagesOptions() gitHubPackagesOptions {
 	}
 }
 
+func (o *DockerRegistryOptions) giteaOptions() giteaOptions {
+	return giteaOptions{
+		defaultImplementationOptions: o.defaultOptions(),
+		giteaCredentials: giteaCredentials{
+			token: o.GiteaToken,
+		},
+	}
+}
+
 func (o *DockerRegistryOptions) gitLabRegistryOptions() gitLabRegistryOptions {
 	return gitLabRegistryOptions{
 		defaultImplementationOptions: o.defaultOptions(),
@@ -80,6 +106,18 @@ func (o *DockerRegistryOptions) harborOptions() harborOptions {
 	}
 }
 
+func (o *DockerRegistryOptions) nexusOptions() nexusOptions {
+	return nexusOptions{
+		defaultImplementationOptions: o.defaultOptions(),
+		nexusCredentials: nexusCredentials{
+			username:   o.NexusUsername,
+			password:   o.NexusPassword,
+			url:        o.NexusURL,
+			repository: o.NexusRepository,
+		},
+	}
+}
+
 func (o *DockerRegistryOptions) quayOptions() quayOptions {
 	return quayOptions{
 		defaultImplementationOptions: o.defaultOptions(),
@@ -121,6 +159,7 @@ func NewDockerRegistry(repositoryAddress, implementation string, options DockerR
 		res = NewDockerRegistryTracer(res, nil)
 	}
 
+	res = newDockerRegistryWithRateLimit(res)
 	res = newDockerRegistryWithCache(res)
 	return res, nil
 }
@@ -131,6 +170,8 @@ func newDockerRegistry(repositoryAddress, implementation string, options DockerR
 	}
 
 	switch implementation {
+	case ArtifactoryImplementationName:
+		return newArtifactory(options.artifactoryOptions())
 	case AwsEcrImplementationName:
 		return newAwsEcr(options.awsEcrOptions())
 	case AzureCrImplementationName:
@@ -141,10 +182,14 @@ func newDockerRegistry(repositoryAddress, implementation string, options DockerR
 		return newGcr(options.gcrOptions())
 	case GitHubPackagesImplementationName:
 		return newGitHubPackages(options.gitHubPackagesOptions())
+	case GiteaImplementationName:
+		return newGitea(options.giteaOptions())
 	case GitLabRegistryImplementationName:
 		return newGitLabRegistry(options.gitLabRegistryOptions())
 	case HarborImplementationName:
 		return newHarbor(options.harborOptions())
+	case NexusImplementationName:
+		return newNexus(options.nexusOptions())
 	case QuayImplementationName:
 		return newQuay(options.quayOptions())
 	case SelectelImplementationName:
@@ -204,6 +249,10 @@ func detectImplementation(accountOrRepositoryAddress string) (string, error) {
 		name     string
 		patterns []string
 	}{
+		{
+			name:     ArtifactoryImplementationName,
+			patterns: artifactoryPatterns,
+		},
 		{
 			name:     AwsEcrImplementationName,
 			patterns: awsEcrPatterns,
@@ -224,6 +273,10 @@ func detectImplementation(accountOrRepositoryAddress string) (string, error) {
 			name:     GitHubPackagesImplementationName,
 			patterns: gitHubPackagesPatterns,
 		},
+		{
+			name:     GiteaImplementationName,
+			patterns: giteaPatterns,
+		},
 		{
 			name:     GitLabRegistryImplementationName,
 			patterns: gitlabPatterns,
@@ -232,6 +285,10 @@ func detectImplementation(accountOrRepositoryAddress string) (string, error) {
 			name:     HarborImplementationName,
 			patterns: harborPatterns,
 		},
+		{
+			name:     NexusImplementationName,
+			patterns: nexusPatterns,
+		},
 		{
 			name:     QuayImplementationName,
 			patterns: quayPatterns,
@@ -258,14 +315,17 @@ func detectImplementation(accountOrRepositoryAddress string) (string, error) {
 
 func ImplementationList() []string {
 	return []string{
+		ArtifactoryImplementationName,
 		AwsEcrImplementationName,
 		AzureCrImplementationName,
 		DefaultImplementationName,
 		DockerHubImplementationName,
 		GcrImplementationName,
 		GitHubPackagesImplementationName,
+		GiteaImplementationName,
 		GitLabRegistryImplementationName,
 		HarborImplementationName,
+		NexusImplementationName,
 		QuayImplementationName,
 		SelectelImplementationName,
 	}
@@ -16,6 +16,14 @@ var _ = DescribeTable("ResolveImplementation", func(entry ResolveImplementationE
 
 	Ω(resolvedImplementation).Should(Equal(entry.expectation))
 },
+	Entry("artifactory", ResolveImplementationEntry{
+		imagesRepoAddress: "company.jfrog.io/docker-local/repo",
+		expectation:       "artifactory",
+	}),
+	Entry("artifactory (self-hosted)", ResolveImplementationEntry{
+		imagesRepoAddress: "artifactory.company.com/docker-local/repo",
+		expectation:       "artifactory",
+	}),
 	Entry("ecr", ResolveImplementationEntry{
 		imagesRepoAddress: "123456789012.dkr.ecr.test.amazonaws.com/repo",
 		expectation:       "ecr",
@@ -40,10 +48,26 @@ var _ = DescribeTable("ResolveImplementation", func(entry ResolveImplementationE
 		imagesRepoAddress: "ghcr.io/package",
 		expectation:       "github",
 	}),
+	Entry("gitea", ResolveImplementationEntry{
+		imagesRepoAddress: "gitea.company.com/owner/repo",
+		expectation:       "gitea",
+	}),
+	Entry("forgejo", ResolveImplementationEntry{
+		imagesRepoAddress: "forgejo.company.com/owner/repo",
+		expectation:       "gitea",
+	}),
+	Entry("codeberg", ResolveImplementationEntry{
+		imagesRepoAddress: "codeberg.org/owner/repo",
+		expectation:       "gitea",
+	}),
 	Entry("harbor", ResolveImplementationEntry{
 		imagesRepoAddress: "harbor.company.com/project/repo",
 		expectation:       "harbor",
 	}),
+	Entry("nexus", ResolveImplementationEntry{
+		imagesRepoAddress: "nexus.company.com:8082/repo",
+		expectation:       "nexus",
+	}),
 	Entry("quay", ResolveImplementationEntry{
 		imagesRepoAddress: "quay.io/account/repo",
 		expectation:       "quay",
@@ -0,0 +1,122 @@
+package docker_registry
+
+import (
+	"context"
+	"io"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+// DockerRegistryWithRateLimit retries the operations rejected by the registry due to the rate limit.
+// Requests are throttled and retried on the transport level (see rateLimitTransport),
+// this layer handles the rest: vendor API and SDK calls and the operations that cannot be replayed request by request.
+type DockerRegistryWithRateLimit struct {
+	Interface
+}
+
+func newDockerRegistryWithRateLimit(dockerRegistry Interface) *DockerRegistryWithRateLimit {
+	return &DockerRegistryWithRateLimit{Interface: dockerRegistry}
+}
+
+func (r *DockerRegistryWithRateLimit) CreateRepo(ctx context.Context, reference string) error {
+	return withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		return r.Interface.CreateRepo(ctx, reference)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) DeleteRepo(ctx context.Context, reference string) error {
+	return withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		return r.Interface.DeleteRepo(ctx, reference)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) Tags(ctx context.Context, reference string, opts ...Option) (res []string, err error) {
+	err = withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		res, err = r.Interface.Tags(ctx, reference, opts...)
+		return err
+	})
+	return
+}
+
+func (r *DockerRegistryWithRateLimit) IsTagExist(ctx context.Context, reference string, opts ...Option) (res bool, err error) {
+	err = withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		res, err = r.Interface.IsTagExist(ctx, reference, opts...)
+		return err
+	})
+	return
+}
+
+func (r *DockerRegistryWithRateLimit) TagRepoImage(ctx context.Context, repoImage *image.Info, tag string) error {
+	return withRateLimitRetry(ctx, registryFromReference(repoImage.Repository), func(ctx context.Context) error {
+		return r.Interface.TagRepoImage(ctx, repoImage, tag)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) GetRepoImage(ctx context.Context, reference string) (res *image.Info, err error) {
+	err = withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		res, err = r.Interface.GetRepoImage(ctx, reference)
+		return err
+	})
+	return
+}
+
+func (r *DockerRegistryWithRateLimit) TryGetRepoImage(ctx context.Context, reference string) (res *image.Info, err error) {
+	err = withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		res, err = r.Interface.TryGetRepoImage(ctx, reference)
+		return err
+	})
+	return
+}
+
+func (r *DockerRegistryWithRateLimit) DeleteRepoImage(ctx context.Context, repoImage *image.Info) error {
+	return withRateLimitRetry(ctx, registryFromReference(repoImage.Repository), func(ctx context.Context) error {
+		return r.Interface.DeleteRepoImage(ctx, repoImage)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) PushImage(ctx context.Context, reference string, opts *PushImageOptions) error {
+	return withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		return r.Interface.PushImage(ctx, reference, opts)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) MutateAndPushImage(ctx context.Context, sourceReference, destinationReference string, mutateConfigFunc func(v1.Config) (v1.Config, error)) error {
+	return withRateLimitRetry(ctx, registryFromReference(destinationReference), func(ctx context.Context) error {
+		return r.Interface.MutateAndPushImage(ctx, sourceReference, destinationReference, mutateConfigFunc)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) CopyImage(ctx context.Context, sourceReference, destinationReference string, opts CopyImageOptions) error {
+	return withRateLimitRetry(ctx, registryFromReference(destinationReference), func(ctx context.Context) error {
+		return r.Interface.CopyImage(ctx, sourceReference, destinationReference, opts)
+	})
+}
+
+func (r *DockerRegistryWithRateLimit) PushImageArchive(ctx context.Context, archiveOpener ArchiveOpener, reference string) error {
+	return withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		return r.Interface.PushImageArchive(ctx, archiveOpener, reference)
+	})
+}
+
+// PullImageArchive is not retried since the archive might have been partially written.
+func (r *DockerRegistryWithRateLimit) PullImageArchive(ctx context.Context, archiveWriter io.Writer, reference string) error {
+	return r.Interface.PullImageArchive(ctx, archiveWriter, reference)
+}
+
+func (r *DockerRegistryWithRateLimit) PushManifestList(ctx context.Context, reference string, opts ManifestListOptions) error {
+	return withRateLimitRetry(ctx, registryFromReference(reference), func(ctx context.Context) error {
+		return r.Interface.PushManifestList(ctx, reference, opts)
+	})
+}
+
+func registryFromReference(reference string) string {
+	parsedReference, err := name.ParseReference(reference)
+	if err != nil {
+		return reference
+	}
+
+	return parsedReference.Context().RegistryStr()
+}
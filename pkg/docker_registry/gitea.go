@@ -0,0 +1,110 @@
+package docker_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+const (
+	GiteaImplementationName = "gitea"
+
+	giteaUnauthorizedErrPrefix = "gitea unauthorized: "
+)
+
+var giteaPatterns = []string{"^gitea\\..*", "^forgejo\\..*", "^codeberg\\.org"}
+
+type GiteaUnauthorizedErr apiError
+
+func NewGiteaUnauthorizedErr(err error) GiteaUnauthorizedErr {
+	return GiteaUnauthorizedErr{
+		error: errors.New(giteaUnauthorizedErrPrefix + err.Error()),
+	}
+}
+
+func IsGiteaUnauthorizedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), giteaUnauthorizedErrPrefix)
+}
+
+type gitea struct {
+	*defaultImplementation
+	giteaApi
+	giteaCredentials
+}
+
+type giteaOptions struct {
+	defaultImplementationOptions
+	giteaCredentials
+}
+
+type giteaCredentials struct {
+	token string
+}
+
+func newGitea(options giteaOptions) (*gitea, error) {
+	d, err := newDefaultAPIForImplementation(GiteaImplementationName, options.defaultImplementationOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	gitea := &gitea{
+		defaultImplementation: d,
+		giteaApi:              newGiteaApi(),
+		giteaCredentials:      options.giteaCredentials,
+	}
+
+	return gitea, nil
+}
+
+// DeleteRepoImage removes the container package version that corresponds to the tag.
+// Gitea (and Forgejo) does not support deleting manifests with the Docker Registry API.
+func (r *gitea) DeleteRepoImage(ctx context.Context, repoImage *image.Info) error {
+	hostname, owner, packageName, err := r.parseReference(repoImage.Repository)
+	if err != nil {
+		return err
+	}
+
+	resp, err := r.giteaApi.deleteContainerPackageVersion(ctx, hostname, owner, packageName, repoImage.Tag, r.giteaCredentials.token)
+	if err != nil {
+		return r.handleFailedApiResponse(resp, err)
+	}
+
+	return nil
+}
+
+func (r *gitea) handleFailedApiResponse(resp *http.Response, err error) error {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return NewGiteaUnauthorizedErr(err)
+		}
+	}
+
+	return err
+}
+
+func (r *gitea) String() string {
+	return GiteaImplementationName
+}
+
+// parseReference returns the registry hostname, the package owner (user or organization) and the package name.
+// The package name can contain slashes (e.g. gitea.company.com/owner/group/app -> owner, group/app).
+func (r *gitea) parseReference(reference string) (string, string, string, error) {
+	parsedReference, err := name.NewRepository(reference)
+	if err != nil {
+		return "", "", "", fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	parts := strings.SplitN(parsedReference.RepositoryStr(), "/", 2)
+	if len(parts) != 2 {
+		return "", "", "", fmt.Errorf("unexpected reference %s: expected <registry>/<owner>/<package>", reference)
+	}
+
+	return parsedReference.RegistryStr(), parts[0], parts[1], nil
+}
@@ -0,0 +1,42 @@
+package docker_registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+)
+
+type giteaApi struct{}
+
+func newGiteaApi() giteaApi {
+	return giteaApi{}
+}
+
+func (api *giteaApi) deleteContainerPackageVersion(ctx context.Context, hostname, owner, packageName, version, token string) (*http.Response, error) {
+	url := giteaContainerPackageVersionURL(hostname, owner, packageName, version)
+
+	headers := map[string]string{
+		"Accept": "application/json",
+	}
+	if token != "" {
+		headers["Authorization"] = fmt.Sprintf("token %s", token)
+	}
+
+	resp, _, err := doRequest(ctx, http.MethodDelete, url, nil, doRequestOptions{
+		Headers:       headers,
+		AcceptedCodes: []int{http.StatusNoContent, http.StatusOK},
+	})
+
+	return resp, err
+}
+
+func giteaContainerPackageVersionURL(hostname, owner, packageName, version string) string {
+	return fmt.Sprintf(
+		"https://%s/api/v1/packages/%s/container/%s/%s",
+		hostname,
+		neturl.PathEscape(owner),
+		neturl.PathEscape(packageName),
+		neturl.PathEscape(version),
+	)
+}
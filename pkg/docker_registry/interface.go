@@ -40,6 +40,11 @@ type GenericApiInterface interface {
 	commonInterface
 
 	GetRepoImageConfigFile(ctx context.Context, reference string) (*v1.ConfigFile, error)
+
+	GetDescriptor(ctx context.Context, reference string) (*v1.Descriptor, error)
+	PushArtifact(ctx context.Context, reference string, artifact v1.Image) error
+	TryGetArtifact(ctx context.Context, reference string) (v1.Image, error)
+	GetReferrers(ctx context.Context, digestReference, artifactType string) ([]v1.Descriptor, error)
 }
 
 type ArchiveOpener interface {
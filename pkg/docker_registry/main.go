@@ -12,7 +12,9 @@ import (
 
 var generic *genericApi
 
-func Init(ctx context.Context, insecureRegistry, skipTlsVerifyRegistry bool) error {
+func Init(ctx context.Context, insecureRegistry, skipTlsVerifyRegistry bool, maxConcurrentRequests int) error {
+	setRateLimitMaxConcurrentRequests(maxConcurrentRequests)
+
 	if logboek.Context(ctx).Debug().IsAccepted() {
 		logs.Progress.SetOutput(logboek.Context(ctx).OutStream())
 		logs.Warn.SetOutput(logboek.Context(ctx).ErrStream())
@@ -0,0 +1,145 @@
+package docker_registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+const (
+	NexusImplementationName = "nexus"
+
+	nexusUnauthorizedErrPrefix = "nexus unauthorized: "
+)
+
+var nexusPatterns = []string{"^nexus\\..*"}
+
+type NexusUnauthorizedErr apiError
+
+func NewNexusUnauthorizedErr(err error) NexusUnauthorizedErr {
+	return NexusUnauthorizedErr{
+		error: errors.New(nexusUnauthorizedErrPrefix + err.Error()),
+	}
+}
+
+func IsNexusUnauthorizedErr(err error) bool {
+	return err != nil && strings.Contains(err.Error(), nexusUnauthorizedErrPrefix)
+}
+
+type nexus struct {
+	*defaultImplementation
+	nexusApi
+	nexusCredentials
+}
+
+type nexusOptions struct {
+	defaultImplementationOptions
+	nexusCredentials
+}
+
+type nexusCredentials struct {
+	username string
+	password string
+	// url is the Nexus Repository Manager address if it differs from the registry hostname
+	// (the docker registry is usually served on a separate connector port or hostname).
+	url string
+	// repository is the Nexus repository name to narrow the component search.
+	repository string
+}
+
+func newNexus(options nexusOptions) (*nexus, error) {
+	d, err := newDefaultAPIForImplementation(NexusImplementationName, options.defaultImplementationOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	nexus := &nexus{
+		defaultImplementation: d,
+		nexusApi:              newNexusApi(),
+		nexusCredentials:      options.nexusCredentials,
+	}
+
+	return nexus, nil
+}
+
+// DeleteRepoImage removes the docker component that corresponds to the tag with the Nexus REST API.
+// Deleting manifests with the Docker Registry API is not available for Nexus docker repositories.
+func (r *nexus) DeleteRepoImage(ctx context.Context, repoImage *image.Info) error {
+	hostname, imageName, err := r.parseReference(repoImage.Repository)
+	if err != nil {
+		return err
+	}
+
+	baseURL := r.baseURL(hostname)
+
+	components, resp, err := r.nexusApi.searchDockerComponents(ctx, baseURL, r.nexusCredentials.repository, imageName, repoImage.Tag, r.nexusCredentials.username, r.nexusCredentials.password)
+	if err != nil {
+		return r.handleFailedApiResponse(resp, err)
+	}
+
+	switch len(components) {
+	case 0:
+		return fmt.Errorf("nexus component %s:%s not found (NOT_FOUND)", imageName, repoImage.Tag)
+	case 1:
+	default:
+		if r.nexusCredentials.repository == "" {
+			var repositories []string
+			for _, component := range components {
+				repositories = append(repositories, component.Repository)
+			}
+
+			return fmt.Errorf("nexus component %s:%s found in several repositories (%s): specify the Nexus repository explicitly", imageName, repoImage.Tag, strings.Join(repositories, ", "))
+		}
+	}
+
+	for _, component := range components {
+		if resp, err := r.nexusApi.deleteComponent(ctx, baseURL, component.Id, r.nexusCredentials.username, r.nexusCredentials.password); err != nil {
+			return r.handleFailedApiResponse(resp, err)
+		}
+	}
+
+	return nil
+}
+
+func (r *nexus) baseURL(hostname string) string {
+	if r.nexusCredentials.url != "" {
+		return strings.TrimSuffix(r.nexusCredentials.url, "/")
+	}
+
+	// The docker connector port does not serve the REST API.
+	if i := strings.LastIndex(hostname, ":"); i != -1 {
+		hostname = hostname[:i]
+	}
+
+	return "https://" + hostname
+}
+
+func (r *nexus) handleFailedApiResponse(resp *http.Response, err error) error {
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return NewNexusUnauthorizedErr(err)
+		}
+	}
+
+	return err
+}
+
+func (r *nexus) String() string {
+	return NexusImplementationName
+}
+
+func (r *nexus) parseReference(reference string) (string, string, error) {
+	parsedReference, err := name.NewRepository(reference)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to parse reference %q: %w", reference, err)
+	}
+
+	return parsedReference.RegistryStr(), parsedReference.RepositoryStr(), nil
+}
@@ -0,0 +1,94 @@
+package docker_registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+)
+
+type nexusApi struct{}
+
+func newNexusApi() nexusApi {
+	return nexusApi{}
+}
+
+type nexusApiComponent struct {
+	Id         string `json:"id"`
+	Repository string `json:"repository"`
+	Format     string `json:"format"`
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+}
+
+type nexusApiSearchResponse struct {
+	Items             []nexusApiComponent `json:"items"`
+	ContinuationToken string              `json:"continuationToken"`
+}
+
+func (api *nexusApi) searchDockerComponents(ctx context.Context, baseURL, repository, imageName, tag, username, password string) ([]nexusApiComponent, *http.Response, error) {
+	var components []nexusApiComponent
+	var continuationToken string
+	for {
+		url := nexusSearchURL(baseURL, repository, imageName, tag, continuationToken)
+		resp, respBody, err := doRequest(ctx, http.MethodGet, url, nil, doRequestOptions{
+			Headers: map[string]string{
+				"Accept": "application/json",
+			},
+			BasicAuth: doRequestBasicAuth{
+				username: username,
+				password: password,
+			},
+			AcceptedCodes: []int{http.StatusOK},
+		})
+		if err != nil {
+			return nil, resp, err
+		}
+
+		var searchResponse nexusApiSearchResponse
+		if err := json.Unmarshal(respBody, &searchResponse); err != nil {
+			return nil, resp, fmt.Errorf("unexpected body %s", string(respBody))
+		}
+
+		for _, component := range searchResponse.Items {
+			// The search matches by keywords, so the exact match must be checked.
+			if component.Name == imageName && component.Version == tag {
+				components = append(components, component)
+			}
+		}
+
+		if searchResponse.ContinuationToken == "" {
+			return components, resp, nil
+		}
+		continuationToken = searchResponse.ContinuationToken
+	}
+}
+
+func (api *nexusApi) deleteComponent(ctx context.Context, baseURL, id, username, password string) (*http.Response, error) {
+	url := fmt.Sprintf("%s/service/rest/v1/components/%s", baseURL, neturl.PathEscape(id))
+	resp, _, err := doRequest(ctx, http.MethodDelete, url, nil, doRequestOptions{
+		BasicAuth: doRequestBasicAuth{
+			username: username,
+			password: password,
+		},
+		AcceptedCodes: []int{http.StatusNoContent, http.StatusOK},
+	})
+
+	return resp, err
+}
+
+func nexusSearchURL(baseURL, repository, imageName, tag, continuationToken string) string {
+	query := neturl.Values{}
+	query.Set("format", "docker")
+	query.Set("docker.imageName", imageName)
+	query.Set("docker.imageTag", tag)
+	if repository != "" {
+		query.Set("repository", repository)
+	}
+	if continuationToken != "" {
+		query.Set("continuationToken", continuationToken)
+	}
+
+	return fmt.Sprintf("%s/service/rest/v1/search?%s", baseURL, query.Encode())
+}
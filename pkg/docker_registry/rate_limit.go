@@ -0,0 +1,480 @@
+package docker_registry
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/werf/logboek"
+)
+
+const (
+	rateLimitMaxRetries    = 8
+	rateLimitBaseDelay     = time.Second
+	rateLimitMaxDelay      = time.Minute
+	rateLimitMaxRetryAfter = 5 * time.Minute
+
+	// The operation is retried only if its requests have not been retried on the transport level.
+	rateLimitMaxOperationRetries = 3
+	// The total time of waiting before the retries of the operation (or the request made outside of any operation).
+	rateLimitMaxOperationWait = 10 * time.Minute
+
+	// The concurrency limit lowered on throttling is increased by one after this number of successful requests.
+	rateLimitRecoverySuccesses = 20
+	// The lowered concurrency limit is removed when it reaches this value (if the user has not set the limit).
+	rateLimitUnlimitedThreshold = 32
+)
+
+var (
+	rateLimitMaxConcurrentRequests int
+
+	rateLimiters    = map[string]*registryRateLimiter{}
+	rateLimitersMux sync.Mutex
+)
+
+// RateLimitStats is the throttling statistics collected for the registry during the werf run.
+type RateLimitStats struct {
+	Registry string
+	// ThrottledRequests is the number of requests rejected by the registry due to the rate limit.
+	ThrottledRequests int
+	// Retries is the number of requests and operations retried after throttling.
+	Retries int
+	// WaitTime is the total time spent waiting before the retries.
+	WaitTime time.Duration
+	// ConcurrencyLimit is the lowest concurrency limit applied to the registry (0 if it was not limited).
+	ConcurrencyLimit int
+}
+
+func setRateLimitMaxConcurrentRequests(maxConcurrentRequests int) {
+	rateLimitersMux.Lock()
+	defer rateLimitersMux.Unlock()
+
+	rateLimitMaxConcurrentRequests = maxConcurrentRequests
+	for _, limiter := range rateLimiters {
+		limiter.setMaxLimit(maxConcurrentRequests)
+	}
+}
+
+func getRegistryRateLimiter(registry string) *registryRateLimiter {
+	rateLimitersMux.Lock()
+	defer rateLimitersMux.Unlock()
+
+	limiter, ok := rateLimiters[registry]
+	if !ok {
+		limiter = newRegistryRateLimiter(registry, rateLimitMaxConcurrentRequests)
+		rateLimiters[registry] = limiter
+	}
+
+	return limiter
+}
+
+// GetRateLimitStats returns statistics for the registries that throttled werf requests.
+func GetRateLimitStats() []RateLimitStats {
+	rateLimitersMux.Lock()
+	defer rateLimitersMux.Unlock()
+
+	var res []RateLimitStats
+	for _, limiter := range rateLimiters {
+		if stats := limiter.getStats(); stats.ThrottledRequests != 0 {
+			res = append(res, stats)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Registry < res[j].Registry
+	})
+
+	return res
+}
+
+func LogRateLimitStats(ctx context.Context) {
+	statsList := GetRateLimitStats()
+	if len(statsList) == 0 {
+		return
+	}
+
+	logboek.Context(ctx).Warn().LogBlock("Container registry throttling").Do(func() {
+		for _, stats := range statsList {
+			msg := fmt.Sprintf("%s: %d throttled requests, %d retries, %s waited", stats.Registry, stats.ThrottledRequests, stats.Retries, stats.WaitTime.Round(time.Millisecond))
+			if stats.ConcurrencyLimit != 0 {
+				msg += fmt.Sprintf(", concurrency limited to %d", stats.ConcurrencyLimit)
+			}
+			logboek.Context(ctx).Warn().LogLn(msg)
+		}
+
+		logboek.Context(ctx).Warn().LogLn()
+		logboek.Context(ctx).Warn().LogLn("Use --registry-max-concurrent-requests option ($WERF_REGISTRY_MAX_CONCURRENT_REQUESTS) to limit the number of concurrent requests to the registry")
+	})
+}
+
+// registryRateLimiter limits the number of concurrent requests to the registry and blocks requests for the time requested by the registry.
+// The concurrency limit is adaptive: it is halved every time the registry throttles requests and slowly restored afterwards.
+type registryRateLimiter struct {
+	registry string
+
+	mux      sync.Mutex
+	released chan struct{}
+
+	inFlight int
+	// limit is the current concurrency limit (0 if not limited).
+	limit int
+	// maxLimit is the concurrency limit set by the user (0 if not limited).
+	maxLimit            int
+	successesSinceLimit int
+	blockedUntil        time.Time
+
+	stats RateLimitStats
+}
+
+func newRegistryRateLimiter(registry string, maxLimit int) *registryRateLimiter {
+	return &registryRateLimiter{
+		registry: registry,
+		released: make(chan struct{}),
+		limit:    maxLimit,
+		maxLimit: maxLimit,
+		stats: RateLimitStats{
+			Registry: registry,
+		},
+	}
+}
+
+func (l *registryRateLimiter) setMaxLimit(maxLimit int) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.maxLimit = maxLimit
+	if l.limit == 0 || (maxLimit != 0 && l.limit > maxLimit) {
+		l.limit = maxLimit
+	}
+	l.notifyLocked()
+}
+
+// acquire waits until the registry is not blocked and the concurrency limit allows one more request.
+func (l *registryRateLimiter) acquire(ctx context.Context) error {
+	for {
+		l.mux.Lock()
+		wait := time.Until(l.blockedUntil)
+		if wait <= 0 && (l.limit == 0 || l.inFlight < l.limit) {
+			l.inFlight++
+			l.mux.Unlock()
+			return nil
+		}
+		released := l.released
+		l.mux.Unlock()
+
+		var timer <-chan time.Time
+		if wait > 0 {
+			timer = time.After(wait)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		case <-timer:
+		}
+	}
+}
+
+func (l *registryRateLimiter) release(throttled bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.inFlight--
+
+	if !throttled && l.limit != l.maxLimit {
+		l.successesSinceLimit++
+		if l.successesSinceLimit >= rateLimitRecoverySuccesses {
+			l.successesSinceLimit = 0
+			l.limit++
+			if (l.maxLimit != 0 && l.limit >= l.maxLimit) || (l.maxLimit == 0 && l.limit >= rateLimitUnlimitedThreshold) {
+				l.limit = l.maxLimit
+			}
+		}
+	}
+
+	l.notifyLocked()
+}
+
+// throttled registers the rejected request and returns the delay before the next attempt.
+// The backoff delay is used if the registry has not specified the delay (retryAfter < 0).
+func (l *registryRateLimiter) throttled(attempt int, retryAfter time.Duration) time.Duration {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.stats.ThrottledRequests++
+
+	limit := l.inFlight
+	if l.limit != 0 && l.limit < limit {
+		limit = l.limit
+	}
+	limit /= 2
+	if limit < 1 {
+		limit = 1
+	}
+	l.limit = limit
+	l.successesSinceLimit = 0
+	if l.stats.ConcurrencyLimit == 0 || limit < l.stats.ConcurrencyLimit {
+		l.stats.ConcurrencyLimit = limit
+	}
+
+	delay := retryAfter
+	if delay < 0 {
+		delay = rateLimitBackoff(attempt)
+	}
+	if blockedUntil := time.Now().Add(delay); blockedUntil.After(l.blockedUntil) {
+		l.blockedUntil = blockedUntil
+	}
+
+	return delay
+}
+
+func (l *registryRateLimiter) retried(wait time.Duration) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	l.stats.Retries++
+	l.stats.WaitTime += wait
+}
+
+func (l *registryRateLimiter) getStats() RateLimitStats {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.stats
+}
+
+func (l *registryRateLimiter) notifyLocked() {
+	close(l.released)
+	l.released = make(chan struct{})
+}
+
+// rateLimitBackoff returns the exponential backoff delay with jitter for the attempt (starting from 1).
+func rateLimitBackoff(attempt int) time.Duration {
+	delay := rateLimitBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > rateLimitMaxDelay {
+		delay = rateLimitMaxDelay
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// parseRetryAfter parses the Retry-After header value (delay in seconds or HTTP date).
+// It returns -1 if the value is not set or invalid.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+
+	var delay time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = date.Sub(now)
+	} else {
+		return -1
+	}
+
+	if delay < 0 {
+		return 0
+	}
+	if delay > rateLimitMaxRetryAfter {
+		return rateLimitMaxRetryAfter
+	}
+
+	return delay
+}
+
+func isThrottlingResponse(resp *http.Response) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusServiceUnavailable:
+		return resp.Header.Get("Retry-After") != ""
+	default:
+		return false
+	}
+}
+
+// IsRateLimitErr returns true if the registry rejected the operation due to the rate limit.
+func IsRateLimitErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	for _, substr := range []string{
+		"TOOMANYREQUESTS",
+		"429 Too Many Requests",
+		"ThrottlingException",
+		"ThrottledException",
+		"toomanyrequests",
+	} {
+		if strings.Contains(err.Error(), substr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rateLimitTransport passes requests to the registry through the registry rate limiter
+// and retries throttled requests honoring the Retry-After header.
+type rateLimitTransport struct {
+	base http.RoundTripper
+}
+
+func newRateLimitTransport(base http.RoundTripper) http.RoundTripper {
+	return &rateLimitTransport{base: base}
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	limiter := getRegistryRateLimiter(req.URL.Host)
+
+	budget := rateLimitBudgetFromContext(req.Context())
+	if budget == nil {
+		budget = newRateLimitBudget()
+	}
+
+	for attempt := 1; ; attempt++ {
+		if err := limiter.acquire(req.Context()); err != nil {
+			return nil, err
+		}
+
+		resp, err := t.base.RoundTrip(req)
+		throttled := err == nil && isThrottlingResponse(resp)
+		limiter.release(throttled)
+
+		if !throttled {
+			return resp, err
+		}
+
+		delay := limiter.throttled(attempt, parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()))
+		if attempt > rateLimitMaxRetries || !isRequestReplayable(req) || !budget.reserve(delay, true) {
+			return resp, nil
+		}
+
+		nextReq, err := rewindRequest(req)
+		if err != nil {
+			return resp, nil
+		}
+		resp.Body.Close()
+
+		limiter.retried(delay)
+		req = nextReq
+	}
+}
+
+func isRequestReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	newReq := req.Clone(req.Context())
+	newReq.Body = body
+
+	return newReq, nil
+}
+
+// withRateLimitRetry retries the operation rejected by the registry due to the rate limit.
+// It covers the operations that are not retried on the transport level (e.g. vendor SDK calls or streamed uploads):
+// the operation is not retried if the transport has already retried its requests, and the retries of both levels
+// share the single waiting budget passed to the requests with the context.
+func withRateLimitRetry(ctx context.Context, registry string, f func(ctx context.Context) error) error {
+	limiter := getRegistryRateLimiter(registry)
+
+	operationCtx := ctx
+	budget := rateLimitBudgetFromContext(ctx)
+	if budget == nil {
+		budget = newRateLimitBudget()
+		operationCtx = context.WithValue(ctx, rateLimitBudgetCtxKey{}, budget)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := f(operationCtx)
+		if !IsRateLimitErr(err) || attempt > rateLimitMaxOperationRetries || budget.isRetriedByTransport() {
+			return err
+		}
+
+		delay := limiter.throttled(attempt, -1)
+		if !budget.reserve(delay, false) {
+			return err
+		}
+		logboek.Context(ctx).Warn().LogF("Registry %s throttled the operation: retrying in %s (%d/%d) ...\n", registry, delay.Round(time.Millisecond), attempt, rateLimitMaxOperationRetries)
+
+		if err := limiter.waitBlocked(ctx); err != nil {
+			return err
+		}
+		limiter.retried(delay)
+	}
+}
+
+func (l *registryRateLimiter) waitBlocked(ctx context.Context) error {
+	l.mux.Lock()
+	wait := time.Until(l.blockedUntil)
+	l.mux.Unlock()
+
+	if wait <= 0 {
+		return nil
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// rateLimitBudget limits the total time of waiting before the retries of the operation.
+type rateLimitBudget struct {
+	mux                sync.Mutex
+	deadline           time.Time
+	retriedByTransport bool
+}
+
+type rateLimitBudgetCtxKey struct{}
+
+func newRateLimitBudget() *rateLimitBudget {
+	return &rateLimitBudget{deadline: time.Now().Add(rateLimitMaxOperationWait)}
+}
+
+func rateLimitBudgetFromContext(ctx context.Context) *rateLimitBudget {
+	budget, _ := ctx.Value(rateLimitBudgetCtxKey{}).(*rateLimitBudget)
+	return budget
+}
+
+// reserve returns true if the retry after the delay fits the budget.
+func (b *rateLimitBudget) reserve(delay time.Duration, byTransport bool) bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	if time.Now().Add(delay).After(b.deadline) {
+		return false
+	}
+
+	if byTransport {
+		b.retriedByTransport = true
+	}
+
+	return true
+}
+
+func (b *rateLimitBudget) isRetriedByTransport() bool {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+
+	return b.retriedByTransport
+}
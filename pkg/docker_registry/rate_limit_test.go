@@ -0,0 +1,191 @@
+package docker_registry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = DescribeTable("parseRetryAfter", func(value string, expectation time.Duration) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	Ω(parseRetryAfter(value, now)).Should(Equal(expectation))
+},
+	Entry("empty", "", time.Duration(-1)),
+	Entry("invalid", "soon", time.Duration(-1)),
+	Entry("seconds", "3", 3*time.Second),
+	Entry("http date", "Sat, 01 Jan 2022 00:00:10 GMT", 10*time.Second),
+	Entry("http date in the past", "Fri, 31 Dec 2021 23:59:00 GMT", time.Duration(0)),
+	Entry("too long", "3600", rateLimitMaxRetryAfter),
+)
+
+var _ = Describe("rateLimitTransport", func() {
+	It("should retry throttled requests honoring Retry-After", func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			body, err := io.ReadAll(r.Body)
+			Ω(err).ShouldNot(HaveOccurred())
+			Ω(string(body)).Should(Equal("payload"))
+
+			if atomic.AddInt32(&requests, 1) < 3 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport)}
+		resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+		Ω(err).ShouldNot(HaveOccurred())
+		resp.Body.Close()
+
+		Ω(resp.StatusCode).Should(Equal(http.StatusOK))
+		Ω(atomic.LoadInt32(&requests)).Should(Equal(int32(3)))
+
+		stats := getRegistryRateLimiter(mustParseURL(server.URL).Host).getStats()
+		Ω(stats.ThrottledRequests).Should(Equal(2))
+		Ω(stats.Retries).Should(Equal(2))
+		Ω(stats.ConcurrencyLimit).Should(Equal(1))
+	})
+
+	It("should not retry the request that cannot be replayed", func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		req, err := http.NewRequest(http.MethodPut, server.URL, io.NopCloser(strings.NewReader("payload")))
+		Ω(err).ShouldNot(HaveOccurred())
+		req.GetBody = nil
+
+		resp, err := newRateLimitTransport(http.DefaultTransport).RoundTrip(req)
+		Ω(err).ShouldNot(HaveOccurred())
+		resp.Body.Close()
+
+		Ω(resp.StatusCode).Should(Equal(http.StatusTooManyRequests))
+		Ω(atomic.LoadInt32(&requests)).Should(Equal(int32(1)))
+	})
+})
+
+var _ = Describe("registryRateLimiter", func() {
+	It("should lower the concurrency limit on throttling and restore it afterwards", func() {
+		ctx := context.Background()
+		limiter := newRegistryRateLimiter("registry.example.com", 0)
+
+		for i := 0; i < 8; i++ {
+			Ω(limiter.acquire(ctx)).Should(Succeed())
+		}
+		limiter.release(true)
+		limiter.throttled(1, 0)
+		Ω(limiter.limit).Should(Equal(3))
+
+		for i := 0; i < 7; i++ {
+			limiter.release(false)
+		}
+
+		for i := 0; i < rateLimitUnlimitedThreshold*rateLimitRecoverySuccesses; i++ {
+			Ω(limiter.acquire(ctx)).Should(Succeed())
+			limiter.release(false)
+		}
+		Ω(limiter.limit).Should(Equal(0))
+		Ω(limiter.getStats().ConcurrencyLimit).Should(Equal(3))
+	})
+
+	It("should not exceed the concurrency limit", func() {
+		limiter := newRegistryRateLimiter("registry.example.com", 1)
+		Ω(limiter.acquire(context.Background())).Should(Succeed())
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		Ω(limiter.acquire(ctx)).Should(MatchError(context.DeadlineExceeded))
+
+		limiter.release(false)
+		Ω(limiter.acquire(context.Background())).Should(Succeed())
+	})
+})
+
+var _ = Describe("withRateLimitRetry", func() {
+	It("should retry only throttled operations", func() {
+		ctx := context.Background()
+
+		attempts := 0
+		Ω(withRateLimitRetry(ctx, "retry.example.com", func(context.Context) error {
+			attempts++
+			if attempts == 1 {
+				return errors.New("ThrottlingException: Rate exceeded")
+			}
+			return nil
+		})).Should(Succeed())
+		Ω(attempts).Should(Equal(2))
+
+		attempts = 0
+		Ω(withRateLimitRetry(ctx, "retry.example.com", func(context.Context) error {
+			attempts++
+			return errors.New("MANIFEST_UNKNOWN")
+		})).ShouldNot(Succeed())
+		Ω(attempts).Should(Equal(1))
+	})
+
+	It("should not retry the operation which requests have been retried by the transport", func() {
+		var requests int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requests, 1)
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &http.Client{Transport: newRateLimitTransport(http.DefaultTransport)}
+
+		attempts := 0
+		Ω(withRateLimitRetry(context.Background(), mustParseURL(server.URL).Host, func(ctx context.Context) error {
+			attempts++
+
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			Ω(err).ShouldNot(HaveOccurred())
+
+			resp, err := client.Do(req)
+			Ω(err).ShouldNot(HaveOccurred())
+			resp.Body.Close()
+
+			return errors.New(resp.Status)
+		})).Should(MatchError("429 Too Many Requests"))
+
+		Ω(attempts).Should(Equal(1))
+		Ω(atomic.LoadInt32(&requests)).Should(Equal(int32(rateLimitMaxRetries + 1)))
+	})
+})
+
+var _ = Describe("rateLimitBudget", func() {
+	It("should not allow the retries exceeding the total wait time", func() {
+		budget := newRateLimitBudget()
+
+		Ω(budget.reserve(rateLimitMaxOperationWait+time.Minute, true)).Should(BeFalse())
+		Ω(budget.isRetriedByTransport()).Should(BeFalse())
+
+		Ω(budget.reserve(time.Second, true)).Should(BeTrue())
+		Ω(budget.isRetriedByTransport()).Should(BeTrue())
+	})
+})
+
+func mustParseURL(rawURL string) *url.URL {
+	u, err := url.Parse(rawURL)
+	Ω(err).ShouldNot(HaveOccurred())
+	return u
+}
@@ -0,0 +1,85 @@
+package docker_registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+var _ = Describe("Gitea", func() {
+	It("should build the container package version url", func() {
+		hostname, owner, packageName, err := (&gitea{}).parseReference("gitea.company.com/owner/group/app")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(giteaContainerPackageVersionURL(hostname, owner, packageName, "tag")).Should(Equal("https://gitea.company.com/api/v1/packages/owner/container/group%2Fapp/tag"))
+	})
+
+	It("should fail if the package owner is not specified", func() {
+		_, _, _, err := (&gitea{}).parseReference("gitea.company.com/app")
+		Ω(err).Should(HaveOccurred())
+	})
+})
+
+var _ = Describe("Artifactory", func() {
+	It("should build the tag item url", func() {
+		hostname, repositoryKey, imagePath, err := (&artifactory{}).parseReference("company.jfrog.io/docker-local/group/app")
+		Ω(err).ShouldNot(HaveOccurred())
+		Ω(artifactoryItemURL(hostname, repositoryKey, imagePath+"/tag")).Should(Equal("https://company.jfrog.io/artifactory/docker-local/group/app/tag"))
+	})
+})
+
+var _ = Describe("Nexus", func() {
+	It("should use the registry hostname without the connector port as the default url", func() {
+		Ω((&nexus{}).baseURL("nexus.company.com:8082")).Should(Equal("https://nexus.company.com"))
+		Ω((&nexus{nexusCredentials: nexusCredentials{url: "https://repo.company.com/"}}).baseURL("nexus.company.com:8082")).Should(Equal("https://repo.company.com"))
+	})
+
+	It("should delete the matching component", func() {
+		var deletedComponents []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer GinkgoRecover()
+
+			username, password, _ := r.BasicAuth()
+			Ω(username).Should(Equal("user"))
+			Ω(password).Should(Equal("pass"))
+
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/service/rest/v1/search":
+				Ω(r.URL.Query().Get("docker.imageName")).Should(Equal("group/app"))
+				Ω(r.URL.Query().Get("docker.imageTag")).Should(Equal("tag"))
+				Ω(r.URL.Query().Get("repository")).Should(Equal("docker-hosted"))
+
+				Ω(json.NewEncoder(w).Encode(nexusApiSearchResponse{Items: []nexusApiComponent{
+					{Id: "id-1", Repository: "docker-hosted", Name: "group/app", Version: "tag"},
+					{Id: "id-2", Repository: "docker-hosted", Name: "group/app", Version: "tag-2"},
+				}})).Should(Succeed())
+			case r.Method == http.MethodDelete:
+				deletedComponents = append(deletedComponents, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer server.Close()
+
+		r := &nexus{nexusCredentials: nexusCredentials{username: "user", password: "pass", url: server.URL, repository: "docker-hosted"}}
+		Ω(r.DeleteRepoImage(context.Background(), &image.Info{Repository: "nexus.company.com:8082/group/app", Tag: "tag"})).Should(Succeed())
+		Ω(deletedComponents).Should(Equal([]string{"/service/rest/v1/components/id-1"}))
+	})
+
+	It("should return the unauthorized error", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		r := &nexus{nexusCredentials: nexusCredentials{url: server.URL}}
+		err := r.DeleteRepoImage(context.Background(), &image.Info{Repository: "nexus.company.com/app", Tag: "tag"})
+		Ω(IsNexusUnauthorizedErr(err)).Should(BeTrue())
+	})
+})
@@ -18,12 +18,68 @@ func ParseDockerfileWithBuildkit(dockerfileID string, dockerfileBytes []byte, we
 		return nil, fmt.Errorf("parsing dockerfile data: %w", err)
 	}
 
+	copyParentsLines := map[int]bool{}
+	for _, node := range p.AST.Children {
+		if parents, err := stripCopyParentsFlag(node); err != nil {
+			return nil, parser.WithLocation(err, node.Location())
+		} else if parents {
+			copyParentsLines[node.StartLine] = true
+		}
+	}
+
 	dockerStages, dockerMetaArgsCommands, err := instructions.Parse(p.AST)
 	if err != nil {
 		return nil, fmt.Errorf("parsing instructions tree: %w", err)
 	}
 
-	expanderFactory := NewShlexExpanderFactory(p.EscapeToken)
+	copyParents := map[*instructions.CopyCommand]bool{}
+	for _, dockerStage := range dockerStages {
+		for _, cmd := range dockerStage.Commands {
+			if copyCmd, ok := cmd.(*instructions.CopyCommand); ok && len(copyCmd.Location()) > 0 && copyParentsLines[copyCmd.Location()[0].Start.Line] {
+				copyParents[copyCmd] = true
+			}
+		}
+	}
+
+	return newDockerfileFromBuildkitStages(dockerfileID, dockerStages, dockerMetaArgsCommands, copyParents, p.EscapeToken, werfImageName, opts)
+}
+
+// stripCopyParentsFlag removes the COPY --parents flag from the node, the flag is not supported by the buildkit parser.
+func stripCopyParentsFlag(node *parser.Node) (bool, error) {
+	if !strings.EqualFold(node.Value, "copy") {
+		return false, nil
+	}
+
+	var parents bool
+	var flags []string
+	for _, flag := range node.Flags {
+		name, value, hasValue := strings.Cut(strings.TrimPrefix(flag, "--"), "=")
+		if name != "parents" {
+			flags = append(flags, flag)
+			continue
+		}
+
+		switch strings.ToLower(value) {
+		case "true":
+			parents = true
+		case "false":
+			parents = false
+		case "":
+			if hasValue {
+				return false, fmt.Errorf("missing a value on flag: parents")
+			}
+			parents = true
+		default:
+			return false, fmt.Errorf("expecting boolean value for flag parents, not: %s", value)
+		}
+	}
+	node.Flags = flags
+
+	return parents, nil
+}
+
+func newDockerfileFromBuildkitStages(dockerfileID string, dockerStages []instructions.Stage, dockerMetaArgsCommands []instructions.ArgCommand, copyParents map[*instructions.CopyCommand]bool, escapeToken rune, werfImageName string, opts dockerfile.DockerfileOptions) (*dockerfile.Dockerfile, error) {
+	expanderFactory := NewShlexExpanderFactory(escapeToken)
 
 	metaArgs, err := resolveMetaArgs(dockerMetaArgsCommands, opts.BuildArgs, opts.DependenciesArgsKeys, expanderFactory)
 	if err != nil {
@@ -48,7 +104,7 @@ func ParseDockerfileWithBuildkit(dockerfileID string, dockerfileBytes []byte, we
 		//   Same underhood stage could be printed several times for each werf-image-target-name.
 		// <werf-image>/stage<N> || <werf-image>/stage/<name>
 
-		if stage, err := NewDockerfileStageFromBuildkitStage(i, werfImageName, dockerStage, expanderFactory, metaArgs, opts.BuildArgs, opts.DependenciesArgsKeys); err != nil {
+		if stage, err := NewDockerfileStageFromBuildkitStage(i, werfImageName, dockerStage, expanderFactory, metaArgs, opts.BuildArgs, opts.DependenciesArgsKeys, copyParents); err != nil {
 			return nil, fmt.Errorf("error converting buildkit stage to dockerfile stage: %w", err)
 		} else {
 			stages = append(stages, stage)
@@ -64,7 +120,7 @@ func ParseDockerfileWithBuildkit(dockerfileID string, dockerfileBytes []byte, we
 	return d, nil
 }
 
-func NewDockerfileStageFromBuildkitStage(index int, werfImageName string, stage instructions.Stage, expanderFactory *ShlexExpanderFactory, metaArgs, buildArgs map[string]string, dependenciesArgsKeys []string) (*dockerfile.DockerfileStage, error) {
+func NewDockerfileStageFromBuildkitStage(index int, werfImageName string, stage instructions.Stage, expanderFactory *ShlexExpanderFactory, metaArgs, buildArgs map[string]string, dependenciesArgsKeys []string, copyParents map[*instructions.CopyCommand]bool) (*dockerfile.DockerfileStage, error) {
 	var stageInstructions []dockerfile.DockerfileStageInstructionInterface
 
 	env := make(map[string]string)
@@ -115,6 +171,7 @@ func NewDockerfileStageFromBuildkitStage(index int, werfImageName string, stage
 			if instr, err := createAndExpandInstruction(instrData, expanderFactory, env); err != nil {
 				return nil, err
 			} else {
+				instr.Parents = copyParents[instrData]
 				i = instr
 			}
 		case *instructions.EntrypointCommand:
@@ -0,0 +1,64 @@
+package frontend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+
+	"github.com/werf/werf/pkg/dockerfile"
+)
+
+const testDockerfileWithHeredocs = `FROM alpine:3.18
+ARG CONFIG_DIR=/etc/app
+COPY --parents --link src/*/go.mod ./
+RUN <<EOF
+apk add --no-cache curl
+EOF
+COPY <<EOF ${CONFIG_DIR}/config.yaml
+debug: true
+EOF
+COPY --parents=false doc/ /doc/
+`
+
+func TestParseDockerfileWithBuildkitHeredocsAndParents(t *testing.T) {
+	d, err := ParseDockerfileWithBuildkit("id", []byte(testDockerfileWithHeredocs), "app", dockerfile.DockerfileOptions{})
+	if err != nil {
+		t.Fatalf("ParseDockerfileWithBuildkit() error: %s", err)
+	}
+
+	if len(d.Stages) != 1 {
+		t.Fatalf("expected 1 stage, got %d", len(d.Stages))
+	}
+	stage := d.Stages[0]
+
+	parentsCopy := stage.Instructions[1].(*dockerfile.DockerfileStageInstruction[*instructions.CopyCommand])
+	if !parentsCopy.Parents || !parentsCopy.Data.Link || !reflect.DeepEqual(parentsCopy.Data.SourcePaths, []string{"src/*/go.mod"}) {
+		t.Errorf("unexpected COPY --parents %+v (parents %v)", parentsCopy.Data, parentsCopy.Parents)
+	}
+
+	run := stage.Instructions[2].(*dockerfile.DockerfileStageInstruction[*instructions.RunCommand]).Data
+	expectedFiles := []instructions.ShellInlineFile{{Name: "EOF", Data: "apk add --no-cache curl\n", Chomp: false}}
+	if !reflect.DeepEqual(run.Files, expectedFiles) {
+		t.Errorf("unexpected RUN heredocs %+v", run.Files)
+	}
+
+	heredocCopy := stage.Instructions[3].(*dockerfile.DockerfileStageInstruction[*instructions.CopyCommand]).Data
+	if heredocCopy.DestPath != "/etc/app/config.yaml" {
+		t.Errorf("unexpected COPY heredoc dest %q", heredocCopy.DestPath)
+	}
+	if len(heredocCopy.SourcePaths) != 0 || len(heredocCopy.SourceContents) != 1 || heredocCopy.SourceContents[0].Data != "debug: true\n" {
+		t.Errorf("unexpected COPY heredoc sources %+v", heredocCopy.SourcesAndDest)
+	}
+
+	if stage.Instructions[4].(*dockerfile.DockerfileStageInstruction[*instructions.CopyCommand]).Parents {
+		t.Errorf("COPY --parents=false should not set parents")
+	}
+}
+
+func TestParseDockerfileWithBuildkitInvalidParentsFlag(t *testing.T) {
+	data := "FROM alpine:3.18\nCOPY --parents=maybe src/ /src/\n"
+	if _, err := ParseDockerfileWithBuildkit("id", []byte(data), "app", dockerfile.DockerfileOptions{}); err == nil {
+		t.Errorf("expected error")
+	}
+}
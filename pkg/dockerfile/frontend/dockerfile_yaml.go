@@ -1,4 +1,484 @@
 package frontend
 
-// TODO: implement Dockerfile.yaml or werf.yaml section parser primitives and conversion to Dockerfile and DockerfileStage primitives
-type DockerfileYaml struct{}
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+	"github.com/moby/buildkit/frontend/dockerfile/parser"
+	"gopkg.in/yaml.v2"
+
+	"github.com/werf/werf/pkg/dockerfile"
+)
+
+var dockerfileYamlStageNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9-_.]*$`)
+
+// DockerfileYaml is the YAML description of the Dockerfile stages and instructions, which is used instead of the
+// Dockerfile text. Each instruction is a map with the single Dockerfile instruction name key:
+//
+//	args:
+//	  BASE_IMAGE: alpine:3.18
+//	stages:
+//	- name: builder
+//	  from: golang:1.21
+//	  instructions:
+//	  - WORKDIR: /src
+//	  - COPY: [".", "."]
+//	  - RUN:
+//	      command: go build -o /app .
+//	      mount:
+//	      - {type: cache, target: /root/.cache/go-build}
+//	- from: $BASE_IMAGE
+//	  instructions:
+//	  - COPY: {from: builder, src: /app, dest: /usr/local/bin/app}
+//	  - ENTRYPOINT: ["/usr/local/bin/app"]
+type DockerfileYaml struct {
+	// Args are the build args declared before the first stage, the arg without the value has no default.
+	Args   map[string]*string     `yaml:"args,omitempty"`
+	Stages []*DockerfileYamlStage `yaml:"stages"`
+}
+
+type DockerfileYamlStage struct {
+	Name         string                   `yaml:"name,omitempty"`
+	From         string                   `yaml:"from"`
+	Platform     string                   `yaml:"platform,omitempty"`
+	Instructions []map[string]interface{} `yaml:"instructions,omitempty"`
+}
+
+func ParseDockerfileYaml(data []byte) (*DockerfileYaml, error) {
+	d := &DockerfileYaml{}
+	if err := yaml.UnmarshalStrict(data, d); err != nil {
+		return nil, fmt.Errorf("unmarshal dockerfile yaml: %w", err)
+	}
+
+	if len(d.Stages) == 0 {
+		return nil, fmt.Errorf("at least one stage required")
+	}
+
+	return d, nil
+}
+
+func ParseDockerfileYamlWithBuildkit(dockerfileID string, data []byte, werfImageName string, opts dockerfile.DockerfileOptions) (*dockerfile.Dockerfile, error) {
+	d, err := ParseDockerfileYaml(data)
+	if err != nil {
+		return nil, err
+	}
+
+	dockerStages, dockerMetaArgsCommands, copyParents, err := d.ToBuildkitStages()
+	if err != nil {
+		return nil, err
+	}
+
+	return newDockerfileFromBuildkitStages(dockerfileID, dockerStages, dockerMetaArgsCommands, copyParents, parser.DefaultEscapeToken, werfImageName, opts)
+}
+
+// ToBuildkitStages converts the stages into the buildkit primitives, which are produced by the buildkit parser
+// for the equivalent Dockerfile. The COPY --parents flag is not supported by the buildkit parser and is returned
+// separately.
+func (d *DockerfileYaml) ToBuildkitStages() ([]instructions.Stage, []instructions.ArgCommand, map[*instructions.CopyCommand]bool, error) {
+	var metaArgs []instructions.ArgCommand
+	if len(d.Args) > 0 {
+		var args []string
+		for _, key := range sortedKeys(d.Args) {
+			if value := d.Args[key]; value != nil {
+				args = append(args, fmt.Sprintf("%s=%s", key, *value))
+			} else {
+				args = append(args, key)
+			}
+		}
+
+		cmd, err := instructions.ParseCommand(newInstructionNode("ARG", nil, args, false))
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("unable to parse args: %w", err)
+		}
+		metaArgs = append(metaArgs, *cmd.(*instructions.ArgCommand))
+	}
+
+	var stages []instructions.Stage
+	copyParents := map[*instructions.CopyCommand]bool{}
+	for i, s := range d.Stages {
+		stage, err := s.toBuildkitStage(copyParents)
+		if err != nil {
+			if s.Name != "" {
+				return nil, nil, nil, fmt.Errorf("stage %q: %w", s.Name, err)
+			}
+			return nil, nil, nil, fmt.Errorf("stage %d: %w", i, err)
+		}
+		stages = append(stages, stage)
+	}
+
+	return stages, metaArgs, copyParents, nil
+}
+
+func (s *DockerfileYamlStage) toBuildkitStage(copyParents map[*instructions.CopyCommand]bool) (instructions.Stage, error) {
+	if s.From == "" {
+		return instructions.Stage{}, fmt.Errorf("from required")
+	}
+
+	stage := instructions.Stage{
+		Name:     strings.ToLower(s.Name),
+		BaseName: s.From,
+		Platform: s.Platform,
+	}
+
+	if stage.Name != "" && !dockerfileYamlStageNameRegexp.MatchString(stage.Name) {
+		return instructions.Stage{}, fmt.Errorf("invalid name %q, name can't start with a number or contain symbols", s.Name)
+	}
+
+	for i, instr := range s.Instructions {
+		if len(instr) != 1 {
+			return instructions.Stage{}, fmt.Errorf("instruction %d: expected single instruction name key, got %d keys", i, len(instr))
+		}
+
+		for name, value := range instr {
+			node, err := newDockerfileYamlInstructionNode(name, value)
+			if err != nil {
+				return instructions.Stage{}, fmt.Errorf("instruction %d %s: %w", i, strings.ToUpper(name), err)
+			}
+
+			parents, err := stripCopyParentsFlag(node)
+			if err != nil {
+				return instructions.Stage{}, fmt.Errorf("instruction %d %s: %w", i, strings.ToUpper(name), err)
+			}
+
+			cmd, err := instructions.ParseCommand(node)
+			if err != nil {
+				return instructions.Stage{}, fmt.Errorf("instruction %d %s: %w", i, strings.ToUpper(name), err)
+			}
+
+			if copyCmd, ok := cmd.(*instructions.CopyCommand); ok && parents {
+				copyParents[copyCmd] = true
+			}
+
+			stage.Commands = append(stage.Commands, cmd)
+		}
+	}
+
+	return stage, nil
+}
+
+// newDockerfileYamlInstructionNode creates the node of the buildkit AST, which is the same as the node produced by
+// the buildkit parser for the equivalent Dockerfile instruction.
+func newDockerfileYamlInstructionNode(name string, value interface{}) (*parser.Node, error) {
+	cmd := strings.ToUpper(name)
+
+	switch cmd {
+	case "RUN", "CMD", "ENTRYPOINT", "SHELL":
+		flags, args, isJSON, err := commandParts(value)
+		if err != nil {
+			return nil, err
+		}
+		return newInstructionNode(cmd, flags, args, isJSON), nil
+
+	case "HEALTHCHECK":
+		if s, ok := value.(string); ok && strings.ToUpper(s) == "NONE" {
+			return newInstructionNode(cmd, nil, []string{"NONE"}, false), nil
+		}
+
+		flags, args, isJSON, err := commandParts(value)
+		if err != nil {
+			return nil, err
+		}
+		return newInstructionNode(cmd, flags, append([]string{"CMD"}, args...), isJSON), nil
+
+	case "COPY", "ADD":
+		var flags, args []string
+		switch v := value.(type) {
+		case []interface{}:
+			var err error
+			if args, err = toStrings(v); err != nil {
+				return nil, err
+			}
+		case map[interface{}]interface{}:
+			m, err := toStringKeysMap(v)
+			if err != nil {
+				return nil, err
+			}
+
+			src, err := toStrings(m["src"])
+			if err != nil {
+				return nil, fmt.Errorf("src: %w", err)
+			}
+			dest, err := toScalarString(m["dest"])
+			if err != nil {
+				return nil, fmt.Errorf("dest: %w", err)
+			}
+			if len(src) == 0 || dest == "" {
+				return nil, fmt.Errorf("src and dest required")
+			}
+
+			if flags, err = toFlags(m, "src", "dest"); err != nil {
+				return nil, err
+			}
+			args = append(src, dest)
+		default:
+			return nil, fmt.Errorf("expected list of sources and destination or map with src and dest, got %T", value)
+		}
+		return newInstructionNode(cmd, flags, args, false), nil
+
+	case "ENV", "LABEL":
+		v, ok := value.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("expected map, got %T", value)
+		}
+
+		m, err := toStringKeysMap(v)
+		if err != nil {
+			return nil, err
+		}
+
+		var args []string
+		for _, key := range sortedKeys(m) {
+			s, err := toScalarString(m[key])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			args = append(args, key, s)
+		}
+		return newInstructionNode(cmd, nil, args, false), nil
+
+	case "ARG":
+		var args []string
+		switch v := value.(type) {
+		case map[interface{}]interface{}:
+			m, err := toStringKeysMap(v)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, key := range sortedKeys(m) {
+				if m[key] == nil {
+					args = append(args, key)
+					continue
+				}
+
+				s, err := toScalarString(m[key])
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", key, err)
+				}
+				args = append(args, fmt.Sprintf("%s=%s", key, s))
+			}
+		default:
+			var err error
+			if args, err = toStrings(value); err != nil {
+				return nil, err
+			}
+		}
+		return newInstructionNode(cmd, nil, args, false), nil
+
+	case "EXPOSE", "VOLUME":
+		if s, ok := value.(string); ok {
+			return newInstructionNode(cmd, nil, strings.Fields(s), false), nil
+		}
+
+		args, err := toStrings(value)
+		if err != nil {
+			return nil, err
+		}
+		return newInstructionNode(cmd, nil, args, false), nil
+
+	case "WORKDIR", "USER", "STOPSIGNAL", "MAINTAINER":
+		s, err := toScalarString(value)
+		if err != nil {
+			return nil, err
+		}
+		return newInstructionNode(cmd, nil, []string{s}, false), nil
+
+	case "FROM":
+		return nil, fmt.Errorf("use the stage from directive instead")
+
+	case "ONBUILD":
+		return nil, fmt.Errorf("instruction is not supported")
+
+	default:
+		return nil, fmt.Errorf("unknown instruction")
+	}
+}
+
+// commandParts returns the flags and the command of the shell-dependent instruction: the string is the shell form,
+// the list is the exec form and the map contains the command and the flags.
+func commandParts(value interface{}) ([]string, []string, bool, error) {
+	switch v := value.(type) {
+	case string:
+		return nil, []string{v}, false, nil
+	case []interface{}:
+		args, err := toStrings(v)
+		if err != nil {
+			return nil, nil, false, err
+		}
+		return nil, args, true, nil
+	case map[interface{}]interface{}:
+		m, err := toStringKeysMap(v)
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		command, ok := m["command"]
+		if !ok {
+			return nil, nil, false, fmt.Errorf("command required")
+		}
+
+		_, args, isJSON, err := commandParts(command)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("command: %w", err)
+		}
+
+		flags, err := toFlags(m, "command")
+		if err != nil {
+			return nil, nil, false, err
+		}
+
+		return flags, args, isJSON, nil
+	default:
+		return nil, nil, false, fmt.Errorf("expected string, list or map, got %T", value)
+	}
+}
+
+// toFlags converts the map keys into the instruction flags sorted by name: the list produces the repeated flag
+// and the map produces the comma-separated key=value pairs (e.g. --mount=type=cache,target=/root/.cache).
+func toFlags(m map[string]interface{}, skipKeys ...string) ([]string, error) {
+	var flags []string
+
+FlagsLoop:
+	for _, key := range sortedKeys(m) {
+		for _, skipKey := range skipKeys {
+			if key == skipKey {
+				continue FlagsLoop
+			}
+		}
+
+		values := []interface{}{m[key]}
+		if list, ok := m[key].([]interface{}); ok {
+			values = list
+		}
+
+		for _, value := range values {
+			s, err := toFlagValue(value)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", key, err)
+			}
+			flags = append(flags, fmt.Sprintf("--%s=%s", key, s))
+		}
+	}
+
+	return flags, nil
+}
+
+func toFlagValue(value interface{}) (string, error) {
+	v, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return toScalarString(value)
+	}
+
+	m, err := toStringKeysMap(v)
+	if err != nil {
+		return "", err
+	}
+
+	var fields []string
+	for _, key := range sortedKeys(m) {
+		s, err := toScalarString(m[key])
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", key, err)
+		}
+		fields = append(fields, fmt.Sprintf("%s=%s", key, s))
+	}
+
+	// The fields are parsed by buildkit as csv.
+	buf := bytes.NewBuffer(nil)
+	w := csv.NewWriter(buf)
+	if err := w.Write(fields); err != nil {
+		return "", err
+	}
+	w.Flush()
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+func newInstructionNode(cmd string, flags, args []string, isJSON bool) *parser.Node {
+	original := []string{cmd}
+	original = append(original, flags...)
+	if isJSON {
+		data, _ := json.Marshal(args)
+		original = append(original, string(data))
+	} else {
+		original = append(original, args...)
+	}
+
+	node := &parser.Node{
+		Value:      strings.ToLower(cmd),
+		Flags:      flags,
+		Attributes: map[string]bool{"json": isJSON},
+		Original:   strings.Join(original, " "),
+	}
+
+	cur := node
+	for _, arg := range args {
+		cur.Next = &parser.Node{Value: arg}
+		cur = cur.Next
+	}
+
+	return node
+}
+
+func toStrings(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var res []string
+		for _, elm := range v {
+			s, err := toScalarString(elm)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, s)
+		}
+		return res, nil
+	default:
+		s, err := toScalarString(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{s}, nil
+	}
+}
+
+func toScalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("expected scalar value, got %T", value)
+	}
+}
+
+func toStringKeysMap(m map[interface{}]interface{}) (map[string]interface{}, error) {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		key, ok := k.(string)
+		if !ok {
+			return nil, fmt.Errorf("expected string key, got %T", k)
+		}
+		res[key] = v
+	}
+	return res, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,115 @@
+package frontend
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/moby/buildkit/frontend/dockerfile/instructions"
+
+	"github.com/werf/werf/pkg/dockerfile"
+)
+
+const testDockerfileYaml = `
+args:
+  BASE_IMAGE: alpine:3.18
+stages:
+- name: Builder
+  from: golang:1.21
+  instructions:
+  - WORKDIR: /src
+  - ENV:
+      CGO_ENABLED: 0
+      GOFLAGS: -mod=mod
+  - COPY: [go.mod, go.sum, ./]
+  - RUN:
+      command: go build -o /app .
+      network: none
+      mount:
+      - {type: cache, target: /root/.cache/go-build, id: go-build}
+- from: $BASE_IMAGE
+  instructions:
+  - COPY: {from: builder, src: /app, dest: /usr/local/bin/app, link: true}
+  - EXPOSE: 8080 8443/tcp
+  - USER: 1000
+  - ENTRYPOINT: ["/usr/local/bin/app"]
+`
+
+func TestParseDockerfileYamlWithBuildkit(t *testing.T) {
+	d, err := ParseDockerfileYamlWithBuildkit("id", []byte(testDockerfileYaml), "app", dockerfile.DockerfileOptions{})
+	if err != nil {
+		t.Fatalf("ParseDockerfileYamlWithBuildkit() error: %s", err)
+	}
+
+	if len(d.Stages) != 2 {
+		t.Fatalf("expected 2 stages, got %d", len(d.Stages))
+	}
+
+	builder, final := d.Stages[0], d.Stages[1]
+	if builder.StageName != "builder" || builder.BaseName != "golang:1.21" {
+		t.Errorf("unexpected builder stage %q from %q", builder.StageName, builder.BaseName)
+	}
+	if final.BaseName != "alpine:3.18" {
+		t.Errorf("meta arg is not expanded in the stage base name %q", final.BaseName)
+	}
+	if len(final.Dependencies) != 1 || final.Dependencies[0] != builder {
+		t.Errorf("final stage should depend on the builder stage")
+	}
+
+	env := builder.Instructions[1].(*dockerfile.DockerfileStageInstruction[*instructions.EnvCommand]).Data
+	expectedEnv := instructions.KeyValuePairs{{Key: "CGO_ENABLED", Value: "0"}, {Key: "GOFLAGS", Value: "-mod=mod"}}
+	if !reflect.DeepEqual(env.Env, expectedEnv) {
+		t.Errorf("unexpected ENV %v", env.Env)
+	}
+
+	run := builder.Instructions[3].(*dockerfile.DockerfileStageInstruction[*instructions.RunCommand]).Data
+	if !reflect.DeepEqual([]string(run.CmdLine), []string{"go build -o /app ."}) || !run.PrependShell {
+		t.Errorf("unexpected RUN command %v (prepend shell %v)", run.CmdLine, run.PrependShell)
+	}
+	if network := instructions.GetNetwork(run); network != instructions.NetworkNone {
+		t.Errorf("unexpected RUN network %q", network)
+	}
+	mounts := instructions.GetMounts(run)
+	if len(mounts) != 1 || mounts[0].Type != instructions.MountTypeCache || mounts[0].Target != "/root/.cache/go-build" || mounts[0].CacheID != "go-build" {
+		t.Errorf("unexpected RUN mounts %+v", mounts)
+	}
+
+	cp := final.Instructions[0].(*dockerfile.DockerfileStageInstruction[*instructions.CopyCommand]).Data
+	if cp.From != "builder" || !cp.Link || !reflect.DeepEqual(cp.SourcePaths, []string{"/app"}) || cp.DestPath != "/usr/local/bin/app" {
+		t.Errorf("unexpected COPY %+v", cp)
+	}
+
+	expose := final.Instructions[1].(*dockerfile.DockerfileStageInstruction[*instructions.ExposeCommand]).Data
+	if !reflect.DeepEqual(expose.Ports, []string{"8080", "8443/tcp"}) {
+		t.Errorf("unexpected EXPOSE ports %v", expose.Ports)
+	}
+
+	user := final.Instructions[2].(*dockerfile.DockerfileStageInstruction[*instructions.UserCommand]).Data
+	if user.User != "1000" {
+		t.Errorf("unexpected USER %q", user.User)
+	}
+
+	entrypoint := final.Instructions[3].(*dockerfile.DockerfileStageInstruction[*instructions.EntrypointCommand]).Data
+	if !reflect.DeepEqual([]string(entrypoint.CmdLine), []string{"/usr/local/bin/app"}) || entrypoint.PrependShell {
+		t.Errorf("unexpected ENTRYPOINT %v (prepend shell %v)", entrypoint.CmdLine, entrypoint.PrependShell)
+	}
+}
+
+func TestParseDockerfileYamlErrors(t *testing.T) {
+	for name, data := range map[string]string{
+		"no stages":             `stages: []`,
+		"no from":               `stages: [{instructions: [{RUN: echo}]}]`,
+		"invalid stage name":    `stages: [{name: 1st, from: alpine}]`,
+		"unknown directive":     `stages: [{from: alpine, image: alpine}]`,
+		"several instructions":  `stages: [{from: alpine, instructions: [{RUN: echo, USER: root}]}]`,
+		"unknown instruction":   `stages: [{from: alpine, instructions: [{FETCH: url}]}]`,
+		"from instruction":      `stages: [{from: alpine, instructions: [{FROM: alpine}]}]`,
+		"unknown flag":          `stages: [{from: alpine, instructions: [{RUN: {command: echo, unknown: true}}]}]`,
+		"copy without dest":     `stages: [{from: alpine, instructions: [{COPY: {src: app}}]}]`,
+		"run without command":   `stages: [{from: alpine, instructions: [{RUN: {network: none}}]}]`,
+		"env with nested value": `stages: [{from: alpine, instructions: [{ENV: {A: [1, 2]}}]}]`,
+	} {
+		if _, err := ParseDockerfileYamlWithBuildkit("id", []byte(data), "app", dockerfile.DockerfileOptions{}); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
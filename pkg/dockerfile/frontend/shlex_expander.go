@@ -17,5 +17,6 @@ func NewShlexExpanderFactory(escapeToken rune) *ShlexExpanderFactory {
 func (factory *ShlexExpanderFactory) GetExpander(opts dockerfile.ExpandOptions) dockerfile.Expander {
 	shlex := shell.NewLex(factory.EscapeToken)
 	shlex.SkipUnsetEnv = opts.SkipUnsetEnv
+	shlex.SkipProcessQuotes = opts.SkipProcessQuotes
 	return shlex
 }
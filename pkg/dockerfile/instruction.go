@@ -12,6 +12,8 @@ type InstructionDataInterface interface {
 
 type ExpandOptions struct {
 	SkipUnsetEnv bool
+	// SkipProcessQuotes preserves the quotes, the raw expansion is used for the heredoc content.
+	SkipProcessQuotes bool
 }
 
 type DockerfileStageInstructionInterface interface {
@@ -42,6 +44,9 @@ type DockerfileStageInstruction[T InstructionDataInterface] struct {
 	Env                    map[string]string
 	DependenciesByStageRef map[string]*DockerfileStage
 	ExpanderFactory        ExpanderFactory
+
+	// Parents is the COPY --parents flag, which is not supported by the buildkit parser and is handled by werf.
+	Parents bool
 }
 
 func NewDockerfileStageInstruction[T InstructionDataInterface](data T, opts DockerfileStageInstructionOptions) *DockerfileStageInstruction[T] {
@@ -102,6 +107,15 @@ func (i *DockerfileStageInstruction[T]) Expand(env map[string]string, opts Expan
 	}
 	expander := i.ExpanderFactory.GetExpander(opts)
 
+	if instr, ok := any(i.Data).(instructions.SupportsSingleWordExpansionRaw); ok {
+		rawExpander := i.ExpanderFactory.GetExpander(ExpandOptions{SkipUnsetEnv: opts.SkipUnsetEnv, SkipProcessQuotes: true})
+		if err := instr.ExpandRaw(func(word string) (string, error) {
+			return rawExpander.ProcessWordWithMap(word, env)
+		}); err != nil {
+			return fmt.Errorf("unable to expand heredoc: %w", err)
+		}
+	}
+
 	switch instr := any(i.Data).(type) {
 	case instructions.SupportsSingleWordExpansion:
 		return instr.Expand(func(word string) (string, error) {
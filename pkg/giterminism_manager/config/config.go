@@ -84,6 +84,10 @@ func (c Config) IsConfigStapelMountFromPathAccepted(fromPath string) bool {
 	return c.Config.Stapel.Mount.IsFromPathAccepted(fromPath)
 }
 
+func (c Config) IsUncommittedConfigStapelArchiveAccepted(relPath string) bool {
+	return c.Config.Stapel.Archive.IsUncommittedAccepted(relPath)
+}
+
 func (c Config) IsConfigDockerfileContextAddFileAccepted(relPath string) bool {
 	return c.Config.Dockerfile.IsContextAddFileAccepted(relPath)
 }
@@ -153,9 +157,10 @@ func (r goTemplateRendering) UncommittedFilePathMatcher() path_matcher.PathMatch
 }
 
 type stapel struct {
-	AllowFromLatest bool  `json:"allowFromLatest"`
-	Git             git   `json:"git"`
-	Mount           mount `json:"mount"`
+	AllowFromLatest bool    `json:"allowFromLatest"`
+	Git             git     `json:"git"`
+	Mount           mount   `json:"mount"`
+	Archive         archive `json:"archive"`
 }
 
 type git struct {
@@ -171,6 +176,14 @@ func (m mount) IsFromPathAccepted(path string) bool {
 	return isPathMatched(m.AllowFromPaths, path)
 }
 
+type archive struct {
+	AllowUncommitted []string `json:"allowUncommitted"`
+}
+
+func (a archive) IsUncommittedAccepted(path string) bool {
+	return isPathMatched(a.AllowUncommitted, path)
+}
+
 type dockerfile struct {
 	AllowUncommitted                  []string `json:"allowUncommitted"`
 	AllowUncommittedDockerignoreFiles []string `json:"allowUncommittedDockerignoreFiles"`
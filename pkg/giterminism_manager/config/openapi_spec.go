@@ -75,6 +75,8 @@ definitions:
         $ref: '#/definitions/ConfigStapelGit'
       mount:
         $ref: '#/definitions/ConfigStapelMount'
+      archive:
+        $ref: '#/definitions/ConfigStapelArchive'
   ConfigStapelGit:
     type: object
     additionalProperties: {}
@@ -91,6 +93,14 @@ definitions:
         type: array
         items:
           type: string
+  ConfigStapelArchive:
+    type: object
+    additionalProperties: {}
+    properties:
+      allowUncommitted:
+        type: array
+        items:
+          type: string
   ConfigDockerfile:
     type: object
     additionalProperties: {}
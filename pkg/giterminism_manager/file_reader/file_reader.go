@@ -24,6 +24,7 @@ type giterminismConfig interface {
 	IsUncommittedConfigAccepted() bool
 	UncommittedConfigTemplateFilePathMatcher() path_matcher.PathMatcher
 	UncommittedConfigGoTemplateRenderingFilePathMatcher() path_matcher.PathMatcher
+	IsUncommittedConfigStapelArchiveAccepted(relPath string) bool
 	IsUncommittedDockerfileAccepted(relPath string) bool
 	IsUncommittedDockerignoreAccepted(relPath string) bool
 	UncommittedHelmFilePathMatcher() path_matcher.PathMatcher
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/build/cache_mounts"
 	"github.com/werf/werf/pkg/container_backend"
 	"github.com/werf/werf/pkg/git_repo/gitdata"
 	"github.com/werf/werf/pkg/tmp_manager"
@@ -144,6 +145,15 @@ func RunHostCleanup(ctx context.Context, containerBackend container_backend.Cont
 		return err
 	}
 
+	if err := logboek.Context(ctx).Default().LogProcess("Running GC for build cache mounts").DoError(func() error {
+		if err := cache_mounts.RunGC(ctx, allowedLocalCacheVolumeUsagePercentage, allowedLocalCacheVolumeUsageMarginPercentage); err != nil {
+			return fmt.Errorf("build cache mounts GC failed: %w", err)
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
 	if options.CleanupDockerServer {
 		dockerServerStoragePath, err := getDockerServerStoragePath(ctx, options.DockerServerStoragePath)
 		if err != nil {
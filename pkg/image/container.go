@@ -4,6 +4,9 @@ type Container struct {
 	ID      string
 	ImageID string
 	Names   []string
+	// State is the container state (created, running, exited, etc.), it is empty if the backend does not support it.
+	State  string
+	Labels map[string]string
 }
 
 func (container Container) LogName() string {
@@ -11,6 +11,8 @@ type InfoGetter struct {
 	WerfImageName string
 	Repo          string
 	Tag           string
+	// PinnedName is the verified reference REPO:TAG@DIGEST, which is used instead of REPO:TAG if set.
+	PinnedName string
 
 	InfoGetterOptions
 }
@@ -39,6 +41,9 @@ func (d *InfoGetter) GetWerfImageName() string {
 }
 
 func (d *InfoGetter) GetName() string {
+	if d.PinnedName != "" {
+		return d.PinnedName
+	}
 	return fmt.Sprintf("%s:%s", d.Repo, d.GetTag())
 }
 
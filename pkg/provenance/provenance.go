@@ -0,0 +1,126 @@
+package provenance
+
+import (
+	"strings"
+	"time"
+)
+
+// The provenance is an in-toto statement with the SLSA provenance v0.2 predicate (https://slsa.dev/provenance/v0.2).
+const (
+	StatementType  = "https://in-toto.io/Statement/v0.1"
+	PredicateType  = "https://slsa.dev/provenance/v0.2"
+	MediaType      = "application/vnd.in-toto+json"
+	BuildType      = "https://werf.io/build/v1"
+	BuilderIDBase  = "https://werf.io/werf"
+	WerfConfigPath = "werf.yaml"
+)
+
+type Statement struct {
+	Type          string      `json:"_type"`
+	PredicateType string      `json:"predicateType"`
+	Subject       []Subject   `json:"subject"`
+	Predicate     *Provenance `json:"predicate"`
+}
+
+type Subject struct {
+	Name   string    `json:"name"`
+	Digest DigestSet `json:"digest"`
+}
+
+// DigestSet maps the algorithm to the hex-encoded digest (e.g. "sha256" => "abc...").
+type DigestSet map[string]string
+
+type Provenance struct {
+	Builder     Builder     `json:"builder"`
+	BuildType   string      `json:"buildType"`
+	Invocation  Invocation  `json:"invocation"`
+	BuildConfig BuildConfig `json:"buildConfig"`
+	Metadata    Metadata    `json:"metadata"`
+	Materials   []Material  `json:"materials,omitempty"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+type Invocation struct {
+	ConfigSource ConfigSource `json:"configSource"`
+	Parameters   Parameters   `json:"parameters"`
+}
+
+type ConfigSource struct {
+	URI        string    `json:"uri,omitempty"`
+	Digest     DigestSet `json:"digest,omitempty"`
+	EntryPoint string    `json:"entryPoint"`
+}
+
+type Parameters struct {
+	Image     string                 `json:"image"`
+	Platforms []string               `json:"platforms"`
+	BuildArgs map[string]interface{} `json:"buildArgs,omitempty"`
+}
+
+// BuildConfig lists the stages the image has been built from for each target platform.
+type BuildConfig struct {
+	Platforms map[string][]Stage `json:"platforms"`
+}
+
+type Stage struct {
+	Name string `json:"name"`
+	// Digest is the werf content-based stage digest.
+	Digest string `json:"digest"`
+	Image  string `json:"image"`
+	// ImageDigest is the digest of the stage image manifest.
+	ImageDigest string `json:"imageDigest,omitempty"`
+}
+
+type Metadata struct {
+	BuildStartedOn  *time.Time   `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn *time.Time   `json:"buildFinishedOn,omitempty"`
+	Completeness    Completeness `json:"completeness"`
+	Reproducible    bool         `json:"reproducible"`
+}
+
+type Completeness struct {
+	Parameters  bool `json:"parameters"`
+	Environment bool `json:"environment"`
+	Materials   bool `json:"materials"`
+}
+
+// Material is the source code repository, base image or imported werf image used to build the image.
+type Material struct {
+	URI    string    `json:"uri"`
+	Digest DigestSet `json:"digest,omitempty"`
+}
+
+func NewStatement(subjectName, subjectDigest string, predicate *Provenance) *Statement {
+	return &Statement{
+		Type:          StatementType,
+		PredicateType: PredicateType,
+		Subject: []Subject{
+			{Name: subjectName, Digest: ParseDigest(subjectDigest)},
+		},
+		Predicate: predicate,
+	}
+}
+
+// ParseDigest converts ALGORITHM:HEX digest into the digest set (nil if the digest is not valid).
+func ParseDigest(digest string) DigestSet {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil
+	}
+
+	return DigestSet{parts[0]: parts[1]}
+}
+
+// AddMaterial adds the material unless the material with the same URI already exists.
+func (p *Provenance) AddMaterial(material Material) {
+	for _, m := range p.Materials {
+		if m.URI == material.URI {
+			return
+		}
+	}
+
+	p.Materials = append(p.Materials, material)
+}
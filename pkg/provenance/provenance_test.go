@@ -0,0 +1,56 @@
+package provenance
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestNewStatement(t *testing.T) {
+	p := &Provenance{BuildType: BuildType}
+	p.AddMaterial(Material{URI: "git+https://example.com/project.git", Digest: DigestSet{"sha1": "abc"}})
+	p.AddMaterial(Material{URI: "git+https://example.com/project.git", Digest: DigestSet{"sha1": "abc"}})
+
+	statement := NewStatement("registry.example.com/project", "sha256:0123", p)
+
+	data, err := json.Marshal(statement)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	if parsed["_type"] != StatementType || parsed["predicateType"] != PredicateType {
+		t.Fatalf("unexpected statement type: %s", data)
+	}
+
+	expectedSubject := []interface{}{
+		map[string]interface{}{
+			"name":   "registry.example.com/project",
+			"digest": map[string]interface{}{"sha256": "0123"},
+		},
+	}
+	if !reflect.DeepEqual(parsed["subject"], expectedSubject) {
+		t.Fatalf("unexpected subject: %v", parsed["subject"])
+	}
+
+	if materials := parsed["predicate"].(map[string]interface{})["materials"].([]interface{}); len(materials) != 1 {
+		t.Fatalf("expected 1 material, got %d", len(materials))
+	}
+}
+
+func TestParseDigest(t *testing.T) {
+	for digest, expected := range map[string]DigestSet{
+		"sha256:0123": {"sha256": "0123"},
+		"0123":        nil,
+		"sha256:":     nil,
+		"":            nil,
+	} {
+		if actual := ParseDigest(digest); !reflect.DeepEqual(actual, expected) {
+			t.Errorf("ParseDigest(%q) = %v, expected %v", digest, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package sbom
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// CycloneDX 1.4 JSON document (https://cyclonedx.org/docs/1.4/json/).
+type cycloneDXDocument struct {
+	BOMFormat    string                 `json:"bomFormat"`
+	SpecVersion  string                 `json:"specVersion"`
+	Version      int                    `json:"version"`
+	Metadata     cycloneDXMetadata      `json:"metadata"`
+	Components   []cycloneDXComponent   `json:"components"`
+	Compositions []cycloneDXComposition `json:"compositions,omitempty"`
+}
+
+type cycloneDXMetadata struct {
+	Timestamp  string              `json:"timestamp"`
+	Tools      []cycloneDXTool     `json:"tools"`
+	Component  *cycloneDXComponent `json:"component,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXTool struct {
+	Vendor  string `json:"vendor"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string              `json:"bom-ref,omitempty"`
+	Type       string              `json:"type"`
+	Name       string              `json:"name"`
+	Version    string              `json:"version,omitempty"`
+	PURL       string              `json:"purl,omitempty"`
+	Licenses   []cycloneDXLicense  `json:"licenses,omitempty"`
+	Properties []cycloneDXProperty `json:"properties,omitempty"`
+}
+
+type cycloneDXLicense struct {
+	Expression string `json:"expression,omitempty"`
+	License    *struct {
+		Name string `json:"name"`
+	} `json:"license,omitempty"`
+}
+
+type cycloneDXComposition struct {
+	Aggregate string `json:"aggregate"`
+}
+
+type cycloneDXProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func (d *Document) marshalCycloneDX() ([]byte, error) {
+	doc := cycloneDXDocument{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.4",
+		Version:     1,
+		Metadata: cycloneDXMetadata{
+			Timestamp: d.Created.Format(time.RFC3339),
+			Tools:     []cycloneDXTool{{Vendor: "werf", Name: "werf", Version: d.ToolVersion}},
+			Component: &cycloneDXComponent{Type: "container", Name: d.Name},
+		},
+		Components: []cycloneDXComponent{},
+	}
+
+	if len(d.IncompleteReasons) > 0 {
+		doc.Compositions = []cycloneDXComposition{{Aggregate: "incomplete"}}
+		for _, reason := range d.IncompleteReasons {
+			doc.Metadata.Properties = append(doc.Metadata.Properties, cycloneDXProperty{Name: "werf:incomplete", Value: reason})
+		}
+	}
+
+	for _, pkg := range d.Packages {
+		component := cycloneDXComponent{
+			BOMRef:  pkg.PURL() + "#" + pkg.id(),
+			Type:    "library",
+			Name:    pkg.Name,
+			Version: pkg.Version,
+			PURL:    pkg.PURL(),
+			Properties: []cycloneDXProperty{
+				{Name: "werf:location", Value: pkg.Location},
+				{Name: "werf:platforms", Value: joinPlatforms(pkg.Platforms)},
+			},
+		}
+		if pkg.Namespace != "" && pkg.Type == PackageTypeNpm {
+			component.Name = pkg.Namespace + "/" + pkg.Name
+		}
+
+		if pkg.License != "" {
+			if spdxLicenseExpression.MatchString(pkg.License) {
+				component.Licenses = []cycloneDXLicense{{Expression: pkg.License}}
+			} else {
+				license := cycloneDXLicense{License: &struct {
+					Name string `json:"name"`
+				}{Name: pkg.License}}
+				component.Licenses = []cycloneDXLicense{license}
+			}
+		}
+
+		doc.Components = append(doc.Components, component)
+	}
+
+	return json.MarshalIndent(doc, "", "  ")
+}
@@ -0,0 +1,69 @@
+package sbom
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PURL returns the package URL (https://github.com/package-url/purl-spec).
+func (p *Package) PURL() string {
+	var b strings.Builder
+	b.WriteString("pkg:")
+	b.WriteString(string(p.Type))
+	b.WriteString("/")
+	if p.Namespace != "" {
+		b.WriteString(escapePURLPath(p.Namespace))
+		b.WriteString("/")
+	}
+	b.WriteString(escapePURLPath(p.purlName()))
+	if p.Version != "" {
+		b.WriteString("@")
+		b.WriteString(url.PathEscape(p.Version))
+	}
+
+	qualifiers := map[string]string{}
+	if p.Arch != "" {
+		qualifiers["arch"] = p.Arch
+	}
+	if p.distro != "" {
+		qualifiers["distro"] = p.distro
+	}
+	if len(qualifiers) > 0 {
+		var keys []string
+		for k := range qualifiers {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("?")
+		for i, k := range keys {
+			if i > 0 {
+				b.WriteString("&")
+			}
+			b.WriteString(k)
+			b.WriteString("=")
+			b.WriteString(url.QueryEscape(qualifiers[k]))
+		}
+	}
+
+	return b.String()
+}
+
+func (p *Package) purlName() string {
+	if p.Type == PackageTypePyPI {
+		// PyPI names are case-insensitive and underscores are normalized to dashes.
+		return strings.ReplaceAll(strings.ToLower(p.Name), "_", "-")
+	}
+	return p.Name
+}
+
+// escapePURLPath escapes each segment of the namespace or name keeping the separators.
+func escapePURLPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		// The "@" separates the version, so it is escaped too (e.g. the npm scope "@babel" becomes "%40babel").
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "@", "%40")
+	}
+	return strings.Join(segments, "/")
+}
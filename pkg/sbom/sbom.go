@@ -0,0 +1,132 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatSPDX      Format = "spdx"
+	FormatCycloneDX Format = "cyclonedx"
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch f := Format(format); f {
+	case FormatSPDX, FormatCycloneDX:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported SBOM format %q: %q or %q expected", format, FormatSPDX, FormatCycloneDX)
+	}
+}
+
+// MediaType returns the media type of the SBOM document, it is also used as the OCI artifact type.
+func (f Format) MediaType() string {
+	switch f {
+	case FormatSPDX:
+		return "application/spdx+json"
+	case FormatCycloneDX:
+		return "application/vnd.cyclonedx+json"
+	default:
+		panic(fmt.Sprintf("unexpected SBOM format %q", f))
+	}
+}
+
+type PackageType string
+
+const (
+	PackageTypeDeb    PackageType = "deb"
+	PackageTypeApk    PackageType = "apk"
+	PackageTypePyPI   PackageType = "pypi"
+	PackageTypeNpm    PackageType = "npm"
+	PackageTypeGolang PackageType = "golang"
+)
+
+type Package struct {
+	Type    PackageType
+	Name    string
+	Version string
+	// Namespace is the distro for OS packages or the npm scope.
+	Namespace string
+	Arch      string
+	License   string
+	// Location is the path of the file the package has been found in.
+	Location string
+	// Platforms are the target platforms of the images the package has been found in.
+	Platforms []string
+
+	distro string
+}
+
+// id is unique for the package found in the particular location.
+func (p *Package) id() string {
+	sum := sha256.Sum256([]byte(p.PURL() + "\x00" + p.Location))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func joinPlatforms(platforms []string) string {
+	return strings.Join(platforms, ",")
+}
+
+// Document is the list of packages found in the image (all platforms of the multiplatform image).
+type Document struct {
+	Name        string
+	ToolVersion string
+	Created     time.Time
+	Packages    []*Package
+	// IncompleteReasons are set when some packages of the image have not been found, the document is marked incomplete.
+	IncompleteReasons []string
+}
+
+func NewDocument(name, toolVersion string) *Document {
+	return &Document{Name: name, ToolVersion: toolVersion, Created: time.Now().UTC()}
+}
+
+// AddPackages adds the packages found in the image for the platform, the same packages found for different platforms are merged.
+func (d *Document) AddPackages(platform string, packages []*Package) {
+AddPackages:
+	for _, pkg := range packages {
+		for _, existing := range d.Packages {
+			if existing.PURL() == pkg.PURL() && existing.Location == pkg.Location {
+				existing.Platforms = append(existing.Platforms, platform)
+				continue AddPackages
+			}
+		}
+
+		pkg.Platforms = append(pkg.Platforms, platform)
+		d.Packages = append(d.Packages, pkg)
+	}
+
+	sort.SliceStable(d.Packages, func(i, j int) bool {
+		if d.Packages[i].PURL() != d.Packages[j].PURL() {
+			return d.Packages[i].PURL() < d.Packages[j].PURL()
+		}
+		return d.Packages[i].Location < d.Packages[j].Location
+	})
+}
+
+// MarkIncomplete records the reason why the document is incomplete, the same reasons are recorded once.
+func (d *Document) MarkIncomplete(reason string) {
+	for _, existing := range d.IncompleteReasons {
+		if existing == reason {
+			return
+		}
+	}
+	d.IncompleteReasons = append(d.IncompleteReasons, reason)
+}
+
+func (d *Document) Marshal(format Format) ([]byte, error) {
+	switch format {
+	case FormatSPDX:
+		return d.marshalSPDX()
+	case FormatCycloneDX:
+		return d.marshalCycloneDX()
+	default:
+		panic(fmt.Sprintf("unexpected SBOM format %q", format))
+	}
+}
@@ -0,0 +1,276 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestScan(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, root, "etc/os-release", `ID=debian
+VERSION_ID="11"
+`)
+	writeFile(t, root, "var/lib/dpkg/status", `Package: bash
+Status: install ok installed
+Architecture: amd64
+Version: 5.1-2+deb11u1
+Description: GNU Bourne Again SHell
+ Bash is an sh-compatible command language interpreter.
+
+Package: removed
+Status: deinstall ok config-files
+Version: 1.0
+`)
+	writeFile(t, root, "lib/apk/db/installed", `P:musl
+V:1.2.3-r4
+A:x86_64
+L:MIT
+
+P:busybox
+V:1.35.0-r29
+`)
+	writeFile(t, root, "usr/lib/python3/dist-packages/PyYAML-6.0.dist-info/METADATA", `Metadata-Version: 2.1
+Name: PyYAML
+Version: 6.0
+License: MIT
+
+Long description.
+`)
+	writeFile(t, root, "app/node_modules/lodash/package.json", `{"name": "lodash", "version": "4.17.21", "license": "MIT"}`)
+	writeFile(t, root, "app/node_modules/@babel/core/package.json", `{"name": "@babel/core", "version": "7.20.0", "license": {"type": "MIT"}}`)
+	writeFile(t, root, "app/node_modules/lodash/fp/package.json", `{"name": "not-a-package"}`)
+	writeFile(t, root, "proc/node_modules/ignored/package.json", `{"name": "ignored", "version": "1.0.0"}`)
+
+	if err := os.Symlink("/etc/shadow", filepath.Join(root, "app/node_modules/link")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.IncompleteReasons) != 0 {
+		t.Errorf("unexpected incomplete reasons: %v", result.IncompleteReasons)
+	}
+	packages := result.Packages
+
+	var purls []string
+	for _, pkg := range packages {
+		purls = append(purls, pkg.PURL())
+	}
+	sort.Strings(purls)
+
+	expected := []string{
+		"pkg:apk/alpine/busybox@1.35.0-r29?distro=debian-11",
+		"pkg:apk/alpine/musl@1.2.3-r4?arch=x86_64&distro=debian-11",
+		"pkg:deb/debian/bash@5.1-2+deb11u1?arch=amd64&distro=debian-11",
+		"pkg:npm/%40babel/core@7.20.0",
+		"pkg:npm/lodash@4.17.21",
+		"pkg:pypi/pyyaml@6.0",
+	}
+	if len(purls) != len(expected) {
+		t.Fatalf("expected packages %v, got %v", expected, purls)
+	}
+	for i := range expected {
+		if purls[i] != expected[i] {
+			t.Errorf("expected package %q, got %q", expected[i], purls[i])
+		}
+	}
+
+	for _, pkg := range packages {
+		if pkg.Type == PackageTypeNpm && pkg.Name == "core" && pkg.License != "MIT" {
+			t.Errorf("expected MIT license for %s, got %q", pkg.PURL(), pkg.License)
+		}
+		if pkg.Type == PackageTypeDeb && pkg.Location != "/var/lib/dpkg/status" {
+			t.Errorf("unexpected location %q for %s", pkg.Location, pkg.PURL())
+		}
+	}
+}
+
+func TestScanDoesNotFollowSymlinksOutsideRoot(t *testing.T) {
+	outside := t.TempDir()
+	writeFile(t, outside, "dpkg/status", "Package: outside\nVersion: 1.0\n")
+	writeFile(t, outside, "os-release", "ID=outside\n")
+
+	root := t.TempDir()
+	writeFile(t, root, "etc/.keep", "")
+	if err := os.Symlink(filepath.Join(outside, "os-release"), filepath.Join(root, "etc/os-release")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(root, "var/lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "dpkg"), filepath.Join(root, "var/lib/dpkg")); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Packages) != 0 {
+		t.Fatalf("expected no packages, got %d: %s", len(result.Packages), result.Packages[0].PURL())
+	}
+}
+
+func TestScanMarksRpmImagesIncomplete(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          map[string]string
+		expectedReason string
+	}{
+		{
+			name: "rpm database",
+			files: map[string]string{
+				"etc/os-release":           "ID=\"rocky\"\nID_LIKE=\"rhel centos fedora\"\n",
+				"var/lib/rpm/rpmdb.sqlite": "SQLite format 3\x00",
+			},
+			expectedReason: "RPM database /var/lib/rpm/rpmdb.sqlite is not supported",
+		},
+		{
+			name: "rpm-based distro without database",
+			files: map[string]string{
+				"etc/os-release": "ID=\"ubi-micro\"\nID_LIKE=\"rhel\"\n",
+			},
+			expectedReason: "RPM database of the ubi-micro distro is not found",
+		},
+		{
+			name: "rpm database of the unknown distro",
+			files: map[string]string{
+				"usr/lib/sysimage/rpm/Packages.db": "data",
+			},
+			expectedReason: "RPM database /usr/lib/sysimage/rpm/Packages.db is not supported",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for path, content := range tt.files {
+				writeFile(t, root, path, content)
+			}
+
+			result, err := Scan(root)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(result.IncompleteReasons) != 1 || !strings.Contains(result.IncompleteReasons[0], tt.expectedReason) {
+				t.Fatalf("expected incomplete reason %q, got %v", tt.expectedReason, result.IncompleteReasons)
+			}
+		})
+	}
+}
+
+func TestDocumentMarshal(t *testing.T) {
+	doc := NewDocument("app", "dev")
+	doc.AddPackages("linux/amd64", []*Package{
+		{Type: PackageTypeNpm, Name: "lodash", Version: "4.17.21", License: "MIT", Location: "/app/node_modules/lodash/package.json"},
+	})
+	doc.AddPackages("linux/arm64", []*Package{
+		{Type: PackageTypeNpm, Name: "lodash", Version: "4.17.21", License: "MIT", Location: "/app/node_modules/lodash/package.json"},
+		{Type: PackageTypeGolang, Name: "github.com/werf/werf", Version: "v1.2.0", License: "Apache License, Version 2.0", Location: "/usr/bin/werf"},
+	})
+
+	if len(doc.Packages) != 2 {
+		t.Fatalf("expected 2 merged packages, got %d", len(doc.Packages))
+	}
+	if platforms := joinPlatforms(doc.Packages[1].Platforms); platforms != "linux/amd64,linux/arm64" {
+		t.Errorf("unexpected platforms of the merged package: %q", platforms)
+	}
+
+	spdxData, err := doc.Marshal(FormatSPDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spdx spdxDocument
+	if err := json.Unmarshal(spdxData, &spdx); err != nil {
+		t.Fatal(err)
+	}
+	if spdx.SPDXVersion != "SPDX-2.3" || len(spdx.Packages) != 2 || len(spdx.Relationships) != 2 {
+		t.Fatalf("unexpected SPDX document: %s", spdxData)
+	}
+	if spdx.Packages[0].LicenseDeclared != spdxNoAssertion {
+		t.Errorf("expected free-form license not to be declared, got %q", spdx.Packages[0].LicenseDeclared)
+	}
+	if spdx.Packages[1].LicenseDeclared != "MIT" {
+		t.Errorf("expected MIT license, got %q", spdx.Packages[1].LicenseDeclared)
+	}
+
+	cycloneDXData, err := doc.Marshal(FormatCycloneDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cycloneDX cycloneDXDocument
+	if err := json.Unmarshal(cycloneDXData, &cycloneDX); err != nil {
+		t.Fatal(err)
+	}
+	if cycloneDX.BOMFormat != "CycloneDX" || len(cycloneDX.Components) != 2 {
+		t.Fatalf("unexpected CycloneDX document: %s", cycloneDXData)
+	}
+	if cycloneDX.Components[1].PURL != "pkg:npm/lodash@4.17.21" {
+		t.Errorf("unexpected purl %q", cycloneDX.Components[1].PURL)
+	}
+	if len(cycloneDX.Compositions) != 0 || spdx.CreationInfo.Comment != "" {
+		t.Errorf("expected complete documents:\n%s\n%s", spdxData, cycloneDXData)
+	}
+}
+
+func TestDocumentMarshalIncomplete(t *testing.T) {
+	doc := NewDocument("app", "dev")
+	doc.MarkIncomplete("linux/amd64: RPM database /var/lib/rpm/rpmdb.sqlite is not supported, OS packages are missing")
+	doc.MarkIncomplete("linux/amd64: RPM database /var/lib/rpm/rpmdb.sqlite is not supported, OS packages are missing")
+
+	spdxData, err := doc.Marshal(FormatSPDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spdx spdxDocument
+	if err := json.Unmarshal(spdxData, &spdx); err != nil {
+		t.Fatal(err)
+	}
+	if spdx.CreationInfo.Comment != "The document is incomplete: linux/amd64: RPM database /var/lib/rpm/rpmdb.sqlite is not supported, OS packages are missing." {
+		t.Errorf("unexpected SPDX creation comment %q", spdx.CreationInfo.Comment)
+	}
+
+	cycloneDXData, err := doc.Marshal(FormatCycloneDX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cycloneDX cycloneDXDocument
+	if err := json.Unmarshal(cycloneDXData, &cycloneDX); err != nil {
+		t.Fatal(err)
+	}
+	if len(cycloneDX.Compositions) != 1 || cycloneDX.Compositions[0].Aggregate != "incomplete" {
+		t.Errorf("expected incomplete composition, got %v", cycloneDX.Compositions)
+	}
+	if len(cycloneDX.Metadata.Properties) != 1 || cycloneDX.Metadata.Properties[0].Name != "werf:incomplete" {
+		t.Errorf("unexpected metadata properties %v", cycloneDX.Metadata.Properties)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for _, format := range []string{"spdx", "cyclonedx"} {
+		if _, err := ParseFormat(format); err != nil {
+			t.Errorf("unexpected error for %q: %s", format, err)
+		}
+	}
+
+	if _, err := ParseFormat("syft"); err == nil {
+		t.Errorf("expected error for unsupported format")
+	}
+}
+
+func writeFile(t *testing.T, root, path, content string) {
+	p := filepath.Join(root, path)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,503 @@
+package sbom
+
+import (
+	"bufio"
+	"bytes"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/textproto"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	securejoin "github.com/cyphar/filepath-securejoin"
+)
+
+// Binaries bigger than this are not inspected for the Go build information.
+const maxGoBinarySize = 256 * 1024 * 1024
+
+var skipDirs = map[string]bool{
+	"/proc": true,
+	"/sys":  true,
+	"/dev":  true,
+}
+
+// rpmDBPaths are the locations of the RPM database in the sqlite, NDB and BerkeleyDB formats.
+var rpmDBPaths = []string{
+	"/var/lib/rpm/rpmdb.sqlite",
+	"/var/lib/rpm/Packages.db",
+	"/var/lib/rpm/Packages",
+	"/usr/lib/sysimage/rpm/rpmdb.sqlite",
+	"/usr/lib/sysimage/rpm/Packages.db",
+}
+
+// rpmDistroIDs are the os-release ID and ID_LIKE values of the distros which use rpm.
+var rpmDistroIDs = map[string]bool{
+	"rhel":       true,
+	"fedora":     true,
+	"centos":     true,
+	"rocky":      true,
+	"almalinux":  true,
+	"ol":         true,
+	"amzn":       true,
+	"suse":       true,
+	"opensuse":   true,
+	"sles":       true,
+	"mariner":    true,
+	"azurelinux": true,
+	"photon":     true,
+}
+
+type ScanResult struct {
+	Packages []*Package
+	// IncompleteReasons explain why some packages of the image might be missing from the result.
+	IncompleteReasons []string
+}
+
+// Scan finds OS packages and language dependencies in the image filesystem mounted into the root directory.
+// Symlinks are not followed outside the root, paths in the found packages are relative to the image root.
+func Scan(root string) (*ScanResult, error) {
+	distroID, distroVersion, distroIDLike := readOSRelease(root)
+	distro := distroID
+	if distroVersion != "" {
+		distro = fmt.Sprintf("%s-%s", distroID, distroVersion)
+	}
+
+	var res []*Package
+	var incompleteReasons []string
+
+	if packages, err := scanDpkgStatus(root, "/var/lib/dpkg/status", distroID, distro); err != nil {
+		return nil, err
+	} else {
+		res = append(res, packages...)
+	}
+
+	statusDir, err := securejoin.SecureJoin(root, "var/lib/dpkg/status.d")
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve dpkg status dir: %w", err)
+	}
+	if entries, err := os.ReadDir(statusDir); err == nil {
+		for _, entry := range entries {
+			if !entry.Type().IsRegular() || strings.HasSuffix(entry.Name(), ".md5sums") {
+				continue
+			}
+
+			packages, err := scanDpkgStatus(root, path.Join("/var/lib/dpkg/status.d", entry.Name()), distroID, distro)
+			if err != nil {
+				return nil, err
+			}
+			res = append(res, packages...)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("unable to read dir %q: %w", statusDir, err)
+	}
+
+	if packages, err := scanApkInstalled(root, "/lib/apk/db/installed", distro); err != nil {
+		return nil, err
+	} else {
+		res = append(res, packages...)
+	}
+
+	if dbPath, err := findRpmDB(root); err != nil {
+		return nil, err
+	} else if dbPath != "" {
+		incompleteReasons = append(incompleteReasons, fmt.Sprintf("RPM database %s is not supported, OS packages are missing", dbPath))
+	} else if isRpmDistro(distroID, distroIDLike) {
+		incompleteReasons = append(incompleteReasons, fmt.Sprintf("RPM database of the %s distro is not found, OS packages are missing", distroID))
+	}
+
+	err = filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				return nil
+			}
+			return err
+		}
+
+		relPath, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		imagePath := path.Join("/", filepath.ToSlash(relPath))
+
+		if d.IsDir() {
+			if skipDirs[imagePath] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		pkg, err := scanFile(p, imagePath, d)
+		if err != nil {
+			return fmt.Errorf("unable to scan %q: %w", imagePath, err)
+		}
+		if pkg != nil {
+			res = append(res, pkg)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to walk image filesystem: %w", err)
+	}
+
+	return &ScanResult{Packages: res, IncompleteReasons: incompleteReasons}, nil
+}
+
+func scanFile(p, imagePath string, d fs.DirEntry) (*Package, error) {
+	dir, base := path.Split(imagePath)
+	dir = strings.TrimSuffix(dir, "/")
+
+	switch {
+	case base == "METADATA" && strings.HasSuffix(dir, ".dist-info"), base == "PKG-INFO" && strings.HasSuffix(dir, ".egg-info"):
+		return scanPythonMetadata(p, imagePath)
+	case base == "package.json" && path.Base(path.Dir(dir)) == "node_modules",
+		base == "package.json" && path.Base(path.Dir(path.Dir(dir))) == "node_modules" && strings.HasPrefix(path.Base(path.Dir(dir)), "@"):
+		return scanNpmPackage(p, imagePath)
+	}
+
+	info, err := d.Info()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if info.Mode().Perm()&0o111 != 0 && info.Size() > 4 && info.Size() <= maxGoBinarySize {
+		return scanGoBinary(p, imagePath)
+	}
+
+	return nil, nil
+}
+
+// readOSRelease returns the distro ID, VERSION_ID and ID_LIKE from the os-release file.
+func readOSRelease(root string) (string, string, string) {
+	for _, p := range []string{"etc/os-release", "usr/lib/os-release"} {
+		data, err := readImageFile(root, p)
+		if err != nil || data == nil {
+			continue
+		}
+
+		fields := map[string]string{}
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			key, value, found := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+			if !found || strings.HasPrefix(key, "#") {
+				continue
+			}
+			fields[key] = strings.Trim(value, `"'`)
+		}
+
+		return fields["ID"], fields["VERSION_ID"], fields["ID_LIKE"]
+	}
+
+	return "", "", ""
+}
+
+func isRpmDistro(distroID, distroIDLike string) bool {
+	for _, id := range append([]string{distroID}, strings.Fields(distroIDLike)...) {
+		if rpmDistroIDs[id] {
+			return true
+		}
+	}
+	return false
+}
+
+// findRpmDB returns the path of the first RPM database found in the image.
+func findRpmDB(root string) (string, error) {
+	for _, dbPath := range rpmDBPaths {
+		dir, err := securejoin.SecureJoin(root, path.Dir(dbPath))
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve %q: %w", dbPath, err)
+		}
+
+		info, err := os.Lstat(filepath.Join(dir, path.Base(dbPath)))
+		if err != nil {
+			if os.IsNotExist(err) || os.IsPermission(err) {
+				continue
+			}
+			return "", fmt.Errorf("unable to stat %q: %w", dbPath, err)
+		}
+		if info.Mode().IsRegular() && info.Size() > 0 {
+			return dbPath, nil
+		}
+	}
+
+	return "", nil
+}
+
+// scanDpkgStatus parses the dpkg status file which consists of RFC 822-like paragraphs separated by empty lines.
+func scanDpkgStatus(root, statusPath, distroID, distro string) ([]*Package, error) {
+	data, err := readImageFile(root, statusPath)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	paragraphs := parseControlParagraphs(data)
+
+	var res []*Package
+	for _, fields := range paragraphs {
+		if fields.Get("Package") == "" {
+			continue
+		}
+		if status := fields.Get("Status"); status != "" && !strings.HasSuffix(status, " installed") {
+			continue
+		}
+
+		res = append(res, &Package{
+			Type:      PackageTypeDeb,
+			Name:      fields.Get("Package"),
+			Version:   fields.Get("Version"),
+			Namespace: distroID,
+			Arch:      fields.Get("Architecture"),
+			Location:  statusPath,
+			distro:    distro,
+		})
+	}
+
+	return res, nil
+}
+
+// scanApkInstalled parses the apk database which consists of "K:value" lines grouped into paragraphs.
+func scanApkInstalled(root, dbPath, distro string) ([]*Package, error) {
+	data, err := readImageFile(root, dbPath)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var res []*Package
+	var pkg *Package
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			pkg = nil
+			continue
+		}
+
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+
+		if pkg == nil {
+			pkg = &Package{Type: PackageTypeApk, Namespace: "alpine", Location: dbPath, distro: distro}
+			res = append(res, pkg)
+		}
+
+		switch key {
+		case "P":
+			pkg.Name = value
+		case "V":
+			pkg.Version = value
+		case "A":
+			pkg.Arch = value
+		case "L":
+			pkg.License = value
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read %q: %w", dbPath, err)
+	}
+
+	return filterNamed(res), nil
+}
+
+func scanPythonMetadata(p, imagePath string) (*Package, error) {
+	paragraphs, err := readControlParagraphs(p)
+	if err != nil || len(paragraphs) == 0 {
+		return nil, err
+	}
+
+	fields := paragraphs[0]
+	if fields.Get("Name") == "" {
+		return nil, nil
+	}
+
+	return &Package{
+		Type:     PackageTypePyPI,
+		Name:     fields.Get("Name"),
+		Version:  fields.Get("Version"),
+		License:  fields.Get("License"),
+		Location: imagePath,
+	}, nil
+}
+
+func scanNpmPackage(p, imagePath string) (*Package, error) {
+	data, err := readRegularFile(p)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var packageJSON struct {
+		Name    string          `json:"name"`
+		Version string          `json:"version"`
+		License json.RawMessage `json:"license"`
+	}
+	if err := json.Unmarshal(data, &packageJSON); err != nil || packageJSON.Name == "" {
+		// Broken package.json files are not the reason to fail the build.
+		return nil, nil
+	}
+
+	pkg := &Package{
+		Type:     PackageTypeNpm,
+		Name:     packageJSON.Name,
+		Version:  packageJSON.Version,
+		Location: imagePath,
+	}
+
+	if scope, name, found := strings.Cut(packageJSON.Name, "/"); found && strings.HasPrefix(scope, "@") {
+		pkg.Namespace = scope
+		pkg.Name = name
+	}
+
+	// The license is either the SPDX expression or the deprecated {"type": "..."} object.
+	var license string
+	if err := json.Unmarshal(packageJSON.License, &license); err == nil {
+		pkg.License = license
+	} else {
+		var licenseObject struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(packageJSON.License, &licenseObject); err == nil {
+			pkg.License = licenseObject.Type
+		}
+	}
+
+	return pkg, nil
+}
+
+func scanGoBinary(p, imagePath string) (*Package, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil || !bytes.Equal(magic, []byte("\x7fELF")) {
+		return nil, nil
+	}
+
+	info, err := buildinfo.Read(f)
+	if err != nil {
+		// Not a Go binary or the binary is built without the module information.
+		return nil, nil
+	}
+
+	if info.Main.Path == "" {
+		return nil, nil
+	}
+
+	version := info.Main.Version
+	if version == "(devel)" {
+		version = ""
+	}
+
+	return &Package{
+		Type:     PackageTypeGolang,
+		Name:     info.Main.Path,
+		Version:  version,
+		Location: imagePath,
+	}, nil
+}
+
+// readControlParagraphs reads the file in the RFC 822-like format used by dpkg and python package metadata.
+func readControlParagraphs(p string) ([]textproto.MIMEHeader, error) {
+	data, err := readRegularFile(p)
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	return parseControlParagraphs(data), nil
+}
+
+func parseControlParagraphs(data []byte) []textproto.MIMEHeader {
+	var res []textproto.MIMEHeader
+	for _, paragraph := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n\n") {
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+
+		fields := textproto.MIMEHeader{}
+		for _, line := range strings.Split(paragraph, "\n") {
+			if line == "" {
+				continue
+			}
+
+			if line[0] == ' ' || line[0] == '\t' {
+				// Continuation of the multiline value, only the first line is needed.
+				continue
+			}
+
+			key, value, found := strings.Cut(line, ":")
+			if !found {
+				continue
+			}
+			if key = strings.TrimSpace(key); fields.Get(key) == "" {
+				fields.Set(key, strings.TrimSpace(value))
+			}
+		}
+
+		res = append(res, fields)
+	}
+
+	return res
+}
+
+// readImageFile reads the file by the image path, parent dirs are resolved inside the root
+// so that symlinks from the image cannot point outside of it.
+func readImageFile(root, imagePath string) ([]byte, error) {
+	dir, err := securejoin.SecureJoin(root, path.Dir(path.Join("/", imagePath)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve %q: %w", imagePath, err)
+	}
+
+	return readRegularFile(filepath.Join(dir, path.Base(imagePath)))
+}
+
+// readRegularFile returns nil if the file does not exist or is not a regular file (symlinks are not followed).
+func readRegularFile(p string) ([]byte, error) {
+	info, err := os.Lstat(p)
+	if err != nil {
+		if os.IsNotExist(err) || os.IsPermission(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to stat %q: %w", p, err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsPermission(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unable to read %q: %w", p, err)
+	}
+
+	return data, nil
+}
+
+func filterNamed(packages []*Package) []*Package {
+	var res []*Package
+	for _, pkg := range packages {
+		if pkg.Name != "" {
+			res = append(res, pkg)
+		}
+	}
+	return res
+}
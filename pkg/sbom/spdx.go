@@ -0,0 +1,113 @@
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SPDX 2.3 JSON document (https://spdx.github.io/spdx-spec/v2.3/).
+type spdxDocument struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+	Comment  string   `json:"comment,omitempty"`
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	LicenseDeclared  string            `json:"licenseDeclared"`
+	SourceInfo       string            `json:"sourceInfo,omitempty"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const spdxNoAssertion = "NOASSERTION"
+
+var spdxIDInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9.-]`)
+
+func (d *Document) marshalSPDX() ([]byte, error) {
+	doc := spdxDocument{
+		SPDXVersion: "SPDX-2.3",
+		DataLicense: "CC0-1.0",
+		SPDXID:      "SPDXRef-DOCUMENT",
+		Name:        d.Name,
+		CreationInfo: spdxCreationInfo{
+			Created:  d.Created.Format(time.RFC3339),
+			Creators: []string{fmt.Sprintf("Tool: werf-%s", d.ToolVersion)},
+		},
+		Packages:      []spdxPackage{},
+		Relationships: []spdxRelationship{},
+	}
+
+	if len(d.IncompleteReasons) > 0 {
+		doc.CreationInfo.Comment = fmt.Sprintf("The document is incomplete: %s.", strings.Join(d.IncompleteReasons, "; "))
+	}
+
+	namespaceHash := sha256.New()
+	for _, pkg := range d.Packages {
+		namespaceHash.Write([]byte(pkg.PURL() + pkg.Location + "\n"))
+
+		spdxID := "SPDXRef-Package-" + spdxIDInvalidChars.ReplaceAllString(fmt.Sprintf("%s-%s-%s", pkg.Type, pkg.Name, pkg.id()), "-")
+
+		license := spdxNoAssertion
+		if pkg.License != "" {
+			// Free-form licenses are not valid SPDX expressions, so the license is only declared when it looks like one.
+			if spdxLicenseExpression.MatchString(pkg.License) {
+				license = pkg.License
+			}
+		}
+
+		doc.Packages = append(doc.Packages, spdxPackage{
+			SPDXID:           spdxID,
+			Name:             pkg.Name,
+			VersionInfo:      pkg.Version,
+			DownloadLocation: spdxNoAssertion,
+			LicenseDeclared:  license,
+			SourceInfo:       fmt.Sprintf("found in %s (%s)", pkg.Location, joinPlatforms(pkg.Platforms)),
+			ExternalRefs: []spdxExternalRef{
+				{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: pkg.PURL()},
+			},
+		})
+		doc.Relationships = append(doc.Relationships, spdxRelationship{
+			SPDXElementID:      doc.SPDXID,
+			RelationshipType:   "DESCRIBES",
+			RelatedSPDXElement: spdxID,
+		})
+	}
+
+	doc.DocumentNamespace = fmt.Sprintf("https://werf.io/spdx/%s-%s", spdxIDInvalidChars.ReplaceAllString(d.Name, "-"), hex.EncodeToString(namespaceHash.Sum(nil))[:16])
+
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+var spdxLicenseExpression = regexp.MustCompile(`^[A-Za-z0-9.+-]+( (AND|OR|WITH) [A-Za-z0-9.+-]+)*$`)
@@ -12,8 +12,16 @@ import (
 	"strings"
 )
 
+// aesGcmFormatHeader starts every value encrypted in the current versioned format: "WS" magic followed by the format version.
+// Legacy AES-CBC values always start with the little-endian IV size (0x10 0x00), so formats cannot be confused.
+var aesGcmFormatHeader = []byte{'W', 'S', 2}
+
 type AesEncoder struct {
 	CipherBlock cipher.Block
+
+	// LegacyFormat makes Encrypt produce values in the legacy AES-CBC format without integrity check.
+	// Decrypt always supports both formats.
+	LegacyFormat bool
 }
 
 func GenerateAesSecretKey() ([]byte, error) {
@@ -38,11 +46,37 @@ func NewAesEncoder(key []byte) (*AesEncoder, error) {
 		return nil, err
 	}
 
-	secret := &AesEncoder{c}
+	secret := &AesEncoder{CipherBlock: c}
 	return secret, nil
 }
 
 func (s *AesEncoder) Encrypt(data []byte) ([]byte, error) {
+	if s.LegacyFormat {
+		return s.encryptLegacy(data)
+	}
+
+	aead, err := cipher.NewGCM(s.CipherBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	var args []byte
+	args = append(args, aesGcmFormatHeader...)
+	args = append(args, nonce...)
+	args = aead.Seal(args, nonce, data, aesGcmFormatHeader)
+
+	result := make([]byte, hex.EncodedLen(len(args)))
+	hex.Encode(result, args)
+
+	return result, nil
+}
+
+func (s *AesEncoder) encryptLegacy(data []byte) ([]byte, error) {
 	dataToEncrypt := pad(data)
 
 	cipherData := make([]byte, aes.BlockSize+len(dataToEncrypt))
@@ -77,6 +111,36 @@ func (s *AesEncoder) Decrypt(data []byte) ([]byte, error) {
 		return nil, err
 	}
 
+	if IsAesGcmFormat(dataToExtract) {
+		return s.decryptGcm(dataToExtract)
+	}
+
+	return s.decryptLegacy(dataToExtract)
+}
+
+func (s *AesEncoder) decryptGcm(dataToExtract []byte) ([]byte, error) {
+	aead, err := cipher.NewGCM(s.CipherBlock)
+	if err != nil {
+		return nil, err
+	}
+
+	minimalDataBinarySize := len(aesGcmFormatHeader) + aead.NonceSize() + aead.Overhead()
+	if len(dataToExtract) < minimalDataBinarySize {
+		return nil, fmt.Errorf("minimum required data length: '%v'", minimalDataBinarySize*2)
+	}
+
+	nonce := dataToExtract[len(aesGcmFormatHeader) : len(aesGcmFormatHeader)+aead.NonceSize()]
+	cipherText := dataToExtract[len(aesGcmFormatHeader)+aead.NonceSize():]
+
+	result, err := aead.Open(nil, nonce, cipherText, aesGcmFormatHeader)
+	if err != nil {
+		return nil, fmt.Errorf("data integrity check failed: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *AesEncoder) decryptLegacy(dataToExtract []byte) ([]byte, error) {
 	ivLengthInfoSize := 2
 	ivSize := aes.BlockSize
 	paddingMaxSize := aes.BlockSize
@@ -104,6 +168,11 @@ func (s *AesEncoder) Decrypt(data []byte) ([]byte, error) {
 	return result, nil
 }
 
+// IsAesGcmFormat reports whether the binary (hex decoded) data is in the current versioned AES-GCM format.
+func IsAesGcmFormat(data []byte) bool {
+	return bytes.HasPrefix(data, aesGcmFormatHeader)
+}
+
 func pad(data []byte) []byte {
 	padding := aes.BlockSize - len(data)%aes.BlockSize
 	padtext := bytes.Repeat([]byte{byte(padding)}, padding)
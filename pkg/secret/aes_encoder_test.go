@@ -183,3 +183,115 @@ func TestAesSecret(t *testing.T) {
 		})
 	}
 }
+
+func TestAesSecret_Format(t *testing.T) {
+	s, err := NewAesEncoder(AesSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacy := &AesEncoder{CipherBlock: s.CipherBlock, LegacyFormat: true}
+
+	for _, test := range []struct {
+		name          string
+		encoder       *AesEncoder
+		expectGcmData bool
+	}{
+		{name: "default", encoder: s, expectGcmData: true},
+		{name: "legacy", encoder: legacy, expectGcmData: false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			encodedData, err := test.encoder.Encrypt([]byte("value"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			binaryData, err := hexToBinary(encodedData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if IsAesGcmFormat(binaryData) != test.expectGcmData {
+				t.Errorf("Unexpected format of encoded data %q", encodedData)
+			}
+
+			// both formats are decrypted by any encoder
+			for _, decoder := range []*AesEncoder{s, legacy} {
+				result, err := decoder.Decrypt(encodedData)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if string(result) != "value" {
+					t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", "value", result)
+				}
+			}
+		})
+	}
+}
+
+func TestAesSecret_Extract_integrity(t *testing.T) {
+	s, err := NewAesEncoder(AesSecretKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	otherKey, err := GenerateAesSecretKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := NewAesEncoder(otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encodedData, err := s.Encrypt([]byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tamperedData := append([]byte{}, encodedData...)
+	if tamperedData[len(tamperedData)-1] == '0' {
+		tamperedData[len(tamperedData)-1] = '1'
+	} else {
+		tamperedData[len(tamperedData)-1] = '0'
+	}
+
+	tests := []struct {
+		name         string
+		encoder      *AesEncoder
+		encodedData  []byte
+		errorMessage string
+	}{
+		{
+			name:         "minimum required data length",
+			encoder:      s,
+			encodedData:  []byte("575302"),
+			errorMessage: "minimum required data length: '62'",
+		},
+		{
+			name:         "tampered data",
+			encoder:      s,
+			encodedData:  tamperedData,
+			errorMessage: "data integrity check failed: cipher: message authentication failed",
+		},
+		{
+			name:         "wrong key",
+			encoder:      other,
+			encodedData:  encodedData,
+			errorMessage: "data integrity check failed: cipher: message authentication failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err = test.encoder.Decrypt(test.encodedData)
+			if err == nil {
+				t.Errorf("Expected error: %s", test.errorMessage)
+			} else if err.Error() != test.errorMessage {
+				t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", test.errorMessage, err.Error())
+			}
+		})
+	}
+}
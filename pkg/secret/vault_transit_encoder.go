@@ -0,0 +1,141 @@
+package secret
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultVaultTransitMountPath = "transit"
+
+// VaultTransitEncoder delegates encryption to the HashiCorp Vault transit secrets engine (or any compatible HTTP endpoint).
+// The key material never leaves the server, werf only stores ciphertexts in the "vault:v<N>:<base64>" format.
+type VaultTransitEncoder struct {
+	Address   string
+	MountPath string
+	KeyName   string
+	Token     string
+
+	Client *http.Client
+}
+
+type VaultTransitEncoderOptions struct {
+	Address   string
+	MountPath string
+	KeyName   string
+	Token     string
+}
+
+func NewVaultTransitEncoder(opts VaultTransitEncoderOptions) (*VaultTransitEncoder, error) {
+	if opts.Address == "" {
+		return nil, fmt.Errorf("vault address required")
+	}
+
+	if opts.KeyName == "" {
+		return nil, fmt.Errorf("vault transit key name required")
+	}
+
+	mountPath := opts.MountPath
+	if mountPath == "" {
+		mountPath = DefaultVaultTransitMountPath
+	}
+
+	return &VaultTransitEncoder{
+		Address:   strings.TrimSuffix(opts.Address, "/"),
+		MountPath: strings.Trim(mountPath, "/"),
+		KeyName:   opts.KeyName,
+		Token:     opts.Token,
+		Client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (s *VaultTransitEncoder) Encrypt(data []byte) ([]byte, error) {
+	var response struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+
+	request := map[string]string{"plaintext": base64.StdEncoding.EncodeToString(data)}
+	if err := s.call("encrypt", request, &response); err != nil {
+		return nil, err
+	}
+
+	if response.Data.Ciphertext == "" {
+		return nil, fmt.Errorf("vault transit returned empty ciphertext")
+	}
+
+	return []byte(response.Data.Ciphertext), nil
+}
+
+func (s *VaultTransitEncoder) Decrypt(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	if !bytes.HasPrefix(data, []byte("vault:")) {
+		return nil, fmt.Errorf("unexpected vault transit ciphertext format: expected \"vault:\" prefix")
+	}
+
+	var response struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+
+	request := map[string]string{"ciphertext": string(bytes.TrimSpace(data))}
+	if err := s.call("decrypt", request, &response); err != nil {
+		return nil, err
+	}
+
+	result, err := base64.StdEncoding.DecodeString(response.Data.Plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode vault transit plaintext: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *VaultTransitEncoder) call(operation string, request, response interface{}) error {
+	requestData, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", s.Address, s.MountPath, operation, s.KeyName)
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(requestData))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("X-Vault-Token", s.Token)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault transit %s request failed: %w", operation, err)
+	}
+	defer resp.Body.Close()
+
+	responseData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("unable to read vault transit %s response: %w", operation, err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault transit %s request failed: %s: %s", operation, resp.Status, strings.TrimSpace(string(responseData)))
+	}
+
+	if err := json.Unmarshal(responseData, response); err != nil {
+		return fmt.Errorf("unable to unmarshal vault transit %s response: %w", operation, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package secret
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newVaultTransitTestServer(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != token {
+			http.Error(w, `{"errors":["permission denied"]}`, http.StatusForbidden)
+			return
+		}
+
+		var request map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatal(err)
+		}
+
+		var response map[string]interface{}
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/werf":
+			response = map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + request["plaintext"]}}
+		case "/v1/transit/decrypt/werf":
+			response = map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(request["ciphertext"], "vault:v1:")}}
+		default:
+			http.NotFound(w, r)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Fatal(err)
+		}
+	}))
+}
+
+func TestVaultTransitSecret(t *testing.T) {
+	server := newVaultTransitTestServer(t, "token")
+	defer server.Close()
+
+	s, err := NewVaultTransitEncoder(VaultTransitEncoderOptions{Address: server.URL, KeyName: "werf", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []string{"", "value"} {
+		t.Run(test, func(t *testing.T) {
+			encodedData, err := s.Encrypt([]byte(test))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result, err := s.Decrypt(encodedData)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if test != string(result) {
+				t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", test, result)
+			}
+		})
+	}
+}
+
+func TestVaultTransitSecret_negative(t *testing.T) {
+	server := newVaultTransitTestServer(t, "token")
+	defer server.Close()
+
+	s, err := NewVaultTransitEncoder(VaultTransitEncoderOptions{Address: server.URL, KeyName: "werf", Token: "bad-token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Encrypt([]byte("value")); err == nil || !strings.Contains(err.Error(), "403 Forbidden") {
+		t.Errorf("Expected permission denied error, got: %v", err)
+	}
+
+	if _, err := s.Decrypt([]byte("10000f13a718d019612ab8ad30d9bec8")); err == nil || !strings.Contains(err.Error(), "expected \"vault:\" prefix") {
+		t.Errorf("Expected bad format error, got: %v", err)
+	}
+}
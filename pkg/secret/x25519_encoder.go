@@ -0,0 +1,253 @@
+package secret
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	x25519KeySize        = curve25519.ScalarSize
+	x25519FileKeySize    = chacha20poly1305.KeySize
+	x25519WrappedKeySize = x25519FileKeySize + chacha20poly1305.Overhead
+	x25519HkdfInfo       = "werf x25519 secret"
+)
+
+// X25519Encoder encrypts data for a set of X25519 recipients (public keys) and decrypts data with an X25519 identity (private key).
+// Every encrypted value gets its own random file key, which is wrapped separately for each recipient,
+// so any of the recipients is able to decrypt the value using its own identity.
+type X25519Encoder struct {
+	Identity   []byte
+	Recipients [][]byte
+}
+
+// GenerateX25519Identity returns new hex encoded identity (private key) and corresponding recipient (public key).
+func GenerateX25519Identity() ([]byte, []byte, error) {
+	identity := make([]byte, x25519KeySize)
+	if _, err := rand.Read(identity); err != nil {
+		return nil, nil, err
+	}
+
+	recipient, err := curve25519.X25519(identity, curve25519.Basepoint)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return []byte(hex.EncodeToString(identity)), []byte(hex.EncodeToString(recipient)), nil
+}
+
+// X25519RecipientFromIdentity returns hex encoded recipient (public key) for the hex encoded identity (private key).
+func X25519RecipientFromIdentity(identity []byte) ([]byte, error) {
+	identityBinary, err := decodeX25519Key(identity)
+	if err != nil {
+		return nil, fmt.Errorf("bad identity: %w", err)
+	}
+
+	recipient, err := curve25519.X25519(identityBinary, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(recipient)), nil
+}
+
+// NewX25519Encoder creates encoder with hex encoded identity and recipients.
+// Identity is optional when only encryption is needed, recipients are optional when only decryption is needed.
+// When identity is specified it is always added to the recipients list.
+func NewX25519Encoder(identity []byte, recipients [][]byte) (*X25519Encoder, error) {
+	encoder := &X25519Encoder{}
+
+	if len(identity) > 0 {
+		identityBinary, err := decodeX25519Key(identity)
+		if err != nil {
+			return nil, fmt.Errorf("bad identity: %w", err)
+		}
+		encoder.Identity = identityBinary
+
+		ownRecipient, err := curve25519.X25519(identityBinary, curve25519.Basepoint)
+		if err != nil {
+			return nil, err
+		}
+		encoder.Recipients = append(encoder.Recipients, ownRecipient)
+	}
+
+AddRecipients:
+	for _, recipient := range recipients {
+		recipientBinary, err := decodeX25519Key(recipient)
+		if err != nil {
+			return nil, fmt.Errorf("bad recipient %q: %w", string(recipient), err)
+		}
+
+		for _, r := range encoder.Recipients {
+			if string(r) == string(recipientBinary) {
+				continue AddRecipients
+			}
+		}
+
+		encoder.Recipients = append(encoder.Recipients, recipientBinary)
+	}
+
+	return encoder, nil
+}
+
+func (s *X25519Encoder) Encrypt(data []byte) ([]byte, error) {
+	if len(s.Recipients) == 0 {
+		return nil, fmt.Errorf("no x25519 recipients specified")
+	}
+
+	fileKey := make([]byte, x25519FileKeySize)
+	if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+		return nil, err
+	}
+
+	ephemeralIdentity := make([]byte, x25519KeySize)
+	if _, err := io.ReadFull(rand.Reader, ephemeralIdentity); err != nil {
+		return nil, err
+	}
+
+	ephemeralShare, err := curve25519.X25519(ephemeralIdentity, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+
+	recipientsNumber := make([]byte, 2)
+	binary.LittleEndian.PutUint16(recipientsNumber, uint16(len(s.Recipients)))
+
+	var args []byte
+	args = append(args, ephemeralShare...)
+	args = append(args, recipientsNumber...)
+
+	for _, recipient := range s.Recipients {
+		sharedSecret, err := curve25519.X25519(ephemeralIdentity, recipient)
+		if err != nil {
+			return nil, err
+		}
+
+		wrappedKey, err := x25519Seal(x25519WrapKey(sharedSecret, ephemeralShare, recipient), fileKey)
+		if err != nil {
+			return nil, err
+		}
+
+		args = append(args, wrappedKey...)
+	}
+
+	payload, err := x25519Seal(fileKey, data)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, payload...)
+
+	result := make([]byte, hex.EncodedLen(len(args)))
+	hex.Encode(result, args)
+
+	return result, nil
+}
+
+func (s *X25519Encoder) Decrypt(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+
+	if len(s.Identity) == 0 {
+		return nil, fmt.Errorf("no x25519 identity specified")
+	}
+
+	dataToExtract, err := hexToBinary(data)
+	if err != nil {
+		return nil, err
+	}
+
+	headerSize := x25519KeySize + 2
+	minimalDataBinarySize := headerSize + x25519WrappedKeySize + chacha20poly1305.Overhead
+	if len(dataToExtract) < minimalDataBinarySize {
+		return nil, fmt.Errorf("minimum required data length: '%v'", minimalDataBinarySize*2)
+	}
+
+	ephemeralShare := dataToExtract[:x25519KeySize]
+	recipientsNumber := int(binary.LittleEndian.Uint16(dataToExtract[x25519KeySize:headerSize]))
+	payloadOffset := headerSize + recipientsNumber*x25519WrappedKeySize
+	if len(dataToExtract) < payloadOffset+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("inconsistent data, unexpected recipients number %d", recipientsNumber)
+	}
+
+	sharedSecret, err := curve25519.X25519(s.Identity, ephemeralShare)
+	if err != nil {
+		return nil, err
+	}
+
+	ownRecipient, err := curve25519.X25519(s.Identity, curve25519.Basepoint)
+	if err != nil {
+		return nil, err
+	}
+	wrapKey := x25519WrapKey(sharedSecret, ephemeralShare, ownRecipient)
+
+	for i := 0; i < recipientsNumber; i++ {
+		offset := headerSize + i*x25519WrappedKeySize
+
+		fileKey, err := x25519Open(wrapKey, dataToExtract[offset:offset+x25519WrappedKeySize])
+		if err != nil {
+			continue
+		}
+
+		result, err := x25519Open(fileKey, dataToExtract[payloadOffset:])
+		if err != nil {
+			return nil, fmt.Errorf("data integrity check failed: %w", err)
+		}
+
+		return result, nil
+	}
+
+	return nil, fmt.Errorf("x25519 identity is not in the recipients list of the data")
+}
+
+func x25519WrapKey(sharedSecret, ephemeralShare, recipient []byte) []byte {
+	salt := make([]byte, 0, len(ephemeralShare)+len(recipient))
+	salt = append(salt, ephemeralShare...)
+	salt = append(salt, recipient...)
+
+	wrapKey := make([]byte, chacha20poly1305.KeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, sharedSecret, salt, []byte(x25519HkdfInfo)), wrapKey); err != nil {
+		panic(fmt.Sprintf("hkdf failed: %s", err))
+	}
+
+	return wrapKey
+}
+
+// x25519Seal uses zero nonce, which is safe because every key is used only once.
+func x25519Seal(key, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), data, nil), nil
+}
+
+func x25519Open(key, data []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), data, nil)
+}
+
+func decodeX25519Key(key []byte) ([]byte, error) {
+	keyBinary, err := hexToBinary(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(keyBinary) != x25519KeySize {
+		return nil, fmt.Errorf("invalid x25519 key size %d, expected %d", len(keyBinary), x25519KeySize)
+	}
+
+	return keyBinary, nil
+}
@@ -0,0 +1,141 @@
+package secret
+
+import (
+	"testing"
+)
+
+func TestX25519Secret(t *testing.T) {
+	tests := []string{"", "value"}
+
+	aliceIdentity, aliceRecipient, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bobIdentity, bobRecipient, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := NewX25519Encoder(nil, [][]byte{aliceRecipient, bobRecipient})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, identity := range [][]byte{aliceIdentity, bobIdentity} {
+		d, err := NewX25519Encoder(identity, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Run(string(identity), func(t *testing.T) {
+			for _, test := range tests {
+				t.Run(test, func(t *testing.T) {
+					encodedData, err := s.Encrypt([]byte(test))
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					result, err := d.Decrypt(encodedData)
+					if err != nil {
+						t.Fatal(err)
+					}
+
+					if test != string(result) {
+						t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", test, result)
+					}
+				})
+			}
+		})
+	}
+}
+
+func TestX25519RecipientFromIdentity(t *testing.T) {
+	identity, recipient, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := X25519RecipientFromIdentity(identity)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(result) != string(recipient) {
+		t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", recipient, result)
+	}
+}
+
+func TestX25519Secret_Extract_negative(t *testing.T) {
+	identity, _, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, otherRecipient, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := NewX25519Encoder(identity, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foreign, err := NewX25519Encoder(nil, [][]byte{otherRecipient})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	foreignData, err := foreign.Encrypt([]byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ownData, err := d.Encrypt([]byte("value"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tamperedData := []byte(string(ownData[:len(ownData)-2]) + "00")
+	if string(tamperedData) == string(ownData) {
+		tamperedData = []byte(string(ownData[:len(ownData)-2]) + "11")
+	}
+
+	tests := []struct {
+		name         string
+		encodedData  []byte
+		errorMessage string
+	}{
+		{
+			name:         "odd length hex string",
+			encodedData:  []byte("1"),
+			errorMessage: "encoding/hex: odd length hex string",
+		},
+		{
+			name:         "minimum required data length",
+			encodedData:  []byte("12"),
+			errorMessage: "minimum required data length: '196'",
+		},
+		{
+			name:         "not a recipient",
+			encodedData:  foreignData,
+			errorMessage: "x25519 identity is not in the recipients list of the data",
+		},
+		{
+			name:         "tampered data",
+			encodedData:  tamperedData,
+			errorMessage: "data integrity check failed: chacha20poly1305: message authentication failed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err = d.Decrypt(test.encodedData)
+			if err == nil {
+				t.Errorf("Expected error: %s", test.errorMessage)
+			} else if err.Error() != test.errorMessage {
+				t.Errorf("\n[EXPECTED]: %s\n[GOT]: %s", test.errorMessage, err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package secret
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	yaml_v3 "gopkg.in/yaml.v3"
+)
+
+type YamlDiffChangeType string
+
+const (
+	YamlDiffAdded    YamlDiffChangeType = "+"
+	YamlDiffRemoved  YamlDiffChangeType = "-"
+	YamlDiffModified YamlDiffChangeType = "~"
+)
+
+// YamlDiffEntry describes change of the single leaf value of the yaml document.
+// Path is a dot separated path to the value, e.g. "postgresql.hosts[0].password".
+type YamlDiffEntry struct {
+	Type     YamlDiffChangeType
+	Path     string
+	OldValue string
+	NewValue string
+}
+
+// String returns human readable representation of the change.
+// Values are replaced with the mask if the mask is not empty.
+func (e *YamlDiffEntry) String(mask string) string {
+	oldValue, newValue := e.OldValue, e.NewValue
+	if mask != "" {
+		oldValue, newValue = mask, mask
+	}
+
+	switch e.Type {
+	case YamlDiffAdded:
+		return fmt.Sprintf("%s %s: %s", e.Type, e.Path, newValue)
+	case YamlDiffRemoved:
+		return fmt.Sprintf("%s %s: %s", e.Type, e.Path, oldValue)
+	default:
+		return fmt.Sprintf("%s %s: %s -> %s", e.Type, e.Path, oldValue, newValue)
+	}
+}
+
+type yamlLeaf struct {
+	Path  string
+	Value string
+}
+
+// DiffYamlData compares decrypted yaml documents value by value.
+// Entries are ordered by appearance of the paths in the old document, the paths added in the new document go last.
+func DiffYamlData(oldData, newData []byte) ([]*YamlDiffEntry, error) {
+	oldLeaves, err := getYamlLeaves(oldData)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse old data: %w", err)
+	}
+
+	newLeaves, err := getYamlLeaves(newData)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse new data: %w", err)
+	}
+
+	newValues := map[string]string{}
+	for _, leaf := range newLeaves {
+		newValues[leaf.Path] = leaf.Value
+	}
+
+	oldValues := map[string]string{}
+	var entries []*YamlDiffEntry
+	for _, leaf := range oldLeaves {
+		oldValues[leaf.Path] = leaf.Value
+
+		if newValue, hasPath := newValues[leaf.Path]; !hasPath {
+			entries = append(entries, &YamlDiffEntry{Type: YamlDiffRemoved, Path: leaf.Path, OldValue: leaf.Value})
+		} else if newValue != leaf.Value {
+			entries = append(entries, &YamlDiffEntry{Type: YamlDiffModified, Path: leaf.Path, OldValue: leaf.Value, NewValue: newValue})
+		}
+	}
+
+	for _, leaf := range newLeaves {
+		if _, hasPath := oldValues[leaf.Path]; !hasPath {
+			entries = append(entries, &YamlDiffEntry{Type: YamlDiffAdded, Path: leaf.Path, NewValue: leaf.Value})
+		}
+	}
+
+	return entries, nil
+}
+
+func getYamlLeaves(data []byte) ([]*yamlLeaf, error) {
+	var config yaml_v3.Node
+	if err := yaml_v3.Unmarshal(data, &config); err != nil {
+		return nil, err
+	}
+
+	if len(config.Content) == 0 {
+		return nil, nil
+	}
+
+	var leaves []*yamlLeaf
+	collectYamlLeaves(config.Content[0], "", &leaves)
+
+	return leaves, nil
+}
+
+func collectYamlLeaves(node *yaml_v3.Node, path string, leaves *[]*yamlLeaf) {
+	switch node.Kind {
+	case yaml_v3.AliasNode:
+		collectYamlLeaves(node.Alias, path, leaves)
+	case yaml_v3.MappingNode:
+		if len(node.Content) == 0 {
+			*leaves = append(*leaves, &yamlLeaf{Path: path, Value: "{}"})
+			return
+		}
+
+		for pos := 0; pos < len(node.Content); pos += 2 {
+			keyNode, valueNode := node.Content[pos], node.Content[pos+1]
+
+			// The envelope holds only wrapped data keys of the recipients and is not a part of the values
+			if keyNode.Value == YamlEnvelopeKey && path == "" {
+				continue
+			}
+
+			collectYamlLeaves(valueNode, joinYamlPath(path, keyNode.Value), leaves)
+		}
+	case yaml_v3.SequenceNode:
+		if len(node.Content) == 0 {
+			*leaves = append(*leaves, &yamlLeaf{Path: path, Value: "[]"})
+			return
+		}
+
+		for ind, itemNode := range node.Content {
+			collectYamlLeaves(itemNode, fmt.Sprintf("%s[%d]", path, ind), leaves)
+		}
+	default:
+		value := node.Value
+		if node.Style&(yaml_v3.LiteralStyle|yaml_v3.FoldedStyle) != 0 || strings.Contains(value, "\n") {
+			value = fmt.Sprintf("%q", value)
+		}
+
+		*leaves = append(*leaves, &yamlLeaf{Path: path, Value: value})
+	}
+}
+
+var yamlPathPlainKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+func joinYamlPath(path, key string) string {
+	if !yamlPathPlainKeyRegexp.MatchString(key) {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,61 @@
+package secret
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffYamlData", func() {
+	It("should report added, removed and modified values by path", func() {
+		oldData := []byte(`
+database:
+  user: vasya
+  password: gfhjkm
+hosts:
+- one
+- two
+"my.key": a
+`)
+		newData := []byte(`
+database:
+  user: vasya
+  password: gfhjkm1
+hosts:
+- one
+mailbox:
+  password: qwerty
+"my.key": a
+`)
+
+		entries, err := DiffYamlData(oldData, newData)
+		Expect(err).To(Succeed())
+		Expect(entries).To(Equal([]*YamlDiffEntry{
+			{Type: YamlDiffModified, Path: "database.password", OldValue: "gfhjkm", NewValue: "gfhjkm1"},
+			{Type: YamlDiffRemoved, Path: "hosts[1]", OldValue: "two"},
+			{Type: YamlDiffAdded, Path: "mailbox.password", NewValue: "qwerty"},
+		}))
+	})
+
+	It("should not report changes of the envelope and treat empty document as no values", func() {
+		oldData := []byte(``)
+		newData := []byte(`
+_werf_secret_envelope:
+  recipients:
+  - id: "1"
+    dataKey: key
+a: one
+`)
+
+		entries, err := DiffYamlData(oldData, newData)
+		Expect(err).To(Succeed())
+		Expect(entries).To(Equal([]*YamlDiffEntry{
+			{Type: YamlDiffAdded, Path: "a", NewValue: "one"},
+		}))
+	})
+
+	It("should mask values", func() {
+		entry := &YamlDiffEntry{Type: YamlDiffModified, Path: "a", OldValue: "one", NewValue: "two"}
+		Expect(entry.String("")).To(Equal("~ a: one -> two"))
+		Expect(entry.String("***")).To(Equal("~ a: *** -> ***"))
+	})
+})
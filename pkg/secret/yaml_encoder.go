@@ -61,6 +61,26 @@ func (s *YamlEncoder) Decrypt(data []byte) ([]byte, error) {
 }
 
 func (s *YamlEncoder) DecryptYamlData(data []byte) ([]byte, error) {
+	if bytes.Contains(data, []byte(YamlEnvelopeKey)) {
+		envelope, payload, err := GetYamlEnvelope(data)
+		if err != nil {
+			return nil, fmt.Errorf("decryption failed: %w", err)
+		}
+
+		if envelope != nil {
+			if s.Encoder == nil {
+				return payload, nil
+			}
+
+			dataEncoder, err := s.GetYamlEnvelopeDataEncoder(envelope)
+			if err != nil {
+				return nil, fmt.Errorf("decryption failed: check encryption key and data: %w", err)
+			}
+
+			return dataEncoder.DecryptYamlData(payload)
+		}
+	}
+
 	resultData, err := doYamlDataV2(s.extractFunc, data, decryptYamlMode)
 	if err != nil {
 		if IsExtractDataError(err) {
@@ -73,6 +93,25 @@ func (s *YamlEncoder) DecryptYamlData(data []byte) ([]byte, error) {
 	return resultData, nil
 }
 
+// GetYamlEnvelopeDataEncoder returns encoder of the envelope data key, which is unwrapped with the current encoder.
+func (s *YamlEncoder) GetYamlEnvelopeDataEncoder(envelope *YamlEnvelope) (*YamlEncoder, error) {
+	if s.Encoder == nil {
+		return nil, fmt.Errorf("secrets decryption disabled")
+	}
+
+	dataKey, err := envelope.UnwrapDataKey(s.Encoder)
+	if err != nil {
+		return nil, err
+	}
+
+	dataEncoder, err := NewAesEncoder(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("bad data key: %w", err)
+	}
+
+	return NewYamlEncoder(dataEncoder), nil
+}
+
 func doYamlDataV2(doFunc func([]byte) ([]byte, error), data []byte, mode yamlProcessorMode) ([]byte, error) {
 	var config yaml_v3.Node
 
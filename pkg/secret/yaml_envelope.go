@@ -0,0 +1,221 @@
+package secret
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	yaml_v3 "gopkg.in/yaml.v3"
+)
+
+// YamlEnvelopeKey is the reserved top-level key of the secret values file, which holds the envelope.
+const YamlEnvelopeKey = "_werf_secret_envelope"
+
+// YamlEnvelope allows several recipient keys to decrypt the same secret values file.
+// Values of the file are encrypted with the random data key, and the data key is encrypted (wrapped) separately for each recipient.
+// Recipients can be added and removed without re-encryption of the values.
+type YamlEnvelope struct {
+	Recipients []*YamlEnvelopeRecipient `yaml:"recipients"`
+}
+
+type YamlEnvelopeRecipient struct {
+	ID      string `yaml:"id"`
+	Name    string `yaml:"name,omitempty"`
+	DataKey string `yaml:"dataKey"`
+}
+
+// NewYamlEnvelope creates envelope with the new random data key, which is wrapped for the specified recipient.
+func NewYamlEnvelope(recipientID, recipientName string, recipientEncoder Encoder) (*YamlEnvelope, []byte, error) {
+	dataKey, err := GenerateYamlEnvelopeDataKey()
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate data key: %w", err)
+	}
+
+	envelope := &YamlEnvelope{}
+	if err := envelope.AddRecipient(recipientID, recipientName, dataKey, recipientEncoder); err != nil {
+		return nil, nil, err
+	}
+
+	return envelope, dataKey, nil
+}
+
+// GenerateYamlEnvelopeDataKey returns hex encoded AES-256 key.
+func GenerateYamlEnvelopeDataKey() ([]byte, error) {
+	randomBytes := make([]byte, 32)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return nil, err
+	}
+
+	return []byte(hex.EncodeToString(randomBytes)), nil
+}
+
+// YamlEnvelopeRecipientID returns short fingerprint of the recipient key, which does not disclose the key.
+func YamlEnvelopeRecipientID(recipientKey []byte) string {
+	sum := sha256.Sum256(bytes.TrimSpace(recipientKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func (e *YamlEnvelope) GetRecipient(id string) *YamlEnvelopeRecipient {
+	for _, recipient := range e.Recipients {
+		if recipient.ID == id {
+			return recipient
+		}
+	}
+
+	return nil
+}
+
+func (e *YamlEnvelope) AddRecipient(id, name string, dataKey []byte, recipientEncoder Encoder) error {
+	if e.GetRecipient(id) != nil {
+		return fmt.Errorf("recipient %q already exists", id)
+	}
+
+	wrappedDataKey, err := recipientEncoder.Encrypt(dataKey)
+	if err != nil {
+		return fmt.Errorf("unable to encrypt data key for recipient %q: %w", id, err)
+	}
+
+	e.Recipients = append(e.Recipients, &YamlEnvelopeRecipient{
+		ID:      id,
+		Name:    name,
+		DataKey: string(wrappedDataKey),
+	})
+
+	return nil
+}
+
+func (e *YamlEnvelope) RemoveRecipient(id string) error {
+	for ind, recipient := range e.Recipients {
+		if recipient.ID != id {
+			continue
+		}
+
+		if len(e.Recipients) == 1 {
+			return fmt.Errorf("unable to remove the last recipient %q", id)
+		}
+
+		e.Recipients = append(e.Recipients[:ind], e.Recipients[ind+1:]...)
+		return nil
+	}
+
+	return fmt.Errorf("recipient %q not found", id)
+}
+
+// UnwrapDataKey decrypts the data key with the first recipient key that suits the encoder.
+func (e *YamlEnvelope) UnwrapDataKey(keyEncoder Encoder) ([]byte, error) {
+	for _, recipient := range e.Recipients {
+		dataKey, err := keyEncoder.Decrypt([]byte(recipient.DataKey))
+		if err != nil {
+			continue
+		}
+
+		// Data key is always a hex encoded AES-256 key, anything else means that the recipient key does not match
+		if _, err := NewAesEncoder(dataKey); err != nil {
+			continue
+		}
+
+		return dataKey, nil
+	}
+
+	return nil, fmt.Errorf("the secret key is not in the recipients list of the secret values envelope")
+}
+
+// GetYamlEnvelope extracts envelope from the encrypted secret values data and returns the rest of the data.
+// Nil envelope is returned if the data does not contain an envelope.
+func GetYamlEnvelope(data []byte) (*YamlEnvelope, []byte, error) {
+	var config yaml_v3.Node
+	if err := yaml_v3.Unmarshal(data, &config); err != nil {
+		return nil, nil, fmt.Errorf("unable to unmarshal config data: %w", err)
+	}
+
+	mappingNode := getYamlDocumentMappingNode(&config)
+	if mappingNode == nil {
+		return nil, data, nil
+	}
+
+	for pos := 0; pos < len(mappingNode.Content); pos += 2 {
+		if mappingNode.Content[pos].Value != YamlEnvelopeKey {
+			continue
+		}
+
+		envelope := &YamlEnvelope{}
+		if err := mappingNode.Content[pos+1].Decode(envelope); err != nil {
+			return nil, nil, fmt.Errorf("unable to decode secret values envelope: %w", err)
+		}
+
+		if len(envelope.Recipients) == 0 {
+			return nil, nil, fmt.Errorf("bad secret values envelope: no recipients")
+		}
+
+		mappingNode.Content = append(mappingNode.Content[:pos], mappingNode.Content[pos+2:]...)
+
+		payload, err := marshalYamlNode(&config)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return envelope, payload, nil
+	}
+
+	return nil, data, nil
+}
+
+// SetYamlEnvelope puts envelope into the encrypted secret values data as the first top-level key replacing the existing one.
+func SetYamlEnvelope(data []byte, envelope *YamlEnvelope) ([]byte, error) {
+	var config yaml_v3.Node
+	if err := yaml_v3.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal config data: %w", err)
+	}
+
+	if len(config.Content) == 0 {
+		config = yaml_v3.Node{Kind: yaml_v3.DocumentNode, Content: []*yaml_v3.Node{{Kind: yaml_v3.MappingNode, Tag: "!!map"}}}
+	}
+
+	mappingNode := getYamlDocumentMappingNode(&config)
+	if mappingNode == nil {
+		return nil, fmt.Errorf("unable to set secret values envelope: top-level map expected")
+	}
+
+	for pos := 0; pos < len(mappingNode.Content); pos += 2 {
+		if mappingNode.Content[pos].Value == YamlEnvelopeKey {
+			mappingNode.Content = append(mappingNode.Content[:pos], mappingNode.Content[pos+2:]...)
+			break
+		}
+	}
+
+	keyNode := &yaml_v3.Node{}
+	if err := keyNode.Encode(YamlEnvelopeKey); err != nil {
+		return nil, err
+	}
+
+	valueNode := &yaml_v3.Node{}
+	if err := valueNode.Encode(envelope); err != nil {
+		return nil, fmt.Errorf("unable to encode secret values envelope: %w", err)
+	}
+
+	mappingNode.Content = append([]*yaml_v3.Node{keyNode, valueNode}, mappingNode.Content...)
+
+	return marshalYamlNode(&config)
+}
+
+func getYamlDocumentMappingNode(node *yaml_v3.Node) *yaml_v3.Node {
+	if node.Kind != yaml_v3.DocumentNode || len(node.Content) == 0 || node.Content[0].Kind != yaml_v3.MappingNode {
+		return nil
+	}
+
+	return node.Content[0]
+}
+
+func marshalYamlNode(node *yaml_v3.Node) ([]byte, error) {
+	var resultData bytes.Buffer
+
+	yamlEncoder := yaml_v3.NewEncoder(&resultData)
+	yamlEncoder.SetIndent(2)
+	if err := yamlEncoder.Encode(node); err != nil {
+		return nil, fmt.Errorf("unable to marshal modified config data: %w", err)
+	}
+
+	return resultData.Bytes(), nil
+}
@@ -0,0 +1,98 @@
+package secret
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("YamlEnvelope", func() {
+	const originalData = `
+a: one
+postgresql:
+  password: "1234"
+`
+
+	newAesEncoder := func() (*AesEncoder, []byte) {
+		key, err := GenerateAesSecretKey()
+		Expect(err).To(Succeed())
+
+		enc, err := NewAesEncoder(key)
+		Expect(err).To(Succeed())
+
+		return enc, key
+	}
+
+	newEnvelopeData := func(ownEncoder Encoder, ownKey []byte) ([]byte, *YamlEnvelope, []byte) {
+		envelope, dataKey, err := NewYamlEnvelope(YamlEnvelopeRecipientID(ownKey), "", ownEncoder)
+		Expect(err).To(Succeed())
+
+		dataEncoder, err := NewAesEncoder(dataKey)
+		Expect(err).To(Succeed())
+
+		payload, err := NewYamlEncoder(dataEncoder).EncryptYamlData([]byte(originalData))
+		Expect(err).To(Succeed())
+
+		data, err := SetYamlEnvelope(payload, envelope)
+		Expect(err).To(Succeed())
+
+		return data, envelope, dataKey
+	}
+
+	It("should allow every recipient to decrypt data", func() {
+		devEncoder, devKey := newAesEncoder()
+		prodEncoder, prodKey := newAesEncoder()
+
+		data, envelope, dataKey := newEnvelopeData(devEncoder, devKey)
+		Expect(envelope.AddRecipient(YamlEnvelopeRecipientID(prodKey), "production", dataKey, prodEncoder)).To(Succeed())
+
+		_, payload, err := GetYamlEnvelope(data)
+		Expect(err).To(Succeed())
+
+		data, err = SetYamlEnvelope(payload, envelope)
+		Expect(err).To(Succeed())
+		Expect(string(data)).To(HavePrefix(YamlEnvelopeKey + ":"))
+
+		for _, enc := range []Encoder{devEncoder, prodEncoder} {
+			resultData, err := NewYamlEncoder(enc).DecryptYamlData(data)
+			Expect(err).To(Succeed())
+			Expect(strings.TrimSpace(string(resultData))).To(Equal(strings.TrimSpace(originalData)))
+		}
+	})
+
+	It("should not allow removed or unknown recipient to decrypt data", func() {
+		devEncoder, devKey := newAesEncoder()
+		prodEncoder, prodKey := newAesEncoder()
+		otherEncoder, _ := newAesEncoder()
+
+		data, envelope, dataKey := newEnvelopeData(devEncoder, devKey)
+		Expect(envelope.AddRecipient(YamlEnvelopeRecipientID(prodKey), "production", dataKey, prodEncoder)).To(Succeed())
+		Expect(envelope.RemoveRecipient(YamlEnvelopeRecipientID(devKey))).To(Succeed())
+		Expect(envelope.RemoveRecipient(YamlEnvelopeRecipientID(prodKey))).To(MatchError(ContainSubstring("unable to remove the last recipient")))
+
+		data, err := SetYamlEnvelope(data, envelope)
+		Expect(err).To(Succeed())
+
+		for _, enc := range []Encoder{devEncoder, otherEncoder} {
+			_, err := NewYamlEncoder(enc).DecryptYamlData(data)
+			Expect(err).To(MatchError(ContainSubstring("the secret key is not in the recipients list")))
+		}
+	})
+
+	It("should strip envelope when decryption is disabled", func() {
+		devEncoder, devKey := newAesEncoder()
+		data, _, _ := newEnvelopeData(devEncoder, devKey)
+
+		resultData, err := NewYamlEncoder(nil).DecryptYamlData(data)
+		Expect(err).To(Succeed())
+		Expect(string(resultData)).NotTo(ContainSubstring(YamlEnvelopeKey))
+	})
+
+	It("should return no envelope for regular data", func() {
+		envelope, payload, err := GetYamlEnvelope([]byte(originalData))
+		Expect(err).To(Succeed())
+		Expect(envelope).To(BeNil())
+		Expect(string(payload)).To(Equal(originalData))
+	})
+})
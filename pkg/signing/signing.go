@@ -0,0 +1,323 @@
+package signing
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+	"github.com/sigstore/sigstore/pkg/signature"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/docker_registry"
+)
+
+// The signature format is compatible with cosign: signatures are stored as the layers of the image manifest
+// tagged as sha256-<digest>.sig, the manifest also references the signed image as the subject (OCI referrers).
+const (
+	SignatureArtifactType  = "application/vnd.dev.cosign.artifact.sig.v1+json"
+	SimpleSigningMediaType = "application/vnd.dev.cosign.simplesigning.v1+json"
+	SignatureAnnotation    = "dev.cosignproject.cosign/signature"
+	SignatureTagSuffix     = ".sig"
+
+	simpleSigningType = "cosign container image signature"
+)
+
+var ErrNoValidSignature = errors.New("no valid signature found")
+
+type simpleSigningPayload struct {
+	Critical struct {
+		Identity struct {
+			DockerReference string `json:"docker-reference"`
+		} `json:"identity"`
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+	Optional map[string]interface{} `json:"optional"`
+}
+
+type Signer struct {
+	signer   signature.Signer
+	verifier signature.Verifier
+}
+
+// LoadSigner loads the private key in PEM format (cosign.key, PKCS#8, PKCS#1 or EC private key).
+// The password of the encrypted key is taken from $WERF_SIGN_KEY_PASSWORD or $COSIGN_PASSWORD.
+func LoadSigner(keyPath string) (*Signer, error) {
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read signing key %q: %w", keyPath, err)
+	}
+
+	privateKey, err := cryptoutils.UnmarshalPEMToPrivateKey(data, func(bool) ([]byte, error) {
+		for _, envName := range []string{"WERF_SIGN_KEY_PASSWORD", "COSIGN_PASSWORD"} {
+			if password, ok := os.LookupEnv(envName); ok {
+				return []byte(password), nil
+			}
+		}
+		return []byte{}, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to load signing key %q: %w", keyPath, err)
+	}
+
+	signerVerifier, err := signature.LoadSignerVerifier(privateKey, crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load signing key %q: %w", keyPath, err)
+	}
+
+	return &Signer{signer: signerVerifier, verifier: signerVerifier}, nil
+}
+
+type Verifier struct {
+	keyPath  string
+	verifier signature.Verifier
+}
+
+// LoadVerifiers loads the public keys in PEM format (cosign.pub).
+func LoadVerifiers(keyPaths []string) ([]*Verifier, error) {
+	var verifiers []*Verifier
+	for _, keyPath := range keyPaths {
+		data, err := os.ReadFile(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read verification key %q: %w", keyPath, err)
+		}
+
+		publicKey, err := cryptoutils.UnmarshalPEMToPublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load verification key %q: %w", keyPath, err)
+		}
+
+		verifier, err := signature.LoadVerifier(publicKey, crypto.SHA256)
+		if err != nil {
+			return nil, fmt.Errorf("unable to load verification key %q: %w", keyPath, err)
+		}
+
+		verifiers = append(verifiers, &Verifier{keyPath: keyPath, verifier: verifier})
+	}
+
+	return verifiers, nil
+}
+
+// SignImage signs the image or image index manifest by the reference (REPO:TAG or REPO@DIGEST)
+// and pushes the signature into the same repository.
+// Signing is skipped if the image has already been signed with the key.
+func SignImage(ctx context.Context, registry docker_registry.GenericApiInterface, signer *Signer, reference string) error {
+	repository, desc, err := getSubject(ctx, registry, reference)
+	if err != nil {
+		return err
+	}
+
+	signatureReference := SignatureReference(repository, desc.Digest)
+	signatureImage, err := registry.TryGetArtifact(ctx, signatureReference)
+	if err != nil {
+		return fmt.Errorf("unable to get signature %q: %w", signatureReference, err)
+	}
+
+	if signatureImage != nil {
+		valid, err := hasValidSignature(signatureImage, desc.Digest, []signature.Verifier{signer.verifier})
+		if err != nil {
+			return fmt.Errorf("unable to check signature %q: %w", signatureReference, err)
+		}
+
+		if valid {
+			logboek.Context(ctx).Info().LogF("Image %s@%s is already signed\n", repository, desc.Digest)
+			return nil
+		}
+	} else {
+		signatureImage = mutate.MediaType(empty.Image, types.OCIManifestSchema1)
+		signatureImage = mutate.ConfigMediaType(signatureImage, SignatureArtifactType)
+	}
+
+	payload, err := newSimpleSigningPayload(repository, desc.Digest)
+	if err != nil {
+		return err
+	}
+
+	sig, err := signer.signer.SignMessage(bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("unable to sign image %s@%s: %w", repository, desc.Digest, err)
+	}
+
+	signatureImage, err = mutate.Append(signatureImage, mutate.Addendum{
+		Layer: static.NewLayer(payload, SimpleSigningMediaType),
+		Annotations: map[string]string{
+			SignatureAnnotation: base64.StdEncoding.EncodeToString(sig),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("unable to add signature layer: %w", err)
+	}
+
+	subject := v1.Descriptor{MediaType: desc.MediaType, Size: desc.Size, Digest: desc.Digest}
+	withSubject := mutate.Subject(signatureImage, subject)
+	signatureImage, ok := withSubject.(v1.Image)
+	if !ok {
+		return fmt.Errorf("unable to sign image %s@%s: unexpected signature image type %T", repository, desc.Digest, withSubject)
+	}
+
+	if err := registry.PushArtifact(ctx, signatureReference, signatureImage); err != nil {
+		return fmt.Errorf("unable to push signature %q: %w", signatureReference, err)
+	}
+
+	return nil
+}
+
+// VerifyImage checks that the image or image index manifest by the reference (REPO:TAG or REPO@DIGEST)
+// has a valid signature made with one of the keys.
+// It returns the reference pinned to the verified manifest digest (REPO[:TAG]@DIGEST),
+// which must be used instead of the original one so that the tag cannot be moved after the verification.
+func VerifyImage(ctx context.Context, registry docker_registry.GenericApiInterface, verifiers []*Verifier, reference string) (string, error) {
+	repository, desc, err := getSubject(ctx, registry, reference)
+	if err != nil {
+		return "", err
+	}
+
+	var signatureVerifiers []signature.Verifier
+	for _, v := range verifiers {
+		signatureVerifiers = append(signatureVerifiers, v.verifier)
+	}
+
+	signatureReferences := []string{SignatureReference(repository, desc.Digest)}
+
+	referrers, err := registry.GetReferrers(ctx, fmt.Sprintf("%s@%s", repository, desc.Digest), SignatureArtifactType)
+	if err != nil {
+		return "", err
+	}
+	for _, referrer := range referrers {
+		signatureReferences = append(signatureReferences, fmt.Sprintf("%s@%s", repository, referrer.Digest))
+	}
+
+	for _, signatureReference := range signatureReferences {
+		signatureImage, err := registry.TryGetArtifact(ctx, signatureReference)
+		if err != nil {
+			return "", fmt.Errorf("unable to get signature %q: %w", signatureReference, err)
+		}
+
+		if signatureImage == nil {
+			continue
+		}
+
+		valid, err := hasValidSignature(signatureImage, desc.Digest, signatureVerifiers)
+		if err != nil {
+			return "", fmt.Errorf("unable to check signature %q: %w", signatureReference, err)
+		}
+
+		if valid {
+			return PinReference(reference, desc.Digest), nil
+		}
+	}
+
+	return "", fmt.Errorf("image %s@%s: %w", repository, desc.Digest, ErrNoValidSignature)
+}
+
+// PinReference returns the reference REPO[:TAG][@DIGEST] pinned to the digest (REPO[:TAG]@DIGEST).
+func PinReference(reference string, digest v1.Hash) string {
+	return fmt.Sprintf("%s@%s", strings.SplitN(reference, "@", 2)[0], digest)
+}
+
+// SignatureReference returns the reference of the signature tag (cosign tag schema).
+func SignatureReference(repository string, digest v1.Hash) string {
+	return fmt.Sprintf("%s:%s-%s%s", repository, digest.Algorithm, digest.Hex, SignatureTagSuffix)
+}
+
+func getSubject(ctx context.Context, registry docker_registry.GenericApiInterface, reference string) (string, *v1.Descriptor, error) {
+	desc, err := registry.GetDescriptor(ctx, reference)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return trimReferenceIdentifier(reference), desc, nil
+}
+
+// trimReferenceIdentifier returns the repository of the reference REPO[:TAG][@DIGEST].
+func trimReferenceIdentifier(reference string) string {
+	reference = strings.SplitN(reference, "@", 2)[0]
+	if i := strings.LastIndex(reference, ":"); i > strings.LastIndex(reference, "/") {
+		reference = reference[:i]
+	}
+	return reference
+}
+
+func newSimpleSigningPayload(repository string, digest v1.Hash) ([]byte, error) {
+	var payload simpleSigningPayload
+	payload.Critical.Identity.DockerReference = repository
+	payload.Critical.Image.DockerManifestDigest = digest.String()
+	payload.Critical.Type = simpleSigningType
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal signature payload: %w", err)
+	}
+
+	return data, nil
+}
+
+func hasValidSignature(signatureImage v1.Image, digest v1.Hash, verifiers []signature.Verifier) (bool, error) {
+	manifest, err := signatureImage.Manifest()
+	if err != nil {
+		return false, err
+	}
+
+	for _, layerDesc := range manifest.Layers {
+		if layerDesc.MediaType != SimpleSigningMediaType {
+			continue
+		}
+
+		sig, err := base64.StdEncoding.DecodeString(layerDesc.Annotations[SignatureAnnotation])
+		if err != nil || len(sig) == 0 {
+			continue
+		}
+
+		payload, err := readLayer(signatureImage, layerDesc.Digest)
+		if err != nil {
+			return false, err
+		}
+
+		var parsedPayload simpleSigningPayload
+		if err := json.Unmarshal(payload, &parsedPayload); err != nil {
+			continue
+		}
+
+		if parsedPayload.Critical.Image.DockerManifestDigest != digest.String() {
+			continue
+		}
+
+		for _, verifier := range verifiers {
+			if err := verifier.VerifySignature(bytes.NewReader(sig), bytes.NewReader(payload)); err == nil {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func readLayer(img v1.Image, digest v1.Hash) ([]byte, error) {
+	layer, err := img.LayerByDigest(digest)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get layer %s: %w", digest, err)
+	}
+
+	rc, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read layer %s: %w", digest, err)
+	}
+	defer rc.Close()
+
+	return io.ReadAll(rc)
+}
@@ -0,0 +1,160 @@
+package signing
+
+import (
+	"context"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/sigstore/sigstore/pkg/cryptoutils"
+
+	"github.com/werf/werf/pkg/docker_registry"
+)
+
+func TestSignAndVerifyImage(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(registry.New())
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := docker_registry.Init(ctx, true, false, 0); err != nil {
+		t.Fatal(err)
+	}
+	api := docker_registry.API()
+
+	repository := fmt.Sprintf("%s/test/app", serverURL.Host)
+	reference := repository + ":latest"
+	pushRandomImage(t, reference)
+
+	tmpDir := t.TempDir()
+	signer, verifiers := generateKeys(t, tmpDir, "key")
+	_, otherVerifiers := generateKeys(t, tmpDir, "other")
+
+	if _, err := VerifyImage(ctx, api, verifiers, reference); !errors.Is(err, ErrNoValidSignature) {
+		t.Fatalf("expected %v for unsigned image, got %v", ErrNoValidSignature, err)
+	}
+
+	if err := SignImage(ctx, api, signer, reference); err != nil {
+		t.Fatal(err)
+	}
+
+	// Repeated signing should not add the same signature twice.
+	if err := SignImage(ctx, api, signer, reference); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := api.GetDescriptor(ctx, reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pinnedReference, err := VerifyImage(ctx, api, verifiers, reference)
+	if err != nil {
+		t.Fatalf("unexpected verification error: %s", err)
+	}
+	if expected := fmt.Sprintf("%s@%s", reference, desc.Digest); pinnedReference != expected {
+		t.Fatalf("expected pinned reference %q, got %q", expected, pinnedReference)
+	}
+
+	if _, err := VerifyImage(ctx, api, otherVerifiers, reference); !errors.Is(err, ErrNoValidSignature) {
+		t.Fatalf("expected %v for the other key, got %v", ErrNoValidSignature, err)
+	}
+
+	signatureImage, err := api.TryGetArtifact(ctx, SignatureReference(repository, desc.Digest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest, err := signatureImage.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Layers) != 1 {
+		t.Fatalf("expected 1 signature layer, got %d", len(manifest.Layers))
+	}
+
+	referrers, err := api.GetReferrers(ctx, fmt.Sprintf("%s@%s", repository, desc.Digest), SignatureArtifactType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(referrers) == 0 {
+		t.Fatalf("expected the signature to be referenced by the image")
+	}
+
+	// The image pushed into the same tag has a different digest and is not signed.
+	pushRandomImage(t, reference)
+	if _, err := VerifyImage(ctx, api, verifiers, reference); !errors.Is(err, ErrNoValidSignature) {
+		t.Fatalf("expected %v for the new image, got %v", ErrNoValidSignature, err)
+	}
+}
+
+func TestTrimReferenceIdentifier(t *testing.T) {
+	for reference, expected := range map[string]string{
+		"registry.example.com/app":                    "registry.example.com/app",
+		"registry.example.com/app:tag":                "registry.example.com/app",
+		"registry.example.com:5000/app":               "registry.example.com:5000/app",
+		"registry.example.com:5000/app:tag":           "registry.example.com:5000/app",
+		"registry.example.com:5000/app@sha256:abcdef": "registry.example.com:5000/app",
+	} {
+		if actual := trimReferenceIdentifier(reference); actual != expected {
+			t.Errorf("trimReferenceIdentifier(%q) = %q, expected %q", reference, actual, expected)
+		}
+	}
+}
+
+func pushRandomImage(t *testing.T, reference string) {
+	img, err := random.Image(1024, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func generateKeys(t *testing.T, dir, keyName string) (*Signer, []*Verifier) {
+	privateKey, publicKey, err := cryptoutils.GeneratePEMEncodedECDSAKeyPair(elliptic.P256(), cryptoutils.SkipPassword)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	privateKeyPath := filepath.Join(dir, keyName+".key")
+	publicKeyPath := filepath.Join(dir, keyName+".pub")
+	if err := os.WriteFile(privateKeyPath, privateKey, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicKeyPath, publicKey, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	signer, err := LoadSigner(privateKeyPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	verifiers, err := LoadVerifiers([]string{publicKeyPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signer, verifiers
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltSynchronizationDatabaseFileName is the name of the embedded database file in the file+db synchronization directory.
+const BoltSynchronizationDatabaseFileName = "synchronization.db"
+
+const boltDatabaseFileLockTimeout = 5 * time.Minute
+
+// boltView and boltUpdate open the database for each operation, so the file can be shared by several processes:
+// readers hold a shared file lock and work concurrently, writers hold an exclusive file lock and change records in a single transaction.
+func boltView(databasePath string, f func(tx *bolt.Tx) error) error {
+	// Read-only open of the not existing file is an error, there is just nothing to read yet.
+	// The empty file is just created by boltUpdate which has not taken the file lock and written the meta pages yet,
+	// read-only open of it fails too.
+	if info, err := os.Stat(databasePath); os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error accessing %s: %w", databasePath, err)
+	} else if info.Size() == 0 {
+		return nil
+	}
+
+	db, err := bolt.Open(databasePath, 0o644, &bolt.Options{ReadOnly: true, Timeout: boltDatabaseFileLockTimeout})
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", databasePath, err)
+	}
+	defer db.Close()
+
+	return db.View(f)
+}
+
+func boltUpdate(databasePath string, f func(tx *bolt.Tx) error) error {
+	if err := os.MkdirAll(filepath.Dir(databasePath), os.ModePerm); err != nil {
+		return fmt.Errorf("unable to create dir %s: %w", filepath.Dir(databasePath), err)
+	}
+
+	db, err := bolt.Open(databasePath, 0o644, &bolt.Options{Timeout: boltDatabaseFileLockTimeout})
+	if err != nil {
+		return fmt.Errorf("unable to open %s: %w", databasePath, err)
+	}
+	defer db.Close()
+
+	return db.Update(f)
+}
@@ -0,0 +1,222 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/lockgate/pkg/distributed_locker"
+)
+
+const boltLockerRootBucket = "locks"
+
+// BoltLockerBackend keeps lock leases in the embedded database file.
+// It is a distributed_locker.DistributedLockerBackend, the leases are renewed by the distributed_locker.DistributedLocker and expire if the holder is gone.
+type BoltLockerBackend struct {
+	DatabasePath string
+	// Namespace separates locks of different clients in the same database file, e.g. synchronization server client IDs.
+	Namespace string
+	// Owner is recorded by the acquired locks, see GetLockOwnerClientID.
+	Owner string
+}
+
+type BoltLockLeaseRecord struct {
+	distributed_locker.LockLeaseRecord
+	AcquiredAtTimestamp int64
+	Owner               string
+}
+
+func NewBoltLockerBackend(databasePath, namespace string) *BoltLockerBackend {
+	return &BoltLockerBackend{DatabasePath: databasePath, Namespace: namespace}
+}
+
+// WithOwner returns the backend, which records the specified owner of the acquired locks.
+func (backend *BoltLockerBackend) WithOwner(owner string) distributed_locker.DistributedLockerBackend {
+	return &BoltLockerBackend{DatabasePath: backend.DatabasePath, Namespace: backend.Namespace, Owner: owner}
+}
+
+func (backend *BoltLockerBackend) Acquire(lockName string, opts distributed_locker.AcquireOptions) (lockgate.LockHandle, error) {
+	var handle lockgate.LockHandle
+
+	err := boltUpdate(backend.DatabasePath, func(tx *bolt.Tx) error {
+		bucket, err := backend.bucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		lease, err := getBoltLockLease(bucket, lockName)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+
+		switch {
+		case lease == nil || now.After(time.Unix(lease.ExpireAtTimestamp, 0)):
+			lease = &BoltLockLeaseRecord{
+				LockLeaseRecord:     *distributed_locker.NewLockLeaseRecord(lockName, opts.Shared),
+				AcquiredAtTimestamp: now.Unix(),
+				Owner:               backend.Owner,
+			}
+		case opts.Shared && lease.IsShared:
+			lease.SharedHoldersCount++
+			lease.ExpireAtTimestamp = now.Unix() + distributed_locker.DistributedLockLeaseTTLSeconds
+		default:
+			return distributed_locker.ErrShouldWait
+		}
+
+		handle = lease.LockHandle
+
+		return putBoltLockLease(bucket, lease)
+	})
+
+	return handle, err
+}
+
+func (backend *BoltLockerBackend) RenewLease(handle lockgate.LockHandle) error {
+	return backend.changeLease(handle, func(bucket *bolt.Bucket, lease *BoltLockLeaseRecord) error {
+		lease.ExpireAtTimestamp = time.Now().Unix() + distributed_locker.DistributedLockLeaseTTLSeconds
+		return putBoltLockLease(bucket, lease)
+	})
+}
+
+func (backend *BoltLockerBackend) Release(handle lockgate.LockHandle) error {
+	return backend.changeLease(handle, func(bucket *bolt.Bucket, lease *BoltLockLeaseRecord) error {
+		lease.SharedHoldersCount--
+		if lease.SharedHoldersCount == 0 {
+			return bucket.Delete([]byte(lease.LockName))
+		}
+		return putBoltLockLease(bucket, lease)
+	})
+}
+
+func (backend *BoltLockerBackend) ListLocks(_ context.Context) ([]*LockInfo, error) {
+	var res []*LockInfo
+
+	err := boltView(backend.DatabasePath, func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(boltLockerRootBucket))
+		if bucket != nil && backend.Namespace != "" {
+			bucket = bucket.Bucket([]byte(backend.Namespace))
+		}
+		if bucket == nil {
+			return nil
+		}
+
+		now := time.Now()
+
+		return bucket.ForEach(func(k, v []byte) error {
+			// Nested buckets of the namespaces have nil value
+			if v == nil {
+				return nil
+			}
+
+			lease, err := getBoltLockLease(bucket, string(k))
+			if err != nil {
+				return err
+			}
+
+			if now.After(time.Unix(lease.ExpireAtTimestamp, 0)) {
+				return nil
+			}
+
+			res = append(res, lease.LockInfo())
+			return nil
+		})
+	})
+
+	return res, err
+}
+
+func (backend *BoltLockerBackend) ForceReleaseLock(_ context.Context, lockName string) error {
+	return boltUpdate(backend.DatabasePath, func(tx *bolt.Tx) error {
+		bucket, err := backend.bucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		if lease, err := getBoltLockLease(bucket, lockName); err != nil {
+			return err
+		} else if lease == nil {
+			return distributed_locker.ErrNoExistingLockLeaseFound
+		}
+
+		return bucket.Delete([]byte(lockName))
+	})
+}
+
+func (lease *BoltLockLeaseRecord) LockInfo() *LockInfo {
+	info := &LockInfo{
+		Name:               lease.LockName,
+		UUID:               lease.UUID,
+		OwnerClientID:      lease.Owner,
+		ExpireAt:           time.Unix(lease.ExpireAtTimestamp, 0),
+		IsShared:           lease.IsShared,
+		SharedHoldersCount: lease.SharedHoldersCount,
+	}
+	if lease.AcquiredAtTimestamp != 0 {
+		info.AcquiredAt = time.Unix(lease.AcquiredAtTimestamp, 0)
+	}
+	return info
+}
+
+func (backend *BoltLockerBackend) changeLease(handle lockgate.LockHandle, changeFunc func(bucket *bolt.Bucket, lease *BoltLockLeaseRecord) error) error {
+	return boltUpdate(backend.DatabasePath, func(tx *bolt.Tx) error {
+		bucket, err := backend.bucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		lease, err := getBoltLockLease(bucket, handle.LockName)
+		if err != nil {
+			return err
+		} else if lease == nil {
+			return distributed_locker.ErrNoExistingLockLeaseFound
+		} else if lease.UUID != handle.UUID {
+			return distributed_locker.ErrLockAlreadyLeased
+		}
+
+		return changeFunc(bucket, lease)
+	})
+}
+
+func (backend *BoltLockerBackend) bucketForUpdate(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(boltLockerRootBucket))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create root bucket: %w", err)
+	}
+
+	if backend.Namespace != "" {
+		if bucket, err = bucket.CreateBucketIfNotExists([]byte(backend.Namespace)); err != nil {
+			return nil, fmt.Errorf("unable to create namespace %s bucket: %w", backend.Namespace, err)
+		}
+	}
+
+	return bucket, nil
+}
+
+func getBoltLockLease(bucket *bolt.Bucket, lockName string) (*BoltLockLeaseRecord, error) {
+	data := bucket.Get([]byte(lockName))
+	if data == nil {
+		return nil, nil
+	}
+
+	lease := &BoltLockLeaseRecord{}
+	if err := json.Unmarshal(data, lease); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal lock %q lease: %w", lockName, err)
+	}
+
+	return lease, nil
+}
+
+func putBoltLockLease(bucket *bolt.Bucket, lease *BoltLockLeaseRecord) error {
+	data, err := json.Marshal(lease)
+	if err != nil {
+		return err
+	}
+
+	return bucket.Put([]byte(lease.LockName), data)
+}
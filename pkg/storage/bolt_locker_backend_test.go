@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/werf/lockgate/pkg/distributed_locker"
+)
+
+func TestBoltLockerBackend(t *testing.T) {
+	backend := NewBoltLockerBackend(filepath.Join(t.TempDir(), BoltSynchronizationDatabaseFileName), "client")
+
+	handle, err := backend.Acquire("lock", distributed_locker.AcquireOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backend.Acquire("lock", distributed_locker.AcquireOptions{}); !distributed_locker.IsErrShouldWait(err) {
+		t.Errorf("expected should wait error for the acquired lock, got: %v", err)
+	}
+
+	if _, err := NewBoltLockerBackend(backend.DatabasePath, "other-client").Acquire("lock", distributed_locker.AcquireOptions{}); err != nil {
+		t.Errorf("expected the lock of the other namespace to be free, got: %v", err)
+	}
+
+	if err := backend.RenewLease(handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Release(handle); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := backend.Release(handle); !distributed_locker.IsErrNoExistingLockLeaseFound(err) {
+		t.Errorf("expected no existing lease error for the released lock, got: %v", err)
+	}
+
+	sharedHandle, err := backend.Acquire("lock", distributed_locker.AcquireOptions{Shared: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if h, err := backend.Acquire("lock", distributed_locker.AcquireOptions{Shared: true}); err != nil {
+		t.Fatal(err)
+	} else if h.UUID != sharedHandle.UUID {
+		t.Errorf("expected shared lease %s to be reused, got %s", sharedHandle.UUID, h.UUID)
+	}
+
+	if _, err := backend.Acquire("lock", distributed_locker.AcquireOptions{}); !distributed_locker.IsErrShouldWait(err) {
+		t.Errorf("expected should wait error for the shared lock, got: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := backend.Release(sharedHandle); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := backend.Acquire("lock", distributed_locker.AcquireOptions{}); err != nil {
+		t.Errorf("expected the lock to be free after all shared holders released it, got: %v", err)
+	}
+}
+
+func TestBoltLockerBackendLocksInspection(t *testing.T) {
+	ctx := context.Background()
+
+	backend := NewBoltLockerBackend(filepath.Join(t.TempDir(), BoltSynchronizationDatabaseFileName), "")
+	backend.Owner = "host/1"
+
+	lockManager := NewGenericLockManager(distributed_locker.NewDistributedLocker(backend))
+	lockManager.Inspector = backend
+
+	// The backend is used directly, because the locker crashes the process, which has lost the lease of the force released lock
+	handle, err := backend.Acquire(genericStageLockName("project", "digest"), distributed_locker.AcquireOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	lock := LockHandle{ProjectName: "project", LockgateHandle: handle}
+
+	if _, err := backend.Acquire(genericStageLockName("other-project", "digest"), distributed_locker.AcquireOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	locks, err := lockManager.ListLocks(ctx, "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(locks) != 1 {
+		t.Fatalf("expected single lock of the project, got %d", len(locks))
+	}
+
+	if locks[0].Name != lock.LockgateHandle.LockName || locks[0].UUID != lock.LockgateHandle.UUID || locks[0].OwnerClientID != "host/1" {
+		t.Errorf("unexpected lock info %+v", locks[0])
+	}
+
+	if locks[0].AcquiredAt.IsZero() || locks[0].TTL() <= 0 {
+		t.Errorf("expected acquisition time and ttl to be set, got %+v", locks[0])
+	}
+
+	if err := lockManager.ForceUnlock(ctx, "other-project", lock.LockgateHandle.LockName); err == nil {
+		t.Errorf("expected error releasing the lock of another project")
+	}
+
+	if err := lockManager.ForceUnlock(ctx, "project", lock.LockgateHandle.LockName); err != nil {
+		t.Fatal(err)
+	}
+
+	if locks, err := lockManager.ListLocks(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(locks) != 0 {
+		t.Errorf("expected no locks after force release, got %d", len(locks))
+	}
+
+	if _, err := backend.Acquire(lock.LockgateHandle.LockName, distributed_locker.AcquireOptions{}); err != nil {
+		t.Errorf("expected the force released lock to be free, got: %v", err)
+	}
+}
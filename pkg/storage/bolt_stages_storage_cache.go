@@ -0,0 +1,165 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/image"
+)
+
+const boltStagesStorageCacheRootBucket = "stages_storage_cache"
+
+// BoltStagesStorageCache keeps stages storage cache in the embedded database file, which can be shared by several processes on one host.
+type BoltStagesStorageCache struct {
+	DatabasePath string
+	// Namespace separates records of different clients in the same database file, e.g. synchronization server client IDs.
+	Namespace string
+}
+
+func NewBoltStagesStorageCache(databasePath, namespace string) *BoltStagesStorageCache {
+	return &BoltStagesStorageCache{DatabasePath: databasePath, Namespace: namespace}
+}
+
+func (cache *BoltStagesStorageCache) String() string {
+	if cache.Namespace != "" {
+		return fmt.Sprintf("%s/%s", cache.DatabasePath, cache.Namespace)
+	}
+	return cache.DatabasePath
+}
+
+func (cache *BoltStagesStorageCache) GetAllStages(ctx context.Context, projectName string) (bool, []image.StageID, error) {
+	var found bool
+	var res []image.StageID
+
+	if err := boltView(cache.DatabasePath, func(tx *bolt.Tx) error {
+		projectBucket := cache.projectBucket(tx, projectName)
+		if projectBucket == nil {
+			return nil
+		}
+		found = true
+
+		return projectBucket.ForEach(func(digest, data []byte) error {
+			if stages, ok := cache.unmarshalRecord(ctx, projectName, string(digest), data); ok {
+				res = append(res, stages...)
+			}
+			return nil
+		})
+	}); err != nil {
+		return false, nil, err
+	}
+
+	return found, res, nil
+}
+
+func (cache *BoltStagesStorageCache) DeleteAllStages(_ context.Context, projectName string) error {
+	return boltUpdate(cache.DatabasePath, func(tx *bolt.Tx) error {
+		namespaceBucket, err := cache.namespaceBucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := namespaceBucket.DeleteBucket([]byte(projectName)); err != nil && err != bolt.ErrBucketNotFound {
+			return fmt.Errorf("unable to delete project %s records: %w", projectName, err)
+		}
+		return nil
+	})
+}
+
+func (cache *BoltStagesStorageCache) GetStagesByDigest(ctx context.Context, projectName, digest string) (bool, []image.StageID, error) {
+	var found bool
+	var res []image.StageID
+
+	if err := boltView(cache.DatabasePath, func(tx *bolt.Tx) error {
+		projectBucket := cache.projectBucket(tx, projectName)
+		if projectBucket == nil {
+			return nil
+		}
+
+		if data := projectBucket.Get([]byte(digest)); data != nil {
+			res, found = cache.unmarshalRecord(ctx, projectName, digest, data)
+		}
+		return nil
+	}); err != nil {
+		return false, nil, err
+	}
+
+	return found, res, nil
+}
+
+func (cache *BoltStagesStorageCache) StoreStagesByDigest(_ context.Context, projectName, digest string, stages []image.StageID) error {
+	dataBytes, err := json.Marshal(StagesStorageCacheRecord{Stages: stages})
+	if err != nil {
+		return err
+	}
+
+	return boltUpdate(cache.DatabasePath, func(tx *bolt.Tx) error {
+		namespaceBucket, err := cache.namespaceBucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		projectBucket, err := namespaceBucket.CreateBucketIfNotExists([]byte(projectName))
+		if err != nil {
+			return fmt.Errorf("unable to create project %s bucket: %w", projectName, err)
+		}
+
+		return projectBucket.Put([]byte(digest), dataBytes)
+	})
+}
+
+func (cache *BoltStagesStorageCache) DeleteStagesByDigest(_ context.Context, projectName, digest string) error {
+	return boltUpdate(cache.DatabasePath, func(tx *bolt.Tx) error {
+		namespaceBucket, err := cache.namespaceBucketForUpdate(tx)
+		if err != nil {
+			return err
+		}
+
+		projectBucket := namespaceBucket.Bucket([]byte(projectName))
+		if projectBucket == nil {
+			return nil
+		}
+
+		return projectBucket.Delete([]byte(digest))
+	})
+}
+
+func (cache *BoltStagesStorageCache) unmarshalRecord(ctx context.Context, projectName, digest string, data []byte) ([]image.StageID, bool) {
+	res := &StagesStorageCacheRecord{}
+	if err := json.Unmarshal(data, res); err != nil {
+		logboek.Context(ctx).Error().LogF("Error unmarshalling json of project %s digest %s record from %s: %s: will ignore cache\n", projectName, digest, cache.String(), err)
+		return nil, false
+	}
+
+	return res.Stages, true
+}
+
+func (cache *BoltStagesStorageCache) projectBucket(tx *bolt.Tx, projectName string) *bolt.Bucket {
+	bucket := tx.Bucket([]byte(boltStagesStorageCacheRootBucket))
+	if bucket != nil && cache.Namespace != "" {
+		bucket = bucket.Bucket([]byte(cache.Namespace))
+	}
+	if bucket == nil {
+		return nil
+	}
+
+	return bucket.Bucket([]byte(projectName))
+}
+
+func (cache *BoltStagesStorageCache) namespaceBucketForUpdate(tx *bolt.Tx) (*bolt.Bucket, error) {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(boltStagesStorageCacheRootBucket))
+	if err != nil {
+		return nil, fmt.Errorf("unable to create root bucket: %w", err)
+	}
+
+	if cache.Namespace != "" {
+		if bucket, err = bucket.CreateBucketIfNotExists([]byte(cache.Namespace)); err != nil {
+			return nil, fmt.Errorf("unable to create namespace %s bucket: %w", cache.Namespace, err)
+		}
+	}
+
+	return bucket, nil
+}
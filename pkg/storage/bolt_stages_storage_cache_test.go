@@ -0,0 +1,126 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/werf/werf/pkg/image"
+)
+
+func TestBoltStagesStorageCache(t *testing.T) {
+	ctx := context.Background()
+	cache := NewBoltStagesStorageCache(filepath.Join(t.TempDir(), BoltSynchronizationDatabaseFileName), "client")
+
+	if found, _, err := cache.GetStagesByDigest(ctx, "project", "digest"); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Errorf("expected no records in the not existing database")
+	}
+
+	stages := []image.StageID{{Digest: "digest", UniqueID: 1}, {Digest: "digest", UniqueID: 2}}
+	if err := cache.StoreStagesByDigest(ctx, "project", "digest", stages); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.StoreStagesByDigest(ctx, "project", "digest2", []image.StageID{{Digest: "digest2", UniqueID: 3}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, res, err := cache.GetStagesByDigest(ctx, "project", "digest"); err != nil {
+		t.Fatal(err)
+	} else if !found || len(res) != 2 || res[1] != stages[1] {
+		t.Errorf("unexpected stages by digest: found=%v stages=%#v", found, res)
+	}
+
+	otherNamespaceCache := NewBoltStagesStorageCache(cache.DatabasePath, "other-client")
+	if found, _, err := otherNamespaceCache.GetAllStages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Errorf("expected no records of the other namespace")
+	}
+
+	if err := cache.DeleteStagesByDigest(ctx, "project", "digest"); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, res, err := cache.GetAllStages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if !found || len(res) != 1 || res[0].UniqueID != 3 {
+		t.Errorf("unexpected all stages: found=%v stages=%#v", found, res)
+	}
+
+	if err := cache.DeleteAllStages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	}
+
+	if found, _, err := cache.GetAllStages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Errorf("expected no records after deletion of all stages")
+	}
+}
+
+func TestBoltStagesStorageCache_Concurrency(t *testing.T) {
+	ctx := context.Background()
+	databasePath := filepath.Join(t.TempDir(), BoltSynchronizationDatabaseFileName)
+
+	var wg sync.WaitGroup
+	errCh := make(chan error, 40)
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+			digest := fmt.Sprintf("digest-%d", i)
+			errCh <- NewBoltStagesStorageCache(databasePath, "").StoreStagesByDigest(ctx, "project", digest, []image.StageID{{Digest: digest, UniqueID: int64(i)}})
+		}(i)
+
+		go func() {
+			defer wg.Done()
+			_, _, err := NewBoltStagesStorageCache(databasePath, "").GetAllStages(ctx, "project")
+			errCh <- err
+		}()
+	}
+	wg.Wait()
+	close(errCh)
+
+	for err := range errCh {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, res, err := NewBoltStagesStorageCache(databasePath, "").GetAllStages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(res) != 20 {
+		t.Errorf("expected 20 stages, got %d", len(res))
+	}
+}
+
+func TestBoltStagesStorageCache_EmptyDatabaseFile(t *testing.T) {
+	ctx := context.Background()
+	databasePath := filepath.Join(t.TempDir(), BoltSynchronizationDatabaseFileName)
+	// The file is created but not initialized yet by the concurrent writer.
+	if err := os.WriteFile(databasePath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewBoltStagesStorageCache(databasePath, "client")
+
+	if found, _, err := cache.GetStagesByDigest(ctx, "project", "digest"); err != nil {
+		t.Fatal(err)
+	} else if found {
+		t.Errorf("expected no records in the empty database file")
+	}
+
+	if err := cache.StoreStagesByDigest(ctx, "project", "digest", []image.StageID{{Digest: "digest", UniqueID: 1}}); err != nil {
+		t.Fatal(err)
+	}
+	if found, stages, err := cache.GetStagesByDigest(ctx, "project", "digest"); err != nil {
+		t.Fatal(err)
+	} else if !found || len(stages) != 1 {
+		t.Errorf("unexpected stages: %v", stages)
+	}
+}
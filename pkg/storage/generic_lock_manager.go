@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/werf/lockgate"
 	"github.com/werf/logboek"
@@ -16,6 +17,8 @@ func NewGenericLockManager(locker lockgate.Locker) *GenericLockManager {
 type GenericLockManager struct {
 	// Single Locker for all projects
 	Locker lockgate.Locker
+	// Inspector of the Locker locks, optional
+	Inspector LocksInspector
 }
 
 func (manager *GenericLockManager) LockStage(ctx context.Context, projectName, digest string) (LockHandle, error) {
@@ -36,6 +39,42 @@ func (manager *GenericLockManager) Unlock(ctx context.Context, lock LockHandle)
 	return err
 }
 
+func (manager *GenericLockManager) ListLocks(ctx context.Context, projectName string) ([]*LockInfo, error) {
+	if manager.Inspector == nil {
+		return nil, ErrLocksInspectionNotSupported
+	}
+
+	locks, err := manager.Inspector.ListLocks(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*LockInfo
+	for _, lock := range locks {
+		if strings.HasPrefix(lock.Name, genericProjectLockNamePrefix(projectName)) {
+			res = append(res, lock)
+		}
+	}
+
+	return res, nil
+}
+
+func (manager *GenericLockManager) ForceUnlock(ctx context.Context, projectName, lockName string) error {
+	if manager.Inspector == nil {
+		return ErrLocksInspectionNotSupported
+	}
+
+	if !strings.HasPrefix(lockName, genericProjectLockNamePrefix(projectName)) {
+		return fmt.Errorf("lock %q does not belong to the project %q", lockName, projectName)
+	}
+
+	return manager.Inspector.ForceReleaseLock(ctx, lockName)
+}
+
+func genericProjectLockNamePrefix(projectName string) string {
+	return fmt.Sprintf("%s.", projectName)
+}
+
 func genericStageLockName(projectName, digest string) string {
 	return fmt.Sprintf("%s.%s", projectName, digest)
 }
@@ -2,15 +2,23 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/werf/lockgate"
 	"github.com/werf/lockgate/pkg/distributed_locker"
+	"github.com/werf/lockgate/pkg/util"
 	"github.com/werf/logboek"
 	"github.com/werf/werf/pkg/kubeutils"
 	"github.com/werf/werf/pkg/werf"
@@ -69,7 +77,13 @@ func (manager *KubernetesLockManager) LockStage(ctx context.Context, projectName
 		return LockHandle{}, err
 	} else {
 		_, lock, err := locker.Acquire(kubernetesStageLockName(projectName, digest), werf.SetupLockerDefaultOptions(ctx, lockgate.AcquireOptions{}))
-		return LockHandle{LockgateHandle: lock, ProjectName: projectName}, err
+		if err != nil {
+			return LockHandle{}, err
+		}
+
+		manager.recordLockOwner(ctx, projectName, lock)
+
+		return LockHandle{LockgateHandle: lock, ProjectName: projectName}, nil
 	}
 }
 
@@ -78,7 +92,13 @@ func (manager *KubernetesLockManager) LockStageCache(ctx context.Context, projec
 		return LockHandle{}, err
 	} else {
 		_, lock, err := locker.Acquire(kubernetesStageCacheLockName(projectName, digest), werf.SetupLockerDefaultOptions(ctx, lockgate.AcquireOptions{}))
-		return LockHandle{LockgateHandle: lock, ProjectName: projectName}, err
+		if err != nil {
+			return LockHandle{}, err
+		}
+
+		manager.recordLockOwner(ctx, projectName, lock)
+
+		return LockHandle{LockgateHandle: lock, ProjectName: projectName}, nil
 	}
 }
 
@@ -89,9 +109,151 @@ func (manager *KubernetesLockManager) Unlock(ctx context.Context, lock LockHandl
 		err := locker.Release(lock.LockgateHandle)
 		if err != nil {
 			logboek.Context(ctx).Error().LogF("ERROR: unable to release lock for %q: %s\n", lock.LockgateHandle.LockName, err)
+			return err
+		}
+
+		return nil
+	}
+}
+
+func (manager *KubernetesLockManager) ListLocks(ctx context.Context, projectName string) ([]*LockInfo, error) {
+	obj, err := manager.KubeClient.CoreV1().ConfigMaps(manager.Namespace).Get(ctx, manager.GetConfigMapNameFunc(projectName), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to get cm/%s: %w", manager.GetConfigMapNameFunc(projectName), err)
+	}
+
+	now := time.Now()
+
+	var res []*LockInfo
+	for key, value := range obj.Annotations {
+		if !strings.HasPrefix(key, kubernetesLockLeaseAnnotationPrefix) {
+			continue
 		}
+
+		lease := &distributed_locker.LockLeaseRecord{}
+		if err := json.Unmarshal([]byte(value), lease); err != nil {
+			return nil, fmt.Errorf("unable to unmarshal lock lease annotation %q: %w", key, err)
+		}
+
+		if now.After(time.Unix(lease.ExpireAtTimestamp, 0)) {
+			continue
+		}
+
+		info := &LockInfo{
+			Name:               lease.LockName,
+			UUID:               lease.UUID,
+			ExpireAt:           time.Unix(lease.ExpireAtTimestamp, 0),
+			IsShared:           lease.IsShared,
+			SharedHoldersCount: lease.SharedHoldersCount,
+		}
+
+		if ownerData, hasKey := obj.Annotations[kubernetesLockOwnerAnnotationName(lease.LockName)]; hasKey {
+			owner := &kubernetesLockOwnerRecord{}
+			if err := json.Unmarshal([]byte(ownerData), owner); err == nil && owner.UUID == lease.UUID {
+				info.OwnerClientID = owner.Owner
+				info.AcquiredAt = time.Unix(owner.AcquiredAtTimestamp, 0)
+			}
+		}
+
+		res = append(res, info)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res, nil
+}
+
+func (manager *KubernetesLockManager) ForceUnlock(ctx context.Context, projectName, lockName string) error {
+	return manager.changeLocksConfigMap(ctx, projectName, func(obj *v1.ConfigMap) error {
+		if _, hasKey := obj.Annotations[kubernetesLockLeaseAnnotationName(lockName)]; !hasKey {
+			return distributed_locker.ErrNoExistingLockLeaseFound
+		}
+
+		delete(obj.Annotations, kubernetesLockLeaseAnnotationName(lockName))
+		delete(obj.Annotations, kubernetesLockOwnerAnnotationName(lockName))
+
+		return nil
+	})
+}
+
+type kubernetesLockOwnerRecord struct {
+	UUID                string `json:"uuid"`
+	Owner               string `json:"owner"`
+	AcquiredAtTimestamp int64  `json:"acquiredAtTimestamp"`
+}
+
+// recordLockOwner records the owner of the lock in the background, so that the ConfigMap update does not slow down
+// the acquiring of the lock. The owner is informational only: the record is matched with the lease by the UUID.
+func (manager *KubernetesLockManager) recordLockOwner(ctx context.Context, projectName string, lock lockgate.LockHandle) {
+	go func() {
+		if err := manager.setLockOwner(context.Background(), projectName, lock); err != nil {
+			logboek.Context(ctx).Debug().LogF("Unable to record owner of the lock %q: %s\n", lock.LockName, err)
+		}
+	}()
+}
+
+// setLockOwner records the owner of the lock next to the lease annotation of the lockgate. The owner records of the
+// released locks are removed at the same time, so the owner is not removed on the release of the lock.
+func (manager *KubernetesLockManager) setLockOwner(ctx context.Context, projectName string, lock lockgate.LockHandle) error {
+	data, err := json.Marshal(kubernetesLockOwnerRecord{UUID: lock.UUID, Owner: GetLockOwnerClientID(), AcquiredAtTimestamp: time.Now().Unix()})
+	if err != nil {
 		return err
 	}
+
+	return manager.changeLocksConfigMap(ctx, projectName, func(obj *v1.ConfigMap) error {
+		if obj.Annotations == nil {
+			obj.Annotations = make(map[string]string)
+		}
+
+		for key := range obj.Annotations {
+			if hash := strings.TrimPrefix(key, kubernetesLockOwnerAnnotationPrefix); hash != key {
+				if _, hasLease := obj.Annotations[kubernetesLockLeaseAnnotationPrefix+hash]; !hasLease {
+					delete(obj.Annotations, key)
+				}
+			}
+		}
+
+		obj.Annotations[kubernetesLockOwnerAnnotationName(lock.LockName)] = string(data)
+		return nil
+	})
+}
+
+func (manager *KubernetesLockManager) changeLocksConfigMap(ctx context.Context, projectName string, changeFunc func(obj *v1.ConfigMap) error) error {
+RETRY_CHANGE:
+
+	obj, err := manager.KubeClient.CoreV1().ConfigMaps(manager.Namespace).Get(ctx, manager.GetConfigMapNameFunc(projectName), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to get cm/%s: %w", manager.GetConfigMapNameFunc(projectName), err)
+	}
+
+	if err := changeFunc(obj); err != nil {
+		return err
+	}
+
+	if _, err := manager.KubeClient.CoreV1().ConfigMaps(manager.Namespace).Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		if errors.IsConflict(err) {
+			goto RETRY_CHANGE
+		}
+
+		return fmt.Errorf("update cm/%s error: %w", obj.Name, err)
+	}
+
+	return nil
+}
+
+// Lease annotations are managed by the lockgate.KubernetesLocker
+const kubernetesLockLeaseAnnotationPrefix = "lockgate.io/"
+
+func kubernetesLockLeaseAnnotationName(lockName string) string {
+	return kubernetesLockLeaseAnnotationPrefix + util.Sha3_224Hash(lockName)
+}
+
+const kubernetesLockOwnerAnnotationPrefix = "lock-owner.werf.io/"
+
+func kubernetesLockOwnerAnnotationName(lockName string) string {
+	return kubernetesLockOwnerAnnotationPrefix + util.Sha3_224Hash(lockName)
 }
 
 func kubernetesStageLockName(projectName, digest string) string {
@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/lockgate/pkg/distributed_locker"
+)
+
+func TestKubernetesLockManagerSetLockOwner(t *testing.T) {
+	ctx := context.Background()
+
+	lease, err := json.Marshal(distributed_locker.LockLeaseRecord{
+		LockHandle:        lockgate.LockHandle{UUID: "held-uuid", LockName: "project/stage/held"},
+		ExpireAtTimestamp: time.Now().Add(time.Minute).Unix(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "werf-project",
+			Namespace: "ns",
+			Annotations: map[string]string{
+				kubernetesLockLeaseAnnotationName("project/stage/held"):     string(lease),
+				kubernetesLockOwnerAnnotationName("project/stage/held"):     `{"uuid":"held-uuid","owner":"host/1","acquiredAtTimestamp":1}`,
+				kubernetesLockOwnerAnnotationName("project/stage/released"): `{"uuid":"released-uuid","owner":"host/2","acquiredAtTimestamp":1}`,
+			},
+		},
+	})
+	manager := NewKubernetesLockManager("ns", client, nil, func(projectName string) string { return "werf-" + projectName })
+
+	if err := manager.setLockOwner(ctx, "project", lockgate.LockHandle{UUID: "new-uuid", LockName: "project/stage/new"}); err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := client.CoreV1().ConfigMaps("ns").Get(ctx, "werf-project", metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, hasKey := obj.Annotations[kubernetesLockOwnerAnnotationName("project/stage/released")]; hasKey {
+		t.Errorf("expected owner of the released lock to be removed")
+	}
+	if _, hasKey := obj.Annotations[kubernetesLockOwnerAnnotationName("project/stage/new")]; !hasKey {
+		t.Errorf("expected owner of the new lock to be recorded")
+	}
+
+	locks, err := manager.ListLocks(ctx, "project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(locks) != 1 || locks[0].Name != "project/stage/held" || locks[0].OwnerClientID != "host/1" {
+		t.Errorf("unexpected locks: %#v", locks)
+	}
+}
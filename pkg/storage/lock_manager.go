@@ -2,13 +2,24 @@ package storage
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
 
 	"github.com/werf/lockgate"
 )
 
+var ErrLocksInspectionNotSupported = errors.New("listing and force releasing of locks is not supported by the synchronization")
+
 type LockManager interface {
 	LockStage(ctx context.Context, projectName, digest string) (LockHandle, error)
 	Unlock(ctx context.Context, lockHandle LockHandle) error
+
+	// ListLocks returns currently held locks of the project.
+	ListLocks(ctx context.Context, projectName string) ([]*LockInfo, error)
+	// ForceUnlock releases the lock regardless of its holders, e.g. the stale lock of the killed werf process.
+	ForceUnlock(ctx context.Context, projectName, lockName string) error
 }
 
 type LockHandle struct {
@@ -19,3 +30,38 @@ type LockHandle struct {
 type LockStagesAndImagesOptions struct {
 	GetOrCreateImagesOnly bool `json:"getOrCreateImagesOnly"`
 }
+
+type LockInfo struct {
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+	// OwnerClientID identifies the werf process, which has acquired the lock, empty if unknown.
+	OwnerClientID      string    `json:"ownerClientID,omitempty"`
+	AcquiredAt         time.Time `json:"acquiredAt,omitempty"`
+	ExpireAt           time.Time `json:"expireAt"`
+	IsShared           bool      `json:"isShared"`
+	SharedHoldersCount int64     `json:"sharedHoldersCount"`
+}
+
+// TTL returns the time left till the lock expires unless its lease is renewed by the holder.
+func (info *LockInfo) TTL() time.Duration {
+	return time.Until(info.ExpireAt)
+}
+
+// LocksInspector provides access to all locks of the locker backend.
+type LocksInspector interface {
+	ListLocks(ctx context.Context) ([]*LockInfo, error)
+	ForceReleaseLock(ctx context.Context, lockName string) error
+}
+
+var lockOwnerClientID = func() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s/%d", hostname, os.Getpid())
+}()
+
+// GetLockOwnerClientID returns the identifier of the current werf process, which is recorded by the locks it acquires.
+func GetLockOwnerClientID() string {
+	return lockOwnerClientID
+}
@@ -79,6 +79,7 @@ type StorageManagerInterface interface {
 	GetFinalStageDescriptionList(ctx context.Context) ([]*image.StageDescription, error)
 
 	FetchStage(ctx context.Context, containerBackend container_backend.ContainerBackend, stg stage.Interface) error
+	GetStageFetchSource(stageImageName string) storage.StagesStorage
 	SelectSuitableStage(ctx context.Context, c stage.Conveyor, stg stage.Interface, stages []*image.StageDescription) (*image.StageDescription, error)
 	CopySuitableByDigestStage(ctx context.Context, stageDesc *image.StageDescription, sourceStagesStorage, destinationStagesStorage storage.StagesStorage, containerBackend container_backend.ContainerBackend, targetPlatform string) (*image.StageDescription, error)
 	CopyStageIntoCacheStorages(ctx context.Context, stageID image.StageID, cacheStagesStorages []storage.StagesStorage, opts CopyStageIntoStorageOptions) error
@@ -183,6 +184,9 @@ type StorageManager struct {
 
 	FinalStagesListCacheMux sync.Mutex
 	FinalStagesListCache    *StagesList
+
+	stagesFetchSourcesMux sync.Mutex
+	stagesFetchSources    map[string]storage.StagesStorage
 }
 
 func (m *StorageManager) GetStagesStorage() storage.PrimaryStagesStorage {
@@ -556,6 +560,7 @@ func (m *StorageManager) FetchStage(ctx context.Context, containerBackend contai
 		}
 
 		fetchedImg = cacheImg
+		m.setStageFetchSource(stg.GetStageImage().Image.Name(), cacheStagesStorage)
 		break
 	}
 
@@ -589,6 +594,7 @@ func (m *StorageManager) FetchStage(ctx context.Context, containerBackend contai
 		}
 
 		fetchedImg = img.Image
+		m.setStageFetchSource(stg.GetStageImage().Image.Name(), m.StagesStorage)
 	}
 
 	for _, cacheStagesStorage := range cacheStagesStorageListToRefill {
@@ -612,6 +618,23 @@ func (m *StorageManager) FetchStage(ctx context.Context, containerBackend contai
 	return nil
 }
 
+// GetStageFetchSource returns the stages storage the stage image has been fetched from (nil if the stage image has not been fetched).
+func (m *StorageManager) GetStageFetchSource(stageImageName string) storage.StagesStorage {
+	m.stagesFetchSourcesMux.Lock()
+	defer m.stagesFetchSourcesMux.Unlock()
+	return m.stagesFetchSources[stageImageName]
+}
+
+func (m *StorageManager) setStageFetchSource(stageImageName string, stagesStorage storage.StagesStorage) {
+	m.stagesFetchSourcesMux.Lock()
+	defer m.stagesFetchSourcesMux.Unlock()
+
+	if m.stagesFetchSources == nil {
+		m.stagesFetchSources = make(map[string]storage.StagesStorage)
+	}
+	m.stagesFetchSources[stageImageName] = stagesStorage
+}
+
 func (m *StorageManager) CopyStageIntoCacheStorages(ctx context.Context, stageID image.StageID, cacheStagesStorageList []storage.StagesStorage, opts CopyStageIntoStorageOptions) error {
 	for _, cache := range cacheStagesStorageList {
 		err := logboek.Context(ctx).Default().LogProcess("Copy stage %s into cache %s", opts.LogDetailedName, cache.String()).
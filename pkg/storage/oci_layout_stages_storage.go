@@ -0,0 +1,1112 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/match"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/container_backend"
+	"github.com/werf/werf/pkg/docker_registry"
+	"github.com/werf/werf/pkg/docker_registry/container_registry_extensions"
+	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/slug"
+	"github.com/werf/werf/pkg/werf"
+)
+
+const (
+	OCILayoutStorageAddressPrefix = "oci-layout://"
+
+	OCILayoutStage_ImageRepoFormat = "werf-oci-layout/%s"
+	OCILayoutArchive_ImageRepo     = "werf-oci-layout"
+
+	// OCILayoutRefNameAnnotation is the standard annotation used to tag manifests in the index.json of the layout
+	OCILayoutRefNameAnnotation = "org.opencontainers.image.ref.name"
+)
+
+func IsOCILayoutStorageAddress(address string) bool {
+	return strings.HasPrefix(address, OCILayoutStorageAddressPrefix)
+}
+
+// OCILayoutStagesStorage keeps stages and all related werf records in the OCI image layout directory.
+// Records are stored with the same tags as in the RepoStagesStorage, tag is saved in the
+// org.opencontainers.image.ref.name annotation of the index.json manifest descriptor.
+type OCILayoutStagesStorage struct {
+	LayoutDir        string
+	ContainerBackend container_backend.ContainerBackend
+	DockerRegistry   docker_registry.Interface
+
+	// mux serializes access within the process, the host lock taken by lock() serializes werf processes
+	// working with the same layout dir.
+	mux sync.RWMutex
+}
+
+func NewOCILayoutStagesStorage(address string, containerBackend container_backend.ContainerBackend, dockerRegistry docker_registry.Interface) *OCILayoutStagesStorage {
+	return &OCILayoutStagesStorage{
+		LayoutDir:        strings.TrimPrefix(address, OCILayoutStorageAddressPrefix),
+		ContainerBackend: containerBackend,
+		DockerRegistry:   dockerRegistry,
+	}
+}
+
+func (storage *OCILayoutStagesStorage) ConstructStageImageName(projectName, digest string, uniqueID int64) string {
+	return fmt.Sprintf("%s:%s", fmt.Sprintf(OCILayoutStage_ImageRepoFormat, projectName), makeOCILayoutStageTag(digest, uniqueID))
+}
+
+func makeOCILayoutStageTag(digest string, uniqueID int64) string {
+	if uniqueID == 0 {
+		return digest
+	}
+	return fmt.Sprintf("%s-%d", digest, uniqueID)
+}
+
+func (storage *OCILayoutStagesStorage) GetStagesIDs(ctx context.Context, _ string, _ ...Option) ([]image.StageID, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.GetStagesIDs fetched tags for %q: %#v\n", storage.LayoutDir, tags)
+
+	return getStagesIDsFromTags(ctx, tags)
+}
+
+func (storage *OCILayoutStagesStorage) GetStagesIDsByDigest(ctx context.Context, _, digest string, _ ...Option) ([]image.StageID, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	return getStagesIDsByDigestFromTags(ctx, tags, digest)
+}
+
+func (storage *OCILayoutStagesStorage) GetStageDescription(ctx context.Context, projectName string, stageID image.StageID) (*image.StageDescription, error) {
+	tag := makeOCILayoutStageTag(stageID.Digest, stageID.UniqueID)
+
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.GetStageDescription %s %s\n", projectName, tag)
+
+	imgInfo, err := storage.getImageInfo(fmt.Sprintf(OCILayoutStage_ImageRepoFormat, projectName), tag)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get stage %s: %w", tag, err)
+	}
+	if imgInfo == nil {
+		return nil, nil
+	}
+
+	if isRejected, err := storage.hasTag(makeOCILayoutRejectedStageTag(stageID.Digest, stageID.UniqueID)); err != nil {
+		return nil, err
+	} else if isRejected {
+		logboek.Context(ctx).Info().LogF("Stage digest %s uniqueID %d image is rejected: ignore stage image\n", stageID.Digest, stageID.UniqueID)
+		return nil, nil
+	}
+
+	return &image.StageDescription{
+		StageID: image.NewStageID(stageID.Digest, stageID.UniqueID),
+		Info:    imgInfo,
+	}, nil
+}
+
+func (storage *OCILayoutStagesStorage) ExportStage(ctx context.Context, stageDescription *image.StageDescription, destinationReference string, mutateConfigFunc func(config v1.Config) (v1.Config, error)) error {
+	return storage.withImageArchive(stageDescription.Info.Tag, mutateExportStageConfig(mutateConfigFunc), func(opener docker_registry.ArchiveOpener) error {
+		return storage.DockerRegistry.PushImageArchive(ctx, opener, destinationReference)
+	})
+}
+
+func (storage *OCILayoutStagesStorage) DeleteStage(ctx context.Context, stageDescription *image.StageDescription, _ DeleteImageOptions) error {
+	return storage.removeTags(stageDescription.Info.Tag)
+}
+
+func makeOCILayoutRejectedStageTag(digest string, uniqueID int64) string {
+	return fmt.Sprintf("%s-%d%s", digest, uniqueID, RepoRejectedStageImageRecord_ImageTagSuffix)
+}
+
+func (storage *OCILayoutStagesStorage) RejectStage(ctx context.Context, projectName, digest string, uniqueID int64) error {
+	if err := storage.putRecord(makeOCILayoutRejectedStageTag(digest, uniqueID), map[string]string{image.WerfLabel: projectName}); err != nil {
+		return fmt.Errorf("unable to put rejected stage record: %w", err)
+	}
+
+	logboek.Context(ctx).Info().LogF("Rejected stage by digest %s uniqueID %d\n", digest, uniqueID)
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) CreateRepo(_ context.Context) error {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	_, err = storage.layoutPath()
+	return err
+}
+
+func (storage *OCILayoutStagesStorage) DeleteRepo(_ context.Context) error {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	return os.RemoveAll(storage.LayoutDir)
+}
+
+func (storage *OCILayoutStagesStorage) CheckStageCustomTag(_ context.Context, stageDescription *image.StageDescription, tag string) error {
+	customTagImgInfo, err := storage.getImageInfo(OCILayoutArchive_ImageRepo, tag)
+	if err != nil {
+		return err
+	}
+
+	if customTagImgInfo == nil {
+		return fmt.Errorf("custom tag %q not found", tag)
+	}
+
+	if customTagImgInfo.ID != stageDescription.Info.ID {
+		return fmt.Errorf("custom tag %q image must be the same as associated content-based tag %q image", tag, stageDescription.StageID.String())
+	}
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) AddStageCustomTag(_ context.Context, stageDescription *image.StageDescription, tag string) error {
+	found, err := storage.tagImage(stageDescription.Info.Tag, tag)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("stage %s not found", stageDescription.StageID.String())
+	}
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) DeleteStageCustomTag(_ context.Context, tag string) error {
+	return storage.removeTags(tag)
+}
+
+func makeOCILayoutCustomTagMetadataTag(tag string) string {
+	return RepoCustomTagMetadata_ImageTagPrefix + slug.LimitedSlug(tag, 48)
+}
+
+func (storage *OCILayoutStagesStorage) GetStageCustomTagMetadataIDs(_ context.Context, _ ...Option) ([]string, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, RepoCustomTagMetadata_ImageTagPrefix) {
+			continue
+		}
+
+		res = append(res, strings.TrimPrefix(tag, RepoCustomTagMetadata_ImageTagPrefix))
+	}
+
+	return res, nil
+}
+
+func (storage *OCILayoutStagesStorage) GetStageCustomTagMetadata(_ context.Context, tagOrID string) (*CustomTagMetadata, error) {
+	recordTag := makeOCILayoutCustomTagMetadataTag(tagOrID)
+	labels, err := storage.getRecordLabels(recordTag)
+	if err != nil {
+		return nil, err
+	}
+
+	if labels == nil {
+		return nil, fmt.Errorf("custom tag metadata %q not found", recordTag)
+	}
+
+	return newCustomTagMetadataFromLabels(labels), nil
+}
+
+func (storage *OCILayoutStagesStorage) RegisterStageCustomTag(_ context.Context, projectName string, stageDescription *image.StageDescription, tag string) error {
+	labels := newCustomTagMetadata(stageDescription.StageID.String(), tag).ToLabels()
+	labels[image.WerfLabel] = projectName
+
+	if err := storage.putRecord(makeOCILayoutCustomTagMetadataTag(tag), labels); err != nil {
+		return fmt.Errorf("unable to add stage custom tag metadata: %w", err)
+	}
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) UnregisterStageCustomTag(_ context.Context, tag string) error {
+	if err := storage.removeTags(makeOCILayoutCustomTagMetadataTag(tag)); err != nil {
+		return fmt.Errorf("unable to delete stage custom tag metadata: %w", err)
+	}
+	return nil
+}
+
+func makeOCILayoutManagedImageTag(imageNameOrManagedImageName string) string {
+	return RepoManagedImageRecord_ImageTagPrefix + getManagedImageID(imageNameOrManagedImageName)
+}
+
+func (storage *OCILayoutStagesStorage) AddManagedImage(ctx context.Context, projectName, imageNameOrManagedImageName string) error {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.AddManagedImage %s %s\n", projectName, imageNameOrManagedImageName)
+
+	return storage.putRecord(makeOCILayoutManagedImageTag(imageNameOrManagedImageName), map[string]string{image.WerfLabel: projectName})
+}
+
+func (storage *OCILayoutStagesStorage) RmManagedImage(ctx context.Context, projectName, imageNameOrManagedImageName string) error {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.RmManagedImage %s %s\n", projectName, imageNameOrManagedImageName)
+
+	return storage.removeTags(makeOCILayoutManagedImageTag(imageNameOrManagedImageName))
+}
+
+func (storage *OCILayoutStagesStorage) IsManagedImageExist(_ context.Context, _, imageNameOrManagedImageName string, _ ...Option) (bool, error) {
+	return storage.hasTag(makeOCILayoutManagedImageTag(imageNameOrManagedImageName))
+}
+
+func (storage *OCILayoutStagesStorage) GetManagedImages(ctx context.Context, projectName string, _ ...Option) ([]string, error) {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.GetManagedImages %s\n", projectName)
+
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, RepoManagedImageRecord_ImageTagPrefix) {
+			continue
+		}
+
+		res = append(res, getManagedImageNameFromManagedImageID(strings.TrimPrefix(tag, RepoManagedImageRecord_ImageTagPrefix)))
+	}
+
+	return res, nil
+}
+
+func (storage *OCILayoutStagesStorage) PutImageMetadata(ctx context.Context, projectName, imageNameOrManagedImageName, commit, stageID string) error {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.PutImageMetadata %s %s %s %s\n", projectName, imageNameOrManagedImageName, commit, stageID)
+
+	if err := storage.putRecord(makeRepoImageMetadataTagName(imageNameOrManagedImageName, commit, stageID), map[string]string{image.WerfLabel: projectName}); err != nil {
+		return fmt.Errorf("unable to put image metadata: %w", err)
+	}
+	logboek.Context(ctx).Info().LogF("Put image %s commit %s stage ID %s\n", imageNameOrManagedImageName, commit, stageID)
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) RmImageMetadata(ctx context.Context, projectName, imageNameOrManagedImageNameOrImageMetadataID, commit, stageID string) error {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.RmImageMetadata %s %s %s %s\n", projectName, imageNameOrManagedImageNameOrImageMetadataID, commit, stageID)
+
+	tags := []string{makeRepoImageMetadataTagName(imageNameOrManagedImageNameOrImageMetadataID, commit, stageID)}
+	if tagByID := makeRepoImageMetadataTagNameByImageMetadataID(imageNameOrManagedImageNameOrImageMetadataID, commit, stageID); slug.IsValidDockerTag(tagByID) {
+		tags = append(tags, tagByID)
+	}
+
+	if err := storage.removeTags(tags...); err != nil {
+		return fmt.Errorf("unable to remove image metadata: %w", err)
+	}
+
+	logboek.Context(ctx).Info().LogF("Removed image %s commit %s stage ID %s\n", imageNameOrManagedImageNameOrImageMetadataID, commit, stageID)
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) IsImageMetadataExist(_ context.Context, _, imageNameOrManagedImageName, commit, stageID string, _ ...Option) (bool, error) {
+	return storage.hasTag(makeRepoImageMetadataTagName(imageNameOrManagedImageName, commit, stageID))
+}
+
+func (storage *OCILayoutStagesStorage) GetAllAndGroupImageMetadataByImageName(ctx context.Context, _ string, imageNameOrManagedImageList []string, _ ...Option) (map[string]map[string][]string, map[string]map[string][]string, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return groupImageMetadataTagsByImageName(ctx, imageNameOrManagedImageList, tags, RepoImageMetadataByCommitRecord_ImageTagPrefix)
+}
+
+func (storage *OCILayoutStagesStorage) GetImportMetadata(_ context.Context, _, id string) (*ImportMetadata, error) {
+	labels, err := storage.getRecordLabels(RepoImportMetadata_ImageTagPrefix + id)
+	if err != nil {
+		return nil, err
+	}
+
+	if labels == nil {
+		return nil, nil
+	}
+
+	return newImportMetadataFromLabels(labels), nil
+}
+
+func (storage *OCILayoutStagesStorage) PutImportMetadata(_ context.Context, projectName string, metadata *ImportMetadata) error {
+	labels := metadata.ToLabelsMap()
+	labels[image.WerfLabel] = projectName
+
+	return storage.putRecord(RepoImportMetadata_ImageTagPrefix+metadata.ImportSourceID, labels)
+}
+
+func (storage *OCILayoutStagesStorage) RmImportMetadata(_ context.Context, _, id string) error {
+	return storage.removeTags(RepoImportMetadata_ImageTagPrefix + id)
+}
+
+func (storage *OCILayoutStagesStorage) GetImportMetadataIDs(_ context.Context, _ string, _ ...Option) ([]string, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, RepoImportMetadata_ImageTagPrefix) {
+			continue
+		}
+
+		ids = append(ids, getImportMetadataIDFromRepoTag(tag))
+	}
+
+	return ids, nil
+}
+
+func (storage *OCILayoutStagesStorage) GetClientIDRecords(ctx context.Context, _ string, _ ...Option) ([]*ClientIDRecord, error) {
+	tags, err := storage.tags()
+	if err != nil {
+		return nil, err
+	}
+
+	return getClientIDRecordsFromTags(ctx, tags), nil
+}
+
+func (storage *OCILayoutStagesStorage) PostClientIDRecord(ctx context.Context, projectName string, rec *ClientIDRecord) error {
+	tag := fmt.Sprintf("%s%s-%d", RepoClientIDRecord_ImageTagPrefix, rec.ClientID, rec.TimestampMillisec)
+	if err := storage.putRecord(tag, map[string]string{image.WerfLabel: projectName}); err != nil {
+		return fmt.Errorf("unable to put client id record: %w", err)
+	}
+
+	logboek.Context(ctx).Info().LogF("Posted new clientID %q for project %s\n", rec.ClientID, projectName)
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) PostMultiplatformImage(ctx context.Context, projectName, tag string, allPlatformsImages []*image.Info) error {
+	logboek.Context(ctx).Debug().LogF("-- OCILayoutStagesStorage.PostMultiplatformImage by tag %s for project %s\n", tag, projectName)
+
+	ii := mutate.IndexMediaType(empty.Index, types.DockerManifestList)
+	for _, platformImageInfo := range allPlatformsImages {
+		// The platform images are read again by putImageIndex under the exclusive lock.
+		err := storage.withImage(platformImageInfo.Tag, func(img v1.Image) error {
+			if img == nil {
+				return fmt.Errorf("platform image %s not found", platformImageInfo.Name)
+			}
+
+			configFile, err := img.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("unable to get platform image %s config: %w", platformImageInfo.Name, err)
+			}
+
+			ii = mutate.AppendManifests(ii, mutate.IndexAddendum{
+				Add:        img,
+				Descriptor: v1.Descriptor{Platform: configFile.Platform()},
+			})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := storage.putImageIndex(tag, ii); err != nil {
+		return fmt.Errorf("unable to put manifest list %s: %w", tag, err)
+	}
+
+	logboek.Context(ctx).Info().LogF("Posted manifest list %s for project %s\n", tag, projectName)
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) FetchImage(ctx context.Context, img container_backend.LegacyImageInterface) error {
+	ref, err := name.NewTag(img.Name())
+	if err != nil {
+		return fmt.Errorf("unable to parse image name %q: %w", img.Name(), err)
+	}
+
+	err = storage.withImage(ref.TagStr(), func(layoutImg v1.Image) error {
+		if layoutImg == nil {
+			return fmt.Errorf("image %s not found in the oci layout %s", img.Name(), storage.LayoutDir)
+		}
+
+		pr, pw := io.Pipe()
+		writeDone := make(chan struct{})
+		go func() {
+			defer close(writeDone)
+			pw.CloseWithError(tarball.Write(ref, layoutImg, pw))
+		}()
+		// The blobs must be read before the lock is released.
+		defer func() {
+			pr.Close()
+			<-writeDone
+		}()
+
+		if err := storage.ContainerBackend.LoadImageFromStream(ctx, pr); err != nil {
+			return fmt.Errorf("unable to load image %s: %w", img.Name(), err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if info, err := storage.ContainerBackend.GetImageInfo(ctx, img.Name(), container_backend.GetImageInfoOpts{TargetPlatform: img.GetTargetPlatform()}); err != nil {
+		return fmt.Errorf("unable to get inspect of image %s: %w", img.Name(), err)
+	} else {
+		img.SetInfo(info)
+	}
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) StoreImage(ctx context.Context, img container_backend.LegacyImageInterface) error {
+	ref, err := name.NewTag(img.Name())
+	if err != nil {
+		return fmt.Errorf("unable to parse image name %q: %w", img.Name(), err)
+	}
+
+	if img.BuiltID() != "" {
+		if err := storage.ContainerBackend.Tag(ctx, img.BuiltID(), img.Name(), container_backend.TagOpts{TargetPlatform: img.GetTargetPlatform()}); err != nil {
+			return fmt.Errorf("unable to tag built image %q by %q: %w", img.BuiltID(), img.Name(), err)
+		}
+	}
+
+	archivePath, err := storage.saveImageToTmpArchive(ctx, img.Name())
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archivePath)
+
+	archiveImg, err := tarball.ImageFromPath(archivePath, &ref)
+	if err != nil {
+		return fmt.Errorf("unable to open image %s archive: %w", img.Name(), err)
+	}
+
+	if err := storage.putImage(ref.TagStr(), archiveImg); err != nil {
+		return fmt.Errorf("unable to store image %s: %w", img.Name(), err)
+	}
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) saveImageToTmpArchive(ctx context.Context, ref string) (string, error) {
+	rc, err := storage.ContainerBackend.SaveImageToStream(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("unable to save image %s: %w", ref, err)
+	}
+	defer rc.Close()
+
+	f, err := os.CreateTemp("", "werf-oci-layout-image-*.tar")
+	if err != nil {
+		return "", fmt.Errorf("unable to create tmp file: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := io.Copy(f, rc); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("unable to save image %s: %w", ref, err)
+	}
+
+	return f.Name(), nil
+}
+
+func (storage *OCILayoutStagesStorage) ShouldFetchImage(ctx context.Context, img container_backend.LegacyImageInterface) (bool, error) {
+	if info, err := storage.ContainerBackend.GetImageInfo(ctx, img.Name(), container_backend.GetImageInfoOpts{TargetPlatform: img.GetTargetPlatform()}); err != nil {
+		return false, fmt.Errorf("unable to get inspect for image %s: %w", img.Name(), err)
+	} else if info != nil {
+		img.SetInfo(info)
+		return false, nil
+	}
+	return true, nil
+}
+
+func (storage *OCILayoutStagesStorage) CopyFromStorage(ctx context.Context, src StagesStorage, projectName string, stageID image.StageID, _ CopyFromStorageOptions) (*image.StageDescription, error) {
+	desc, err := storage.GetStageDescription(ctx, projectName, stageID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get stage %s description: %w", stageID, err)
+	}
+	if desc != nil {
+		return desc, nil
+	}
+
+	tag := makeOCILayoutStageTag(stageID.Digest, stageID.UniqueID)
+
+	switch srcStorage := src.(type) {
+	case *OCILayoutStagesStorage:
+		err := srcStorage.withImage(tag, func(img v1.Image) error {
+			if img == nil {
+				return fmt.Errorf("stage %s not found in the oci layout %s", stageID, srcStorage.LayoutDir)
+			}
+
+			if err := storage.putImage(tag, img); err != nil {
+				return fmt.Errorf("unable to copy stage %s: %w", stageID, err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	case *LocalStagesStorage:
+		return nil, fmt.Errorf("copying from the %s storage into the oci layout is not supported", src.String())
+	default:
+		if err := storage.pullImage(ctx, src.ConstructStageImageName(projectName, stageID.Digest, stageID.UniqueID), tag); err != nil {
+			return nil, fmt.Errorf("unable to copy stage %s: %w", stageID, err)
+		}
+	}
+
+	return storage.GetStageDescription(ctx, projectName, stageID)
+}
+
+func (storage *OCILayoutStagesStorage) pullImage(ctx context.Context, reference, tag string) error {
+	f, err := os.CreateTemp("", "werf-oci-layout-image-*.tar")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp file: %w", err)
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if err := storage.DockerRegistry.PullImageArchive(ctx, f, reference); err != nil {
+		return fmt.Errorf("unable to pull image %s: %w", reference, err)
+	}
+
+	img, err := tarball.ImageFromPath(f.Name(), nil)
+	if err != nil {
+		return fmt.Errorf("unable to open image %s archive: %w", reference, err)
+	}
+
+	return storage.putImage(tag, img)
+}
+
+// pushStageImage pushes the stage image from the layout into the registry using specified docker registry client.
+func (storage *OCILayoutStagesStorage) pushStageImage(ctx context.Context, dockerRegistry docker_registry.Interface, stageID image.StageID, destinationReference string) error {
+	return storage.withImageArchive(makeOCILayoutStageTag(stageID.Digest, stageID.UniqueID), nil, func(opener docker_registry.ArchiveOpener) error {
+		return dockerRegistry.PushImageArchive(ctx, opener, destinationReference)
+	})
+}
+
+func (storage *OCILayoutStagesStorage) FilterStagesAndProcessRelatedData(_ context.Context, stageDescriptions []*image.StageDescription, _ FilterStagesAndProcessRelatedDataOptions) ([]*image.StageDescription, error) {
+	return stageDescriptions, nil
+}
+
+func (storage *OCILayoutStagesStorage) String() string {
+	return storage.Address()
+}
+
+func (storage *OCILayoutStagesStorage) Address() string {
+	return OCILayoutStorageAddressPrefix + storage.LayoutDir
+}
+
+type ociLayoutArchiveOpener struct {
+	path string
+}
+
+func (opener *ociLayoutArchiveOpener) Open() (io.ReadCloser, error) {
+	return os.Open(opener.path)
+}
+
+// withImageArchive writes the tagged image from the layout into the temporary docker archive.
+func (storage *OCILayoutStagesStorage) withImageArchive(tag string, mutateConfigFunc func(config v1.Config) (v1.Config, error), f func(opener docker_registry.ArchiveOpener) error) error {
+	desc, err := storage.getDescriptor(tag)
+	if err != nil {
+		return err
+	}
+	if desc == nil {
+		return fmt.Errorf("image %s not found in the oci layout %s", tag, storage.LayoutDir)
+	}
+	if desc.MediaType.IsIndex() {
+		return fmt.Errorf("multiplatform image %s cannot be exported from the oci layout", tag)
+	}
+
+	ref, err := name.NewTag(fmt.Sprintf("%s:%s", OCILayoutArchive_ImageRepo, tag))
+	if err != nil {
+		return fmt.Errorf("unable to construct archive reference: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "werf-oci-layout-image-*.tar")
+	if err != nil {
+		return fmt.Errorf("unable to create tmp file: %w", err)
+	}
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	err = storage.withImage(tag, func(img v1.Image) error {
+		if img == nil {
+			return fmt.Errorf("image %s not found in the oci layout %s", tag, storage.LayoutDir)
+		}
+
+		if mutateConfigFunc != nil {
+			configFile, err := img.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("unable to get image %s config: %w", tag, err)
+			}
+
+			newConfig, err := mutateConfigFunc(configFile.Config)
+			if err != nil {
+				return err
+			}
+
+			if img, err = mutate.Config(img, newConfig); err != nil {
+				return fmt.Errorf("unable to mutate image %s config: %w", tag, err)
+			}
+		}
+
+		if err := tarball.WriteToFile(tmpFile.Name(), ref, img); err != nil {
+			return fmt.Errorf("unable to write image %s archive: %w", tag, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return f(&ociLayoutArchiveOpener{path: tmpFile.Name()})
+}
+
+func (storage *OCILayoutStagesStorage) layoutPath() (layout.Path, error) {
+	p, err := layout.FromPath(storage.LayoutDir)
+	if err == nil {
+		return p, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("unable to open oci layout %s: %w", storage.LayoutDir, err)
+	}
+
+	p, err = layout.Write(storage.LayoutDir, empty.Index)
+	if err != nil {
+		return "", fmt.Errorf("unable to create oci layout %s: %w", storage.LayoutDir, err)
+	}
+
+	return p, nil
+}
+
+func (storage *OCILayoutStagesStorage) indexManifest() (layout.Path, *v1.IndexManifest, error) {
+	p, err := storage.layoutPath()
+	if err != nil {
+		return "", nil, err
+	}
+
+	ii, err := p.ImageIndex()
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read oci layout %s index: %w", storage.LayoutDir, err)
+	}
+
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to read oci layout %s index manifest: %w", storage.LayoutDir, err)
+	}
+
+	return p, im, nil
+}
+
+// lock takes the in-process mutex and the host lock of the layout dir: index.json is rewritten in place and the garbage
+// collection removes blobs not referenced by the index yet, so readers take the lock shared and writers exclusively.
+func (storage *OCILayoutStagesStorage) lock(shared bool) (func(), error) {
+	muxLock, muxUnlock := storage.mux.Lock, storage.mux.Unlock
+	if shared {
+		muxLock, muxUnlock = storage.mux.RLock, storage.mux.RUnlock
+	}
+	muxLock()
+
+	layoutDir, err := filepath.Abs(storage.LayoutDir)
+	if err != nil {
+		muxUnlock()
+		return nil, fmt.Errorf("unable to get absolute path of oci layout %s: %w", storage.LayoutDir, err)
+	}
+
+	_, lock, err := werf.AcquireHostLock(context.Background(), fmt.Sprintf("oci_layout.%s", layoutDir), lockgate.AcquireOptions{Shared: shared})
+	if err != nil {
+		muxUnlock()
+		return nil, fmt.Errorf("unable to lock oci layout %s: %w", storage.LayoutDir, err)
+	}
+
+	return func() {
+		if err := werf.ReleaseHostLock(lock); err != nil {
+			logboek.Warn().LogF("WARNING: unable to release oci layout %s lock: %s\n", storage.LayoutDir, err)
+		}
+		muxUnlock()
+	}, nil
+}
+
+func (storage *OCILayoutStagesStorage) tags() ([]string, error) {
+	unlock, err := storage.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, im, err := storage.indexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var tags []string
+	for _, desc := range im.Manifests {
+		if tag := desc.Annotations[OCILayoutRefNameAnnotation]; tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags, nil
+}
+
+func (storage *OCILayoutStagesStorage) hasTag(tag string) (bool, error) {
+	desc, err := storage.getDescriptor(tag)
+	return desc != nil, err
+}
+
+func (storage *OCILayoutStagesStorage) getDescriptor(tag string) (*v1.Descriptor, error) {
+	unlock, err := storage.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	_, im, err := storage.indexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	return findOCILayoutDescriptor(im, tag), nil
+}
+
+func findOCILayoutDescriptor(im *v1.IndexManifest, tag string) *v1.Descriptor {
+	for _, desc := range im.Manifests {
+		if desc.Annotations[OCILayoutRefNameAnnotation] == tag {
+			desc := desc
+			return &desc
+		}
+	}
+	return nil
+}
+
+// withImage calls f with the tagged image or nil if there is no such tag. The image blobs are read lazily, so f is called
+// under the shared lock and the image must not be read after f returns. f must not take the exclusive lock of the storage.
+func (storage *OCILayoutStagesStorage) withImage(tag string, f func(img v1.Image) error) error {
+	unlock, err := storage.lock(true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, im, err := storage.indexManifest()
+	if err != nil {
+		return err
+	}
+
+	desc := findOCILayoutDescriptor(im, tag)
+	if desc == nil {
+		return f(nil)
+	}
+
+	img, err := p.Image(desc.Digest)
+	if err != nil {
+		return fmt.Errorf("unable to read image %s from oci layout %s: %w", tag, storage.LayoutDir, err)
+	}
+
+	return f(img)
+}
+
+// tagImage adds the tag to the image of the existing tag, returns false if there is no such tag.
+func (storage *OCILayoutStagesStorage) tagImage(existingTag, tag string) (bool, error) {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	p, im, err := storage.indexManifest()
+	if err != nil {
+		return false, err
+	}
+
+	desc := findOCILayoutDescriptor(im, existingTag)
+	if desc == nil {
+		return false, nil
+	}
+
+	img, err := p.Image(desc.Digest)
+	if err != nil {
+		return false, fmt.Errorf("unable to read image %s from oci layout %s: %w", existingTag, storage.LayoutDir, err)
+	}
+
+	if err := p.ReplaceImage(img, match.Annotation(OCILayoutRefNameAnnotation, tag), layout.WithAnnotations(map[string]string{OCILayoutRefNameAnnotation: tag})); err != nil {
+		return false, fmt.Errorf("unable to write image %s into oci layout %s: %w", tag, storage.LayoutDir, err)
+	}
+
+	return true, nil
+}
+
+func (storage *OCILayoutStagesStorage) getImageInfo(repository, tag string) (*image.Info, error) {
+	unlock, err := storage.lock(true)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	p, im, err := storage.indexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	desc := findOCILayoutDescriptor(im, tag)
+	if desc == nil {
+		return nil, nil
+	}
+
+	if !desc.MediaType.IsIndex() {
+		img, err := p.Image(desc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read image %s from oci layout %s: %w", tag, storage.LayoutDir, err)
+		}
+		return newOCILayoutImageInfo(repository, tag, img)
+	}
+
+	platformsIndex, err := p.ImageIndex()
+	if err == nil {
+		platformsIndex, err = platformsIndex.ImageIndex(desc.Digest)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read image index %s from oci layout %s: %w", tag, storage.LayoutDir, err)
+	}
+
+	platformsIndexManifest, err := platformsIndex.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &image.Info{
+		Name:       fmt.Sprintf("%s:%s", repository, tag),
+		Repository: repository,
+		Tag:        tag,
+		RepoDigest: fmt.Sprintf("%s@%s", repository, desc.Digest),
+		IsIndex:    true,
+	}
+
+	for _, platformDesc := range platformsIndexManifest.Manifests {
+		img, err := platformsIndex.Image(platformDesc.Digest)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read image %s@%s from oci layout %s: %w", tag, platformDesc.Digest, storage.LayoutDir, err)
+		}
+
+		platformInfo, err := newOCILayoutImageInfo(repository, tag, img)
+		if err != nil {
+			return nil, err
+		}
+		info.Index = append(info.Index, platformInfo)
+	}
+
+	return info, nil
+}
+
+func newOCILayoutImageInfo(repository, tag string, img v1.Image) (*image.Info, error) {
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, err
+	}
+
+	configFile, err := img.ConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	info := &image.Info{
+		Name:       fmt.Sprintf("%s:%s", repository, tag),
+		Repository: repository,
+		Tag:        tag,
+		RepoDigest: fmt.Sprintf("%s@%s", repository, digest),
+		ID:         manifest.Config.Digest.String(),
+		Labels:     configFile.Config.Labels,
+		OnBuild:    configFile.Config.OnBuild,
+		Env:        configFile.Config.Env,
+	}
+	info.SetCreatedAtUnix(configFile.Created.Unix())
+
+	for _, l := range manifest.Layers {
+		info.Size += l.Size
+	}
+
+	if baseImageID, ok := configFile.Config.Labels["werf.io/base-image-id"]; ok {
+		info.ParentID = baseImageID
+	} else {
+		info.ParentID = configFile.Config.Image
+	}
+
+	return info, nil
+}
+
+func (storage *OCILayoutStagesStorage) getRecordLabels(tag string) (map[string]string, error) {
+	var labels map[string]string
+	err := storage.withImage(tag, func(img v1.Image) error {
+		if img == nil {
+			return nil
+		}
+
+		configFile, err := img.ConfigFile()
+		if err != nil {
+			return fmt.Errorf("unable to get record %s config: %w", tag, err)
+		}
+
+		labels = configFile.Config.Labels
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		return nil
+	})
+
+	return labels, err
+}
+
+func (storage *OCILayoutStagesStorage) putRecord(tag string, labels map[string]string) error {
+	return storage.putImage(tag, container_registry_extensions.NewManifestOnlyImage(labels))
+}
+
+func (storage *OCILayoutStagesStorage) putImage(tag string, img v1.Image) error {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := storage.layoutPath()
+	if err != nil {
+		return err
+	}
+
+	if err := p.ReplaceImage(img, match.Annotation(OCILayoutRefNameAnnotation, tag), layout.WithAnnotations(map[string]string{OCILayoutRefNameAnnotation: tag})); err != nil {
+		return fmt.Errorf("unable to write image %s into oci layout %s: %w", tag, storage.LayoutDir, err)
+	}
+
+	return nil
+}
+
+func (storage *OCILayoutStagesStorage) putImageIndex(tag string, ii v1.ImageIndex) error {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := storage.layoutPath()
+	if err != nil {
+		return err
+	}
+
+	if err := p.ReplaceIndex(ii, match.Annotation(OCILayoutRefNameAnnotation, tag), layout.WithAnnotations(map[string]string{OCILayoutRefNameAnnotation: tag})); err != nil {
+		return fmt.Errorf("unable to write image index %s into oci layout %s: %w", tag, storage.LayoutDir, err)
+	}
+
+	return nil
+}
+
+// removeTags removes tagged descriptors from the index.json and deletes blobs not referenced anymore.
+func (storage *OCILayoutStagesStorage) removeTags(tags ...string) error {
+	unlock, err := storage.lock(false)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p, err := storage.layoutPath()
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		if err := p.RemoveDescriptors(match.Annotation(OCILayoutRefNameAnnotation, tag)); err != nil {
+			return fmt.Errorf("unable to remove %s from oci layout %s: %w", tag, storage.LayoutDir, err)
+		}
+	}
+
+	return storage.collectGarbage(p)
+}
+
+func (storage *OCILayoutStagesStorage) collectGarbage(p layout.Path) error {
+	ii, err := p.ImageIndex()
+	if err != nil {
+		return err
+	}
+
+	used := map[v1.Hash]bool{}
+	if err := markUsedOCILayoutBlobs(ii, used); err != nil {
+		return fmt.Errorf("unable to walk oci layout %s: %w", storage.LayoutDir, err)
+	}
+
+	blobsDir := filepath.Join(string(p), "blobs", "sha256")
+	entries, err := os.ReadDir(blobsDir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		h := v1.Hash{Algorithm: "sha256", Hex: entry.Name()}
+		if used[h] {
+			continue
+		}
+
+		if err := p.RemoveBlob(h); err != nil {
+			return fmt.Errorf("unable to remove unused blob %s: %w", h, err)
+		}
+	}
+
+	return nil
+}
+
+func markUsedOCILayoutBlobs(ii v1.ImageIndex, used map[v1.Hash]bool) error {
+	im, err := ii.IndexManifest()
+	if err != nil {
+		return err
+	}
+
+	for _, desc := range im.Manifests {
+		used[desc.Digest] = true
+
+		if desc.MediaType.IsIndex() {
+			childIndex, err := ii.ImageIndex(desc.Digest)
+			if err != nil {
+				return err
+			}
+
+			if err := markUsedOCILayoutBlobs(childIndex, used); err != nil {
+				return err
+			}
+			continue
+		}
+
+		img, err := ii.Image(desc.Digest)
+		if err != nil {
+			return err
+		}
+
+		manifest, err := img.Manifest()
+		if err != nil {
+			return err
+		}
+
+		used[manifest.Config.Digest] = true
+		for _, l := range manifest.Layers {
+			used[l.Digest] = true
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+
+	"github.com/werf/werf/pkg/image"
+	"github.com/werf/werf/pkg/werf"
+)
+
+const ociLayoutTestDigest = "2604b86b2c7a1c6d19c62601aadb19e7d5c6bb8f17bc2bf26a390ea7"
+
+func newTestOCILayoutStagesStorage(t *testing.T) *OCILayoutStagesStorage {
+	if err := werf.Init(t.TempDir(), t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewOCILayoutStagesStorage(OCILayoutStorageAddressPrefix+filepath.Join(t.TempDir(), "layout"), nil, nil)
+}
+
+func countOCILayoutBlobs(t *testing.T, storage *OCILayoutStagesStorage) int {
+	entries, err := os.ReadDir(filepath.Join(storage.LayoutDir, "blobs", "sha256"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(entries)
+}
+
+func TestOCILayoutStagesStorage_Records(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestOCILayoutStagesStorage(t)
+
+	if err := storage.CreateRepo(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(storage.LayoutDir, "index.json")); err != nil {
+		t.Fatalf("expected index.json to be created: %s", err)
+	}
+
+	for _, name := range []string{"backend", "frontend/app", ""} {
+		if err := storage.AddManagedImage(ctx, "project", name); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if managedImages, err := storage.GetManagedImages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(managedImages) != 3 || managedImages[1] != "frontend/app" || managedImages[2] != "" {
+		t.Errorf("unexpected managed images: %#v", managedImages)
+	}
+
+	if err := storage.RmManagedImage(ctx, "project", "backend"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := storage.IsManagedImageExist(ctx, "project", "backend"); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Errorf("expected managed image backend to be removed")
+	}
+
+	if err := storage.PutImageMetadata(ctx, "project", "frontend/app", "commit", "stage-id"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := storage.IsImageMetadataExist(ctx, "project", "frontend/app", "commit", "stage-id"); err != nil {
+		t.Fatal(err)
+	} else if !exists {
+		t.Errorf("expected image metadata to exist")
+	}
+
+	if metadata, _, err := storage.GetAllAndGroupImageMetadataByImageName(ctx, "project", []string{"frontend/app"}); err != nil {
+		t.Fatal(err)
+	} else if commits := metadata["frontend/app"]["stage-id"]; len(commits) != 1 || commits[0] != "commit" {
+		t.Errorf("unexpected image metadata: %#v", metadata)
+	}
+
+	if err := storage.RmImageMetadata(ctx, "project", getImageMetadataID("frontend/app"), "commit", "stage-id"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := storage.IsImageMetadataExist(ctx, "project", "frontend/app", "commit", "stage-id"); err != nil {
+		t.Fatal(err)
+	} else if exists {
+		t.Errorf("expected image metadata to be removed by image metadata id")
+	}
+
+	importMetadata := &ImportMetadata{ImportSourceID: "source", SourceImageID: "image-id", Checksum: "checksum"}
+	if err := storage.PutImportMetadata(ctx, "project", importMetadata); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := storage.GetImportMetadata(ctx, "project", "source"); err != nil {
+		t.Fatal(err)
+	} else if res == nil || *res != *importMetadata {
+		t.Errorf("unexpected import metadata: %#v", res)
+	}
+	if ids, err := storage.GetImportMetadataIDs(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != "source" {
+		t.Errorf("unexpected import metadata ids: %#v", ids)
+	}
+	if err := storage.RmImportMetadata(ctx, "project", "source"); err != nil {
+		t.Fatal(err)
+	}
+	if res, err := storage.GetImportMetadata(ctx, "project", "source"); err != nil {
+		t.Fatal(err)
+	} else if res != nil {
+		t.Errorf("expected import metadata to be removed, got %#v", res)
+	}
+
+	if err := storage.PostClientIDRecord(ctx, "project", &ClientIDRecord{ClientID: "client-a", TimestampMillisec: 1611836746968}); err != nil {
+		t.Fatal(err)
+	}
+	if records, err := storage.GetClientIDRecords(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(records) != 1 || records[0].ClientID != "client-a" || records[0].TimestampMillisec != 1611836746968 {
+		t.Errorf("unexpected client id records: %#v", records)
+	}
+}
+
+func TestOCILayoutStagesStorage_Stages(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestOCILayoutStagesStorage(t)
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stageID := image.NewStageID(ociLayoutTestDigest, 1611836746968)
+	if err := storage.putImage(stageID.String(), img); err != nil {
+		t.Fatal(err)
+	}
+
+	if ids, err := storage.GetStagesIDs(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 1 || ids[0] != *stageID {
+		t.Errorf("unexpected stages ids: %#v", ids)
+	}
+
+	desc, err := storage.GetStageDescription(ctx, "project", *stageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc == nil {
+		t.Fatalf("expected stage description")
+	}
+	if expected := storage.ConstructStageImageName("project", stageID.Digest, stageID.UniqueID); desc.Info.Name != expected {
+		t.Errorf("expected stage image name %q, got %q", expected, desc.Info.Name)
+	}
+
+	if err := storage.AddStageCustomTag(ctx, desc, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.CheckStageCustomTag(ctx, desc, "v1.0.0"); err != nil {
+		t.Errorf("unexpected custom tag check error: %s", err)
+	}
+	if err := storage.RegisterStageCustomTag(ctx, "project", desc, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if metadata, err := storage.GetStageCustomTagMetadata(ctx, "v1.0.0"); err != nil {
+		t.Fatal(err)
+	} else if metadata.StageID != stageID.String() || metadata.Tag != "v1.0.0" {
+		t.Errorf("unexpected custom tag metadata: %#v", metadata)
+	}
+
+	dst := newTestOCILayoutStagesStorage(t)
+	if copiedDesc, err := dst.CopyFromStorage(ctx, storage, "project", *stageID, CopyFromStorageOptions{}); err != nil {
+		t.Fatal(err)
+	} else if copiedDesc == nil || copiedDesc.Info.ID != desc.Info.ID {
+		t.Errorf("unexpected copied stage description: %#v", copiedDesc)
+	}
+
+	if err := storage.RejectStage(ctx, "project", stageID.Digest, stageID.UniqueID); err != nil {
+		t.Fatal(err)
+	}
+	if rejectedDesc, err := storage.GetStageDescription(ctx, "project", *stageID); err != nil {
+		t.Fatal(err)
+	} else if rejectedDesc != nil {
+		t.Errorf("expected rejected stage to be ignored")
+	}
+	if ids, err := storage.GetStagesIDsByDigest(ctx, "project", stageID.Digest); err != nil {
+		t.Fatal(err)
+	} else if len(ids) != 0 {
+		t.Errorf("expected no suitable stages by digest, got %#v", ids)
+	}
+
+	blobsBefore := countOCILayoutBlobs(t, dst)
+	copiedDesc, err := dst.GetStageDescription(ctx, "project", *stageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.DeleteStage(ctx, copiedDesc, DeleteImageOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if blobsAfter := countOCILayoutBlobs(t, dst); blobsAfter != 0 {
+		t.Errorf("expected all %d blobs to be removed with the stage, got %d left", blobsBefore, blobsAfter)
+	}
+}
+
+func TestOCILayoutStagesStorage_PostMultiplatformImage(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestOCILayoutStagesStorage(t)
+
+	var platformImages []*image.Info
+	for _, uniqueID := range []int64{1611836746968, 1611836746969} {
+		img, err := random.Image(512, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stageID := image.NewStageID(ociLayoutTestDigest, uniqueID)
+		if err := storage.putImage(stageID.String(), img); err != nil {
+			t.Fatal(err)
+		}
+
+		desc, err := storage.GetStageDescription(ctx, "project", *stageID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		platformImages = append(platformImages, desc.Info)
+	}
+
+	if err := storage.PostMultiplatformImage(ctx, "project", ociLayoutTestDigest, platformImages); err != nil {
+		t.Fatal(err)
+	}
+
+	desc, err := storage.GetStageDescription(ctx, "project", *image.NewStageID(ociLayoutTestDigest, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if desc == nil || !desc.Info.IsIndex || len(desc.Info.Index) != 2 {
+		t.Fatalf("unexpected multiplatform stage description: %#v", desc)
+	}
+}
+
+func TestOCILayoutStagesStorage_ConcurrentWriters(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestOCILayoutStagesStorage(t)
+	if err := storage.CreateRepo(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// Separate storage instances do not share the mutex and rely on the host lock only, as werf processes do.
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			writer := NewOCILayoutStagesStorage(OCILayoutStorageAddressPrefix+storage.LayoutDir, nil, nil)
+			errs <- writer.AddManagedImage(ctx, "project", fmt.Sprintf("image-%d", i))
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if managedImages, err := storage.GetManagedImages(ctx, "project"); err != nil {
+		t.Fatal(err)
+	} else if len(managedImages) != 20 {
+		t.Errorf("expected 20 managed images, got %d: %#v", len(managedImages), managedImages)
+	}
+}
+
+func TestOCILayoutStagesStorage_WithImageHoldsLock(t *testing.T) {
+	ctx := context.Background()
+	storage := newTestOCILayoutStagesStorage(t)
+
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stageID := image.NewStageID(ociLayoutTestDigest, 1611836746968)
+	if err := storage.putImage(stageID.String(), img); err != nil {
+		t.Fatal(err)
+	}
+	desc, err := storage.GetStageDescription(ctx, "project", *stageID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The other instance does not share the mutex, so the deletion waits for the host lock only.
+	deleter := NewOCILayoutStagesStorage(OCILayoutStorageAddressPrefix+storage.LayoutDir, nil, nil)
+	deleted := make(chan error, 1)
+
+	err = storage.withImage(stageID.String(), func(layoutImg v1.Image) error {
+		go func() {
+			deleted <- deleter.DeleteStage(ctx, desc, DeleteImageOptions{})
+		}()
+		time.Sleep(500 * time.Millisecond)
+
+		select {
+		case err := <-deleted:
+			return fmt.Errorf("stage deleted while the image is being read: %v", err)
+		default:
+		}
+
+		layers, err := layoutImg.Layers()
+		if err != nil {
+			return err
+		}
+		for _, layer := range layers {
+			rc, err := layer.Compressed()
+			if err != nil {
+				return err
+			}
+			_, err = io.Copy(io.Discard, rc)
+			rc.Close()
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-deleted; err != nil {
+		t.Fatal(err)
+	}
+	if blobs := countOCILayoutBlobs(t, storage); blobs != 0 {
+		t.Errorf("expected all blobs to be removed after the image is read, got %d left", blobs)
+	}
+}
@@ -86,40 +86,43 @@ func (storage *RepoStagesStorage) ConstructStageImageName(_, digest string, uniq
 }
 
 func (storage *RepoStagesStorage) GetStagesIDs(ctx context.Context, _ string, opts ...Option) ([]image.StageID, error) {
-	var res []image.StageID
-
 	o := makeOptions(opts...)
 	if tags, err := storage.DockerRegistry.Tags(ctx, storage.RepoAddress, o.dockerRegistryOptions...); err != nil {
 		return nil, fmt.Errorf("unable to fetch tags for repo %q: %w", storage.RepoAddress, err)
 	} else {
 		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetStagesIDs fetched tags for %q: %#v\n", storage.RepoAddress, tags)
+		return getStagesIDsFromTags(ctx, tags)
+	}
+}
 
-		for _, tag := range tags {
-			isRegularStage := (len(tag) == 70 && len(strings.Split(tag, "-")) == 2) // 2604b86b2c7a1c6d19c62601aadb19e7d5c6bb8f17bc2bf26a390ea7-1611836746968
-			isMultiplatformStage := (len(tag) == 56)                                // 2604b86b2c7a1c6d19c62601aadb19e7d5c6bb8f17bc2bf26a390ea7
-			if !isRegularStage && !isMultiplatformStage {
-				continue
-			}
+func getStagesIDsFromTags(ctx context.Context, tags []string) ([]image.StageID, error) {
+	var res []image.StageID
 
-			if strings.HasPrefix(tag, RepoManagedImageRecord_ImageTagPrefix) || strings.HasPrefix(tag, RepoImageMetadataByCommitRecord_ImageTagPrefix) || strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
-				continue
-			}
+	for _, tag := range tags {
+		isRegularStage := (len(tag) == 70 && len(strings.Split(tag, "-")) == 2) // 2604b86b2c7a1c6d19c62601aadb19e7d5c6bb8f17bc2bf26a390ea7-1611836746968
+		isMultiplatformStage := (len(tag) == 56)                                // 2604b86b2c7a1c6d19c62601aadb19e7d5c6bb8f17bc2bf26a390ea7
+		if !isRegularStage && !isMultiplatformStage {
+			continue
+		}
 
-			if digest, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(tag); err != nil {
-				if isUnexpectedTagFormatError(err) {
-					logboek.Context(ctx).Debug().LogLn(err.Error())
-					continue
-				}
-				return nil, err
-			} else {
-				res = append(res, *image.NewStageID(digest, uniqueID))
+		if strings.HasPrefix(tag, RepoManagedImageRecord_ImageTagPrefix) || strings.HasPrefix(tag, RepoImageMetadataByCommitRecord_ImageTagPrefix) || strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
+			continue
+		}
 
-				logboek.Context(ctx).Debug().LogF("Selected stage by digest %q uniqueID %d\n", digest, uniqueID)
+		if digest, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(tag); err != nil {
+			if isUnexpectedTagFormatError(err) {
+				logboek.Context(ctx).Debug().LogLn(err.Error())
+				continue
 			}
-		}
+			return nil, err
+		} else {
+			res = append(res, *image.NewStageID(digest, uniqueID))
 
-		return res, nil
+			logboek.Context(ctx).Debug().LogF("Selected stage by digest %q uniqueID %d\n", digest, uniqueID)
+		}
 	}
+
+	return res, nil
 }
 
 func (storage *RepoStagesStorage) ExportStage(ctx context.Context, stageDescription *image.StageDescription, destinationReference string, mutateConfigFunc func(config v1.Config) (v1.Config, error)) error {
@@ -193,68 +196,77 @@ func (storage *RepoStagesStorage) DeleteRepo(ctx context.Context) error {
 }
 
 func (storage *RepoStagesStorage) GetStagesIDsByDigest(ctx context.Context, _, digest string, opts ...Option) ([]image.StageID, error) {
-	var res []image.StageID
-
 	o := makeOptions(opts...)
-	if tags, err := storage.DockerRegistry.Tags(ctx, storage.RepoAddress, o.dockerRegistryOptions...); err != nil {
+	tags, err := storage.DockerRegistry.Tags(ctx, storage.RepoAddress, o.dockerRegistryOptions...)
+	if err != nil {
 		return nil, fmt.Errorf("unable to fetch tags for repo %q: %w", storage.RepoAddress, err)
-	} else {
-		var rejectedStages []image.StageID
+	}
 
-		for _, tag := range tags {
-			if !strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
-				continue
-			}
+	res, err := getStagesIDsByDigestFromTags(ctx, tags, digest)
+	if err != nil {
+		return nil, err
+	}
 
-			realTag := strings.TrimSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix)
+	logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetRepoImagesByDigest result for %q: %#v\n", storage.RepoAddress, res)
 
-			if _, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(realTag); err != nil {
-				if isUnexpectedTagFormatError(err) {
-					logboek.Context(ctx).Info().LogF("Unexpected tag %q format: %s\n", realTag, err)
-					continue
-				}
-				return nil, fmt.Errorf("unable to get digest and uniqueID from rejected stage tag %q: %w", tag, err)
-			} else {
-				logboek.Context(ctx).Info().LogF("Found rejected stage %q\n", tag)
-				rejectedStages = append(rejectedStages, *image.NewStageID(digest, uniqueID))
-			}
+	return res, nil
+}
+
+func getStagesIDsByDigestFromTags(ctx context.Context, tags []string, digest string) ([]image.StageID, error) {
+	var res []image.StageID
+	var rejectedStages []image.StageID
+
+	for _, tag := range tags {
+		if !strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
+			continue
 		}
 
-	FindSuitableStages:
-		for _, tag := range tags {
-			if !strings.HasPrefix(tag, digest) {
+		realTag := strings.TrimSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix)
+
+		if _, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(realTag); err != nil {
+			if isUnexpectedTagFormatError(err) {
+				logboek.Context(ctx).Info().LogF("Unexpected tag %q format: %s\n", realTag, err)
 				continue
 			}
+			return nil, fmt.Errorf("unable to get digest and uniqueID from rejected stage tag %q: %w", tag, err)
+		} else {
+			logboek.Context(ctx).Info().LogF("Found rejected stage %q\n", tag)
+			rejectedStages = append(rejectedStages, *image.NewStageID(digest, uniqueID))
+		}
+	}
+
+FindSuitableStages:
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, digest) {
+			continue
+		}
 
-			if strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
+		if strings.HasSuffix(tag, RepoRejectedStageImageRecord_ImageTagSuffix) {
+			continue
+		}
+
+		if _, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(tag); err != nil {
+			if isUnexpectedTagFormatError(err) {
+				logboek.Context(ctx).Debug().LogLn(err.Error())
+				logboek.Context(ctx).Info().LogF("Unexpected tag %q format: %s\n", tag, err)
 				continue
 			}
+			return nil, fmt.Errorf("unable to get digest and uniqueID from tag %q: %w", tag, err)
+		} else {
+			stageID := image.NewStageID(digest, uniqueID)
 
-			if _, uniqueID, err := getDigestAndUniqueIDFromRepoStageImageTag(tag); err != nil {
-				if isUnexpectedTagFormatError(err) {
-					logboek.Context(ctx).Debug().LogLn(err.Error())
-					logboek.Context(ctx).Info().LogF("Unexpected tag %q format: %s\n", tag, err)
-					continue
+			for _, rejectedStage := range rejectedStages {
+				if rejectedStage.Digest == stageID.Digest && rejectedStage.UniqueID == stageID.UniqueID {
+					logboek.Context(ctx).Info().LogF("Discarding rejected stage %q\n", tag)
+					continue FindSuitableStages
 				}
-				return nil, fmt.Errorf("unable to get digest and uniqueID from tag %q: %w", tag, err)
-			} else {
-				stageID := image.NewStageID(digest, uniqueID)
-
-				for _, rejectedStage := range rejectedStages {
-					if rejectedStage.Digest == stageID.Digest && rejectedStage.UniqueID == stageID.UniqueID {
-						logboek.Context(ctx).Info().LogF("Discarding rejected stage %q\n", tag)
-						continue FindSuitableStages
-					}
-				}
-
-				logboek.Context(ctx).Debug().LogF("Stage %q is suitable for digest %q\n", tag, digest)
-				res = append(res, *stageID)
 			}
+
+			logboek.Context(ctx).Debug().LogF("Stage %q is suitable for digest %q\n", tag, digest)
+			res = append(res, *stageID)
 		}
 	}
 
-	logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetRepoImagesByDigest result for %q: %#v\n", storage.RepoAddress, res)
-
 	return res, nil
 }
 
@@ -831,6 +843,10 @@ func (storage *RepoStagesStorage) GetClientIDRecords(ctx context.Context, projec
 		return nil, fmt.Errorf("unable to get repo %s tags: %w", storage.RepoAddress, err)
 	}
 
+	return getClientIDRecordsFromTags(ctx, tags), nil
+}
+
+func getClientIDRecordsFromTags(ctx context.Context, tags []string) []*ClientIDRecord {
 	var res []*ClientIDRecord
 	for _, tag := range tags {
 		if !strings.HasPrefix(tag, RepoClientIDRecord_ImageTagPrefix) {
@@ -853,10 +869,10 @@ func (storage *RepoStagesStorage) GetClientIDRecords(ctx context.Context, projec
 		rec := &ClientIDRecord{ClientID: clientID, TimestampMillisec: timestampMillisec}
 		res = append(res, rec)
 
-		logboek.Context(ctx).Debug().LogF("-- RepoStagesStorage.GetClientIDRecords got clientID record: %s\n", rec)
+		logboek.Context(ctx).Debug().LogF("-- getClientIDRecordsFromTags got clientID record: %s\n", rec)
 	}
 
-	return res, nil
+	return res
 }
 
 func (storage *RepoStagesStorage) PostClientIDRecord(ctx context.Context, projectName string, rec *ClientIDRecord) error {
@@ -903,10 +919,16 @@ func (storage *RepoStagesStorage) CopyFromStorage(ctx context.Context, src Stage
 		return desc, nil
 	}
 
-	srcRef := src.ConstructStageImageName(projectName, stageID.Digest, stageID.UniqueID)
 	dstRef := storage.ConstructStageImageName(projectName, stageID.Digest, stageID.UniqueID)
-	if err := storage.DockerRegistry.CopyImage(ctx, srcRef, dstRef, docker_registry.CopyImageOptions{}); err != nil {
-		return nil, fmt.Errorf("unable to copy image into registry: %w", err)
+	if layoutSrc, ok := src.(*OCILayoutStagesStorage); ok {
+		if err := layoutSrc.pushStageImage(ctx, storage.DockerRegistry, stageID, dstRef); err != nil {
+			return nil, fmt.Errorf("unable to push image from oci layout into registry: %w", err)
+		}
+	} else {
+		srcRef := src.ConstructStageImageName(projectName, stageID.Digest, stageID.UniqueID)
+		if err := storage.DockerRegistry.CopyImage(ctx, srcRef, dstRef, docker_registry.CopyImageOptions{}); err != nil {
+			return nil, fmt.Errorf("unable to copy image into registry: %w", err)
+		}
 	}
 
 	desc, err = storage.GetStageDescription(ctx, projectName, stageID)
@@ -5,7 +5,10 @@ import (
 	"strings"
 )
 
-var ErrBadKubernetesSynchronizationAddress = errors.New("bad kubernetes synchronization address")
+var (
+	ErrBadKubernetesSynchronizationAddress = errors.New("bad kubernetes synchronization address")
+	ErrBadFileDbSynchronizationAddress     = errors.New("bad file+db synchronization address")
+)
 
 type KubernetesSynchronizationParams struct {
 	ConfigContext       string
@@ -49,3 +52,17 @@ func ParseKubernetesSynchronization(address string) (*KubernetesSynchronizationP
 
 	return res, nil
 }
+
+// ParseFileDbSynchronization returns directory of the embedded synchronization database from the file+db://DIR address.
+func ParseFileDbSynchronization(address string) (string, error) {
+	if !strings.HasPrefix(address, "file+db://") {
+		return "", ErrBadFileDbSynchronizationAddress
+	}
+
+	dir := strings.TrimPrefix(address, "file+db://")
+	if dir == "" {
+		return "", ErrBadFileDbSynchronizationAddress
+	}
+
+	return dir, nil
+}
@@ -0,0 +1,116 @@
+package synchronization_server
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// AuthConfig describes projects allowed to use the synchronization server and their credentials.
+//
+//	projects:
+//	- name: myproject
+//	  tokens:
+//	  - "<bearer token>"
+//	  certificateCommonNames:
+//	  - ci-runner.mydomain.org
+type AuthConfig struct {
+	Projects []*AuthConfigProject `json:"projects"`
+}
+
+type AuthConfigProject struct {
+	Name                   string   `json:"name"`
+	Tokens                 []string `json:"tokens"`
+	CertificateCommonNames []string `json:"certificateCommonNames"`
+}
+
+var authConfigProjectNameRegexp = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+func LoadAuthConfig(path string) (*AuthConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read auth config %q: %w", path, err)
+	}
+
+	config := &AuthConfig{}
+	if err := yaml.UnmarshalStrict(data, config); err != nil {
+		return nil, fmt.Errorf("unable to parse auth config %q: %w", path, err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("bad auth config %q: %w", path, err)
+	}
+
+	return config, nil
+}
+
+func (config *AuthConfig) Validate() error {
+	names := map[string]bool{}
+	for _, project := range config.Projects {
+		// The project name is a part of the synchronization data namespace, so it cannot contain the namespace separator
+		if !authConfigProjectNameRegexp.MatchString(project.Name) {
+			return fmt.Errorf("bad project name %q: expected lowercase alphanumeric characters and dashes", project.Name)
+		}
+
+		if names[project.Name] {
+			return fmt.Errorf("project %q is specified more than once", project.Name)
+		}
+		names[project.Name] = true
+
+		if len(project.Tokens) == 0 && len(project.CertificateCommonNames) == 0 {
+			return fmt.Errorf("project %q has neither tokens nor certificate common names", project.Name)
+		}
+
+		for _, token := range project.Tokens {
+			if token == "" {
+				return fmt.Errorf("project %q has an empty token", project.Name)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Authenticate returns the name of the project, which the bearer token or the verified client certificate of the request belongs to.
+func (config *AuthConfig) Authenticate(r *http.Request) (string, bool) {
+	if token, hasToken := getBearerToken(r); hasToken {
+		for _, project := range config.Projects {
+			for _, projectToken := range project.Tokens {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(projectToken)) == 1 {
+					return project.Name, true
+				}
+			}
+		}
+
+		return "", false
+	}
+
+	// Only certificates verified by the server TLS configuration get into the VerifiedChains
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		commonName := r.TLS.VerifiedChains[0][0].Subject.CommonName
+
+		for _, project := range config.Projects {
+			for _, projectCommonName := range project.CertificateCommonNames {
+				if commonName == projectCommonName {
+					return project.Name, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+func getBearerToken(r *http.Request) (string, bool) {
+	authorization := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authorization, "Bearer ") {
+		return "", false
+	}
+
+	return strings.TrimSpace(strings.TrimPrefix(authorization, "Bearer ")), true
+}
@@ -0,0 +1,162 @@
+package synchronization_server
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/lockgate/pkg/distributed_locker"
+	"github.com/werf/werf/pkg/storage"
+)
+
+// LockTrackingBackend keeps info about the locks acquired through it for the backends, which cannot list their locks.
+// The info is kept in memory, so the backend should be the only entrypoint to the underlying locks.
+type LockTrackingBackend struct {
+	distributed_locker.DistributedLockerBackend
+
+	owner string
+	state *lockTrackingState
+}
+
+type lockTrackingState struct {
+	mux   sync.Mutex
+	locks map[string]*storage.LockInfo
+}
+
+func NewLockTrackingBackend(backend distributed_locker.DistributedLockerBackend) *LockTrackingBackend {
+	return &LockTrackingBackend{
+		DistributedLockerBackend: backend,
+		state:                    &lockTrackingState{locks: make(map[string]*storage.LockInfo)},
+	}
+}
+
+// WithOwner returns the backend sharing the tracked locks, which records the specified owner of the acquired locks.
+func (backend *LockTrackingBackend) WithOwner(owner string) distributed_locker.DistributedLockerBackend {
+	return &LockTrackingBackend{DistributedLockerBackend: backend.DistributedLockerBackend, owner: owner, state: backend.state}
+}
+
+func (backend *LockTrackingBackend) Acquire(lockName string, opts distributed_locker.AcquireOptions) (lockgate.LockHandle, error) {
+	handle, err := backend.DistributedLockerBackend.Acquire(lockName, opts)
+	if err != nil {
+		return handle, err
+	}
+
+	backend.state.mux.Lock()
+	defer backend.state.mux.Unlock()
+
+	now := time.Now()
+	expireAt := now.Add(distributed_locker.DistributedLockLeaseTTLSeconds * time.Second)
+
+	if info, hasKey := backend.state.locks[lockName]; hasKey && info.UUID == handle.UUID {
+		info.SharedHoldersCount++
+		info.ExpireAt = expireAt
+	} else {
+		backend.state.locks[lockName] = &storage.LockInfo{
+			Name:               lockName,
+			UUID:               handle.UUID,
+			OwnerClientID:      backend.owner,
+			AcquiredAt:         now,
+			ExpireAt:           expireAt,
+			IsShared:           opts.Shared,
+			SharedHoldersCount: 1,
+		}
+	}
+
+	return handle, nil
+}
+
+func (backend *LockTrackingBackend) RenewLease(handle lockgate.LockHandle) error {
+	if err := backend.DistributedLockerBackend.RenewLease(handle); err != nil {
+		return err
+	}
+
+	backend.state.mux.Lock()
+	defer backend.state.mux.Unlock()
+
+	if info, hasKey := backend.state.locks[handle.LockName]; hasKey && info.UUID == handle.UUID {
+		info.ExpireAt = time.Now().Add(distributed_locker.DistributedLockLeaseTTLSeconds * time.Second)
+	}
+
+	return nil
+}
+
+func (backend *LockTrackingBackend) Release(handle lockgate.LockHandle) error {
+	if err := backend.DistributedLockerBackend.Release(handle); err != nil {
+		return err
+	}
+
+	backend.state.mux.Lock()
+	defer backend.state.mux.Unlock()
+
+	if info, hasKey := backend.state.locks[handle.LockName]; hasKey && info.UUID == handle.UUID {
+		info.SharedHoldersCount--
+		if info.SharedHoldersCount <= 0 {
+			delete(backend.state.locks, handle.LockName)
+		}
+	}
+
+	return nil
+}
+
+func (backend *LockTrackingBackend) ListLocks(_ context.Context) ([]*storage.LockInfo, error) {
+	backend.state.mux.Lock()
+	defer backend.state.mux.Unlock()
+
+	now := time.Now()
+
+	var res []*storage.LockInfo
+	for name, info := range backend.state.locks {
+		if now.After(info.ExpireAt) {
+			delete(backend.state.locks, name)
+			continue
+		}
+
+		infoCopy := *info
+		res = append(res, &infoCopy)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Name < res[j].Name })
+
+	return res, nil
+}
+
+// ForceReleaseLock releases the lock on behalf of all its holders.
+func (backend *LockTrackingBackend) ForceReleaseLock(_ context.Context, lockName string) error {
+	backend.state.mux.Lock()
+	defer backend.state.mux.Unlock()
+
+	info, hasKey := backend.state.locks[lockName]
+	if !hasKey {
+		return distributed_locker.ErrNoExistingLockLeaseFound
+	}
+
+	handle := lockgate.LockHandle{UUID: info.UUID, LockName: lockName}
+	for i := int64(0); i < info.SharedHoldersCount; i++ {
+		if err := backend.DistributedLockerBackend.Release(handle); distributed_locker.IsErrNoExistingLockLeaseFound(err) || distributed_locker.IsErrLockAlreadyLeased(err) {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	delete(backend.state.locks, lockName)
+
+	return nil
+}
+
+// withLockOwner returns the backend recording the specified owner of the acquired locks if the backend supports it.
+func withLockOwner(backend distributed_locker.DistributedLockerBackend, owner string) distributed_locker.DistributedLockerBackend {
+	if owner == "" {
+		return backend
+	}
+
+	if ownerAwareBackend, ok := backend.(interface {
+		WithOwner(owner string) distributed_locker.DistributedLockerBackend
+	}); ok {
+		return ownerAwareBackend.WithOwner(owner)
+	}
+
+	return backend
+}
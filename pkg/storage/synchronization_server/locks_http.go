@@ -0,0 +1,96 @@
+package synchronization_server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/storage"
+	"github.com/werf/werf/pkg/util"
+)
+
+// LockOwnerHeader passes the owner of the acquired locks, see storage.GetLockOwnerClientID.
+const LockOwnerHeader = "X-Werf-Lock-Owner"
+
+func NewLocksHttpHandler(locksInspector storage.LocksInspector) *LocksHttpHandler {
+	handler := &LocksHttpHandler{
+		LocksInspector: locksInspector,
+		ServeMux:       http.NewServeMux(),
+	}
+	handler.HandleFunc("/list", handler.handleListLocks())
+	handler.HandleFunc("/force-release", handler.handleForceReleaseLock())
+	return handler
+}
+
+type LocksHttpHandler struct {
+	*http.ServeMux
+	LocksInspector storage.LocksInspector
+}
+
+type ListLocksRequest struct{}
+
+type ListLocksResponse struct {
+	Err   util.SerializableError `json:"err"`
+	Locks []*storage.LockInfo    `json:"locks"`
+}
+
+func (handler *LocksHttpHandler) handleListLocks() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request ListLocksRequest
+		var response ListLocksResponse
+		HandleRequest(w, r, &request, &response, func() {
+			logboek.Debug().LogF("LocksHttpHandler -- ListLocks request %#v\n", request)
+			response.Locks, response.Err.Error = handler.LocksInspector.ListLocks(context.Background())
+			logboek.Debug().LogF("LocksHttpHandler -- ListLocks response %#v\n", response)
+		})
+	}
+}
+
+type ForceReleaseLockRequest struct {
+	LockName string `json:"lockName"`
+}
+
+type ForceReleaseLockResponse struct {
+	Err util.SerializableError `json:"err"`
+}
+
+func (handler *LocksHttpHandler) handleForceReleaseLock() func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request ForceReleaseLockRequest
+		var response ForceReleaseLockResponse
+		HandleRequest(w, r, &request, &response, func() {
+			logboek.Debug().LogF("LocksHttpHandler -- ForceReleaseLock request %#v\n", request)
+			response.Err.Error = handler.LocksInspector.ForceReleaseLock(context.Background(), request.LockName)
+			logboek.Debug().LogF("LocksHttpHandler -- ForceReleaseLock response %#v\n", response)
+		})
+	}
+}
+
+func NewLocksHttpClient(url string, httpClient *http.Client) *LocksHttpClient {
+	return &LocksHttpClient{
+		URL:        url,
+		HttpClient: httpClient,
+	}
+}
+
+type LocksHttpClient struct {
+	URL        string
+	HttpClient *http.Client
+}
+
+func (client *LocksHttpClient) ListLocks(_ context.Context) ([]*storage.LockInfo, error) {
+	var response ListLocksResponse
+	if err := PerformPost(client.HttpClient, fmt.Sprintf("%s/%s", client.URL, "list"), ListLocksRequest{}, &response); err != nil {
+		return nil, err
+	}
+	return response.Locks, response.Err.Error
+}
+
+func (client *LocksHttpClient) ForceReleaseLock(_ context.Context, lockName string) error {
+	var response ForceReleaseLockResponse
+	if err := PerformPost(client.HttpClient, fmt.Sprintf("%s/%s", client.URL, "force-release"), ForceReleaseLockRequest{LockName: lockName}, &response); err != nil {
+		return err
+	}
+	return response.Err.Error
+}
@@ -0,0 +1,161 @@
+package synchronization_server
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/lockgate/pkg/distributed_locker"
+	"github.com/werf/werf/pkg/image"
+)
+
+// Metrics of the synchronization server, which are exposed in the prometheus format by the /metrics endpoint.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	lockAcquireRequests      *prometheus.CounterVec
+	lockWaitSeconds          *prometheus.HistogramVec
+	stagesStorageCacheLookup *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		lockAcquireRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "werf_synchronization_lock_acquire_requests_total",
+			Help: "Number of lock acquire requests by result: acquired, wait or error.",
+		}, []string{"project", "result"}),
+		lockWaitSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "werf_synchronization_lock_wait_seconds",
+			Help:    "Time from the first refused acquire request of the busy lock till the lock is acquired.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		}, []string{"project"}),
+		stagesStorageCacheLookup: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "werf_synchronization_stages_storage_cache_lookups_total",
+			Help: "Number of stages storage cache lookups by result: hit or miss.",
+		}, []string{"project", "result"}),
+	}
+
+	m.Registry.MustRegister(m.lockAcquireRequests, m.lockWaitSeconds, m.stagesStorageCacheLookup)
+
+	return m
+}
+
+func (m *Metrics) InstrumentDistributedLockerBackend(project string, backend distributed_locker.DistributedLockerBackend) distributed_locker.DistributedLockerBackend {
+	return &instrumentedDistributedLockerBackend{
+		DistributedLockerBackend: backend,
+		metrics:                  m,
+		project:                  project,
+		waits:                    &lockWaits{waitingSince: make(map[string]*lockWait)},
+	}
+}
+
+func (m *Metrics) InstrumentStagesStorageCache(project string, cache StagesStorageCacheInterface) StagesStorageCacheInterface {
+	return &instrumentedStagesStorageCache{StagesStorageCacheInterface: cache, metrics: m, project: project}
+}
+
+type instrumentedDistributedLockerBackend struct {
+	distributed_locker.DistributedLockerBackend
+
+	metrics *Metrics
+	project string
+	waits   *lockWaits
+}
+
+// Clients poll the busy lock, so the wait time is counted from the first refused request of any client
+type lockWaits struct {
+	mux          sync.Mutex
+	waitingSince map[string]*lockWait
+}
+
+type lockWait struct {
+	since         time.Time
+	lastRefusedAt time.Time
+}
+
+// lockWaitTimeout is the time after the last refused request, when the waiting clients are considered gone
+// (e.g. killed or timed out), the polling clients retry every few seconds.
+const lockWaitTimeout = 10 * distributed_locker.DistributedLockPollRetryPeriodSeconds * time.Second
+
+func (waits *lockWaits) removeStale(now time.Time) {
+	for lockName, wait := range waits.waitingSince {
+		if now.Sub(wait.lastRefusedAt) > lockWaitTimeout {
+			delete(waits.waitingSince, lockName)
+		}
+	}
+}
+
+// WithOwner returns the instrumented backend sharing the lock wait times, which records the specified owner of the acquired locks.
+func (backend *instrumentedDistributedLockerBackend) WithOwner(owner string) distributed_locker.DistributedLockerBackend {
+	return &instrumentedDistributedLockerBackend{
+		DistributedLockerBackend: withLockOwner(backend.DistributedLockerBackend, owner),
+		metrics:                  backend.metrics,
+		project:                  backend.project,
+		waits:                    backend.waits,
+	}
+}
+
+func (backend *instrumentedDistributedLockerBackend) Acquire(lockName string, opts distributed_locker.AcquireOptions) (lockgate.LockHandle, error) {
+	handle, err := backend.DistributedLockerBackend.Acquire(lockName, opts)
+
+	backend.waits.mux.Lock()
+	defer backend.waits.mux.Unlock()
+
+	now := time.Now()
+	backend.waits.removeStale(now)
+
+	switch {
+	case distributed_locker.IsErrShouldWait(err):
+		backend.metrics.lockAcquireRequests.WithLabelValues(backend.project, "wait").Inc()
+		if wait, hasKey := backend.waits.waitingSince[lockName]; hasKey {
+			wait.lastRefusedAt = now
+		} else {
+			backend.waits.waitingSince[lockName] = &lockWait{since: now, lastRefusedAt: now}
+		}
+	case err != nil:
+		backend.metrics.lockAcquireRequests.WithLabelValues(backend.project, "error").Inc()
+	default:
+		backend.metrics.lockAcquireRequests.WithLabelValues(backend.project, "acquired").Inc()
+		if wait, hasKey := backend.waits.waitingSince[lockName]; hasKey {
+			backend.metrics.lockWaitSeconds.WithLabelValues(backend.project).Observe(now.Sub(wait.since).Seconds())
+			delete(backend.waits.waitingSince, lockName)
+		}
+	}
+
+	return handle, err
+}
+
+type instrumentedStagesStorageCache struct {
+	StagesStorageCacheInterface
+
+	metrics *Metrics
+	project string
+}
+
+func (cache *instrumentedStagesStorageCache) GetAllStages(ctx context.Context, projectName string) (bool, []image.StageID, error) {
+	found, stages, err := cache.StagesStorageCacheInterface.GetAllStages(ctx, projectName)
+	cache.observeLookup(found, err)
+	return found, stages, err
+}
+
+func (cache *instrumentedStagesStorageCache) GetStagesByDigest(ctx context.Context, projectName, digest string) (bool, []image.StageID, error) {
+	found, stages, err := cache.StagesStorageCacheInterface.GetStagesByDigest(ctx, projectName, digest)
+	cache.observeLookup(found, err)
+	return found, stages, err
+}
+
+func (cache *instrumentedStagesStorageCache) observeLookup(found bool, err error) {
+	if err != nil {
+		return
+	}
+
+	result := "miss"
+	if found {
+		result = "hit"
+	}
+
+	cache.metrics.stagesStorageCacheLookup.WithLabelValues(cache.project, result).Inc()
+}
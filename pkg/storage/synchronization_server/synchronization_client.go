@@ -1,8 +1,11 @@
 package synchronization_server
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"os"
 )
 
 type SynchronizationClient struct {
@@ -10,10 +13,10 @@ type SynchronizationClient struct {
 	URL        string
 }
 
-func NewSynchronizationClient(url string) *SynchronizationClient {
+func NewSynchronizationClient(url string, httpClient *http.Client) *SynchronizationClient {
 	return &SynchronizationClient{
 		URL:        url,
-		HttpClient: &http.Client{},
+		HttpClient: httpClient,
 	}
 }
 
@@ -25,3 +28,74 @@ func (client *SynchronizationClient) NewClientID() (string, error) {
 	}
 	return response.ClientID, response.Err.Error
 }
+
+type HttpClientOptions struct {
+	// Token is sent in the Authorization header of each request
+	Token string
+	// LockOwner is recorded by the server in the acquired locks
+	LockOwner string
+
+	TLSClientCertFile string
+	TLSClientKeyFile  string
+	CACertFile        string
+}
+
+// NewHttpClient creates the client for the synchronization server requiring authentication.
+func NewHttpClient(opts HttpClientOptions) (*http.Client, error) {
+	var transport http.RoundTripper = http.DefaultTransport
+
+	if opts.TLSClientCertFile != "" || opts.CACertFile != "" {
+		tlsTransport := http.DefaultTransport.(*http.Transport).Clone()
+		tlsTransport.TLSClientConfig = &tls.Config{}
+
+		if opts.CACertFile != "" {
+			caData, err := os.ReadFile(opts.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to read CA certificate file %q: %w", opts.CACertFile, err)
+			}
+
+			rootCAs := x509.NewCertPool()
+			if !rootCAs.AppendCertsFromPEM(caData) {
+				return nil, fmt.Errorf("no certificates found in the CA certificate file %q", opts.CACertFile)
+			}
+			tlsTransport.TLSClientConfig.RootCAs = rootCAs
+		}
+
+		if opts.TLSClientCertFile != "" {
+			cert, err := tls.LoadX509KeyPair(opts.TLSClientCertFile, opts.TLSClientKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("unable to load client certificate %q: %w", opts.TLSClientCertFile, err)
+			}
+			tlsTransport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+		}
+
+		transport = tlsTransport
+	}
+
+	headers := http.Header{}
+	if opts.Token != "" {
+		headers.Set("Authorization", fmt.Sprintf("Bearer %s", opts.Token))
+	}
+	if opts.LockOwner != "" {
+		headers.Set(LockOwnerHeader, opts.LockOwner)
+	}
+
+	if len(headers) > 0 {
+		transport = &headersRoundTripper{headers: headers, base: transport}
+	}
+
+	return &http.Client{Transport: transport}, nil
+}
+
+type headersRoundTripper struct {
+	headers http.Header
+	base    http.RoundTripper
+}
+
+func (rt *headersRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	for key, values := range rt.headers {
+		r.Header[key] = values
+	}
+	return rt.base.RoundTrip(r)
+}
@@ -2,21 +2,79 @@ package synchronization_server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/werf/lockgate/pkg/distributed_locker"
 	"github.com/werf/logboek"
+	"github.com/werf/werf/pkg/storage"
 	"github.com/werf/werf/pkg/util"
 )
 
-func RunSynchronizationServer(_ context.Context, ip, port string, distributedLockerBackendFactoryFunc func(clientID string) (distributed_locker.DistributedLockerBackend, error), stagesStorageCacheFactoryFunc func(clientID string) (StagesStorageCacheInterface, error)) error {
+type SynchronizationServerOptions struct {
+	// AuthConfig enables authentication of the requests, each project gets a separate synchronization data namespace.
+	AuthConfig *AuthConfig
+
+	TLSCertFile     string
+	TLSKeyFile      string
+	TLSClientCAFile string
+
+	// MetricsAddress serves /metrics on the separate address without authentication instead of the main address.
+	MetricsAddress string
+}
+
+func RunSynchronizationServer(_ context.Context, ip, port string, distributedLockerBackendFactoryFunc func(clientID string) (distributed_locker.DistributedLockerBackend, error), stagesStorageCacheFactoryFunc func(clientID string) (StagesStorageCacheInterface, error), opts SynchronizationServerOptions) error {
 	handler := NewSynchronizationServerHandler(distributedLockerBackendFactoryFunc, stagesStorageCacheFactoryFunc)
-	return http.ListenAndServe(fmt.Sprintf("%s:%s", ip, port), handler)
+	handler.AuthConfig = opts.AuthConfig
+
+	server := &http.Server{Addr: fmt.Sprintf("%s:%s", ip, port), Handler: handler}
+
+	if opts.TLSClientCAFile != "" {
+		caData, err := os.ReadFile(opts.TLSClientCAFile)
+		if err != nil {
+			return fmt.Errorf("unable to read client CA file %q: %w", opts.TLSClientCAFile, err)
+		}
+
+		clientCAs := x509.NewCertPool()
+		if !clientCAs.AppendCertsFromPEM(caData) {
+			return fmt.Errorf("no certificates found in the client CA file %q", opts.TLSClientCAFile)
+		}
+
+		// Clients without certificate are still allowed to authenticate with the bearer token
+		server.TLSConfig = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.VerifyClientCertIfGiven}
+	}
+
+	errCh := make(chan error, 2)
+
+	if opts.MetricsAddress != "" {
+		handler.ServeMetrics = false
+
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", handler.MetricsHandler())
+		metricsServer := &http.Server{Addr: opts.MetricsAddress, Handler: metricsMux}
+
+		go func() {
+			errCh <- fmt.Errorf("metrics server error: %w", metricsServer.ListenAndServe())
+		}()
+	}
+
+	go func() {
+		if opts.TLSCertFile == "" {
+			errCh <- server.ListenAndServe()
+		} else {
+			errCh <- server.ListenAndServeTLS(opts.TLSCertFile, opts.TLSKeyFile)
+		}
+	}()
+
+	return <-errCh
 }
 
 type SynchronizationServerHandler struct {
@@ -24,6 +82,10 @@ type SynchronizationServerHandler struct {
 
 	DistributedLockerBackendFactoryFunc func(clientID string) (distributed_locker.DistributedLockerBackend, error)
 	StagesStorageCacheFactoryFunc       func(clientID string) (StagesStorageCacheInterface, error)
+	AuthConfig                          *AuthConfig
+	Metrics                             *Metrics
+	// ServeMetrics enables /metrics on the handler, the metrics require authentication if the AuthConfig is set.
+	ServeMetrics bool
 
 	mux                             sync.Mutex
 	SynchronizationServerByClientID map[string]*SynchronizationServerHandlerByClientID
@@ -35,13 +97,47 @@ func NewSynchronizationServerHandler(distributedLockerBackendFactoryFunc func(cl
 		DistributedLockerBackendFactoryFunc: distributedLockerBackendFactoryFunc,
 		StagesStorageCacheFactoryFunc:       stagesStorageCacheFactoryFunc,
 		SynchronizationServerByClientID:     make(map[string]*SynchronizationServerHandlerByClientID),
+		Metrics:                             NewMetrics(),
+		ServeMetrics:                        true,
 	}
 	srv.HandleFunc("/health", srv.handleHealth)
-	srv.HandleFunc("/new-client-id", srv.handleNewClientID)
+	srv.HandleFunc("/metrics", srv.withAuth(func(w http.ResponseWriter, r *http.Request, _ string) {
+		if !srv.ServeMetrics {
+			http.NotFound(w, r)
+			return
+		}
+		srv.MetricsHandler().ServeHTTP(w, r)
+	}))
+	srv.HandleFunc("/new-client-id", srv.withAuth(func(w http.ResponseWriter, r *http.Request, _ string) {
+		srv.handleNewClientID(w, r)
+	}))
 	srv.HandleFunc("/", srv.handleRequestByClientID)
 	return srv
 }
 
+func (server *SynchronizationServerHandler) MetricsHandler() http.Handler {
+	return promhttp.HandlerFor(server.Metrics.Registry, promhttp.HandlerOpts{})
+}
+
+// withAuth passes the name of the authenticated project to the handler, the project is empty if the authentication is disabled.
+func (server *SynchronizationServerHandler) withAuth(handlerFunc func(w http.ResponseWriter, r *http.Request, project string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if server.AuthConfig == nil {
+			handlerFunc(w, r, "")
+			return
+		}
+
+		project, ok := server.AuthConfig.Authenticate(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="werf-synchronization"`)
+			http.Error(w, "Unauthorized: valid bearer token or client certificate required", http.StatusUnauthorized)
+			return
+		}
+
+		handlerFunc(w, r, project)
+	}
+}
+
 type HealthRequest struct {
 	Echo string `json:"echo"`
 }
@@ -111,41 +207,60 @@ func (server *SynchronizationServerHandler) handleRequestByClientID(w http.Respo
 		return
 	}
 
-	clientID := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
-	logboek.Debug().LogF("SynchronizationServerHandler -- ServeHTTP clientID = %q\n", clientID)
+	server.withAuth(func(w http.ResponseWriter, r *http.Request, project string) {
+		clientID := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)[0]
+		logboek.Debug().LogF("SynchronizationServerHandler -- ServeHTTP clientID = %q\n", clientID)
 
-	if clientID == "" {
-		http.Error(w, fmt.Sprintf("Bad request: cannot get clientID from URL path %q", r.URL.Path), http.StatusBadRequest)
-		return
-	}
+		if clientID == "" {
+			http.Error(w, fmt.Sprintf("Bad request: cannot get clientID from URL path %q", r.URL.Path), http.StatusBadRequest)
+			return
+		}
 
-	if clientServer, err := server.getOrCreateHandlerByClientID(clientID); err != nil {
-		http.Error(w, fmt.Sprintf("Internal error: %s", err), http.StatusInternalServerError)
-		return
-	} else {
-		http.StripPrefix(fmt.Sprintf("/%s", clientID), clientServer).ServeHTTP(w, r)
+		if clientServer, err := server.getOrCreateHandlerByClientID(project, clientID); err != nil {
+			http.Error(w, fmt.Sprintf("Internal error: %s", err), http.StatusInternalServerError)
+			return
+		} else {
+			http.StripPrefix(fmt.Sprintf("/%s", clientID), clientServer).ServeHTTP(w, r)
+		}
+	})(w, r)
+}
+
+// getNamespace returns the key of the client synchronization data.
+// Data of the authenticated project is isolated from other projects even if the client ID of another project is known.
+func getNamespace(project, clientID string) string {
+	if project == "" {
+		return clientID
 	}
+	return fmt.Sprintf("%s.%s", project, clientID)
 }
 
-func (server *SynchronizationServerHandler) getOrCreateHandlerByClientID(clientID string) (*SynchronizationServerHandlerByClientID, error) {
+func (server *SynchronizationServerHandler) getOrCreateHandlerByClientID(project, clientID string) (*SynchronizationServerHandlerByClientID, error) {
 	server.mux.Lock()
 	defer server.mux.Unlock()
 
-	if handler, hasKey := server.SynchronizationServerByClientID[clientID]; hasKey {
+	namespace := getNamespace(project, clientID)
+
+	if handler, hasKey := server.SynchronizationServerByClientID[namespace]; hasKey {
 		return handler, nil
 	} else {
-		distributedLockerBackend, err := server.DistributedLockerBackendFactoryFunc(clientID)
+		distributedLockerBackend, err := server.DistributedLockerBackendFactoryFunc(namespace)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create distributed locker backend for clientID %q: %w", clientID, err)
 		}
 
-		stagesStorageCache, err := server.StagesStorageCacheFactoryFunc(clientID)
+		stagesStorageCache, err := server.StagesStorageCacheFactoryFunc(namespace)
 		if err != nil {
 			return nil, fmt.Errorf("unable to create stages storage cache for clientID %q: %w", clientID, err)
 		}
 
-		handler := NewSynchronizationServerHandlerByClientID(clientID, distributedLockerBackend, stagesStorageCache)
-		server.SynchronizationServerByClientID[clientID] = handler
+		// Backends, which cannot list their locks, are inspected by tracking the locks acquired through the server
+		if _, ok := distributedLockerBackend.(storage.LocksInspector); !ok {
+			distributedLockerBackend = NewLockTrackingBackend(distributedLockerBackend)
+		}
+		locksInspector := distributedLockerBackend.(storage.LocksInspector)
+
+		handler := NewSynchronizationServerHandlerByClientID(clientID, server.Metrics.InstrumentDistributedLockerBackend(project, distributedLockerBackend), server.Metrics.InstrumentStagesStorageCache(project, stagesStorageCache), locksInspector)
+		server.SynchronizationServerByClientID[namespace] = handler
 
 		logboek.Debug().LogF("SynchronizationServerHandler -- Created new synchronization server handler by clientID %q: %v\n", clientID, handler)
 		return handler, nil
@@ -158,16 +273,22 @@ type SynchronizationServerHandlerByClientID struct {
 
 	DistributedLockerBackend distributed_locker.DistributedLockerBackend
 	StagesStorageCache       StagesStorageCacheInterface
+	LocksInspector           storage.LocksInspector
 }
 
-func NewSynchronizationServerHandlerByClientID(clientID string, distributedLockerBackend distributed_locker.DistributedLockerBackend, stagesStorageCache StagesStorageCacheInterface) *SynchronizationServerHandlerByClientID {
+func NewSynchronizationServerHandlerByClientID(clientID string, distributedLockerBackend distributed_locker.DistributedLockerBackend, stagesStorageCache StagesStorageCacheInterface, locksInspector storage.LocksInspector) *SynchronizationServerHandlerByClientID {
 	srv := &SynchronizationServerHandlerByClientID{
 		ServeMux:                 http.NewServeMux(),
 		ClientID:                 clientID,
 		DistributedLockerBackend: distributedLockerBackend,
 		StagesStorageCache:       stagesStorageCache,
+		LocksInspector:           locksInspector,
 	}
-	srv.Handle("/locker/", http.StripPrefix("/locker", distributed_locker.NewHttpBackendHandler(srv.DistributedLockerBackend)))
+	srv.Handle("/locker/", http.StripPrefix("/locker", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		backend := withLockOwner(srv.DistributedLockerBackend, r.Header.Get(LockOwnerHeader))
+		distributed_locker.NewHttpBackendHandler(backend).ServeHTTP(w, r)
+	})))
+	srv.Handle("/locks/", http.StripPrefix("/locks", NewLocksHttpHandler(srv.LocksInspector)))
 	srv.Handle("/stages-storage-cache/v1/", http.StripPrefix("/stages-storage-cache/v1", NewStagesStorageCacheHttpHandler(stagesStorageCache)))
 	srv.Handle("/stages-storage-cache/", http.StripPrefix("/stages-storage-cache", NewStagesStorageCacheHttpHandlerLegacy(stagesStorageCache)))
 	return srv
@@ -0,0 +1,186 @@
+package synchronization_server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/werf/lockgate"
+	"github.com/werf/lockgate/pkg/distributed_locker"
+	"github.com/werf/lockgate/pkg/distributed_locker/optimistic_locking_store"
+)
+
+func newTestServer(t *testing.T, authConfig *AuthConfig) (*httptest.Server, *[]string) {
+	var namespaces []string
+
+	handler := NewSynchronizationServerHandler(func(clientID string) (distributed_locker.DistributedLockerBackend, error) {
+		namespaces = append(namespaces, clientID)
+		return distributed_locker.NewOptimisticLockingStorageBasedBackend(optimistic_locking_store.NewInMemoryStore()), nil
+	}, func(clientID string) (StagesStorageCacheInterface, error) {
+		return nil, nil
+	})
+	handler.AuthConfig = authConfig
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return server, &namespaces
+}
+
+func newTestLocker(server *httptest.Server, clientID, token string) *distributed_locker.DistributedLocker {
+	httpClient, _ := NewHttpClient(HttpClientOptions{Token: token})
+	return distributed_locker.NewDistributedLocker(&distributed_locker.HttpBackend{
+		URLEndpoint: server.URL + "/" + clientID + "/locker",
+		HttpClient:  httpClient,
+	})
+}
+
+func TestSynchronizationServerAuth(t *testing.T) {
+	authConfig := &AuthConfig{Projects: []*AuthConfigProject{
+		{Name: "project-a", Tokens: []string{"token-a"}},
+		{Name: "project-b", Tokens: []string{"token-b"}},
+	}}
+	if err := authConfig.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	server, namespaces := newTestServer(t, authConfig)
+
+	for _, token := range []string{"", "bad-token"} {
+		httpClient, _ := NewHttpClient(HttpClientOptions{Token: token})
+		if _, err := NewSynchronizationClient(server.URL, httpClient).NewClientID(); err == nil || !strings.Contains(err.Error(), "401") {
+			t.Errorf("expected unauthorized error for token %q, got: %v", token, err)
+		}
+	}
+
+	httpClient, _ := NewHttpClient(HttpClientOptions{Token: "token-a"})
+	if _, err := NewSynchronizationClient(server.URL, httpClient).NewClientID(); err != nil {
+		t.Fatal(err)
+	}
+
+	if acquired, _, err := newTestLocker(server, "client", "token-a").Acquire("lock", lockgate.AcquireOptions{NonBlocking: true}); err != nil || !acquired {
+		t.Fatalf("expected the lock to be acquired, got acquired=%v err=%v", acquired, err)
+	}
+
+	// The same client ID of another project refers to another namespace
+	if acquired, _, err := newTestLocker(server, "client", "token-b").Acquire("lock", lockgate.AcquireOptions{NonBlocking: true}); err != nil || !acquired {
+		t.Fatalf("expected the lock of another project to be free, got acquired=%v err=%v", acquired, err)
+	}
+
+	if acquired, _, err := newTestLocker(server, "client", "token-a").Acquire("lock", lockgate.AcquireOptions{NonBlocking: true}); err != nil || acquired {
+		t.Fatalf("expected the lock to be busy, got acquired=%v err=%v", acquired, err)
+	}
+
+	if got := strings.Join(*namespaces, ","); got != "project-a.client,project-b.client" {
+		t.Errorf("unexpected namespaces %q", got)
+	}
+
+	resp, err := http.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized metrics request to be rejected, got status %d", resp.StatusCode)
+	}
+
+	resp, err = httpClient.Get(server.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range []string{
+		`werf_synchronization_lock_acquire_requests_total{project="project-a",result="acquired"} 1`,
+		`werf_synchronization_lock_acquire_requests_total{project="project-a",result="wait"} 1`,
+		`werf_synchronization_lock_acquire_requests_total{project="project-b",result="acquired"} 1`,
+	} {
+		if !strings.Contains(string(data), line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, data)
+		}
+	}
+}
+
+func TestAuthConfigValidate(t *testing.T) {
+	for _, config := range []*AuthConfig{
+		{Projects: []*AuthConfigProject{{Name: "project.a", Tokens: []string{"token"}}}},
+		{Projects: []*AuthConfigProject{{Name: "project", Tokens: []string{"token"}}, {Name: "project", Tokens: []string{"other"}}}},
+		{Projects: []*AuthConfigProject{{Name: "project"}}},
+		{Projects: []*AuthConfigProject{{Name: "project", Tokens: []string{""}}}},
+	} {
+		if err := config.Validate(); err == nil {
+			t.Errorf("expected validation error for project %+v", config.Projects)
+		}
+	}
+}
+
+func TestSynchronizationServerLocks(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestServer(t, nil)
+
+	httpClient, err := NewHttpClient(HttpClientOptions{LockOwner: "host/1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &distributed_locker.HttpBackend{URLEndpoint: server.URL + "/client/locker", HttpClient: httpClient}
+	locksClient := NewLocksHttpClient(server.URL+"/client/locks", httpClient)
+
+	handle, err := backend.Acquire("lock", distributed_locker.AcquireOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	locks, err := locksClient.ListLocks(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(locks) != 1 || locks[0].Name != "lock" || locks[0].UUID != handle.UUID || locks[0].OwnerClientID != "host/1" || locks[0].AcquiredAt.IsZero() {
+		t.Fatalf("unexpected locks %+v", locks)
+	}
+
+	if err := locksClient.ForceReleaseLock(ctx, "lock"); err != nil {
+		t.Fatal(err)
+	}
+
+	if locks, err := locksClient.ListLocks(ctx); err != nil {
+		t.Fatal(err)
+	} else if len(locks) != 0 {
+		t.Errorf("expected no locks after force release, got %+v", locks)
+	}
+
+	if _, err := backend.Acquire("lock", distributed_locker.AcquireOptions{}); err != nil {
+		t.Errorf("expected the force released lock to be free, got: %v", err)
+	}
+
+	if err := locksClient.ForceReleaseLock(ctx, "unknown"); err == nil {
+		t.Errorf("expected error releasing unknown lock")
+	}
+}
+
+func TestLockWaitsRemoveStale(t *testing.T) {
+	now := time.Now()
+	waits := &lockWaits{waitingSince: map[string]*lockWait{
+		"polled":    {since: now.Add(-time.Hour), lastRefusedAt: now.Add(-time.Second)},
+		"abandoned": {since: now.Add(-time.Hour), lastRefusedAt: now.Add(-lockWaitTimeout - time.Second)},
+	}}
+
+	waits.removeStale(now)
+
+	if _, hasKey := waits.waitingSince["abandoned"]; hasKey {
+		t.Errorf("expected the wait of the gone clients to be removed")
+	}
+	if _, hasKey := waits.waitingSince["polled"]; !hasKey {
+		t.Errorf("expected the wait of the polling clients to be kept")
+	}
+}
@@ -27,6 +27,20 @@ func TestParseKubernetesSynchronization(t *testing.T) {
 	})
 }
 
+func TestParseFileDbSynchronization(t *testing.T) {
+	for _, address := range []string{"file://allo", "file+db://"} {
+		if dir, err := ParseFileDbSynchronization(address); err != ErrBadFileDbSynchronizationAddress {
+			t.Errorf("unexpected parse response for %q: dir=%q err=%v", address, dir, err)
+		}
+	}
+
+	if dir, err := ParseFileDbSynchronization("file+db:///var/lib/werf/synchronization"); err != nil {
+		t.Error(err)
+	} else if dir != "/var/lib/werf/synchronization" {
+		t.Errorf("expected dir %q, got %q", "/var/lib/werf/synchronization", dir)
+	}
+}
+
 func checkKubernetesSynchronization(t *testing.T, address string, expected *KubernetesSynchronizationParams) {
 	params, err := ParseKubernetesSynchronization(address)
 	if err != nil {
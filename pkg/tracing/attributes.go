@@ -0,0 +1,16 @@
+package tracing
+
+import "go.opentelemetry.io/otel/attribute"
+
+const (
+	CommandKey        = attribute.Key("werf.command")
+	ProjectKey        = attribute.Key("werf.project")
+	PhaseKey          = attribute.Key("werf.phase")
+	ImageNameKey      = attribute.Key("werf.image.name")
+	TargetPlatformKey = attribute.Key("werf.image.target_platform")
+	StageNameKey      = attribute.Key("werf.stage.name")
+	StageDigestKey    = attribute.Key("werf.stage.digest")
+	ReleaseKey        = attribute.Key("werf.release")
+	NamespaceKey      = attribute.Key("k8s.namespace.name")
+	ResourcesKey      = attribute.Key("werf.resources")
+)
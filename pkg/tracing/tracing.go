@@ -0,0 +1,193 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/werf/werf/pkg/werf"
+)
+
+// Tracing is configured with the standard OpenTelemetry exporter environment variables
+// (https://opentelemetry.io/docs/specs/otel/protocol/exporter/) and is enabled when the OTLP endpoint is set.
+const (
+	EndpointEnv       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	TracesEndpointEnv = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+	ProtocolEnv       = "OTEL_EXPORTER_OTLP_PROTOCOL"
+	TracesProtocolEnv = "OTEL_EXPORTER_OTLP_TRACES_PROTOCOL"
+	// TraceParentEnv is the W3C traceparent of the CI job span, werf spans are created as its children.
+	TraceParentEnv = "TRACEPARENT"
+	// DisableEnv allows to disable tracing even if the OTLP endpoint is set for other tools.
+	DisableEnv = "WERF_DISABLE_TRACING"
+
+	ProtocolGRPC         = "grpc"
+	ProtocolHTTPProtobuf = "http/protobuf"
+
+	instrumentationName = "github.com/werf/werf"
+)
+
+var tracerProvider *sdktrace.TracerProvider
+
+func IsEnabled() bool {
+	if os.Getenv(DisableEnv) == "1" || os.Getenv(DisableEnv) == "true" {
+		return false
+	}
+	return os.Getenv(TracesEndpointEnv) != "" || os.Getenv(EndpointEnv) != ""
+}
+
+func Init(ctx context.Context) error {
+	if !IsEnabled() || tracerProvider != nil {
+		return nil
+	}
+
+	exporter, err := newExporter(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			attribute.String("service.name", "werf"),
+			attribute.String("service.version", werf.Version),
+		),
+		// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults above.
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+		resource.WithHost(),
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create tracing resource: %w", err)
+	}
+
+	tracerProvider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	return nil
+}
+
+func newExporter(ctx context.Context) (*otlptrace.Exporter, error) {
+	protocol := os.Getenv(TracesProtocolEnv)
+	if protocol == "" {
+		protocol = os.Getenv(ProtocolEnv)
+	}
+
+	// Endpoint, headers, certificates, compression and timeout are read by the exporters from the environment.
+	switch protocol {
+	case ProtocolGRPC:
+		return otlptracegrpc.New(ctx)
+	case ProtocolHTTPProtobuf, "":
+		return otlptracehttp.New(ctx)
+	default:
+		return nil, fmt.Errorf("unsupported OTLP protocol %q: %q or %q expected", protocol, ProtocolGRPC, ProtocolHTTPProtobuf)
+	}
+}
+
+// Shutdown sends all remaining spans to the collector, spans started after the shutdown are not recorded.
+func Shutdown(ctx context.Context) error {
+	if tracerProvider == nil {
+		return nil
+	}
+
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		return fmt.Errorf("unable to shutdown tracer provider: %w", err)
+	}
+	return nil
+}
+
+func getTracerProvider() trace.TracerProvider {
+	if tracerProvider == nil {
+		return trace.NewNoopTracerProvider()
+	}
+	return tracerProvider
+}
+
+// Start creates the span which is the child of the span from the context (if any).
+func Start(ctx context.Context, spanName string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return getTracerProvider().Tracer(instrumentationName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// End records the error (if any) and ends the span.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Do runs the function inside the span.
+func Do(ctx context.Context, spanName string, attrs []attribute.KeyValue, f func(ctx context.Context) error) error {
+	ctx, span := Start(ctx, spanName, attrs...)
+	err := f(ctx)
+	End(span, err)
+	return err
+}
+
+// ContextWithParentFromEnv makes werf spans the children of the span passed with the TRACEPARENT environment variable.
+func ContextWithParentFromEnv(ctx context.Context) context.Context {
+	traceParent := os.Getenv(TraceParentEnv)
+	if traceParent == "" {
+		return ctx
+	}
+
+	return propagation.TraceContext{}.Extract(ctx, propagation.MapCarrier{"traceparent": strings.TrimSpace(traceParent)})
+}
+
+// ContextWithSpanFrom returns the context with the span from the other context, it is useful when the library
+// calls werf code with the context.Background().
+func ContextWithSpanFrom(ctx, spanCtx context.Context) context.Context {
+	if spanCtx == nil || trace.SpanFromContext(ctx).SpanContext().IsValid() {
+		return ctx
+	}
+	return trace.ContextWithSpan(ctx, trace.SpanFromContext(spanCtx))
+}
+
+// NewTransport creates span for each HTTP request made with the transport inside the werf span.
+// The trace context is not propagated to the remote side.
+func NewTransport(transport http.RoundTripper) http.RoundTripper {
+	if !IsEnabled() {
+		return transport
+	}
+
+	return otelhttp.NewTransport(transport,
+		otelhttp.WithTracerProvider(lazyTracerProvider{}),
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator()),
+		otelhttp.WithFilter(func(r *http.Request) bool {
+			return trace.SpanFromContext(r.Context()).SpanContext().IsValid()
+		}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return fmt.Sprintf("HTTP %s %s", r.Method, r.URL.Host)
+		}),
+	)
+}
+
+// lazyTracerProvider allows to create the transport before the tracing is initialized.
+type lazyTracerProvider struct{}
+
+func (lazyTracerProvider) Tracer(name string, opts ...trace.TracerOption) trace.Tracer {
+	return lazyTracer{name: name, opts: opts}
+}
+
+type lazyTracer struct {
+	name string
+	opts []trace.TracerOption
+}
+
+func (t lazyTracer) Start(ctx context.Context, spanName string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	return getTracerProvider().Tracer(t.name, t.opts...).Start(ctx, spanName, opts...)
+}
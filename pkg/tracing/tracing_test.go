@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	t.Cleanup(func() { tracerProvider = nil })
+	return recorder
+}
+
+func TestIsEnabled(t *testing.T) {
+	t.Setenv(EndpointEnv, "")
+	t.Setenv(TracesEndpointEnv, "")
+	if IsEnabled() {
+		t.Errorf("expected tracing to be disabled without endpoint")
+	}
+
+	t.Setenv(TracesEndpointEnv, "http://localhost:4318")
+	if !IsEnabled() {
+		t.Errorf("expected tracing to be enabled with endpoint")
+	}
+
+	t.Setenv(DisableEnv, "1")
+	if IsEnabled() {
+		t.Errorf("expected tracing to be disabled with %s", DisableEnv)
+	}
+}
+
+func TestDo(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	t.Setenv(TraceParentEnv, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithParentFromEnv(context.Background())
+
+	expectedErr := errors.New("stage failed")
+	err := Do(ctx, "parent", nil, func(ctx context.Context) error {
+		return Do(ctx, "child", nil, func(ctx context.Context) error {
+			return expectedErr
+		})
+	})
+	if !errors.Is(err, expectedErr) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+
+	child, parent := spans[0], spans[1]
+	if parent.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || parent.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("expected parent span to be the child of TRACEPARENT, got %s", parent.Parent().SpanID())
+	}
+	if child.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Errorf("expected child span to be the child of parent span")
+	}
+	if child.Status().Code != codes.Error || child.Status().Description != expectedErr.Error() {
+		t.Errorf("unexpected child span status: %v", child.Status())
+	}
+}
+
+func TestContextWithSpanFrom(t *testing.T) {
+	setupRecorder(t)
+
+	spanCtx, span := Start(context.Background(), "deploy")
+	defer span.End()
+
+	ctx := ContextWithSpanFrom(context.Background(), spanCtx)
+	if trace.SpanFromContext(ctx).SpanContext().SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("expected span from the other context")
+	}
+
+	ownCtx, ownSpan := Start(context.Background(), "own")
+	defer ownSpan.End()
+	if trace.SpanFromContext(ContextWithSpanFrom(ownCtx, spanCtx)).SpanContext().SpanID() != ownSpan.SpanContext().SpanID() {
+		t.Errorf("expected own span to be kept")
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	recorder := setupRecorder(t)
+	t.Setenv(EndpointEnv, "http://localhost:4318")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("traceparent") != "" {
+			t.Errorf("unexpected trace context propagation")
+		}
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewTransport(http.DefaultTransport)}
+
+	doRequest := func(ctx context.Context) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	doRequest(context.Background())
+	if len(recorder.Ended()) != 0 {
+		t.Fatalf("expected no spans for the request without parent span")
+	}
+
+	ctx, span := Start(context.Background(), "registry")
+	doRequest(ctx)
+	span.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "HTTP GET "+server.Listener.Addr().String() {
+		t.Errorf("unexpected span name %q", spans[0].Name())
+	}
+}
@@ -70,6 +70,16 @@ func GetLastBranchCommitSHA(ctx context.Context, repoPath, branch string) (strin
 	return strings.TrimSpace(revParseCmd.OutBuf.String()), nil
 }
 
+// ResolveCommit returns the commit hash of the git revision (branch, tag, short hash, HEAD~1, etc.).
+func ResolveCommit(ctx context.Context, repoPath, rev string) (string, error) {
+	revParseCmd := NewGitCmd(ctx, &GitCmdOptions{RepoDir: repoPath}, "rev-parse", "--verify", "--quiet", rev+"^{commit}")
+	if err := revParseCmd.Run(ctx); err != nil {
+		return "", fmt.Errorf("unable to resolve git revision %q to a commit", rev)
+	}
+
+	return strings.TrimSpace(revParseCmd.OutBuf.String()), nil
+}
+
 func IsShallowClone(ctx context.Context, path string) (bool, error) {
 	if gitVersion.LessThan(semver.MustParse("2.15.0")) {
 		exist, err := util.FileExists(filepath.Join(path, ".git", "shallow"))
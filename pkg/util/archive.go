@@ -9,7 +9,9 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 
+	securejoin "github.com/cyphar/filepath-securejoin"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/filemode"
 	"github.com/go-git/go-git/v5/plumbing/format/index"
@@ -210,9 +212,38 @@ ArchiveCopying:
 
 type ExtractTarOptions struct {
 	UID, GID *uint32
+	// RootDir confines the extraction: entry paths, hardlink targets and symlinks met on the way are resolved inside it.
+	// The dstDir is used by default. The dstDir must be inside the RootDir.
+	RootDir string
+	// SkipHostEscapingSymlinks skips symlinks with absolute targets and relative targets leading out of the RootDir
+	// instead of failing, it is used when the extracted files are accessed on the host.
+	SkipHostEscapingSymlinks bool
+	// PreserveOwnership sets the uid and gid of the extracted entries from the tar headers.
+	PreserveOwnership bool
 }
 
+// ExtractTar extracts the tar into the dstDir. Entries, hardlinks and symlinks escaping the RootDir are rejected.
 func ExtractTar(tarFileReader io.Reader, dstDir string, opts ExtractTarOptions) error {
+	rootDir := opts.RootDir
+	if rootDir == "" {
+		rootDir = dstDir
+	}
+
+	relDstDir, err := filepath.Rel(rootDir, dstDir)
+	if err != nil || !isInsideDir(rootDir, dstDir) {
+		return fmt.Errorf("dir %q is not inside the root dir %q", dstDir, rootDir)
+	}
+
+	// resolvePath resolves the parent dirs of the tar entry inside the root dir, the entry itself is not followed.
+	resolvePath := func(name string) (string, error) {
+		name = filepath.Join(relDstDir, filepath.Clean("/"+name))
+		parentDir, err := securejoin.SecureJoin(rootDir, filepath.Dir(name))
+		if err != nil {
+			return "", fmt.Errorf("unable to resolve path %q: %w", name, err)
+		}
+		return filepath.Join(parentDir, filepath.Base(name)), nil
+	}
+
 	if err := os.MkdirAll(dstDir, os.ModePerm); err != nil {
 		return fmt.Errorf("unable to create dir %q: %w", dstDir, err)
 	}
@@ -230,40 +261,58 @@ func ExtractTar(tarFileReader io.Reader, dstDir string, opts ExtractTarOptions)
 			return fmt.Errorf("unable to Next() while extracting tar: %w", err)
 		}
 
-		tarEntryPath := filepath.Join(dstDir, tarEntryHeader.Name)
+		tarEntryPath, err := resolvePath(tarEntryHeader.Name)
+		if err != nil {
+			return err
+		}
 		tarEntryFileInfo := tarEntryHeader.FileInfo()
 
+		if tarEntryHeader.Typeflag != tar.TypeDir {
+			if err := os.MkdirAll(filepath.Dir(tarEntryPath), os.ModePerm); err != nil {
+				return fmt.Errorf("unable to create new directory %q while extracting tar: %w", filepath.Dir(tarEntryPath), err)
+			}
+
+			// The existing entry is replaced, not followed.
+			if info, err := os.Lstat(tarEntryPath); err == nil && !info.IsDir() {
+				if err := os.Remove(tarEntryPath); err != nil {
+					return fmt.Errorf("unable to remove %q while extracting tar: %w", tarEntryPath, err)
+				}
+			}
+		}
+
 		switch tarEntryHeader.Typeflag {
 		case tar.TypeDir:
 			if err = os.MkdirAll(tarEntryPath, tarEntryFileInfo.Mode()); err != nil {
 				return fmt.Errorf("unable to create new dir %q while extracting tar: %w", tarEntryPath, err)
 			}
 		case tar.TypeBlock, tar.TypeChar, tar.TypeReg, tar.TypeFifo:
-			if err := os.MkdirAll(filepath.Dir(tarEntryPath), os.ModePerm); err != nil {
-				return fmt.Errorf("unable to create new directory %q while extracting tar: %w", filepath.Dir(tarEntryPath), err)
-			}
-
-			file, err := os.OpenFile(tarEntryPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, tarEntryFileInfo.Mode())
+			file, err := os.OpenFile(tarEntryPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, tarEntryFileInfo.Mode())
 			if err != nil {
 				return fmt.Errorf("unable to create new file %q while extracting tar: %w", tarEntryPath, err)
 			}
-			defer file.Close()
 
 			_, err = io.Copy(file, tarReader)
+			file.Close()
 			if err != nil {
 				return fmt.Errorf("unable to create file %q while extracting tar: %w", tarEntryPath, err)
 			}
 		case tar.TypeLink:
-			if err := os.MkdirAll(filepath.Dir(tarEntryPath), os.ModePerm); err != nil {
-				return fmt.Errorf("unable to create new directory %q while extracting tar: %w", filepath.Dir(tarEntryPath), err)
+			linkTargetPath, err := securejoin.SecureJoin(rootDir, filepath.Join(relDstDir, filepath.Clean("/"+tarEntryHeader.Linkname)))
+			if err != nil {
+				return fmt.Errorf("unable to resolve hard link %q target %q: %w", tarEntryHeader.Name, tarEntryHeader.Linkname, err)
 			}
 
-			if err := os.Link(tarEntryHeader.Linkname, tarEntryPath); err != nil {
+			if err := os.Link(linkTargetPath, tarEntryPath); err != nil {
 				return fmt.Errorf("unable to create hard link %q while extracting tar: %w", tarEntryPath, err)
 			}
 		case tar.TypeSymlink:
-			if err := os.MkdirAll(filepath.Dir(tarEntryPath), os.ModePerm); err != nil {
-				return fmt.Errorf("unable to create new directory %q while extracting tar: %w", filepath.Dir(tarEntryPath), err)
+			// Absolute targets are interpreted inside the root dir, relative ones must not lead out of it.
+			isRelativeTargetEscaping := !filepath.IsAbs(tarEntryHeader.Linkname) && !isInsideDir(rootDir, filepath.Join(filepath.Dir(tarEntryPath), tarEntryHeader.Linkname))
+			if opts.SkipHostEscapingSymlinks && (filepath.IsAbs(tarEntryHeader.Linkname) || isRelativeTargetEscaping) {
+				continue
+			}
+			if isRelativeTargetEscaping {
+				return fmt.Errorf("symlink %q target %q is outside of the root dir", tarEntryHeader.Name, tarEntryHeader.Linkname)
 			}
 
 			if err := os.Symlink(tarEntryHeader.Linkname, tarEntryPath); err != nil {
@@ -273,8 +322,19 @@ func ExtractTar(tarFileReader io.Reader, dstDir string, opts ExtractTarOptions)
 			return fmt.Errorf("tar entry %q of unexpected type: %b", tarEntryHeader.Name, tarEntryHeader.Typeflag)
 		}
 
-		for _, p := range FilepathsWithParents(tarEntryHeader.Name) {
-			if err := Chown(filepath.Join(dstDir, p), opts.UID, opts.GID); err != nil {
+		if opts.PreserveOwnership {
+			if err := os.Lchown(tarEntryPath, tarEntryHeader.Uid, tarEntryHeader.Gid); err != nil {
+				return fmt.Errorf("unable to set owner and group to %d:%d for file %q: %w", tarEntryHeader.Uid, tarEntryHeader.Gid, tarEntryPath, err)
+			}
+		}
+
+		for _, p := range FilepathsWithParents(filepath.Clean("/" + tarEntryHeader.Name)) {
+			resolvedPath, err := resolvePath(p)
+			if err != nil {
+				return err
+			}
+
+			if err := lchown(resolvedPath, opts.UID, opts.GID); err != nil {
 				return fmt.Errorf("unable to chown file %q: %w", p, err)
 			}
 		}
@@ -303,6 +363,31 @@ func Chown(path string, uid, gid *uint32) error {
 	return nil
 }
 
+func isInsideDir(dir, path string) bool {
+	relPath, err := filepath.Rel(dir, path)
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator))
+}
+
+func lchown(path string, uid, gid *uint32) error {
+	if uid != nil || gid != nil {
+		osUid := -1
+		osGid := -1
+
+		if uid != nil {
+			osUid = int(*uid)
+		}
+		if gid != nil {
+			osGid = int(*gid)
+		}
+
+		if err := os.Lchown(path, osUid, osGid); err != nil {
+			return fmt.Errorf("unable to set owner and group to %d:%d for file %q: %w", osUid, osGid, path, err)
+		}
+	}
+
+	return nil
+}
+
 func WriteDirAsTar(dir string, w io.Writer) error {
 	tarWriter := tar.NewWriter(w)
 
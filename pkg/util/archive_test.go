@@ -0,0 +1,108 @@
+package util
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testTarEntry struct {
+	Header tar.Header
+	Data   string
+}
+
+func newTestTar(t *testing.T, entries []testTarEntry) *bytes.Buffer {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, entry := range entries {
+		hdr := entry.Header
+		hdr.Size = int64(len(entry.Data))
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if err := tw.WriteHeader(&hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.Data)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return &buf
+}
+
+func TestExtractTar(t *testing.T) {
+	tmpDir := t.TempDir()
+	rootDir := filepath.Join(tmpDir, "root")
+	outsideDir := filepath.Join(tmpDir, "outside")
+	if err := os.MkdirAll(outsideDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// The base image symlink must be resolved inside the root dir.
+	if err := os.MkdirAll(filepath.Join(rootDir, "usr"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outsideDir, filepath.Join(rootDir, "usr", "local")); err != nil {
+		t.Fatal(err)
+	}
+
+	dstDir := filepath.Join(rootDir, "app")
+	archive := newTestTar(t, []testTarEntry{
+		{Header: tar.Header{Name: "../../../escaped", Typeflag: tar.TypeReg}, Data: "escaped"},
+		{Header: tar.Header{Name: "file", Typeflag: tar.TypeReg}, Data: "file"},
+		{Header: tar.Header{Name: "hardlink", Typeflag: tar.TypeLink, Linkname: "file"}},
+		{Header: tar.Header{Name: "local", Typeflag: tar.TypeSymlink, Linkname: "/usr/local"}},
+		{Header: tar.Header{Name: "local/passwd", Typeflag: tar.TypeReg}, Data: "passwd"},
+	})
+
+	if err := ExtractTar(archive, dstDir, ExtractTarOptions{RootDir: rootDir}); err != nil {
+		t.Fatalf("ExtractTar() error: %s", err)
+	}
+
+	for _, p := range []string{filepath.Join(tmpDir, "escaped"), filepath.Join(outsideDir, "passwd")} {
+		if _, err := os.Lstat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %q not to be created outside of the root dir", p)
+		}
+	}
+
+	for p, expected := range map[string]string{
+		filepath.Join(dstDir, "escaped"):             "escaped",
+		filepath.Join(dstDir, "hardlink"):            "file",
+		filepath.Join(rootDir, outsideDir, "passwd"): "passwd",
+	} {
+		if data, err := os.ReadFile(p); err != nil {
+			t.Errorf("unable to read %q: %s", p, err)
+		} else if string(data) != expected {
+			t.Errorf("expected %q content %q, got %q", p, expected, data)
+		}
+	}
+}
+
+func TestExtractTarRejectsEscapingLinks(t *testing.T) {
+	outsideDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outsideDir, "secret"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, entry := range map[string]testTarEntry{
+		"relative symlink": {Header: tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "../../../../../../.."}},
+		"hardlink":         {Header: tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "../../../../../../.." + filepath.Join(outsideDir, "secret")}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			dstDir := t.TempDir()
+			if err := ExtractTar(newTestTar(t, []testTarEntry{entry}), dstDir, ExtractTarOptions{}); err == nil {
+				t.Fatalf("expected error")
+			}
+
+			if _, err := os.Lstat(filepath.Join(dstDir, "link")); !os.IsNotExist(err) {
+				t.Errorf("expected the link not to be created")
+			}
+		})
+	}
+}